@@ -5,6 +5,9 @@ import (
 	"os"
 
 	"github.com/danielpenchev98/UShare/web-client/internal/commands"
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/danielpenchev98/UShare/web-client/internal/session"
 )
 
 func main() {
@@ -34,6 +37,10 @@ func commandsWithHostURL(command string) {
 		commands.Login(hostURL)
 	case "register":
 		commands.RegisterUser(hostURL)
+	case "reset-password":
+		commands.ResetPassword(hostURL)
+	case "drop-file":
+		commands.DropFile(hostURL)
 	default:
 		commandsWithAuth(command, hostURL)
 	}
@@ -41,18 +48,69 @@ func commandsWithHostURL(command string) {
 
 func commandsWithAuth(command, hostURL string) {
 	token := os.Getenv("JWT")
-	if hostURL == "" {
-		fmt.Print("JWT hasnt been set. Please set this environment variable. To get its value, please login again")
+	if token == "" {
+		userSession, err := session.Load()
+		if err != nil {
+			fmt.Printf("Couldnt read the session. Reason: %s\n", err.Error())
+			return
+		}
+
+		token = userSession.Token
+		if userSession.RefreshToken != "" {
+			restclient.EnableTokenRefresh(hostURL+endpoints.RefreshTokenAPIEndpoint, userSession.RefreshToken, func(token, refreshToken string) error {
+				return session.Save(session.Session{Token: token, RefreshToken: refreshToken})
+			})
+		}
+	}
+
+	if token == "" {
+		fmt.Print("You arent logged in. Please login again or set the JWT environment variable to a JWT or a personal access token")
 		return
 	}
 
 	switch command {
+	case "logout":
+		commands.Logout(hostURL, token)
+	case "change-password":
+		commands.ChangePassword(hostURL, token)
+	case "enable-2fa":
+		commands.EnableTwoFactor(hostURL, token)
+	case "disable-2fa":
+		commands.DisableTwoFactor(hostURL, token)
+	case "delete-user":
+		commands.DeleteUser(hostURL, token)
+	case "create-token":
+		commands.CreateToken(hostURL, token)
+	case "tokens":
+		commands.ShowTokens(hostURL, token)
+	case "delete-token":
+		commands.DeleteToken(hostURL, token)
 	case "create-group":
 		commands.CreateGroup(hostURL, token)
 	case "delete-group":
 		commands.DeleteGroup(hostURL, token)
-	case "add-member":
-		commands.AddMember(hostURL, token)
+	case "set-visibility":
+		commands.SetGroupVisibility(hostURL, token)
+	case "join-group":
+		commands.JoinGroup(hostURL, token)
+	case "join-requests":
+		commands.ShowJoinRequests(hostURL, token)
+	case "approve-join":
+		commands.ApproveJoinRequest(hostURL, token)
+	case "reject-join":
+		commands.RejectJoinRequest(hostURL, token)
+	case "invite-member":
+		commands.InviteMember(hostURL, token)
+	case "invitations":
+		commands.ShowInvitations(hostURL, token)
+	case "accept-invite":
+		commands.AcceptInvitation(hostURL, token)
+	case "decline-invite":
+		commands.DeclineInvitation(hostURL, token)
+	case "transfer-group":
+		commands.TransferGroup(hostURL, token)
+	case "set-role":
+		commands.SetRole(hostURL, token)
 	case "remove-member":
 		commands.RemoveMember(hostURL, token)
 	case "upload-file":
@@ -63,12 +121,66 @@ func commandsWithAuth(command, hostURL string) {
 		commands.DeleteFile(hostURL, token)
 	case "show-all-files":
 		commands.ShowAllFilesInGroup(hostURL, token)
+	case "ls":
+		commands.ListFolder(hostURL, token)
+	case "mkdir":
+		commands.CreateFolder(hostURL, token)
+	case "rmdir":
+		commands.DeleteFolder(hostURL, token)
+	case "mv":
+		commands.Move(hostURL, token)
+	case "share-file":
+		commands.ShareFile(hostURL, token)
+	case "share-links":
+		commands.ShowShareLinks(hostURL, token)
+	case "revoke-share":
+		commands.RevokeShareLink(hostURL, token)
+	case "create-dropbox":
+		commands.CreateDropBox(hostURL, token)
+	case "dropboxes":
+		commands.ShowDropBoxes(hostURL, token)
+	case "delete-dropbox":
+		commands.DeleteDropBox(hostURL, token)
+	case "show-trash":
+		commands.ShowTrash(hostURL, token)
+	case "restore-file":
+		commands.RestoreTrashedFile(hostURL, token)
+	case "purge-file":
+		commands.PurgeTrashedFile(hostURL, token)
+	case "show-versions":
+		commands.ShowFileVersions(hostURL, token)
+	case "restore-version":
+		commands.RestoreFileVersion(hostURL, token)
+	case "set-max-versions":
+		commands.SetMaxFileVersions(hostURL, token)
+	case "show-usage":
+		commands.ShowUsage(hostURL, token)
+	case "audit":
+		commands.ShowGroupAuditLog(hostURL, token)
 	case "show-all-groups":
 		commands.ShowAllGroups(hostURL, token)
 	case "show-all-users":
 		commands.ShowAllUsers(hostURL, token)
 	case "show-all-members":
 		commands.ShowAllMembers(hostURL, token)
+	case "admin-users":
+		commands.AdminShowUsers(hostURL, token)
+	case "suspend-user":
+		commands.SuspendUser(hostURL, token)
+	case "unsuspend-user":
+		commands.UnsuspendUser(hostURL, token)
+	case "admin-reset-password":
+		commands.AdminResetPassword(hostURL, token)
+	case "admin-delete-user":
+		commands.AdminDeleteUser(hostURL, token)
+	case "admin-delete-group":
+		commands.AdminDeactivateGroup(hostURL, token)
+	case "admin-transfer-group":
+		commands.AdminTransferGroup(hostURL, token)
+	case "admin-storage":
+		commands.AdminShowStorage(hostURL, token)
+	case "admin-audit":
+		commands.AdminShowAuditLog(hostURL, token)
 	default:
 		fmt.Printf("Invalid command [%s]\n", command)
 		commands.Help()
@@ -0,0 +1,279 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//UserPayload - information used for the administration of a user
+type UserPayload struct {
+	Username string `json:"username"`
+}
+
+//SuspensionPayload - information used for the suspension of a user
+type SuspensionPayload struct {
+	UserPayload
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+//AdminDeleteUserPayload - information used for the deletion of the account of a user by an administrator
+type AdminDeleteUserPayload struct {
+	UserPayload
+	GroupPolicy string `json:"group_policy,omitempty"`
+	FilePolicy  string `json:"file_policy,omitempty"`
+}
+
+//PasswordResetTokenPayload - information used for issuing a password reset token
+type PasswordResetTokenPayload struct {
+	UserPayload
+	ExpiresInHours uint `json:"expires_in_hours,omitempty"`
+}
+
+//PasswordResetTokenResponse - response, containing the issued password reset token. This is the only time it is shown
+type PasswordResetTokenResponse struct {
+	Status    int       `json:"status"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+//AdminUserInfo - contains the details about a user, which are seen only by the administrators
+type AdminUserInfo struct {
+	ID               uint       `json:"id"`
+	Username         string     `json:"username"`
+	IsAdmin          bool       `json:"is_admin"`
+	CreatedAt        time.Time  `json:"created_at"`
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspensionReason string     `json:"suspension_reason"`
+	SuspendedUntil   *time.Time `json:"suspended_until"`
+	Groups           int64      `json:"groups"`
+	Files            int64      `json:"files"`
+	UsedBytes        int64      `json:"used_bytes"`
+}
+
+//AdminUsersResponse - response, containing the details about all users
+type AdminUsersResponse struct {
+	Status int             `json:"status"`
+	Users  []AdminUserInfo `json:"users"`
+}
+
+//StorageTotalsResponse - response, containing the system-wide number of users, groups and files and the storage used by them
+type StorageTotalsResponse struct {
+	Status      int   `json:"status"`
+	Users       int64 `json:"users"`
+	Groups      int64 `json:"groups"`
+	Files       int64 `json:"files"`
+	UsedBytes   int64 `json:"used_bytes"`
+	StoredBytes int64 `json:"stored_bytes"`
+}
+
+//AdminShowUsers - command for showing all users together with their role, status and used storage
+func AdminShowUsers(hostURL, token string) {
+	successBody := AdminUsersResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Get(hostURL+endpoints.AdminUsersAPIEndpoint, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the users. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(successBody.Users))
+	for _, user := range successBody.Users {
+		suspension := "-"
+		if user.SuspendedAt != nil {
+			until := "lifted manually"
+			if user.SuspendedUntil != nil {
+				until = "until " + user.SuspendedUntil.String()
+			}
+			suspension = fmt.Sprintf("%s (%s)", user.SuspensionReason, until)
+		}
+		tableRows = append(tableRows, table.Row{user.ID, user.Username, user.IsAdmin, user.CreatedAt, suspension, user.Groups, user.Files, user.UsedBytes})
+	}
+	PrintTable(table.Row{"ID", "Username", "Admin", "CreatedAt", "Suspension", "Groups", "Files", "UsedBytes"}, tableRows)
+}
+
+//SuspendUser - command for suspension of a user
+func SuspendUser(hostURL, token string) {
+	suspendUserCommand := flag.NewFlagSet("suspend-user", flag.ExitOnError)
+	username := suspendUserCommand.String("usr", "", "Name of the user")
+	reason := suspendUserCommand.String("reason", "", "Reason of the suspension, which is shown to the user")
+	until := suspendUserCommand.String("until", "", "End of the suspension - date (2006-01-02) or RFC 3339 timestamp. Until lifted manually by default")
+	suspendUserCommand.Parse(os.Args[2:])
+
+	if *username == "" || *reason == "" {
+		suspendUserCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := SuspensionPayload{
+		UserPayload: UserPayload{Username: *username},
+		Reason:      *reason,
+	}
+
+	if *until != "" {
+		end, err := parseMoment(*until)
+		if err != nil {
+			fmt.Printf("Invalid end of the suspension [%s]. Use a date (2006-01-02) or RFC 3339 timestamp\n", *until)
+			return
+		}
+		rqBody.Until = &end
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Put(hostURL+endpoints.AdminSuspensionAPIEndpoint, &rqBody, nil); err != nil {
+		fmt.Printf("Problem with the suspension of the user. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("User %s was suspended\n", *username)
+}
+
+//UnsuspendUser - command for lifting the suspension of a user
+func UnsuspendUser(hostURL, token string) {
+	unsuspendUserCommand := flag.NewFlagSet("unsuspend-user", flag.ExitOnError)
+	username := unsuspendUserCommand.String("usr", "", "Name of the user")
+	unsuspendUserCommand.Parse(os.Args[2:])
+
+	if *username == "" {
+		unsuspendUserCommand.PrintDefaults()
+		return
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Delete(hostURL+endpoints.AdminSuspensionAPIEndpoint, &UserPayload{Username: *username}, nil); err != nil {
+		fmt.Printf("Problem with lifting the suspension of the user. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("The suspension of user %s was lifted\n", *username)
+}
+
+//AdminResetPassword - command for issuing a one-time token, with which a user, who has forgotten the password, sets a new one
+func AdminResetPassword(hostURL, token string) {
+	resetPasswordCommand := flag.NewFlagSet("admin-reset-password", flag.ExitOnError)
+	username := resetPasswordCommand.String("usr", "", "Name of the user")
+	expiration := resetPasswordCommand.Uint("exp", 0, "After how many hours the token expires. 24 by default")
+	resetPasswordCommand.Parse(os.Args[2:])
+
+	if *username == "" {
+		resetPasswordCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := PasswordResetTokenPayload{
+		UserPayload:    UserPayload{Username: *username},
+		ExpiresInHours: *expiration,
+	}
+
+	successBody := PasswordResetTokenResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Post(hostURL+endpoints.AdminPasswordResetAPIEndpoint, &rqBody, &successBody); err != nil {
+		fmt.Printf("Problem with issuing the password reset token. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Password reset token for user %s, which expires at %s. Pass it to the user, it will not be shown again:\n%s\n", *username, successBody.ExpiresAt.Format(time.RFC3339), successBody.Token)
+}
+
+//AdminDeleteUser - command for deletion of the account of any user
+func AdminDeleteUser(hostURL, token string) {
+	deleteUserCommand := flag.NewFlagSet("admin-delete-user", flag.ExitOnError)
+	username := deleteUserCommand.String("usr", "", "Name of the user")
+	groupPolicy := deleteUserCommand.String("groups", "", "What happens with the groups of the user - transfer (default) or deactivate")
+	filePolicy := deleteUserCommand.String("files", "", "What happens with the files of the user in other groups - reassign (default) or delete")
+	deleteUserCommand.Parse(os.Args[2:])
+
+	if *username == "" {
+		deleteUserCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := AdminDeleteUserPayload{
+		UserPayload: UserPayload{Username: *username},
+		GroupPolicy: *groupPolicy,
+		FilePolicy:  *filePolicy,
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Delete(hostURL+endpoints.AdminUserAPIEndpoint, &rqBody, nil); err != nil {
+		fmt.Printf("Problem with the user deletion request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("User %s was deleted\n", *username)
+}
+
+//AdminDeactivateGroup - command for deletion of any group
+func AdminDeactivateGroup(hostURL, token string) {
+	deleteGroupCommand := flag.NewFlagSet("admin-delete-group", flag.ExitOnError)
+	groupName := deleteGroupCommand.String("grp", "", "Name of the group")
+	deleteGroupCommand.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		deleteGroupCommand.PrintDefaults()
+		return
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Delete(hostURL+endpoints.AdminGroupAPIEndpoint, &GroupPayload{GroupName: *groupName}, nil); err != nil {
+		fmt.Printf("Problem with the group deletion request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Group %s was deleted\n", *groupName)
+}
+
+//AdminTransferGroup - command for making any user the owner of a group
+func AdminTransferGroup(hostURL, token string) {
+	transferGroupCommand := flag.NewFlagSet("admin-transfer-group", flag.ExitOnError)
+	username := transferGroupCommand.String("usr", "", "Name of the user, who will become the owner")
+	groupName := transferGroupCommand.String("grp", "", "Name of the group")
+	transferGroupCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *username == "" {
+		transferGroupCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := MembershipRequest{
+		Username: *username,
+	}
+	rqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Put(hostURL+endpoints.AdminGroupOwnershipAPIEndpoint, &rqBody, nil); err != nil {
+		fmt.Printf("Problem with the ownership transfer request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("User %s is now the owner of group %s\n", *username, *groupName)
+}
+
+//AdminShowStorage - command for showing the system-wide number of users, groups and files and the storage used by them
+func AdminShowStorage(hostURL, token string) {
+	successBody := StorageTotalsResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Get(hostURL+endpoints.AdminStorageAPIEndpoint, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the storage totals. %s\n", err.Error())
+		return
+	}
+
+	PrintTable(table.Row{"Users", "Groups", "Files", "UsedBytes", "StoredBytes"},
+		[]table.Row{{successBody.Users, successBody.Groups, successBody.Files, successBody.UsedBytes, successBody.StoredBytes}})
+}
+
+func parseMoment(moment string) (time.Time, error) {
+	if date, err := time.ParseInLocation("2006-01-02", moment, time.Local); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, moment)
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//AuditEventInfo - contains the details about an action, recorded in the audit log
+type AuditEventInfo struct {
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ActorID    uint      `json:"actor_id"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	GroupName  string    `json:"group_name"`
+	FileID     uint      `json:"file_id"`
+	TargetUser string    `json:"target_user"`
+	IP         string    `json:"ip"`
+	Outcome    string    `json:"outcome"`
+	StatusCode int       `json:"status_code"`
+}
+
+//AuditEventsResponse - response, containing the audit events, starting from the latest one
+type AuditEventsResponse struct {
+	Status int              `json:"status"`
+	Events []AuditEventInfo `json:"events"`
+}
+
+//auditFilterFlags - the flags, common for the commands, which query the audit log
+type auditFilterFlags struct {
+	action     *string
+	actor      *string
+	targetUser *string
+	outcome    *string
+	from       *string
+	to         *string
+	limit      *int
+}
+
+func addAuditFilterFlags(command *flag.FlagSet) auditFilterFlags {
+	return auditFilterFlags{
+		action:     command.String("action", "", "Only events of this action, for instance file.download"),
+		actor:      command.String("actor", "", "Only events of this user"),
+		targetUser: command.String("target", "", "Only events, targeting this user"),
+		outcome:    command.String("outcome", "", "Only events with this outcome - success or failure"),
+		from:       command.String("from", "", "Only events after this moment - date (2006-01-02) or RFC 3339 timestamp"),
+		to:         command.String("to", "", "Only events before this moment - date (2006-01-02) or RFC 3339 timestamp"),
+		limit:      command.Int("limit", 0, "Maximum number of the latest events. 100 by default"),
+	}
+}
+
+//query - converts the filter into the query parameters of the request
+func (f auditFilterFlags) query() (url.Values, error) {
+	query := url.Values{}
+	for name, value := range map[string]string{"action": *f.action, "actor": *f.actor, "target_user": *f.targetUser, "outcome": *f.outcome} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+
+	for name, value := range map[string]string{"from": *f.from, "to": *f.to} {
+		if value == "" {
+			continue
+		}
+		moment, err := parseMoment(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s [%s]. Use a date (2006-01-02) or RFC 3339 timestamp", name, value)
+		}
+		query.Set(name, moment.Format(time.RFC3339))
+	}
+
+	if *f.limit != 0 {
+		query.Set("limit", strconv.Itoa(*f.limit))
+	}
+	return query, nil
+}
+
+//ShowGroupAuditLog - command for showing the audit log of a group. Only for the group owner
+func ShowGroupAuditLog(hostURL, token string) {
+	auditCommand := flag.NewFlagSet("audit", flag.ExitOnError)
+	groupName := auditCommand.String("grp", "", "Name of the group")
+	filter := addAuditFilterFlags(auditCommand)
+	auditCommand.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		auditCommand.PrintDefaults()
+		return
+	}
+
+	query, err := filter.query()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	query.Set("group_name", *groupName)
+
+	successBody := AuditEventsResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	if err = restClient.Get(hostURL+endpoints.GroupAuditAPIEndpoint+"?"+query.Encode(), &successBody); err != nil {
+		fmt.Printf("Problem with the retrieval of the audit log of the group. %s\n", err.Error())
+		return
+	}
+
+	printAuditEvents(successBody.Events)
+}
+
+//AdminShowAuditLog - command for showing or exporting the audit log of the whole server. Only for administrators
+func AdminShowAuditLog(hostURL, token string) {
+	auditCommand := flag.NewFlagSet("admin-audit", flag.ExitOnError)
+	groupName := auditCommand.String("grp", "", "Only events of this group")
+	targetPath := auditCommand.String("csv", "", "Path to the file, where the events are exported in csv format, instead of being shown")
+	filter := addAuditFilterFlags(auditCommand)
+	auditCommand.Parse(os.Args[2:])
+
+	query, err := filter.query()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if *groupName != "" {
+		query.Set("group_name", *groupName)
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	if *targetPath != "" {
+		query.Set("format", "csv")
+		if err = restClient.DownloadFile(hostURL+endpoints.AdminAuditAPIEndpoint+"?"+query.Encode(), *targetPath); err != nil {
+			fmt.Printf("Problem with the export of the audit log. %s\n", err.Error())
+			return
+		}
+		fmt.Printf("The audit log was exported to %s\n", *targetPath)
+		return
+	}
+
+	successBody := AuditEventsResponse{}
+	if err = restClient.Get(hostURL+endpoints.AdminAuditAPIEndpoint+"?"+query.Encode(), &successBody); err != nil {
+		fmt.Printf("Problem with the retrieval of the audit log. %s\n", err.Error())
+		return
+	}
+
+	printAuditEvents(successBody.Events)
+}
+
+func printAuditEvents(events []AuditEventInfo) {
+	tableRows := make([]table.Row, 0, len(events))
+	for _, event := range events {
+		actor := event.Actor
+		if actor == "" {
+			actor = "-"
+		}
+		tableRows = append(tableRows, table.Row{event.ID, event.CreatedAt, actor, event.Action, event.GroupName, event.FileID, event.TargetUser, event.IP, event.Outcome, event.StatusCode})
+	}
+	PrintTable(table.Row{"ID", "CreatedAt", "Actor", "Action", "Group", "FileID", "TargetUser", "IP", "Outcome", "Status"}, tableRows)
+}
@@ -1,7 +1,10 @@
 package commands
 
 import (
+	"bufio"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 )
@@ -19,3 +22,13 @@ func PrintTable(columNames table.Row, records []table.Row) {
 	t.AppendRows(records)
 	t.Render()
 }
+
+//ReadLine - shows the prompt and reads a line from the standard input
+func ReadLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//DropBoxPayload - information used for the creation of a drop box
+type DropBoxPayload struct {
+	FolderRequest
+	MaxFileSize    int64 `json:"max_file_size"`
+	MaxFiles       uint  `json:"max_files"`
+	ExpiresInHours uint  `json:"expires_in_hours"`
+}
+
+//DropBoxRequestPayload - information used for the deletion of a drop box
+type DropBoxRequestPayload struct {
+	GroupPayload
+	DropBoxID uint `json:"drop_box_id"`
+}
+
+//DropBoxResponse - response, containing the newly created drop box
+type DropBoxResponse struct {
+	Status int    `json:"status"`
+	ID     uint   `json:"id"`
+	Token  string `json:"token"`
+}
+
+//DropBoxInfo - contains all information about a drop box, except its token
+type DropBoxInfo struct {
+	ID          uint       `json:"id"`
+	FolderID    uint       `json:"folder_id"`
+	FolderName  string     `json:"folder_name"`
+	Creator     string     `json:"creator"`
+	MaxFileSize int64      `json:"max_file_size"`
+	MaxFiles    uint       `json:"max_files"`
+	Files       int64      `json:"files"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+//DropBoxesResponse - response, containing the drop boxes of a group
+type DropBoxesResponse struct {
+	Status    int           `json:"status"`
+	DropBoxes []DropBoxInfo `json:"drop_boxes"`
+}
+
+//CreateDropBox - command for creation of a link, through which people without an account send files to a folder of the group
+func CreateDropBox(hostURL, token string) {
+	createDropBoxCommand := flag.NewFlagSet("create-dropbox", flag.ExitOnError)
+	groupName := createDropBoxCommand.String("grp", "", "Name of the group")
+	folderPath := createDropBoxCommand.String("folder", "", "Path of the folder, in which the files are received (the root of the group by default)")
+	maxFileSize := createDropBoxCommand.Int64("size", 0, "Maximum size of a file in bytes")
+	maxFiles := createDropBoxCommand.Uint("max", 0, "How many files can be received through the drop box")
+	expiresInHours := createDropBoxCommand.Uint("exp", 0, "After how many hours the drop box expires")
+
+	createDropBoxCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *maxFileSize < 0 {
+		createDropBoxCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := DropBoxPayload{
+		FolderRequest: FolderRequest{
+			Path: *folderPath,
+		},
+		MaxFileSize:    *maxFileSize,
+		MaxFiles:       *maxFiles,
+		ExpiresInHours: *expiresInHours,
+	}
+	reqBody.GroupName = *groupName
+
+	successBody := DropBoxResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Post(hostURL+endpoints.DropBoxesAPIEndpoint, &reqBody, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the creation of the drop box. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Drop box with id [%d] was created. Its token wont be shown again:\n%s\n", successBody.ID, successBody.Token)
+}
+
+//ShowDropBoxes - command for showing the drop boxes of a group
+func ShowDropBoxes(hostURL, token string) {
+	showDropBoxesCommand := flag.NewFlagSet("dropboxes", flag.ExitOnError)
+	groupName := showDropBoxesCommand.String("grp", "", "Name of the group")
+
+	showDropBoxesCommand.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		showDropBoxesCommand.PrintDefaults()
+		return
+	}
+
+	successBody := DropBoxesResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	url := fmt.Sprintf("%s%s?group_name=%s", hostURL, endpoints.DropBoxesAPIEndpoint, *groupName)
+	err := restClient.Get(url, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the drop boxes. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(successBody.DropBoxes))
+	for _, dropBox := range successBody.DropBoxes {
+		expiresAt := "never"
+		if dropBox.ExpiresAt != nil {
+			expiresAt = dropBox.ExpiresAt.String()
+		}
+
+		files := fmt.Sprintf("%d", dropBox.Files)
+		if dropBox.MaxFiles > 0 {
+			files = fmt.Sprintf("%d/%d", dropBox.Files, dropBox.MaxFiles)
+		}
+		tableRows = append(tableRows, table.Row{dropBox.ID, dropBox.FolderID, dropBox.FolderName, dropBox.Creator, dropBox.MaxFileSize, expiresAt, files})
+	}
+	PrintTable(table.Row{"ID", "FolderID", "FolderName", "Creator", "MaxFileSize", "ExpiresAt", "Files"}, tableRows)
+}
+
+//DeleteDropBox - command for deletion of a drop box
+func DeleteDropBox(hostURL, token string) {
+	deleteDropBoxCommand := flag.NewFlagSet("delete-dropbox", flag.ExitOnError)
+	dropBoxID := deleteDropBoxCommand.Uint("id", 0, "Id of the drop box")
+	groupName := deleteDropBoxCommand.String("grp", "", "Name of the group")
+
+	deleteDropBoxCommand.Parse(os.Args[2:])
+
+	if *dropBoxID == 0 || *groupName == "" {
+		deleteDropBoxCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := DropBoxRequestPayload{
+		DropBoxID: *dropBoxID,
+	}
+	reqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Delete(hostURL+endpoints.DropBoxesAPIEndpoint, &reqBody, nil); err != nil {
+		fmt.Printf("Problem with the deletion of the drop box. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Drop box with id [%d] was deleted\n", *dropBoxID)
+}
+
+//DropFile - command for sending a file through a drop box. It doesnt require an account
+func DropFile(hostURL string) {
+	dropFileCommand := flag.NewFlagSet("drop-file", flag.ExitOnError)
+	dropBoxToken := dropFileCommand.String("token", "", "Token of the drop box")
+	filePath := dropFileCommand.String("filepath", "", "Path to the file")
+	name := dropFileCommand.String("name", "", "Your name")
+	email := dropFileCommand.String("email", "", "Your email")
+
+	dropFileCommand.Parse(os.Args[2:])
+
+	if *dropBoxToken == "" || *filePath == "" || *name == "" {
+		dropFileCommand.PrintDefaults()
+		return
+	}
+
+	formData := map[string]string{"name": *name}
+	if *email != "" {
+		formData["email"] = *email
+	}
+
+	restClient := restclient.NewRestClientImpl("")
+	url := fmt.Sprintf("%s%s/%s", hostURL, endpoints.DropBoxUploadAPIEndpoint, *dropBoxToken)
+	if err := restClient.UploadFile(url, *filePath, formData, nil); err != nil {
+		fmt.Printf("Problem with sending the file. %s\n", err.Error())
+		return
+	}
+
+	fmt.Println("The file was sent")
+}
+
+//fileSender - returns the name and the email of the person, who sent the file through a drop box
+func fileSender(fileInfo FileInfo) string {
+	if fileInfo.UploaderEmail == "" {
+		return fileInfo.UploaderName
+	}
+	return fmt.Sprintf("%s <%s>", fileInfo.UploaderName, fileInfo.UploaderEmail)
+}
@@ -1,9 +1,17 @@
 package commands
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	neturl "net/url"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
@@ -11,6 +19,9 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
+//uploadChunkSize - size of the parts (in bytes), in which the files are uploaded
+const uploadChunkSize = 8 << 20
+
 //FileUploadResponse - used to extract the id of the file, which was uploaded on the server
 type FileUploadResponse struct {
 	FileID uint `json:"file_id"`
@@ -28,6 +39,65 @@ type FileInfo struct {
 	Name       string    `json:"file_name"`
 	OwnerID    uint      `json:"owner_id"`
 	UploadedAt time.Time `json:"uploaded_at"`
+	Checksum   string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	Version    uint      `json:"version"`
+	//UploaderName and UploaderEmail - present only for the files, received through a drop box
+	UploaderName  string `json:"uploader_name,omitempty"`
+	UploaderEmail string `json:"uploader_email,omitempty"`
+}
+
+//FileVersionRequest - used to refer to a particular version of a file
+type FileVersionRequest struct {
+	FileRequest
+	Version uint `json:"version"`
+}
+
+//MaxFileVersionsRequest - used to change how many versions of each file are kept in a group
+type MaxFileVersionsRequest struct {
+	GroupPayload
+	MaxVersions uint `json:"max_versions"`
+}
+
+//FileVersionsResponse - response, containing information about all versions of a file
+type FileVersionsResponse struct {
+	Status   int        `json:"status"`
+	Versions []FileInfo `json:"versions"`
+}
+
+//UploadSessionRequest - used to start an upload of a file in chunks
+type UploadSessionRequest struct {
+	GroupPayload
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+	Folder   string `json:"folder"`
+}
+
+//UploadSessionIDRequest - used to refer to an already started upload session
+type UploadSessionIDRequest struct {
+	GroupPayload
+	SessionID uint `json:"session_id"`
+}
+
+//ByteRange - range of bytes [Start, End) of a file
+type ByteRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+//UploadSessionResponse - contains the state of an upload session
+type UploadSessionResponse struct {
+	Status         int         `json:"status"`
+	SessionID      uint        `json:"session_id"`
+	Size           int64       `json:"size"`
+	ReceivedRanges []ByteRange `json:"received_ranges"`
+}
+
+//uploadState - saved locally, so an interrupted upload can be resumed later
+type uploadState struct {
+	SessionID uint      `json:"session_id"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
 }
 
 //FilesInfoResponse - response, containing information about multiple files
@@ -37,10 +107,12 @@ type FilesInfoResponse struct {
 }
 
 //UploadFile - command for uploading a file to the server
+//the file is sent in chunks, so an interrupted upload is resumed by running the same command again
 func UploadFile(hostURL, token string) {
 	uploadFileCommand := flag.NewFlagSet("upload-file", flag.ExitOnError)
 	filePath := uploadFileCommand.String("filepath", "", "Path to the file")
 	groupName := uploadFileCommand.String("grp", "", "Name of the group, in which the file will be uploaded")
+	folderPath := uploadFileCommand.String("folder", "", "Path of the folder, in which the file will be uploaded (the root of the group by default)")
 
 	uploadFileCommand.Parse(os.Args[2:])
 	if *groupName == "" || *filePath == "" {
@@ -48,26 +120,178 @@ func UploadFile(hostURL, token string) {
 		return
 	}
 
-	successBody := FileUploadResponse{}
+	file, err := os.Open(*filePath)
+	if err != nil {
+		fmt.Printf("Problem with opening the file. %s\n", err.Error())
+		return
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		fmt.Printf("Problem with reading the file. %s\n", err.Error())
+		return
+	}
 
 	restClient := restclient.NewRestClientImpl(token)
-	url := fmt.Sprintf("%s%s?group_name=%s", hostURL, endpoints.UploadFileAPIEndpoint, *groupName)
-	err := restClient.UploadFile(url, *filePath, &successBody)
+	statePath := uploadStatePath(hostURL, *groupName, *folderPath, *filePath)
+	session, err := startUploadSession(restClient, hostURL, *groupName, *folderPath, statePath, fileInfo)
+	if err != nil {
+		fmt.Printf("Problem with the file upload request. %s\n", err.Error())
+		return
+	}
 
+	if err = uploadMissingChunks(restClient, hostURL, *groupName, file, session); err != nil {
+		fmt.Printf("Problem with the file upload request. %s\nRun the same command again to resume the upload\n", err.Error())
+		return
+	}
+
+	checksum, err := fileChecksum(file)
 	if err != nil {
+		fmt.Printf("Problem with reading the file. %s\n", err.Error())
+		return
+	}
+
+	rqBody := UploadSessionIDRequest{
+		SessionID: session.SessionID,
+	}
+	rqBody.GroupName = *groupName
+
+	successBody := FileUploadResponse{}
+	restClient.SetHeader(restclient.ChecksumHeader, checksum)
+	url := hostURL + endpoints.FinalizeUploadAPIEndpoint
+	if err = restClient.Post(url, &rqBody, &successBody); err != nil {
 		fmt.Printf("Problem with the file upload request. %s\n", err.Error())
 		return
 	}
+	os.Remove(statePath)
 
 	fmt.Printf("File was successfully uploaded in group %s.\n The id of the file is %d\n", *groupName, successBody.FileID)
 }
 
+//startUploadSession - continues the upload session, saved by a previous interrupted upload of the same file
+//if there isnt such session, a new one is created
+func startUploadSession(restClient *restclient.RestClientImpl, hostURL, groupName, folderPath, statePath string, fileInfo os.FileInfo) (UploadSessionResponse, error) {
+	session := UploadSessionResponse{}
+
+	if state, err := loadUploadState(statePath); err == nil &&
+		state.Size == fileInfo.Size() && state.ModTime.Equal(fileInfo.ModTime()) {
+
+		url := fmt.Sprintf("%s%s?group_name=%s&session_id=%d", hostURL, endpoints.UploadSessionAPIEndpoint, groupName, state.SessionID)
+		if err = restClient.Get(url, &session); err == nil {
+			fmt.Printf("Resuming the upload of the file. %d of %d bytes are already uploaded\n", receivedBytes(session.ReceivedRanges), session.Size)
+			return session, nil
+		}
+	}
+
+	rqBody := UploadSessionRequest{
+		FileName: fileInfo.Name(),
+		Size:     fileInfo.Size(),
+		Folder:   folderPath,
+	}
+	rqBody.GroupName = groupName
+
+	url := hostURL + endpoints.UploadSessionAPIEndpoint
+	if err := restClient.Post(url, &rqBody, &session); err != nil {
+		return session, err
+	}
+
+	saveUploadState(statePath, uploadState{
+		SessionID: session.SessionID,
+		Size:      fileInfo.Size(),
+		ModTime:   fileInfo.ModTime(),
+	})
+	return session, nil
+}
+
+func uploadMissingChunks(restClient *restclient.RestClientImpl, hostURL, groupName string, file *os.File, session UploadSessionResponse) error {
+	buffer := make([]byte, uploadChunkSize)
+	for offset := int64(0); offset < session.Size; offset += uploadChunkSize {
+		end := offset + uploadChunkSize
+		if end > session.Size {
+			end = session.Size
+		}
+
+		if isReceived(session.ReceivedRanges, offset, end) {
+			continue
+		}
+
+		chunk := buffer[:end-offset]
+		if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return err
+		}
+
+		url := fmt.Sprintf("%s%s?group_name=%s&session_id=%d&offset=%d", hostURL, endpoints.UploadChunkAPIEndpoint, groupName, session.SessionID, offset)
+		if err := restClient.UploadChunk(url, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileChecksum(file *os.File) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(file, 0, math.MaxInt64)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func isReceived(ranges []ByteRange, start, end int64) bool {
+	for _, r := range ranges {
+		if r.Start <= start && end <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+func receivedBytes(ranges []ByteRange) int64 {
+	var total int64
+	for _, r := range ranges {
+		total += r.End - r.Start
+	}
+	return total
+}
+
+func uploadStatePath(hostURL, groupName, folderPath, filePath string) string {
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		filePath = absPath
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+
+	hash := sha256.Sum256([]byte(hostURL + "|" + groupName + "|" + folderPath + "|" + filePath))
+	return filepath.Join(cacheDir, "ushare", "uploads", hex.EncodeToString(hash[:])+".json")
+}
+
+func loadUploadState(statePath string) (uploadState, error) {
+	state := uploadState{}
+	data, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+func saveUploadState(statePath string, state uploadState) {
+	data, _ := json.Marshal(state)
+	if err := os.MkdirAll(filepath.Dir(statePath), 0700); err == nil {
+		ioutil.WriteFile(statePath, data, 0600)
+	}
+}
+
 //DownloadFile - command for downloading a file from the server
 func DownloadFile(hostURL, token string) {
 	downloadFileCommand := flag.NewFlagSet("download-file", flag.ExitOnError)
 	fileID := downloadFileCommand.Int("fileid", -1, "File id")
 	groupName := downloadFileCommand.String("grp", "", "Name of the group, owning the file")
 	targetPath := downloadFileCommand.String("target", "", "Target destination of file")
+	version := downloadFileCommand.Uint("version", 0, "Version of the file (the latest one by default)")
 
 	downloadFileCommand.Parse(os.Args[2:])
 
@@ -78,10 +302,13 @@ func DownloadFile(hostURL, token string) {
 
 	restClient := restclient.NewRestClientImpl(token)
 	url := fmt.Sprintf("%s%s?group_name=%s&file_id=%d", hostURL, endpoints.DownloadFileAPIEndpoint, *groupName, *fileID)
+	if *version != 0 {
+		url = fmt.Sprintf("%s&version=%d", url, *version)
+	}
 	err := restClient.DownloadFile(url, *targetPath)
 
 	if err != nil {
-		fmt.Println(err.Error())
+		fmt.Printf("%s\nRun the same command again to resume the download\n", err.Error())
 		return
 	}
 
@@ -115,13 +342,14 @@ func DeleteFile(hostURL, token string) {
 		return
 	}
 
-	fmt.Println("File was successfully deleted")
+	fmt.Println("File was successfully moved to the trash")
 }
 
-//ShowAllFilesInGroup - command for fetching information about all files uploaded for a specific group
+//ShowAllFilesInGroup - command for fetching information about all files uploaded in a folder of a specific group
 func ShowAllFilesInGroup(hostURL, token string) {
 	getAllFilesCommand := flag.NewFlagSet("show-all-files", flag.ExitOnError)
 	groupName := getAllFilesCommand.String("grp", "", "Name of the group")
+	folderPath := getAllFilesCommand.String("path", "", "Path of the folder (the root of the group by default)")
 
 	getAllFilesCommand.Parse(os.Args[2:])
 
@@ -132,7 +360,7 @@ func ShowAllFilesInGroup(hostURL, token string) {
 
 	successBody := FilesInfoResponse{}
 	restClient := restclient.NewRestClientImpl(token)
-	url := fmt.Sprintf("%s%s?group_name=%s", hostURL, endpoints.GetAllFilesAPIEndpoint, *groupName)
+	url := fmt.Sprintf("%s%s?group_name=%s&path=%s", hostURL, endpoints.GetAllFilesAPIEndpoint, *groupName, neturl.QueryEscape(*folderPath))
 	err := restClient.Get(url, &successBody)
 
 	if err != nil {
@@ -142,7 +370,98 @@ func ShowAllFilesInGroup(hostURL, token string) {
 
 	tableRows := make([]table.Row, len(successBody.FilesInfo))
 	for _, fileInfo := range successBody.FilesInfo {
-		tableRows = append(tableRows, table.Row{fileInfo.ID, fileInfo.Name, fileInfo.UploadedAt, fileInfo.OwnerID})
+		tableRows = append(tableRows, table.Row{fileInfo.ID, fileInfo.Name, fileInfo.Version, fileInfo.UploadedAt, fileInfo.OwnerID, fileSender(fileInfo), fileInfo.Size, fileInfo.Checksum})
 	}
-	PrintTable(table.Row{"ID", "Name", "UploadedAt", "OwnerID"}, tableRows)
+	PrintTable(table.Row{"ID", "Name", "Version", "UploadedAt", "OwnerID", "Sender", "Size", "SHA256"}, tableRows)
+}
+
+//ShowFileVersions - command for fetching information about all versions of a file
+func ShowFileVersions(hostURL, token string) {
+	showVersionsCommand := flag.NewFlagSet("show-versions", flag.ExitOnError)
+	fileID := showVersionsCommand.Int("fileid", -1, "Id of any version of the file")
+	groupName := showVersionsCommand.String("grp", "", "Name of the group")
+
+	showVersionsCommand.Parse(os.Args[2:])
+
+	if *fileID == -1 || *groupName == "" {
+		showVersionsCommand.PrintDefaults()
+		return
+	}
+
+	successBody := FileVersionsResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	url := fmt.Sprintf("%s%s?group_name=%s&file_id=%d", hostURL, endpoints.FileVersionsAPIEndpoint, *groupName, *fileID)
+	err := restClient.Get(url, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of file versions. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(successBody.Versions))
+	for _, version := range successBody.Versions {
+		tableRows = append(tableRows, table.Row{version.Version, version.ID, version.UploadedAt, version.OwnerID, version.Size, version.Checksum})
+	}
+	PrintTable(table.Row{"Version", "ID", "UploadedAt", "OwnerID", "Size", "SHA256"}, tableRows)
+}
+
+//RestoreFileVersion - command for making an old version of a file the latest one
+func RestoreFileVersion(hostURL, token string) {
+	restoreVersionCommand := flag.NewFlagSet("restore-version", flag.ExitOnError)
+	fileID := restoreVersionCommand.Int("fileid", -1, "Id of any version of the file")
+	groupName := restoreVersionCommand.String("grp", "", "Name of the group")
+	version := restoreVersionCommand.Uint("version", 0, "Version, which will be restored")
+
+	restoreVersionCommand.Parse(os.Args[2:])
+
+	if *fileID == -1 || *groupName == "" || *version == 0 {
+		restoreVersionCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := FileVersionRequest{
+		Version: *version,
+	}
+	reqBody.FileID = uint(*fileID)
+	reqBody.GroupName = *groupName
+
+	successBody := FileUploadResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Post(hostURL+endpoints.RestoreFileVersionAPIEndpoint, &reqBody, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the restoration of the version. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Version was successfully restored. New file id: %d\n", successBody.FileID)
+}
+
+//SetMaxFileVersions - command for changing how many versions of each file are kept in a group
+func SetMaxFileVersions(hostURL, token string) {
+	maxVersionsCommand := flag.NewFlagSet("set-max-versions", flag.ExitOnError)
+	groupName := maxVersionsCommand.String("grp", "", "Name of the group")
+	maxVersions := maxVersionsCommand.Int("max", -1, "Number of kept versions of each file (0 for the server default)")
+
+	maxVersionsCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *maxVersions < 0 {
+		maxVersionsCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := MaxFileVersionsRequest{
+		MaxVersions: uint(*maxVersions),
+	}
+	reqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Put(hostURL+endpoints.MaxFileVersionsAPIEndpoint, &reqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with changing the number of kept versions. %s\n", err.Error())
+		return
+	}
+
+	fmt.Println("Number of kept versions was successfully changed")
 }
@@ -0,0 +1,270 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//FolderInfo - contains all the information about a folder
+type FolderInfo struct {
+	ID        uint      `json:"folder_id"`
+	Name      string    `json:"folder_name"`
+	CreatedAt time.Time `json:"created_at"`
+	OwnerID   uint      `json:"owner_id"`
+}
+
+//FolderContentsResponse - response, containing the folders and the files inside a folder
+type FolderContentsResponse struct {
+	Status    int          `json:"status"`
+	Folders   []FolderInfo `json:"folders"`
+	FilesInfo []FileInfo   `json:"files"`
+}
+
+//FolderRequest - used to refer to a folder of a group
+type FolderRequest struct {
+	GroupPayload
+	Path string `json:"path"`
+}
+
+//RenameFolderRequest - used to give a new name to a folder
+type RenameFolderRequest struct {
+	FolderRequest
+	Name string `json:"name"`
+}
+
+//MoveFolderRequest - used to move a folder inside another folder
+type MoveFolderRequest struct {
+	FolderRequest
+	Target string `json:"target"`
+}
+
+//MoveFileRequest - used to move a file in another folder and optionally rename it
+type MoveFileRequest struct {
+	FileRequest
+	Folder   string `json:"folder"`
+	FileName string `json:"file_name"`
+}
+
+//ListFolder - command for showing the folders and files inside a folder of a group
+func ListFolder(hostURL, token string) {
+	lsCommand := flag.NewFlagSet("ls", flag.ExitOnError)
+	groupName := lsCommand.String("grp", "", "Name of the group")
+	folderPath := lsCommand.String("path", "", "Path of the folder, like reports/2026 (the root of the group by default)")
+
+	lsCommand.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		lsCommand.PrintDefaults()
+		return
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	contents, err := getFolderContents(restClient, hostURL, *groupName, *folderPath)
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the folder. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(contents.Folders)+len(contents.FilesInfo))
+	for _, folder := range contents.Folders {
+		tableRows = append(tableRows, table.Row{"folder", folder.ID, folder.Name + "/", "", folder.CreatedAt, folder.OwnerID, ""})
+	}
+	for _, fileInfo := range contents.FilesInfo {
+		tableRows = append(tableRows, table.Row{"file", fileInfo.ID, fileInfo.Name, fileInfo.Version, fileInfo.UploadedAt, fileInfo.OwnerID, fileInfo.Size})
+	}
+	PrintTable(table.Row{"Type", "ID", "Name", "Version", "CreatedAt", "OwnerID", "Size"}, tableRows)
+}
+
+//CreateFolder - command for creating a folder in a group, together with all missing parent folders
+func CreateFolder(hostURL, token string) {
+	mkdirCommand := flag.NewFlagSet("mkdir", flag.ExitOnError)
+	groupName := mkdirCommand.String("grp", "", "Name of the group")
+	folderPath := mkdirCommand.String("path", "", "Path of the new folder, like reports/2026")
+
+	mkdirCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *folderPath == "" {
+		mkdirCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := FolderRequest{
+		Path: *folderPath,
+	}
+	reqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Post(hostURL+endpoints.FolderAPIEndpoint, &reqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the folder creation request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Folder %s was successfully created\n", *folderPath)
+}
+
+//DeleteFolder - command for deleting an empty folder of a group
+func DeleteFolder(hostURL, token string) {
+	rmdirCommand := flag.NewFlagSet("rmdir", flag.ExitOnError)
+	groupName := rmdirCommand.String("grp", "", "Name of the group")
+	folderPath := rmdirCommand.String("path", "", "Path of the folder, like reports/2026")
+
+	rmdirCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *folderPath == "" {
+		rmdirCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := FolderRequest{
+		Path: *folderPath,
+	}
+	reqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Delete(hostURL+endpoints.FolderAPIEndpoint, &reqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the folder deletion request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Folder %s was successfully deleted\n", *folderPath)
+}
+
+//Move - command for moving or renaming a file or a folder of a group, given paths like reports/2026/q3.pdf
+//if the destination ends with "/" or is an existing folder, the source is moved inside it and keeps its name
+func Move(hostURL, token string) {
+	mvCommand := flag.NewFlagSet("mv", flag.ExitOnError)
+	groupName := mvCommand.String("grp", "", "Name of the group")
+	srcPath := mvCommand.String("src", "", "Path of the file or folder, which will be moved")
+	dstPath := mvCommand.String("dst", "", "New path of the file or folder")
+
+	mvCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *srcPath == "" || *dstPath == "" {
+		mvCommand.PrintDefaults()
+		return
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	srcParent, srcName := splitPath(*srcPath)
+	contents, err := getFolderContents(restClient, hostURL, *groupName, srcParent)
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the folder. %s\n", err.Error())
+		return
+	}
+
+	dstParent, dstName, err := resolveDestination(restClient, hostURL, *groupName, *dstPath, srcName)
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the folder. %s\n", err.Error())
+		return
+	}
+
+	if contents.hasFolder(srcName) {
+		err = moveFolder(restClient, hostURL, *groupName, srcParent, srcName, dstParent, dstName)
+	} else if fileInfo, ok := contents.file(srcName); ok {
+		reqBody := MoveFileRequest{
+			Folder:   dstParent,
+			FileName: dstName,
+		}
+		reqBody.FileID = fileInfo.ID
+		reqBody.GroupName = *groupName
+		err = restClient.Put(hostURL+endpoints.MoveFileAPIEndpoint, &reqBody, nil)
+	} else {
+		fmt.Printf("There is no file or folder with path %s\n", *srcPath)
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("Problem with the move request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("%s was successfully moved to %s\n", *srcPath, path.Join(dstParent, dstName))
+}
+
+//moveFolder - moves the folder inside its new parent folder and then gives it its new name
+func moveFolder(restClient *restclient.RestClientImpl, hostURL, groupName, srcParent, srcName, dstParent, dstName string) error {
+	folderPath := path.Join(srcParent, srcName)
+	if strings.Trim(srcParent, "/") != strings.Trim(dstParent, "/") {
+		reqBody := MoveFolderRequest{
+			Target: dstParent,
+		}
+		reqBody.Path = folderPath
+		reqBody.GroupName = groupName
+
+		if err := restClient.Put(hostURL+endpoints.MoveFolderAPIEndpoint, &reqBody, nil); err != nil {
+			return err
+		}
+		folderPath = path.Join(dstParent, srcName)
+	}
+
+	if srcName == dstName {
+		return nil
+	}
+
+	reqBody := RenameFolderRequest{
+		Name: dstName,
+	}
+	reqBody.Path = folderPath
+	reqBody.GroupName = groupName
+	return restClient.Put(hostURL+endpoints.RenameFolderAPIEndpoint, &reqBody, nil)
+}
+
+//resolveDestination - returns the folder, in which the source will be moved, and its new name
+func resolveDestination(restClient *restclient.RestClientImpl, hostURL, groupName, dstPath, srcName string) (string, string, error) {
+	if strings.HasSuffix(dstPath, "/") {
+		return strings.Trim(dstPath, "/"), srcName, nil
+	}
+
+	dstParent, dstName := splitPath(dstPath)
+	contents, err := getFolderContents(restClient, hostURL, groupName, dstParent)
+	if err != nil {
+		return "", "", err
+	} else if contents.hasFolder(dstName) {
+		return path.Join(dstParent, dstName), srcName, nil
+	}
+	return dstParent, dstName, nil
+}
+
+func getFolderContents(restClient *restclient.RestClientImpl, hostURL, groupName, folderPath string) (FolderContentsResponse, error) {
+	contents := FolderContentsResponse{}
+	contentsURL := fmt.Sprintf("%s%s?group_name=%s&path=%s", hostURL, endpoints.GetAllFilesAPIEndpoint, url.QueryEscape(groupName), url.QueryEscape(folderPath))
+	err := restClient.Get(contentsURL, &contents)
+	return contents, err
+}
+
+//splitPath - splits a path like reports/2026/q3.pdf into its folder and name
+func splitPath(fullPath string) (string, string) {
+	parent, name := path.Split(strings.Trim(fullPath, "/"))
+	return strings.Trim(parent, "/"), name
+}
+
+func (r FolderContentsResponse) hasFolder(name string) bool {
+	for _, folder := range r.Folders {
+		if folder.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r FolderContentsResponse) file(name string) (FileInfo, bool) {
+	for _, fileInfo := range r.FilesInfo {
+		if fileInfo.Name == name {
+			return fileInfo, true
+		}
+	}
+	return FileInfo{}, false
+}
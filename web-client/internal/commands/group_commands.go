@@ -16,11 +16,24 @@ type MembershipRequest struct {
 	Username string `json:"username"`
 }
 
+//MemberRoleRequest - request for changing the role of a member of a group
+type MemberRoleRequest struct {
+	MembershipRequest
+	Role string `json:"role"`
+}
+
+//InvitationRequest - request for inviting a user with a specific role to a group
+type InvitationRequest struct {
+	MemberRoleRequest
+	ExpiresInHours uint `json:"expires_in_hours"`
+}
+
 //GroupInfo - contains all information about a group
 type GroupInfo struct {
-	ID      uint
-	OwnerID uint
-	Name    string
+	ID         uint
+	OwnerID    uint
+	Name       string
+	Visibility string
 }
 
 //GroupsInfoResponse - response, containing information about multiple groups
@@ -33,6 +46,7 @@ type GroupsInfoResponse struct {
 func CreateGroup(hostURL, token string) {
 	createGroupCommand := flag.NewFlagSet("create-group", flag.ExitOnError)
 	groupName := createGroupCommand.String("grp", "", "Name of the group to be created")
+	visibility := createGroupCommand.String("vis", "", "Visibility of the group - private (default), discoverable or open")
 
 	createGroupCommand.Parse(os.Args[2:])
 	if *groupName == "" {
@@ -40,9 +54,10 @@ func CreateGroup(hostURL, token string) {
 		return
 	}
 
-	rqBody := GroupPayload{
-		GroupName: *groupName,
+	rqBody := GroupVisibilityRequest{
+		Visibility: *visibility,
 	}
+	rqBody.GroupName = *groupName
 
 	restClient := restclient.NewRestClientImpl(token)
 	url := hostURL + endpoints.CreateGroupAPIEndpoint
@@ -83,15 +98,79 @@ func DeleteGroup(hostURL, token string) {
 	fmt.Printf("Group %s was succesfully deleted", *groupName)
 }
 
-//AddMember - command for creation of membership
-func AddMember(hostURL, token string) {
-	addMemberCommand := flag.NewFlagSet("add-member", flag.ExitOnError)
-	username := addMemberCommand.String("usr", "", "Name of the user to be added to the group")
-	groupName := addMemberCommand.String("grp", "", "Name of the group")
-	addMemberCommand.Parse(os.Args[2:])
+//InviteMember - command for inviting a user to a group. The user becomes a member after accepting the invitation
+func InviteMember(hostURL, token string) {
+	inviteMemberCommand := flag.NewFlagSet("invite-member", flag.ExitOnError)
+	username := inviteMemberCommand.String("usr", "", "Name of the user to be invited to the group")
+	groupName := inviteMemberCommand.String("grp", "", "Name of the group")
+	role := inviteMemberCommand.String("role", "", "Role of the new member - admin, editor (default) or viewer")
+	expiresIn := inviteMemberCommand.Uint("exp", 0, "After how many hours the invitation expires (never by default)")
+	inviteMemberCommand.Parse(os.Args[2:])
 
 	if *groupName == "" || *username == "" {
-		addMemberCommand.PrintDefaults()
+		inviteMemberCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := InvitationRequest{
+		ExpiresInHours: *expiresIn,
+	}
+	rqBody.Role = *role
+	rqBody.Username = *username
+	rqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	url := hostURL + endpoints.InviteMemberAPIEndpoint
+	err := restClient.Post(url, &rqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the invitation request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("User %s was successfully invited to group %s\n", *username, *groupName)
+}
+
+//SetRole - command for changing the role of a member of a group
+func SetRole(hostURL, token string) {
+	setRoleCommand := flag.NewFlagSet("set-role", flag.ExitOnError)
+	username := setRoleCommand.String("usr", "", "Name of the member")
+	groupName := setRoleCommand.String("grp", "", "Name of the group")
+	role := setRoleCommand.String("role", "", "New role of the member - admin, editor or viewer")
+	setRoleCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *username == "" || *role == "" {
+		setRoleCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := MemberRoleRequest{
+		Role: *role,
+	}
+	rqBody.Username = *username
+	rqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	url := hostURL + endpoints.MemberRoleAPIEndpoint
+	err := restClient.Put(url, &rqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the role change request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("User %s is now %s in group %s\n", *username, *role, *groupName)
+}
+
+//TransferGroup - command for making another member the owner of a group
+func TransferGroup(hostURL, token string) {
+	transferGroupCommand := flag.NewFlagSet("transfer-group", flag.ExitOnError)
+	username := transferGroupCommand.String("usr", "", "Name of the member, who will become the owner")
+	groupName := transferGroupCommand.String("grp", "", "Name of the group")
+	transferGroupCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *username == "" {
+		transferGroupCommand.PrintDefaults()
 		return
 	}
 
@@ -101,15 +180,15 @@ func AddMember(hostURL, token string) {
 	rqBody.GroupName = *groupName
 
 	restClient := restclient.NewRestClientImpl(token)
-	url := hostURL + endpoints.AddMemberAPIEndpoint
-	err := restClient.Post(url, &rqBody, nil)
+	url := hostURL + endpoints.TransferGroupAPIEndpoint
+	err := restClient.Put(url, &rqBody, nil)
 
 	if err != nil {
-		fmt.Printf("Problem with the group creation request. %s\n", err.Error())
+		fmt.Printf("Problem with the ownership transfer request. %s\n", err.Error())
 		return
 	}
 
-	fmt.Printf("User %s was successfully added to group %s\n", *username, *groupName)
+	fmt.Printf("User %s is now the owner of group %s. You stay in the group as an admin\n", *username, *groupName)
 }
 
 //RemoveMember - command for revocation of membership
@@ -157,7 +236,7 @@ func ShowAllGroups(hostURL, token string) {
 
 	tableRows := make([]table.Row, len(successBody.GroupsInfo))
 	for _, groupInfo := range successBody.GroupsInfo {
-		tableRows = append(tableRows, table.Row{groupInfo.ID, groupInfo.Name, groupInfo.OwnerID})
+		tableRows = append(tableRows, table.Row{groupInfo.ID, groupInfo.Name, groupInfo.OwnerID, groupInfo.Visibility})
 	}
-	PrintTable(table.Row{"ID", "Name", "OwnerID"}, tableRows)
+	PrintTable(table.Row{"ID", "Name", "OwnerID", "Visibility"}, tableRows)
 }
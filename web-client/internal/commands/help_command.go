@@ -6,18 +6,65 @@ import "github.com/jedib0t/go-pretty/v6/table"
 func Help() {
 	commands := []table.Row{
 		{"register", "register a new user", "-usr=<username>(Required) and -pass=<password>(Required)"},
-		{"login", "login as a registered user", "-usr=<username>(Required) and -pass=<password>(Required)"},
+		{"login", "login as a registered user", "-usr=<username>(Required), -pass=<password>(Required) and -code=<code>(Optional)"},
+		{"logout", "logout and revoke your tokens", "None"},
+		{"change-password", "change your password. Your other sessions and personal access tokens are invalidated", "-pass=<password>(Required) and -new=<new_password>(Required)"},
+		{"reset-password", "set a new password with a reset token from the administrator", "-token=<reset_token>(Required) and -new=<new_password>(Required)"},
+		{"enable-2fa", "enable two-factor authentication with an authenticator app", "None"},
+		{"disable-2fa", "disable two-factor authentication", "-code=<code>(Required)"},
+		{"delete-user", "delete your account", "-pass=<password>(Required), -groups=<transfer|deactivate>(Optional) and -files=<reassign|delete>(Optional)"},
+		{"create-token", "create a personal access token for scripts and CI", "-name=<token_name>(Required), -scopes=<read,upload,write>(Required), -exp=<days>(Required) and -grps=<group_names>(Optional)"},
+		{"tokens", "show your personal access tokens", "None"},
+		{"delete-token", "delete a personal access token", "-id=<token_id>(Required)"},
 		{"show-all-users", "show all existing users", "None"},
-		{"create-group", "create a new group", "-grp=<group_name>(Required)"},
+		{"create-group", "create a new group", "-grp=<group_name>(Required) and -vis=<private|discoverable|open>(Optional)"},
 		{"delete-group", "delete group", "-grp=<group_name>(Required)"},
-		{"show-all-groups", "show all existing groups", "None"},
-		{"add-member", "add a new member to a group", "-usr=<username>(Required) and -grp=<group_name>(Required)"},
+		{"show-all-groups", "show all groups, which you can see", "None"},
+		{"set-visibility", "change who can see and join a group", "-grp=<group_name>(Required) and -vis=<private|discoverable|open>(Required)"},
+		{"join-group", "join an open group or request to join a discoverable group", "-grp=<group_name>(Required)"},
+		{"join-requests", "show the pending join requests of a group", "-grp=<group_name>(Required)"},
+		{"approve-join", "approve a join request", "-usr=<username>(Required), -grp=<group_name>(Required) and -role=<admin|editor|viewer>(Optional)"},
+		{"reject-join", "reject a join request", "-usr=<username>(Required) and -grp=<group_name>(Required)"},
+		{"invite-member", "invite a user to become a member of a group", "-usr=<username>(Required), -grp=<group_name>(Required), -role=<admin|editor|viewer>(Optional) and -exp=<hours>(Optional)"},
+		{"invitations", "show your pending invitations", "None"},
+		{"accept-invite", "accept an invitation and become a member of the group", "-grp=<group_name>(Required)"},
+		{"decline-invite", "decline an invitation", "-grp=<group_name>(Required)"},
+		{"transfer-group", "make another member the owner of a group", "-usr=<username>(Required) and -grp=<group_name>(Required)"},
+		{"set-role", "change the role of a member of a group", "-usr=<username>(Required), -grp=<group_name>(Required) and -role=<admin|editor|viewer>(Required)"},
 		{"remove-member", "revoke membership", "-usr=<username>(Required) and -grp=<group_name>(Required)"},
 		{"show-all-members", "show all members of a group", "-grp=<group_name>(Required)"},
-		{"upload-file", "upload a file to a group", "-grp=<group_name>(Required) and -filepath=<path_to_file>(Required)"},
-		{"download-file", "download a file from a group", "-grp=<group_name>(Required), -fileid=<id_of_file>(Required) and -target=<output_file_path>(Required)"},
-		{"delete-file", "delete file from a group", "-grp=<group_name>(Required) and -fileid=<id_of_file>(Required)"},
-		{"show-all-files", "show all files from a group", "-grp=<group_name>(Required)"},
+		{"upload-file", "upload a file to a group", "-grp=<group_name>(Required), -filepath=<path_to_file>(Required) and -folder=<folder_path>(Optional)"},
+		{"download-file", "download a file from a group", "-grp=<group_name>(Required), -fileid=<id_of_file>(Required), -target=<output_file_path>(Required) and -version=<version>(Optional)"},
+		{"delete-file", "move a file of a group to the trash", "-grp=<group_name>(Required) and -fileid=<id_of_file>(Required)"},
+		{"show-all-files", "show all files in a folder of a group", "-grp=<group_name>(Required) and -path=<folder_path>(Optional)"},
+		{"ls", "show the folders and files in a folder of a group", "-grp=<group_name>(Required) and -path=<folder_path>(Optional)"},
+		{"mkdir", "create a folder in a group, together with its missing parents", "-grp=<group_name>(Required) and -path=<folder_path>(Required)"},
+		{"rmdir", "delete an empty folder of a group", "-grp=<group_name>(Required) and -path=<folder_path>(Required)"},
+		{"mv", "move or rename a file or a folder of a group", "-grp=<group_name>(Required), -src=<source_path>(Required) and -dst=<destination_path>(Required)"},
+		{"share-file", "create a link, through which a file can be downloaded without an account", "-grp=<group_name>(Required), -fileid=<id_of_file>(Required), -pass=<password>(Optional), -exp=<hours>(Optional) and -max=<max_downloads>(Optional)"},
+		{"share-links", "show the share links of the files in a group", "-grp=<group_name>(Required)"},
+		{"revoke-share", "revoke a share link", "-grp=<group_name>(Required) and -id=<link_id>(Required)"},
+		{"create-dropbox", "create a link, through which people without an account send files to a folder of a group", "-grp=<group_name>(Required), -folder=<folder_path>(Optional), -size=<max_file_size>(Optional), -max=<max_files>(Optional) and -exp=<hours>(Optional)"},
+		{"dropboxes", "show the drop boxes of a group", "-grp=<group_name>(Required)"},
+		{"delete-dropbox", "delete a drop box", "-grp=<group_name>(Required) and -id=<drop_box_id>(Required)"},
+		{"drop-file", "send a file through a drop box without an account", "-token=<drop_box_token>(Required), -filepath=<path_to_file>(Required), -name=<your_name>(Required) and -email=<your_email>(Optional)"},
+		{"show-trash", "show the files in the trash of a group", "-grp=<group_name>(Required)"},
+		{"restore-file", "restore a file from the trash", "-grp=<group_name>(Required) and -fileid=<id_of_file>(Required)"},
+		{"purge-file", "permanently delete a file from the trash", "-grp=<group_name>(Required) and -fileid=<id_of_file>(Required)"},
+		{"show-versions", "show all versions of a file", "-grp=<group_name>(Required) and -fileid=<id_of_file>(Required)"},
+		{"restore-version", "make an old version of a file the latest one", "-grp=<group_name>(Required), -fileid=<id_of_file>(Required) and -version=<version>(Required)"},
+		{"set-max-versions", "change how many versions of each file are kept in a group", "-grp=<group_name>(Required) and -max=<number_of_versions>(Required)"},
+		{"show-usage", "show the storage used by you or by a group, together with its quota", "-grp=<group_name>(Optional)"},
+		{"audit", "show the audit log of a group, starting from the latest event. Only for the group owner", "-grp=<group_name>(Required), -action=<action>(Optional), -actor=<username>(Optional), -target=<username>(Optional), -outcome=<success|failure>(Optional), -from=<date>(Optional), -to=<date>(Optional) and -limit=<number_of_events>(Optional)"},
+		{"admin-users", "show all users with their status and used storage. Only for administrators", "None"},
+		{"suspend-user", "suspend a user, who can no longer login. Only for administrators", "-usr=<username>(Required), -reason=<reason>(Required) and -until=<date>(Optional)"},
+		{"unsuspend-user", "lift the suspension of a user. Only for administrators", "-usr=<username>(Required)"},
+		{"admin-reset-password", "issue a one-time password reset token for a user, who has forgotten the password. Only for administrators", "-usr=<username>(Required) and -exp=<hours>(Optional)"},
+		{"admin-delete-user", "delete the account of a user. Only for administrators", "-usr=<username>(Required), -groups=<transfer|deactivate>(Optional) and -files=<reassign|delete>(Optional)"},
+		{"admin-delete-group", "delete any group. Only for administrators", "-grp=<group_name>(Required)"},
+		{"admin-transfer-group", "make any user the owner of a group. Only for administrators", "-usr=<username>(Required) and -grp=<group_name>(Required)"},
+		{"admin-storage", "show the number of users, groups and files and the storage used by them. Only for administrators", "None"},
+		{"admin-audit", "show or export to csv the audit log of the whole server. Only for administrators", "-grp=<group_name>(Optional), -csv=<path_to_file>(Optional), -action=<action>(Optional), -actor=<username>(Optional), -target=<username>(Optional), -outcome=<success|failure>(Optional), -from=<date>(Optional), -to=<date>(Optional) and -limit=<number_of_events>(Optional)"},
 		{"help", "show all available commands", "None"},
 	}
 
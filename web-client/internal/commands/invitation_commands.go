@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//InvitationInfo - contains all information about a pending invitation
+type InvitationInfo struct {
+	ID        uint       `json:"id"`
+	GroupName string     `json:"group_name"`
+	Inviter   string     `json:"inviter"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+//InvitationsResponse - response, containing the pending invitations of the user
+type InvitationsResponse struct {
+	Status      uint             `json:"status"`
+	Invitations []InvitationInfo `json:"invitations"`
+}
+
+//ShowInvitations - command for showing the pending invitations of the current user
+func ShowInvitations(hostURL, token string) {
+	successBody := InvitationsResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Get(hostURL+endpoints.InvitationsAPIEndpoint, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the invitations. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(successBody.Invitations))
+	for _, invitation := range successBody.Invitations {
+		expiresAt := "never"
+		if invitation.ExpiresAt != nil {
+			expiresAt = invitation.ExpiresAt.String()
+		}
+		tableRows = append(tableRows, table.Row{invitation.GroupName, invitation.Inviter, invitation.Role, invitation.CreatedAt, expiresAt})
+	}
+	PrintTable(table.Row{"Group", "Inviter", "Role", "CreatedAt", "ExpiresAt"}, tableRows)
+}
+
+//AcceptInvitation - command for accepting an invitation to a group
+func AcceptInvitation(hostURL, token string) {
+	respondToInvitation(hostURL, token, "accept-invite", func(restClient *restclient.RestClientImpl, rqBody *GroupPayload) error {
+		return restClient.Post(hostURL+endpoints.AcceptInvitationAPIEndpoint, rqBody, nil)
+	}, "You are now a member of group %s\n")
+}
+
+//DeclineInvitation - command for declining an invitation to a group
+func DeclineInvitation(hostURL, token string) {
+	respondToInvitation(hostURL, token, "decline-invite", func(restClient *restclient.RestClientImpl, rqBody *GroupPayload) error {
+		return restClient.Delete(hostURL+endpoints.DeclineInvitationAPIEndpoint, rqBody, nil)
+	}, "The invitation to group %s was declined\n")
+}
+
+func respondToInvitation(hostURL, token, commandName string, send func(*restclient.RestClientImpl, *GroupPayload) error, successMsg string) {
+	command := flag.NewFlagSet(commandName, flag.ExitOnError)
+	groupName := command.String("grp", "", "Name of the group")
+	command.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		command.PrintDefaults()
+		return
+	}
+
+	rqBody := GroupPayload{
+		GroupName: *groupName,
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := send(restClient, &rqBody); err != nil {
+		fmt.Printf("Problem with the invitation request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf(successMsg, *groupName)
+}
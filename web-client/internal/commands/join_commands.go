@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//GroupVisibilityRequest - request for creating a group or changing who can see and join it
+type GroupVisibilityRequest struct {
+	GroupPayload
+	Visibility string `json:"visibility"`
+}
+
+//JoinRequestResolutionRequest - request for approving or rejecting a join request
+type JoinRequestResolutionRequest struct {
+	MemberRoleRequest
+	Approve bool `json:"approve"`
+}
+
+//JoinGroupResponse - response, telling if the user became a member or a join request was created
+type JoinGroupResponse struct {
+	Status uint `json:"status"`
+	Joined bool `json:"joined"`
+}
+
+//JoinRequestInfo - contains all information about a pending join request
+type JoinRequestInfo struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+//JoinRequestsResponse - response, containing the pending join requests of a group
+type JoinRequestsResponse struct {
+	Status       uint              `json:"status"`
+	JoinRequests []JoinRequestInfo `json:"join_requests"`
+}
+
+//SetGroupVisibility - command for changing who can see and join a group
+func SetGroupVisibility(hostURL, token string) {
+	setVisibilityCommand := flag.NewFlagSet("set-visibility", flag.ExitOnError)
+	groupName := setVisibilityCommand.String("grp", "", "Name of the group")
+	visibility := setVisibilityCommand.String("vis", "", "Visibility of the group - private, discoverable or open")
+	setVisibilityCommand.Parse(os.Args[2:])
+
+	if *groupName == "" || *visibility == "" {
+		setVisibilityCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := GroupVisibilityRequest{
+		Visibility: *visibility,
+	}
+	rqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Put(hostURL+endpoints.GroupVisibilityAPIEndpoint, &rqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the visibility change request. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Group %s is now %s\n", *groupName, *visibility)
+}
+
+//JoinGroup - command for joining an open group or requesting to join a discoverable group
+func JoinGroup(hostURL, token string) {
+	joinGroupCommand := flag.NewFlagSet("join-group", flag.ExitOnError)
+	groupName := joinGroupCommand.String("grp", "", "Name of the group")
+	joinGroupCommand.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		joinGroupCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := GroupPayload{
+		GroupName: *groupName,
+	}
+
+	successBody := JoinGroupResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Post(hostURL+endpoints.JoinGroupAPIEndpoint, &rqBody, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the join request. %s\n", err.Error())
+		return
+	}
+
+	if successBody.Joined {
+		fmt.Printf("You are now a member of group %s\n", *groupName)
+	} else {
+		fmt.Printf("Your request to join group %s is waiting for approval\n", *groupName)
+	}
+}
+
+//ShowJoinRequests - command for showing the pending join requests of a group
+func ShowJoinRequests(hostURL, token string) {
+	joinRequestsCommand := flag.NewFlagSet("join-requests", flag.ExitOnError)
+	groupName := joinRequestsCommand.String("grp", "", "Name of the group")
+	joinRequestsCommand.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		joinRequestsCommand.PrintDefaults()
+		return
+	}
+
+	successBody := JoinRequestsResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	requestsURL := fmt.Sprintf("%s%s?group_name=%s", hostURL, endpoints.JoinRequestsAPIEndpoint, url.QueryEscape(*groupName))
+	err := restClient.Get(requestsURL, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the join requests. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(successBody.JoinRequests))
+	for _, joinRequest := range successBody.JoinRequests {
+		tableRows = append(tableRows, table.Row{joinRequest.Username, joinRequest.CreatedAt})
+	}
+	PrintTable(table.Row{"Username", "CreatedAt"}, tableRows)
+}
+
+//ApproveJoinRequest - command for approving a join request. The user becomes a member of the group
+func ApproveJoinRequest(hostURL, token string) {
+	resolveJoinRequest(hostURL, token, "approve-join", true)
+}
+
+//RejectJoinRequest - command for rejecting a join request
+func RejectJoinRequest(hostURL, token string) {
+	resolveJoinRequest(hostURL, token, "reject-join", false)
+}
+
+func resolveJoinRequest(hostURL, token, commandName string, approve bool) {
+	command := flag.NewFlagSet(commandName, flag.ExitOnError)
+	username := command.String("usr", "", "Name of the user, who requested to join the group")
+	groupName := command.String("grp", "", "Name of the group")
+	var role *string
+	if approve {
+		role = command.String("role", "", "Role of the new member - admin, editor (default) or viewer")
+	}
+	command.Parse(os.Args[2:])
+
+	if *groupName == "" || *username == "" {
+		command.PrintDefaults()
+		return
+	}
+
+	rqBody := JoinRequestResolutionRequest{
+		Approve: approve,
+	}
+	if role != nil {
+		rqBody.Role = *role
+	}
+	rqBody.Username = *username
+	rqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Post(hostURL+endpoints.ResolveJoinRequestAPIEndpoint, &rqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the resolution of the join request. %s\n", err.Error())
+		return
+	}
+
+	if approve {
+		fmt.Printf("User %s is now a member of group %s\n", *username, *groupName)
+	} else {
+		fmt.Printf("The request of user %s to join group %s was rejected\n", *username, *groupName)
+	}
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/danielpenchev98/UShare/web-client/internal/session"
+)
+
+//ChangePasswordPayload - information used for changing the password of the user
+type ChangePasswordPayload struct {
+	Password    string `json:"password"`
+	NewPassword string `json:"new_password"`
+}
+
+//ResetPasswordPayload - information used for setting a new password with a one-time reset token
+type ResetPasswordPayload struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+//ChangePassword - command for changing the password of the logged user
+//all previous tokens of the user are invalidated, so the newly issued ones are saved
+func ChangePassword(hostURL, token string) {
+	changeCommand := flag.NewFlagSet("change-password", flag.ExitOnError)
+	password := changeCommand.String("pass", "", "current password")
+	newPassword := changeCommand.String("new", "", "new password")
+	changeCommand.Parse(os.Args[2:])
+
+	if *password == "" || *newPassword == "" {
+		changeCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := ChangePasswordPayload{
+		Password:    *password,
+		NewPassword: *newPassword,
+	}
+
+	successBody := LoginResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Post(hostURL+endpoints.ChangePasswordAPIEndpoint, &rqBody, &successBody); err != nil {
+		fmt.Printf("Problem with changing the password. %s\n", err.Error())
+		return
+	}
+
+	fmt.Println("Password is changed. All other sessions and personal access tokens are invalidated")
+	saveSession(successBody)
+}
+
+//ResetPassword - command for setting a new password with a one-time reset token, issued by the administrator of the server
+func ResetPassword(hostURL string) {
+	resetCommand := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	resetToken := resetCommand.String("token", "", "password reset token")
+	newPassword := resetCommand.String("new", "", "new password")
+	resetCommand.Parse(os.Args[2:])
+
+	if *resetToken == "" || *newPassword == "" {
+		resetCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := ResetPasswordPayload{
+		Token:       *resetToken,
+		NewPassword: *newPassword,
+	}
+
+	restClient := restclient.NewRestClientImpl("")
+	if err := restClient.Put(hostURL+endpoints.ResetPasswordAPIEndpoint, &rqBody, nil); err != nil {
+		fmt.Printf("Problem with resetting the password. %s\n", err.Error())
+		return
+	}
+
+	if err := session.Remove(); err != nil {
+		fmt.Printf("Couldnt remove the previous session. Reason: %s\n", err.Error())
+	}
+	fmt.Println("Password is reset. Please login with the new password")
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//ShareLinkPayload - information used for the creation of a share link for a file
+type ShareLinkPayload struct {
+	FileRequest
+	Password       string `json:"password,omitempty"`
+	ExpiresInHours uint   `json:"expires_in_hours"`
+	MaxDownloads   uint   `json:"max_downloads"`
+}
+
+//ShareLinkRequestPayload - information used for the revocation of a share link
+type ShareLinkRequestPayload struct {
+	GroupPayload
+	LinkID uint `json:"link_id"`
+}
+
+//ShareLinkResponse - response, containing the newly created share link
+type ShareLinkResponse struct {
+	Status int    `json:"status"`
+	ID     uint   `json:"id"`
+	Token  string `json:"token"`
+}
+
+//ShareLinkInfo - contains all information about a share link, except its token
+type ShareLinkInfo struct {
+	ID                uint       `json:"id"`
+	FileID            uint       `json:"file_id"`
+	FileName          string     `json:"file_name"`
+	Version           uint       `json:"version"`
+	Creator           string     `json:"creator"`
+	PasswordProtected bool       `json:"password_protected"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+	MaxDownloads      uint       `json:"max_downloads"`
+	Downloads         uint       `json:"downloads"`
+}
+
+//ShareLinksResponse - response, containing the share links of a group
+type ShareLinksResponse struct {
+	Status int             `json:"status"`
+	Links  []ShareLinkInfo `json:"links"`
+}
+
+//ShareFile - command for creation of a link, through which a file can be downloaded without an account
+func ShareFile(hostURL, token string) {
+	shareFileCommand := flag.NewFlagSet("share-file", flag.ExitOnError)
+	fileID := shareFileCommand.Int("fileid", -1, "File id")
+	groupName := shareFileCommand.String("grp", "", "Name of the group")
+	password := shareFileCommand.String("pass", "", "Password, which has to be sent together with the link")
+	expiresInHours := shareFileCommand.Uint("exp", 0, "After how many hours the link expires")
+	maxDownloads := shareFileCommand.Uint("max", 0, "How many times the file can be downloaded through the link")
+
+	shareFileCommand.Parse(os.Args[2:])
+
+	if *fileID == -1 || *groupName == "" {
+		shareFileCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := ShareLinkPayload{
+		FileRequest: FileRequest{
+			FileID: uint(*fileID),
+		},
+		Password:       *password,
+		ExpiresInHours: *expiresInHours,
+		MaxDownloads:   *maxDownloads,
+	}
+	reqBody.GroupName = *groupName
+
+	successBody := ShareLinkResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Post(hostURL+endpoints.ShareLinksAPIEndpoint, &reqBody, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the creation of the share link. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Share link with id [%d] was created. It wont be shown again:\n%s%s/%s\n", successBody.ID, hostURL, endpoints.SharedFileAPIEndpoint, successBody.Token)
+	if *password != "" {
+		fmt.Println("The password has to be sent in the X-Share-Password header")
+	}
+}
+
+//ShowShareLinks - command for showing the share links of the files in a group
+func ShowShareLinks(hostURL, token string) {
+	showShareLinksCommand := flag.NewFlagSet("share-links", flag.ExitOnError)
+	groupName := showShareLinksCommand.String("grp", "", "Name of the group")
+
+	showShareLinksCommand.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		showShareLinksCommand.PrintDefaults()
+		return
+	}
+
+	successBody := ShareLinksResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	url := fmt.Sprintf("%s%s?group_name=%s", hostURL, endpoints.ShareLinksAPIEndpoint, *groupName)
+	err := restClient.Get(url, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the share links. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(successBody.Links))
+	for _, link := range successBody.Links {
+		expiresAt := "never"
+		if link.ExpiresAt != nil {
+			expiresAt = link.ExpiresAt.String()
+		}
+
+		downloads := fmt.Sprintf("%d", link.Downloads)
+		if link.MaxDownloads > 0 {
+			downloads = fmt.Sprintf("%d/%d", link.Downloads, link.MaxDownloads)
+		}
+		tableRows = append(tableRows, table.Row{link.ID, link.FileID, link.FileName, link.Version, link.Creator, link.PasswordProtected, expiresAt, downloads})
+	}
+	PrintTable(table.Row{"ID", "FileID", "FileName", "Version", "Creator", "Password", "ExpiresAt", "Downloads"}, tableRows)
+}
+
+//RevokeShareLink - command for revocation of a share link
+func RevokeShareLink(hostURL, token string) {
+	revokeShareCommand := flag.NewFlagSet("revoke-share", flag.ExitOnError)
+	linkID := revokeShareCommand.Uint("id", 0, "Id of the share link")
+	groupName := revokeShareCommand.String("grp", "", "Name of the group")
+
+	revokeShareCommand.Parse(os.Args[2:])
+
+	if *linkID == 0 || *groupName == "" {
+		revokeShareCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := ShareLinkRequestPayload{
+		LinkID: *linkID,
+	}
+	reqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Delete(hostURL+endpoints.ShareLinksAPIEndpoint, &reqBody, nil); err != nil {
+		fmt.Printf("Problem with the revocation of the share link. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Share link with id [%d] was revoked\n", *linkID)
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//PersonalAccessTokenPayload - information used for the creation of a personal access token
+type PersonalAccessTokenPayload struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	Groups        []string `json:"groups,omitempty"`
+	ExpiresInDays uint     `json:"expires_in_days"`
+}
+
+//PersonalAccessTokenRequestPayload - information used for the deletion of a personal access token
+type PersonalAccessTokenRequestPayload struct {
+	TokenID uint `json:"token_id"`
+}
+
+//PersonalAccessTokenResponse - response, containing the newly created personal access token
+type PersonalAccessTokenResponse struct {
+	Status int    `json:"status"`
+	ID     uint   `json:"id"`
+	Token  string `json:"token"`
+}
+
+//PersonalAccessTokenInfo - contains all information about a personal access token, except the token itself
+type PersonalAccessTokenInfo struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Groups     []string   `json:"groups"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+//PersonalAccessTokensResponse - response, containing the personal access tokens of the user
+type PersonalAccessTokensResponse struct {
+	Status uint                      `json:"status"`
+	Tokens []PersonalAccessTokenInfo `json:"tokens"`
+}
+
+//CreateToken - command for creation of a personal access token, which can be used instead of the JWT
+func CreateToken(hostURL, token string) {
+	createTokenCommand := flag.NewFlagSet("create-token", flag.ExitOnError)
+	name := createTokenCommand.String("name", "", "Name of the token")
+	scopes := createTokenCommand.String("scopes", "", "Comma separated scopes of the token - read, upload and write")
+	groups := createTokenCommand.String("grps", "", "Comma separated groups, to which the token is limited")
+	expiresInDays := createTokenCommand.Uint("exp", 0, "After how many days the token expires")
+	createTokenCommand.Parse(os.Args[2:])
+
+	if *name == "" || *scopes == "" || *expiresInDays == 0 {
+		createTokenCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := PersonalAccessTokenPayload{
+		Name:          *name,
+		Scopes:        splitList(*scopes),
+		Groups:        splitList(*groups),
+		ExpiresInDays: *expiresInDays,
+	}
+
+	successBody := PersonalAccessTokenResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Post(hostURL+endpoints.PersonalAccessTokensAPIEndpoint, &rqBody, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the creation of the token. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Token with id [%d] was created. It wont be shown again:\n%s\n", successBody.ID, successBody.Token)
+}
+
+//ShowTokens - command for showing the personal access tokens of the current user
+func ShowTokens(hostURL, token string) {
+	successBody := PersonalAccessTokensResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Get(hostURL+endpoints.PersonalAccessTokensAPIEndpoint, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the tokens. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(successBody.Tokens))
+	for _, tokenInfo := range successBody.Tokens {
+		groups := "all"
+		if len(tokenInfo.Groups) > 0 {
+			groups = strings.Join(tokenInfo.Groups, ",")
+		}
+
+		lastUsedAt := "never"
+		if tokenInfo.LastUsedAt != nil {
+			lastUsedAt = tokenInfo.LastUsedAt.String()
+		}
+		tableRows = append(tableRows, table.Row{tokenInfo.ID, tokenInfo.Name, strings.Join(tokenInfo.Scopes, ","), groups, tokenInfo.ExpiresAt, lastUsedAt})
+	}
+	PrintTable(table.Row{"ID", "Name", "Scopes", "Groups", "ExpiresAt", "LastUsedAt"}, tableRows)
+}
+
+//DeleteToken - command for deletion of a personal access token
+func DeleteToken(hostURL, token string) {
+	deleteTokenCommand := flag.NewFlagSet("delete-token", flag.ExitOnError)
+	tokenID := deleteTokenCommand.Uint("id", 0, "Id of the token")
+	deleteTokenCommand.Parse(os.Args[2:])
+
+	if *tokenID == 0 {
+		deleteTokenCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := PersonalAccessTokenRequestPayload{
+		TokenID: *tokenID,
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Delete(hostURL+endpoints.PersonalAccessTokensAPIEndpoint, &rqBody, nil); err != nil {
+		fmt.Printf("Problem with the deletion of the token. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Token with id [%d] was deleted\n", *tokenID)
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//TrashedFileInfo - contains all the information about a file in the trash
+type TrashedFileInfo struct {
+	FileInfo
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy uint      `json:"deleted_by"`
+}
+
+//TrashedFilesResponse - response, containing information about the files in the trash
+type TrashedFilesResponse struct {
+	Status    int               `json:"status"`
+	FilesInfo []TrashedFileInfo `json:"files"`
+}
+
+//ShowTrash - command for fetching information about the files in the trash of a group
+func ShowTrash(hostURL, token string) {
+	showTrashCommand := flag.NewFlagSet("show-trash", flag.ExitOnError)
+	groupName := showTrashCommand.String("grp", "", "Name of the group")
+
+	showTrashCommand.Parse(os.Args[2:])
+
+	if *groupName == "" {
+		showTrashCommand.PrintDefaults()
+		return
+	}
+
+	successBody := TrashedFilesResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	url := fmt.Sprintf("%s%s?group_name=%s", hostURL, endpoints.TrashAPIEndpoint, *groupName)
+	err := restClient.Get(url, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the trash. %s\n", err.Error())
+		return
+	}
+
+	tableRows := make([]table.Row, 0, len(successBody.FilesInfo))
+	for _, fileInfo := range successBody.FilesInfo {
+		tableRows = append(tableRows, table.Row{fileInfo.ID, fileInfo.Name, fileInfo.Version, fileInfo.OwnerID, fileInfo.Size, fileInfo.DeletedAt, fileInfo.DeletedBy})
+	}
+	PrintTable(table.Row{"ID", "Name", "Version", "OwnerID", "Size", "DeletedAt", "DeletedBy"}, tableRows)
+}
+
+//RestoreTrashedFile - command for restoring a file from the trash of a group
+func RestoreTrashedFile(hostURL, token string) {
+	restoreFileCommand := flag.NewFlagSet("restore-file", flag.ExitOnError)
+	fileID := restoreFileCommand.Int("fileid", -1, "File id")
+	groupName := restoreFileCommand.String("grp", "", "Name of the group")
+
+	restoreFileCommand.Parse(os.Args[2:])
+
+	if *fileID == -1 || *groupName == "" {
+		restoreFileCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := FileRequest{
+		FileID: uint(*fileID),
+	}
+	reqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Post(hostURL+endpoints.RestoreTrashedFileAPIEndpoint, &reqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the restoration of the file. %s\n", err.Error())
+		return
+	}
+
+	fmt.Println("File was successfully restored")
+}
+
+//PurgeTrashedFile - command for permanently deleting a file from the trash of a group
+func PurgeTrashedFile(hostURL, token string) {
+	purgeFileCommand := flag.NewFlagSet("purge-file", flag.ExitOnError)
+	fileID := purgeFileCommand.Int("fileid", -1, "File id")
+	groupName := purgeFileCommand.String("grp", "", "Name of the group")
+
+	purgeFileCommand.Parse(os.Args[2:])
+
+	if *fileID == -1 || *groupName == "" {
+		purgeFileCommand.PrintDefaults()
+		return
+	}
+
+	reqBody := FileRequest{
+		FileID: uint(*fileID),
+	}
+	reqBody.GroupName = *groupName
+
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Delete(hostURL+endpoints.DeleteTrashedFileAPIEndpoint, &reqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the permanent deletion of the file. %s\n", err.Error())
+		return
+	}
+
+	fmt.Println("File was permanently deleted")
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+)
+
+//TwoFactorCodePayload - information used for confirming and disabling the two-factor authentication
+type TwoFactorCodePayload struct {
+	Code string `json:"code"`
+}
+
+//TwoFactorEnrollmentResponse - response, containing the secret for the authenticator app
+type TwoFactorEnrollmentResponse struct {
+	Status int    `json:"status"`
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+//RecoveryCodesResponse - response, containing the recovery codes
+type RecoveryCodesResponse struct {
+	Status        int      `json:"status"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+//EnableTwoFactor - command for enabling the two-factor authentication
+//the secret is shown, so it can be added to an authenticator app, and the enrollment is confirmed with a code from the app
+func EnableTwoFactor(hostURL, token string) {
+	enrollment := TwoFactorEnrollmentResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Post(hostURL+endpoints.TwoFactorEnrollmentAPIEndpoint, nil, &enrollment); err != nil {
+		fmt.Printf("Problem with the enrollment of two-factor authentication. %s\n", err.Error())
+		return
+	}
+
+	fmt.Printf("Add the following secret to your authenticator app:\n%s\n", enrollment.Secret)
+	fmt.Printf("or open the following URI (for instance as a QR code):\n%s\n", enrollment.URI)
+
+	code, err := ReadLine("Enter the code from your authenticator app: ")
+	if err != nil {
+		fmt.Printf("Couldnt read the code. Reason: %s\n", err.Error())
+		return
+	}
+
+	rqBody := TwoFactorCodePayload{
+		Code: code,
+	}
+
+	successBody := RecoveryCodesResponse{}
+	if err = restClient.Post(hostURL+endpoints.TwoFactorConfirmationAPIEndpoint, &rqBody, &successBody); err != nil {
+		fmt.Printf("Problem with the confirmation of two-factor authentication. %s\n", err.Error())
+		return
+	}
+
+	fmt.Println("Two-factor authentication is enabled. Keep the following recovery codes in a safe place, they wont be shown again:")
+	for _, recoveryCode := range successBody.RecoveryCodes {
+		fmt.Println(recoveryCode)
+	}
+}
+
+//DisableTwoFactor - command for disabling the two-factor authentication
+func DisableTwoFactor(hostURL, token string) {
+	disableCommand := flag.NewFlagSet("disable-2fa", flag.ExitOnError)
+	code := disableCommand.String("code", "", "code from the authenticator app or a recovery code")
+	disableCommand.Parse(os.Args[2:])
+
+	if *code == "" {
+		disableCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := TwoFactorCodePayload{
+		Code: *code,
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	if err := restClient.Delete(hostURL+endpoints.TwoFactorAPIEndpoint, &rqBody, nil); err != nil {
+		fmt.Printf("Problem with disabling the two-factor authentication. %s\n", err.Error())
+		return
+	}
+
+	fmt.Println("Two-factor authentication is disabled")
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
+	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+//StorageUsageResponse - response, containing the storage used by a user or a group and its quota
+type StorageUsageResponse struct {
+	Status     int   `json:"status"`
+	Files      int64 `json:"files"`
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+//ShowUsage - command for showing the storage used by the current user or, if a group is specified, by the group
+func ShowUsage(hostURL, token string) {
+	showUsageCommand := flag.NewFlagSet("show-usage", flag.ExitOnError)
+	groupName := showUsageCommand.String("grp", "", "Name of the group (the usage of the current user by default)")
+
+	showUsageCommand.Parse(os.Args[2:])
+
+	usageURL := hostURL + endpoints.UserUsageAPIEndpoint
+	if *groupName != "" {
+		usageURL = fmt.Sprintf("%s%s?group_name=%s", hostURL, endpoints.GroupUsageAPIEndpoint, url.QueryEscape(*groupName))
+	}
+
+	successBody := StorageUsageResponse{}
+	restClient := restclient.NewRestClientImpl(token)
+	err := restClient.Get(usageURL, &successBody)
+
+	if err != nil {
+		fmt.Printf("Problem with the retrieval of the storage usage. %s\n", err.Error())
+		return
+	}
+
+	quota := "unlimited"
+	if successBody.QuotaBytes > 0 {
+		quota = fmt.Sprint(successBody.QuotaBytes)
+	}
+	PrintTable(table.Row{"Files", "UsedBytes", "QuotaBytes"}, []table.Row{{successBody.Files, successBody.UsedBytes, quota}})
+}
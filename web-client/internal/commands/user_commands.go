@@ -7,13 +7,29 @@ import (
 
 	"github.com/danielpenchev98/UShare/web-client/internal/endpoints"
 	"github.com/danielpenchev98/UShare/web-client/internal/restclient"
+	"github.com/danielpenchev98/UShare/web-client/internal/session"
 	"github.com/jedib0t/go-pretty/v6/table"
 )
 
-//LoginResponse - response, containing the jw token
+//LoginResponse - response, containing the jw token and the refresh token
+//if the user has enabled two-factor authentication, only a challenge is returned, which is completed with a code
 type LoginResponse struct {
-	Status int    `json:"status"`
-	Token  string `json:"token"`
+	Status            int    `json:"status"`
+	Token             string `json:"token"`
+	RefreshToken      string `json:"refresh_token"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	Challenge         string `json:"challenge"`
+}
+
+//LoginVerificationPayload - information used for completing the login with a TOTP code or a recovery code
+type LoginVerificationPayload struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+//RefreshTokenPayload - information used for the logout of the user
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
 //CredentialsPayload - information used for the login and registration of user
@@ -22,10 +38,18 @@ type CredentialsPayload struct {
 	Password string `json:"password"`
 }
 
+//DeleteAccountPayload - information used for the deletion of the account of the user
+type DeleteAccountPayload struct {
+	Password    string `json:"password"`
+	GroupPolicy string `json:"group_policy,omitempty"`
+	FilePolicy  string `json:"file_policy,omitempty"`
+}
+
 //UserInfo - contains information about a user
 type UserInfo struct {
 	ID       uint   `json:"id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 }
 
 //UsersInfoResponse - response containing information about multiple users
@@ -71,6 +95,7 @@ func Login(hostURL string) {
 
 	username := loginCommand.String("usr", "", "username")
 	password := loginCommand.String("pass", "", "password")
+	code := loginCommand.String("code", "", "code from the authenticator app or a recovery code, if two-factor authentication is enabled")
 
 	loginCommand.Parse(os.Args[2:])
 
@@ -95,8 +120,110 @@ func Login(hostURL string) {
 		return
 	}
 
+	if successBody.TwoFactorRequired {
+		if successBody, err = verifyLogin(hostURL, successBody.Challenge, *code); err != nil {
+			fmt.Printf("Problem with the login request. %s\n", err.Error())
+			return
+		}
+	}
+
 	fmt.Println("Login is successful")
-	fmt.Printf("Please set the env variable 'JWT' with the following value:\n%s\n", successBody.Token)
+	saveSession(successBody)
+}
+
+//saveSession - saves the issued tokens, so the next commands can use them
+func saveSession(successBody LoginResponse) {
+	userSession := session.Session{
+		Token:        successBody.Token,
+		RefreshToken: successBody.RefreshToken,
+	}
+
+	if err := session.Save(userSession); err != nil {
+		fmt.Printf("Couldnt save the session. Reason: %s\n", err.Error())
+		fmt.Printf("Please set the env variable 'JWT' with the following value:\n%s\n", successBody.Token)
+	}
+}
+
+//verifyLogin - completes the login with a code, which is read from the standard input, if it isnt specified
+func verifyLogin(hostURL string, challenge string, code string) (LoginResponse, error) {
+	if code == "" {
+		var err error
+		if code, err = ReadLine("Enter the code from your authenticator app or a recovery code: "); err != nil {
+			return LoginResponse{}, err
+		}
+	}
+
+	rqBody := LoginVerificationPayload{
+		Challenge: challenge,
+		Code:      code,
+	}
+
+	successBody := LoginResponse{}
+	restClient := restclient.NewRestClientImpl("")
+	err := restClient.Post(hostURL+endpoints.LoginVerificationAPIEndpoint, &rqBody, &successBody)
+	return successBody, err
+}
+
+//Logout - command for logout of the user. The tokens of the user are revoked and the session is removed
+func Logout(hostURL string, token string) {
+	userSession, err := session.Load()
+	if err != nil {
+		fmt.Printf("Couldnt read the session. Reason: %s\n", err.Error())
+	}
+
+	rqBody := RefreshTokenPayload{
+		RefreshToken: userSession.RefreshToken,
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	url := hostURL + endpoints.LogoutAPIEndpoint
+	err = restClient.Delete(url, &rqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the logout request. %s\n", err.Error())
+		return
+	}
+
+	if err = session.Remove(); err != nil {
+		fmt.Printf("Couldnt remove the session. Reason: %s\n", err.Error())
+		return
+	}
+
+	fmt.Println("Logout is successful")
+}
+
+//DeleteUser - command for deletion of the account of the logged user
+func DeleteUser(hostURL string, token string) {
+	deleteUserCommand := flag.NewFlagSet("delete-user", flag.ExitOnError)
+
+	password := deleteUserCommand.String("pass", "", "password")
+	groupPolicy := deleteUserCommand.String("groups", "", "What happens with your groups - transfer (default) or deactivate")
+	filePolicy := deleteUserCommand.String("files", "", "What happens with your files in other groups - reassign (default) or delete")
+
+	deleteUserCommand.Parse(os.Args[2:])
+
+	if *password == "" {
+		deleteUserCommand.PrintDefaults()
+		return
+	}
+
+	rqBody := DeleteAccountPayload{
+		Password:    *password,
+		GroupPolicy: *groupPolicy,
+		FilePolicy:  *filePolicy,
+	}
+
+	restClient := restclient.NewRestClientImpl(token)
+	url := hostURL + endpoints.DeleteUserAPIEndpoint
+	err := restClient.Delete(url, &rqBody, nil)
+
+	if err != nil {
+		fmt.Printf("Problem with the user deletion request. %s\n", err.Error())
+		return
+	}
+
+	session.Remove()
+	fmt.Println("User successfully deleted")
 }
 
 //ShowAllUsers - command for showing information about all users
@@ -143,7 +270,7 @@ func ShowAllMembers(hostURL, token string) {
 
 	tableRows := make([]table.Row, len(successBody.UsersInfo))
 	for _, userInfo := range successBody.UsersInfo {
-		tableRows = append(tableRows, table.Row{userInfo.ID, userInfo.Username})
+		tableRows = append(tableRows, table.Row{userInfo.ID, userInfo.Username, userInfo.Role})
 	}
-	PrintTable(table.Row{"ID", "Username"}, tableRows)
+	PrintTable(table.Row{"ID", "Username", "Role"}, tableRows)
 }
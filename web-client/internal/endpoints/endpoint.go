@@ -7,30 +7,126 @@ const (
 	publicAPIPath = apiVersionPath + "/public"
 	//protectedAPIPath - protected api path
 	protectedAPIPath = apiVersionPath + "/protected"
+	//adminAPIPath - api path, accessible only by the administrators
+	adminAPIPath = apiVersionPath + "/admin"
 	//LoginAPIEndpoint - api endpoint for user login
 	LoginAPIEndpoint = publicAPIPath + "/user/login"
+	//LoginVerificationAPIEndpoint - api endpoint for completing the login with a code from the authenticator app
+	LoginVerificationAPIEndpoint = LoginAPIEndpoint + "/verification"
 	//RegisterAPIEndpoint - api endpoint for user registration
 	RegisterAPIEndpoint = publicAPIPath + "/user/registration"
+	//RefreshTokenAPIEndpoint - api endpoint for exchanging a refresh token for new tokens
+	RefreshTokenAPIEndpoint = publicAPIPath + "/user/token/refresh"
+	//ResetPasswordAPIEndpoint - api endpoint for setting a new password with a one-time reset token
+	ResetPasswordAPIEndpoint = publicAPIPath + "/user/password/reset"
+	//ChangePasswordAPIEndpoint - api endpoint for changing the password of the user
+	ChangePasswordAPIEndpoint = protectedAPIPath + "/user/password"
+	//LogoutAPIEndpoint - api endpoint for user logout
+	LogoutAPIEndpoint = protectedAPIPath + "/user/logout"
+	//PersonalAccessTokensAPIEndpoint - api endpoint for creation, retrieval and deletion of the personal access tokens of the user
+	PersonalAccessTokensAPIEndpoint = protectedAPIPath + "/user/tokens"
+	//TwoFactorAPIEndpoint - api endpoint for disabling the two-factor authentication
+	TwoFactorAPIEndpoint = protectedAPIPath + "/user/2fa"
+	//TwoFactorEnrollmentAPIEndpoint - api endpoint for starting the enrollment of two-factor authentication
+	TwoFactorEnrollmentAPIEndpoint = TwoFactorAPIEndpoint + "/enrollment"
+	//TwoFactorConfirmationAPIEndpoint - api endpoint for confirming the enrollment of two-factor authentication
+	TwoFactorConfirmationAPIEndpoint = TwoFactorAPIEndpoint + "/confirmation"
+	//DeleteUserAPIEndpoint - api endpoint for deletion of the account of the user
+	DeleteUserAPIEndpoint = protectedAPIPath + "/group/user/deletion"
 	//CreateGroupAPIEndpoint - api endpoint for group creation
 	CreateGroupAPIEndpoint = protectedAPIPath + "/group/creation"
 	//DeleteGroupAPIEndpoint - api endpoint for group deletion
 	DeleteGroupAPIEndpoint = protectedAPIPath + "/group/deletion"
-	//AddMemberAPIEndpoint - api endpoint for adding an user to a group
-	AddMemberAPIEndpoint = protectedAPIPath + "/group/invitation"
+	//InviteMemberAPIEndpoint - api endpoint for inviting an user to a group
+	InviteMemberAPIEndpoint = protectedAPIPath + "/group/invitation"
+	//AcceptInvitationAPIEndpoint - api endpoint for accepting an invitation to a group
+	AcceptInvitationAPIEndpoint = InviteMemberAPIEndpoint + "/acceptance"
+	//DeclineInvitationAPIEndpoint - api endpoint for declining an invitation to a group
+	DeclineInvitationAPIEndpoint = InviteMemberAPIEndpoint + "/declination"
+	//InvitationsAPIEndpoint - api endpoint for fetching the pending invitations of the current user
+	InvitationsAPIEndpoint = protectedAPIPath + "/user/invitations"
 	//RemoveMemberAPIEndpoint - api endpoint for removing an user from a group
 	RemoveMemberAPIEndpoint = protectedAPIPath + "/group/membership/revocation"
+	//TransferGroupAPIEndpoint - api endpoint for making another member the owner of a group
+	TransferGroupAPIEndpoint = protectedAPIPath + "/group/ownership"
+	//MemberRoleAPIEndpoint - api endpoint for changing the role of a member of a group
+	MemberRoleAPIEndpoint = protectedAPIPath + "/group/membership/role"
+	//GroupVisibilityAPIEndpoint - api endpoint for changing who can see and join a group
+	GroupVisibilityAPIEndpoint = protectedAPIPath + "/group/visibility"
+	//JoinGroupAPIEndpoint - api endpoint for joining an open group or requesting to join a discoverable group
+	JoinGroupAPIEndpoint = protectedAPIPath + "/group/join"
+	//JoinRequestsAPIEndpoint - api endpoint for fetching the pending join requests of a group
+	JoinRequestsAPIEndpoint = JoinGroupAPIEndpoint + "/requests"
+	//ResolveJoinRequestAPIEndpoint - api endpoint for approving or rejecting a join request
+	ResolveJoinRequestAPIEndpoint = JoinRequestsAPIEndpoint + "/resolution"
 	//UploadFileAPIEndpoint - api endpoint for uploading a file for a specific group
 	UploadFileAPIEndpoint = protectedAPIPath + "/group/file/upload"
+	//UploadSessionAPIEndpoint - api endpoint for creation, retrieval and cancellation of an upload session
+	UploadSessionAPIEndpoint = protectedAPIPath + "/group/file/upload/session"
+	//UploadChunkAPIEndpoint - api endpoint for uploading a part of a file in an upload session
+	UploadChunkAPIEndpoint = UploadSessionAPIEndpoint + "/chunk"
+	//FinalizeUploadAPIEndpoint - api endpoint for assembling the uploaded parts into a file
+	FinalizeUploadAPIEndpoint = UploadSessionAPIEndpoint + "/finalization"
 	//DownloadFileAPIEndpoint - api endpoint for downloading a file from a specific group
 	DownloadFileAPIEndpoint = protectedAPIPath + "/group/file/download"
 	//DeleteFileAPIEndpoint - api endpoint for deleting file, given a group
 	DeleteFileAPIEndpoint = protectedAPIPath + "/group/file/deletion"
 	//GetAllFilesAPIEndpoint - api endpoint for fetching all files, uploaded for a specific group
 	GetAllFilesAPIEndpoint = protectedAPIPath + "/group/files"
+	//FileVersionsAPIEndpoint - api endpoint for fetching all versions of a file
+	FileVersionsAPIEndpoint = protectedAPIPath + "/group/file/versions"
+	//RestoreFileVersionAPIEndpoint - api endpoint for restoring an old version of a file
+	RestoreFileVersionAPIEndpoint = FileVersionsAPIEndpoint + "/restoration"
+	//MaxFileVersionsAPIEndpoint - api endpoint for changing how many versions of each file are kept in a group
+	MaxFileVersionsAPIEndpoint = FileVersionsAPIEndpoint + "/limit"
+	//TrashAPIEndpoint - api endpoint for fetching the files in the trash of a group
+	TrashAPIEndpoint = protectedAPIPath + "/group/trash"
+	//RestoreTrashedFileAPIEndpoint - api endpoint for restoring a file from the trash
+	RestoreTrashedFileAPIEndpoint = TrashAPIEndpoint + "/restoration"
+	//DeleteTrashedFileAPIEndpoint - api endpoint for permanently deleting a file from the trash
+	DeleteTrashedFileAPIEndpoint = TrashAPIEndpoint + "/deletion"
+	//MoveFileAPIEndpoint - api endpoint for moving a file in another folder of the group
+	MoveFileAPIEndpoint = protectedAPIPath + "/group/file/move"
+	//ShareLinksAPIEndpoint - api endpoint for creation, retrieval and revocation of the share links of a group
+	ShareLinksAPIEndpoint = protectedAPIPath + "/group/file/share"
+	//SharedFileAPIEndpoint - publicly accessible api endpoint for downloading a file through a share link, followed by its token
+	SharedFileAPIEndpoint = publicAPIPath + "/share"
+	//DropBoxesAPIEndpoint - api endpoint for creation, retrieval and deletion of the drop boxes of a group
+	DropBoxesAPIEndpoint = protectedAPIPath + "/group/dropbox"
+	//DropBoxUploadAPIEndpoint - publicly accessible api endpoint for sending a file through a drop box, followed by its token
+	DropBoxUploadAPIEndpoint = publicAPIPath + "/dropbox"
+	//FolderAPIEndpoint - api endpoint for creating and deleting folders of a group
+	FolderAPIEndpoint = protectedAPIPath + "/group/folder"
+	//RenameFolderAPIEndpoint - api endpoint for renaming a folder of a group
+	RenameFolderAPIEndpoint = FolderAPIEndpoint + "/rename"
+	//MoveFolderAPIEndpoint - api endpoint for moving a folder of a group inside another folder
+	MoveFolderAPIEndpoint = FolderAPIEndpoint + "/move"
+	//GroupUsageAPIEndpoint - api endpoint for fetching the storage, used by the files of a group
+	GroupUsageAPIEndpoint = protectedAPIPath + "/group/usage"
+	//UserUsageAPIEndpoint - api endpoint for fetching the storage, used by the files of the current user
+	UserUsageAPIEndpoint = protectedAPIPath + "/user/usage"
 	//GetAllGroupsAPIEndpoint - api endpoint for fetching all existing groups
 	GetAllGroupsAPIEndpoint = protectedAPIPath + "/groups"
 	//GetAllUsersAPIEndpoint - api endpoint for fetching all users
 	GetAllUsersAPIEndpoint = protectedAPIPath + "/users"
 	//GetAllMembersAPIEndpoint - api endpoint for fetching all members of a group
 	GetAllMembersAPIEndpoint = protectedAPIPath + "/group/users"
+	//GroupAuditAPIEndpoint - api endpoint for fetching the audit log of a group
+	GroupAuditAPIEndpoint = protectedAPIPath + "/group/audit"
+	//AdminUsersAPIEndpoint - api endpoint for fetching all users together with their role, status and used storage
+	AdminUsersAPIEndpoint = adminAPIPath + "/users"
+	//AdminUserAPIEndpoint - api endpoint for deletion of the account of any user
+	AdminUserAPIEndpoint = adminAPIPath + "/user"
+	//AdminSuspensionAPIEndpoint - api endpoint for suspending a user and lifting the suspension
+	AdminSuspensionAPIEndpoint = AdminUserAPIEndpoint + "/suspension"
+	//AdminPasswordResetAPIEndpoint - api endpoint for issuing a password reset token for any user
+	AdminPasswordResetAPIEndpoint = AdminUserAPIEndpoint + "/password-reset"
+	//AdminGroupAPIEndpoint - api endpoint for deactivation of any group
+	AdminGroupAPIEndpoint = adminAPIPath + "/group"
+	//AdminGroupOwnershipAPIEndpoint - api endpoint for making any user the owner of a group
+	AdminGroupOwnershipAPIEndpoint = AdminGroupAPIEndpoint + "/ownership"
+	//AdminStorageAPIEndpoint - api endpoint for fetching the system-wide storage totals
+	AdminStorageAPIEndpoint = adminAPIPath + "/storage"
+	//AdminAuditAPIEndpoint - api endpoint for fetching the audit log of the whole server
+	AdminAuditAPIEndpoint = adminAPIPath + "/audit"
 )
@@ -0,0 +1,69 @@
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+//tokenRefresher - obtains a new access token with the refresh token, when the access token expires
+type tokenRefresher struct {
+	url          string
+	refreshToken string
+	store        func(token, refreshToken string) error
+}
+
+type tokensResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+var refresher *tokenRefresher
+
+//EnableTokenRefresh - makes every client, whose request is rejected because of an expired access token,
+//obtain new tokens from url and retry the request. The new tokens are passed to store, so they can be reused
+func EnableTokenRefresh(url, refreshToken string, store func(token, refreshToken string) error) {
+	refresher = &tokenRefresher{
+		url:          url,
+		refreshToken: refreshToken,
+		store:        store,
+	}
+}
+
+//execute - sends the request, created by newRequest. If the access token has expired, the tokens are refreshed and the request is sent again
+func (i *RestClientImpl) execute(method, url string, newRequest func() *resty.Request) (*resty.Response, error) {
+	resp, err := newRequest().Execute(method, url)
+	if err != nil || resp.StatusCode() != http.StatusUnauthorized || !i.refreshTokens() {
+		return resp, err
+	}
+
+	if body := resp.RawBody(); body != nil {
+		body.Close()
+	}
+	return newRequest().Execute(method, url)
+}
+
+func (i *RestClientImpl) refreshTokens() bool {
+	if refresher == nil || i.jwtToken == "" {
+		return false
+	}
+
+	successBody := tokensResponse{}
+	resp, err := i.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]string{"refresh_token": refresher.refreshToken}).
+		SetResult(&successBody).
+		Post(refresher.url)
+
+	if err != nil || resp.StatusCode() != http.StatusCreated {
+		return false
+	}
+
+	refresher.refreshToken = successBody.RefreshToken
+	i.jwtToken = successBody.Token
+	if err = refresher.store(successBody.Token, successBody.RefreshToken); err != nil {
+		fmt.Printf("Couldnt save the refreshed tokens. Reason: %s\n", err.Error())
+	}
+	return true
+}
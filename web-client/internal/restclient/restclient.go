@@ -1,8 +1,15 @@
 package restclient
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
 )
@@ -12,14 +19,19 @@ type RestClient interface {
 	Post(url string, rqBody, successBody interface{}) error
 	Get(url string, successBody, errorBody interface{}) error
 	Delete(url string, rqBody, successBody interface{}) error
-	UploadFile(url string, filePath string, successBody interface{}) error
+	UploadFile(url string, filePath string, formData map[string]string, successBody interface{}) error
+	UploadChunk(url string, chunk []byte) error
 	DownloadFile(url string, targetPath string, reqBody interface{}) error
 }
 
+//ChecksumHeader - header, containing the hex encoded SHA-256 checksum of a file
+const ChecksumHeader = "X-Content-SHA256"
+
 //RestClientImpl - implementation of RestClient
 type RestClientImpl struct {
 	client   *resty.Client
 	jwtToken string
+	headers  map[string]string
 }
 
 type errorResponse struct {
@@ -32,15 +44,22 @@ func NewRestClientImpl(jwtToken string) *RestClientImpl {
 	return &RestClientImpl{
 		client:   resty.New(),
 		jwtToken: jwtToken,
+		headers:  make(map[string]string),
 	}
 }
 
+//SetHeader - sets a header, which is sent with every following request
+func (i *RestClientImpl) SetHeader(name, value string) {
+	i.headers[name] = value
+}
+
 //Post - creation of resources
 func (i *RestClientImpl) Post(url string, rqBody, successBody interface{}) error {
 	errorBody := errorResponse{}
-	resp, err := i.basicRequest(successBody, &errorBody).
-		SetBody(rqBody).
-		Post(url)
+	resp, err := i.execute(resty.MethodPost, url, func() *resty.Request {
+		return i.basicRequest(successBody, &errorBody).
+			SetBody(rqBody)
+	})
 
 	if err != nil {
 		return err
@@ -55,8 +74,9 @@ func (i *RestClientImpl) Post(url string, rqBody, successBody interface{}) error
 //Get - retrieval of resources
 func (i *RestClientImpl) Get(url string, successBody interface{}) error {
 	errorBody := errorResponse{}
-	resp, err := i.basicRequest(successBody, &errorBody).
-		Get(url)
+	resp, err := i.execute(resty.MethodGet, url, func() *resty.Request {
+		return i.basicRequest(successBody, &errorBody)
+	})
 
 	if err != nil {
 		return err
@@ -68,12 +88,31 @@ func (i *RestClientImpl) Get(url string, successBody interface{}) error {
 	return nil
 }
 
+//Put - modification of resources
+func (i *RestClientImpl) Put(url string, rqBody, successBody interface{}) error {
+	errorBody := errorResponse{}
+	resp, err := i.execute(resty.MethodPut, url, func() *resty.Request {
+		return i.basicRequest(successBody, &errorBody).
+			SetBody(rqBody)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("Problem with Put request. Reason: %s", errorBody.ErrorMsg)
+	}
+	return nil
+}
+
 //Delete - deletion of resources
 func (i *RestClientImpl) Delete(url string, reqBody, successBody interface{}) error {
 	errorBody := errorResponse{}
-	resp, err := i.basicRequest(successBody, &errorBody).
-		SetBody(reqBody).
-		Delete(url)
+	resp, err := i.execute(resty.MethodDelete, url, func() *resty.Request {
+		return i.basicRequest(successBody, &errorBody).
+			SetBody(reqBody)
+	})
 
 	if err != nil {
 		return err
@@ -85,56 +124,169 @@ func (i *RestClientImpl) Delete(url string, reqBody, successBody interface{}) er
 	return nil
 }
 
-//UploadFile - similar to POST, but it uses form-data to include the payload (file)
-func (i *RestClientImpl) UploadFile(url string, filePath string, successBody interface{}) error {
+//UploadFile - similar to POST, but it uses form-data to include the payload (file and optional fields)
+func (i *RestClientImpl) UploadFile(url string, filePath string, formData map[string]string, successBody interface{}) error {
 	errorBody := errorResponse{}
-	req := i.client.R().
-		SetFile("file", filePath).
-		SetError(&errorBody)
+	resp, err := i.execute(resty.MethodPost, url, func() *resty.Request {
+		req := i.client.R().
+			SetFile("file", filePath).
+			SetFormData(formData).
+			SetError(&errorBody)
 
-	if i.jwtToken != "" {
-		req.SetAuthToken(i.jwtToken)
+		if i.jwtToken != "" {
+			req.SetAuthToken(i.jwtToken)
+		}
+		req.SetHeaders(i.headers)
+
+		if successBody != nil {
+			req.SetResult(successBody)
+		}
+		return req
+	})
+	if err != nil {
+		return err
 	}
 
-	if successBody != nil {
-		req.SetResult(successBody)
+	if resp.StatusCode() != http.StatusCreated {
+		return fmt.Errorf("Problem with the Upload file request. Reason: %s", errorBody.ErrorMsg)
 	}
 
-	resp, err := req.Post(url)
+	return nil
+}
+
+//UploadChunk - similar to PUT, but the payload is the raw content of a part of a file
+func (i *RestClientImpl) UploadChunk(url string, chunk []byte) error {
+	errorBody := errorResponse{}
+	resp, err := i.execute(resty.MethodPut, url, func() *resty.Request {
+		req := i.client.R().
+			SetHeader("Content-Type", "application/octet-stream").
+			SetBody(chunk).
+			SetError(&errorBody)
+
+		if i.jwtToken != "" {
+			req.SetAuthToken(i.jwtToken)
+		}
+		return req.SetHeaders(i.headers)
+	})
 	if err != nil {
 		return err
 	}
 
-	if resp.StatusCode() != http.StatusCreated {
-		return fmt.Errorf("Problem with the Upload file request. Reason: %s", errorBody.ErrorMsg)
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("Problem with the Upload chunk request. Reason: %s", errorBody.ErrorMsg)
 	}
 
 	return nil
 }
 
 //DownloadFile - similar to GET, but it requires the target location where the file will be downloaded
+//the ETag of an unfinished download is kept next to the target, so the next call requests only the missing part
 func (i *RestClientImpl) DownloadFile(url string, targetPath string) error {
-	errorBody := errorResponse{}
-	req := i.client.R().
-		SetOutput(targetPath).
-		SetError(&errorBody)
+	etagPath := targetPath + ".etag"
 
-	if i.jwtToken != "" {
-		req.SetAuthToken(i.jwtToken)
-	}
+	resp, err := i.execute(resty.MethodGet, url, func() *resty.Request {
+		req := i.client.R().
+			SetDoNotParseResponse(true)
 
-	resp, err := req.Get(url)
+		if i.jwtToken != "" {
+			req.SetAuthToken(i.jwtToken)
+		}
+		req.SetHeaders(i.headers)
+
+		if etag, err := ioutil.ReadFile(etagPath); err == nil && len(etag) != 0 {
+			if info, err := os.Stat(targetPath); err == nil && info.Size() > 0 {
+				req.SetHeader("Range", fmt.Sprintf("bytes=%d-", info.Size())).
+					SetHeader("If-Range", string(etag))
+			}
+		}
+		return req
+	})
 	if err != nil {
 		return err
 	}
+	body := resp.RawBody()
+	defer body.Close()
 
-	if resp.StatusCode() != http.StatusOK {
+	var flags int
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	case http.StatusPartialContent:
+		flags = os.O_WRONLY | os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		//the file might have been completely downloaded, before the previous download was interrupted
+		//otherwise the local file is stale, so the download is restarted from the beginning
+		os.Remove(etagPath)
+		if expected := resp.Header().Get(ChecksumHeader); expected != "" {
+			if actual, err := fileChecksum(targetPath); err == nil && strings.EqualFold(actual, expected) {
+				return nil
+			}
+		}
+		os.Remove(targetPath)
+		return i.DownloadFile(url, targetPath)
+	default:
+		errorBody := errorResponse{}
+		json.NewDecoder(body).Decode(&errorBody)
 		return fmt.Errorf("Problem with the Download file request. Reason: %s", errorBody.ErrorMsg)
 	}
 
+	if etag := resp.Header().Get("ETag"); etag != "" {
+		ioutil.WriteFile(etagPath, []byte(etag), 0644)
+	} else {
+		os.Remove(etagPath)
+	}
+
+	file, err := os.OpenFile(targetPath, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(file, body); err != nil {
+		file.Close()
+		return fmt.Errorf("Problem with the Download file request. Reason: %s", err.Error())
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+	os.Remove(etagPath)
+
+	if expected := resp.Header().Get(ChecksumHeader); expected != "" {
+		return verifyChecksum(targetPath, expected)
+	}
+	return nil
+}
+
+//verifyChecksum - checks if the SHA-256 checksum of the file matches the expected one
+//a file, which doesnt match, is removed so it isnt mistaken for a correct one
+func verifyChecksum(filePath, expected string) error {
+	actual, err := fileChecksum(filePath)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(actual, expected) {
+		os.Remove(filePath)
+		return fmt.Errorf("The checksum of the downloaded file [%s] doesnt match the expected one [%s]. The file was removed", actual, expected)
+	}
 	return nil
 }
 
+//fileChecksum - returns the hex encoded SHA-256 checksum of the file
+func fileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 func (i *RestClientImpl) basicRequest(successBody, errorBody interface{}) *resty.Request {
 	req := i.client.R().
 		SetHeader("Content-Type", "application/json").
@@ -144,6 +296,7 @@ func (i *RestClientImpl) basicRequest(successBody, errorBody interface{}) *resty
 	if i.jwtToken != "" {
 		req.SetAuthToken(i.jwtToken)
 	}
+	req.SetHeaders(i.headers)
 
 	if successBody != nil {
 		req.SetResult(&successBody)
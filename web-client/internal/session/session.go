@@ -0,0 +1,74 @@
+package session
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+//Session - the tokens of the logged user, kept between the executions of the client
+type Session struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+//Path - returns the path of the file, in which the session is kept
+func Path() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "ushare", "session.json"), nil
+}
+
+//Load - reads the session of the logged user. An empty session is returned, if the user isnt logged in
+func Load() (Session, error) {
+	var session Session
+
+	sessionPath, err := Path()
+	if err != nil {
+		return session, err
+	}
+
+	content, err := ioutil.ReadFile(sessionPath)
+	if os.IsNotExist(err) {
+		return session, nil
+	} else if err != nil {
+		return session, err
+	}
+
+	err = json.Unmarshal(content, &session)
+	return session, err
+}
+
+//Save - stores the session, so that only the current user can read it
+func Save(session Session) error {
+	sessionPath, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(sessionPath), 0700); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(sessionPath, content, 0600)
+}
+
+//Remove - removes the stored session
+func Remove() error {
+	sessionPath, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(sessionPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -3,7 +3,11 @@ package common
 import (
 	"fmt"
 	"log"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
 	"github.com/gin-gonic/gin"
@@ -24,9 +28,40 @@ func GetIDFromContext(c *gin.Context) (uint, error) {
 	return userID, nil
 }
 
+//GetTokenFromContext - extracts the id and the expiration moment of the access token from the context
+func GetTokenFromContext(c *gin.Context) (string, time.Time, error) {
+	tokenID := c.GetString("tokenID")
+	expiresAt := c.GetTime("tokenExpiresAt")
+	if tokenID == "" || expiresAt.IsZero() {
+		log.Println("Problem retieval of the access token from context.")
+		return "", time.Time{}, myerr.NewServerError("Cannot retrieve the access token")
+	}
+	return tokenID, expiresAt, nil
+}
+
+//ClientIPKey - the key in the context, under which the IP of the client, resolved by the middleware, is stored
+const ClientIPKey = "clientIP"
+
+//GetClientIP - returns the IP of the client, resolved by the middleware, or the address of the connection otherwise
+//unlike gin's ClientIP, the forwarded headers, which can be set by anyone, arent trusted here
+func GetClientIP(c *gin.Context) string {
+	if ip := c.GetString(ClientIPKey); ip != "" {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		return c.Request.RemoteAddr
+	}
+	return host
+}
+
 //SendErrorResponse - generic method for sending error response to the user
 func SendErrorResponse(c *gin.Context, err error) {
 	errorCode, errorMsg := getErrorResponseArguments(err)
+	if tooManyRequestsErr, ok := err.(*myerr.TooManyRequestsError); ok && tooManyRequestsErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(tooManyRequestsErr.RetryAfter.Seconds()))))
+	}
 	c.JSON(errorCode, ErrorResponse{
 		ErrorCode: errorCode,
 		ErrorMsg:  errorMsg,
@@ -41,6 +76,12 @@ func getErrorResponseArguments(err error) (errorCode int, errorMsg string) {
 	case *myerr.ItemNotFoundError:
 		errorCode = http.StatusNotFound
 		errorMsg = err.Error()
+	case *myerr.QuotaExceededError:
+		errorCode = http.StatusRequestEntityTooLarge
+		errorMsg = err.Error()
+	case *myerr.TooManyRequestsError:
+		errorCode = http.StatusTooManyRequests
+		errorMsg = err.Error()
 	default:
 		log.Println(err)
 		errorCode = http.StatusInternalServerError
@@ -48,3 +89,24 @@ func getErrorResponseArguments(err error) (errorCode int, errorMsg string) {
 	}
 	return
 }
+
+//SuspensionMessage - builds the message, with which the requests of a suspended user are rejected
+func SuspensionMessage(reason string, until *time.Time) string {
+	if until == nil {
+		return fmt.Sprintf("The account is suspended. Reason: %s", reason)
+	}
+	return fmt.Sprintf("The account is suspended until %s. Reason: %s", until.UTC().Format(time.RFC3339), reason)
+}
+
+const (
+	//AuditGroupKey - key in the context, under which the handlers put the group, affected by the request, if it isnt part of the query or the body
+	AuditGroupKey = "auditGroupName"
+	//AuditFileIDKey - key in the context, under which the handlers put the file, affected by the request, if it isnt part of the query or the body
+	AuditFileIDKey = "auditFileID"
+)
+
+//SetAuditTarget - records the group and the file, affected by the request, so they end up in the audit log. Used when they are known only by the handler, like the file created by an upload
+func SetAuditTarget(c *gin.Context, groupName string, fileID uint) {
+	c.Set(AuditGroupKey, groupName)
+	c.Set(AuditFileIDKey, fileID)
+}
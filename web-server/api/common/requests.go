@@ -1,5 +1,15 @@
 package common
 
+import "time"
+
+//ChecksumHeader - header, containing the hex encoded SHA-256 checksum of a file
+//it can be sent by the client during the upload and is returned by the server during the download
+const ChecksumHeader = "X-Content-SHA256"
+
+//SharePasswordHeader - header, containing the password of a password-protected share link
+//a header is used instead of a query parameter, so the password doesnt end up in the access logs
+const SharePasswordHeader = "X-Share-Password"
+
 //RequestWithCredentials - request representation for login
 type RequestWithCredentials struct {
 	Username string `json:"username"`
@@ -11,14 +21,200 @@ type GroupPayload struct {
 	GroupName string `json:"group_name"`
 }
 
+//GroupVisibilityPayload - request payload, containing the group name and its visibility - private, discoverable or open
+type GroupVisibilityPayload struct {
+	GroupPayload
+	Visibility string `json:"visibility"`
+}
+
+//RefreshTokenPayload - request payload, containing a refresh token
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+//ChangePasswordPayload - request payload, containing the current and the new password of the user
+type ChangePasswordPayload struct {
+	Password    string `json:"password"`
+	NewPassword string `json:"new_password"`
+}
+
+//ResetPasswordPayload - request payload, containing a one-time password reset token and the new password of the user
+type ResetPasswordPayload struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+//TwoFactorCodePayload - request payload, containing a TOTP code or a recovery code
+type TwoFactorCodePayload struct {
+	Code string `json:"code"`
+}
+
+//LoginVerificationPayload - request payload, containing the login challenge and a TOTP code or a recovery code, which completes it
+type LoginVerificationPayload struct {
+	TwoFactorCodePayload
+	Challenge string `json:"challenge"`
+}
+
+//PersonalAccessTokenPayload - request payload, containing the name, the scopes, the groups and after how many days a personal access token expires
+type PersonalAccessTokenPayload struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	//Groups - the groups, to which the token is limited. No groups means that the token can be used in all groups of the user
+	Groups        []string `json:"groups"`
+	ExpiresInDays uint     `json:"expires_in_days"`
+}
+
+//PersonalAccessTokenRequestPayload - request payload, containing the id of a personal access token
+type PersonalAccessTokenRequestPayload struct {
+	TokenID uint `json:"token_id"`
+}
+
+//DeleteAccountPayload - request payload, containing the password of the user and the policies for its groups and files
+type DeleteAccountPayload struct {
+	Password    string `json:"password"`
+	GroupPolicy string `json:"group_policy"`
+	FilePolicy  string `json:"file_policy"`
+}
+
 //GroupMembershipPayload - request payload, containing the group name and username
 type GroupMembershipPayload struct {
 	GroupPayload
 	Username string `json:"username"`
 }
 
+//MemberRolePayload - request payload, containing the group name, the username and the role of the member in that group
+type MemberRolePayload struct {
+	GroupMembershipPayload
+	Role string `json:"role"`
+}
+
+//JoinRequestResolutionPayload - request payload, containing the user, who requested to join the group, whether the request is approved and the role of the new member
+type JoinRequestResolutionPayload struct {
+	MemberRolePayload
+	Approve bool `json:"approve"`
+}
+
+//InvitationPayload - request payload, containing the invited user, the group, the role and after how many hours the invitation expires
+type InvitationPayload struct {
+	MemberRolePayload
+	//ExpiresInHours - 0 means that the invitation doesnt expire
+	ExpiresInHours uint `json:"expires_in_hours"`
+}
+
 //FileRequestPayload - request payload, containing the group name and the file id, owned by that group
 type FileRequestPayload struct {
 	GroupPayload
 	FileID uint `json:"file_id"`
 }
+
+//FileVersionPayload - request payload, containing the group name, the file id and a version of that file
+type FileVersionPayload struct {
+	FileRequestPayload
+	Version uint `json:"version"`
+}
+
+//MaxFileVersionsPayload - request payload, containing the group name and how many versions of each file are kept
+type MaxFileVersionsPayload struct {
+	GroupPayload
+	MaxVersions uint `json:"max_versions"`
+}
+
+//FolderPayload - request payload, containing the group name and the path of a folder in that group
+type FolderPayload struct {
+	GroupPayload
+	Path string `json:"path"`
+}
+
+//RenameFolderPayload - request payload, containing the path of a folder and its new name
+type RenameFolderPayload struct {
+	FolderPayload
+	Name string `json:"name"`
+}
+
+//MoveFolderPayload - request payload, containing the path of a folder and the path of its new parent folder
+type MoveFolderPayload struct {
+	FolderPayload
+	Target string `json:"target"`
+}
+
+//MoveFilePayload - request payload, containing the file id, the path of its new folder and optionally its new name
+type MoveFilePayload struct {
+	FileRequestPayload
+	Folder   string `json:"folder"`
+	FileName string `json:"file_name"`
+}
+
+//UploadSessionPayload - request payload, describing a file, which will be uploaded in chunks in a folder of the group
+type UploadSessionPayload struct {
+	GroupPayload
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+	Folder   string `json:"folder"`
+}
+
+//UploadSessionRequestPayload - request payload, containing the group name and the id of an upload session
+type UploadSessionRequestPayload struct {
+	GroupPayload
+	SessionID uint `json:"session_id"`
+}
+
+//ShareLinkPayload - request payload, describing a share link for a file of the group
+type ShareLinkPayload struct {
+	FileRequestPayload
+	//Password - optional password, which has to be sent together with the link
+	Password string `json:"password"`
+	//ExpiresInHours - 0 means that the link doesnt expire
+	ExpiresInHours uint `json:"expires_in_hours"`
+	//MaxDownloads - 0 means that the file can be downloaded unlimited number of times
+	MaxDownloads uint `json:"max_downloads"`
+}
+
+//ShareLinkRequestPayload - request payload, containing the group name and the id of a share link
+type ShareLinkRequestPayload struct {
+	GroupPayload
+	LinkID uint `json:"link_id"`
+}
+
+//DropBoxPayload - request payload, describing a drop box, through which people without an account send files to a folder of the group
+type DropBoxPayload struct {
+	FolderPayload
+	//MaxFileSize - the maximum size of every file in bytes. 0 means unlimited
+	MaxFileSize int64 `json:"max_file_size"`
+	//MaxFiles - how many files can be sent. 0 means unlimited
+	MaxFiles uint `json:"max_files"`
+	//ExpiresInHours - 0 means that the drop box doesnt expire
+	ExpiresInHours uint `json:"expires_in_hours"`
+}
+
+//DropBoxRequestPayload - request payload, containing the group name and the id of a drop box
+type DropBoxRequestPayload struct {
+	GroupPayload
+	DropBoxID uint `json:"drop_box_id"`
+}
+
+//UserPayload - request payload, containing the username of a user
+type UserPayload struct {
+	Username string `json:"username"`
+}
+
+//SuspensionPayload - request payload, containing the suspended user, the reason of the suspension and its optional end
+type SuspensionPayload struct {
+	UserPayload
+	Reason string `json:"reason"`
+	//Until - when the suspension is lifted automatically. If missing, it lasts until an administrator lifts it
+	Until *time.Time `json:"until"`
+}
+
+//AdminDeleteUserPayload - request payload, containing the user, deleted by an administrator, and the policies for its groups and files
+type AdminDeleteUserPayload struct {
+	UserPayload
+	GroupPolicy string `json:"group_policy"`
+	FilePolicy  string `json:"file_policy"`
+}
+
+//PasswordResetTokenPayload - request payload, containing the user, who has forgotten the password, and after how many hours the reset token expires
+type PasswordResetTokenPayload struct {
+	UserPayload
+	//ExpiresInHours - 0 means the default expiration
+	ExpiresInHours uint `json:"expires_in_hours"`
+}
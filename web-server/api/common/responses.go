@@ -21,23 +21,79 @@ type RegistrationResponse struct {
 	JWTToken string `json:"jwt_token"`
 }
 
-//LoginResponse - when the login is succesfull a JWT is sent to the user
+//LoginResponse - when the login is succesfull a short-lived JWT and a refresh token are sent to the user
+//if the user has enabled two-factor authentication, only a challenge is sent, which has to be completed with a TOTP code
 type LoginResponse struct {
+	Status            int    `json:"status"`
+	Token             string `json:"token,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	Challenge         string `json:"challenge,omitempty"`
+}
+
+//TwoFactorEnrollmentResponse - response, containing the TOTP secret and its otpauth URI for the authenticator app
+type TwoFactorEnrollmentResponse struct {
 	Status int    `json:"status"`
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+//RecoveryCodesResponse - response, containing the recovery codes, which are shown only once
+type RecoveryCodesResponse struct {
+	Status        int      `json:"status"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+//PersonalAccessTokenResponse - when a personal access token is created, the token is sent to the user. This is the only time it is shown
+type PersonalAccessTokenResponse struct {
+	Status int    `json:"status"`
+	ID     uint   `json:"id"`
 	Token  string `json:"token"`
 }
 
+//PersonalAccessTokenInfo - response payload, containing the details about a personal access token without the token itself
+type PersonalAccessTokenInfo struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Groups     []string   `json:"groups"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
 //GroupInfo - response payload, containing only the most important details about a group
 type GroupInfo struct {
 	ID      uint   `json:"id"`
 	Name    string `json:"name"`
 	OwnerID uint   `josn:"owner_id"`
+	//Visibility - one of private, discoverable and open
+	Visibility string `json:"visibility"`
 }
 
 //UserInfo - response payload, containing only the most important details about a user
 type UserInfo struct {
 	ID       uint   `json:"id"`
 	Username string `json:"username"`
+	//Role - role of the user in a group, present only when the members of the group are fetched
+	Role string `json:"role,omitempty"`
+}
+
+//InvitationInfo - response payload, containing the details about a pending invitation
+type InvitationInfo struct {
+	ID        uint       `json:"id"`
+	GroupName string     `json:"group_name"`
+	Inviter   string     `json:"inviter"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+//JoinRequestInfo - response payload, containing the details about a pending join request
+type JoinRequestInfo struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 //FileInfoResponse - response of a request for fetching information about file
@@ -46,4 +102,137 @@ type FileInfoResponse struct {
 	Name       string    `json:"file_name"`
 	UploadedAt time.Time `json:"uploaded_at"`
 	OwnerID    uint      `json:"owner_id"`
+	Checksum   string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	Version    uint      `json:"version"`
+	//UploaderName and UploaderEmail - present only for the files, received through a drop box
+	UploaderName  string `json:"uploader_name,omitempty"`
+	UploaderEmail string `json:"uploader_email,omitempty"`
+}
+
+//TrashedFileInfoResponse - response, containing information about a file in the trash
+type TrashedFileInfoResponse struct {
+	FileInfoResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy uint      `json:"deleted_by"`
+}
+
+//FolderInfoResponse - response, containing information about a folder
+type FolderInfoResponse struct {
+	ID        uint      `json:"folder_id"`
+	Name      string    `json:"folder_name"`
+	CreatedAt time.Time `json:"created_at"`
+	OwnerID   uint      `json:"owner_id"`
+}
+
+//StorageUsageResponse - response, containing the storage used by the files of a user or a group
+type StorageUsageResponse struct {
+	Status     int   `json:"status"`
+	Files      int64 `json:"files"`
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+//ByteRange - range of bytes [Start, End) of a file
+type ByteRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+//UploadSessionResponse - response, containing the state of an upload session
+type UploadSessionResponse struct {
+	Status         int         `json:"status"`
+	SessionID      uint        `json:"session_id"`
+	FileName       string      `json:"file_name"`
+	Size           int64       `json:"size"`
+	ReceivedRanges []ByteRange `json:"received_ranges"`
+}
+
+//ShareLinkResponse - when a share link is created, its token is sent to the user. This is the only time it is shown
+type ShareLinkResponse struct {
+	Status int    `json:"status"`
+	ID     uint   `json:"id"`
+	Token  string `json:"token"`
+}
+
+//ShareLinkInfo - response payload, containing the details about a share link without its token
+type ShareLinkInfo struct {
+	ID                uint       `json:"id"`
+	FileID            uint       `json:"file_id"`
+	FileName          string     `json:"file_name"`
+	Version           uint       `json:"version"`
+	Creator           string     `json:"creator"`
+	PasswordProtected bool       `json:"password_protected"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	MaxDownloads      uint       `json:"max_downloads"`
+	Downloads         uint       `json:"downloads"`
+}
+
+//DropBoxResponse - when a drop box is created, its token is sent to the user. This is the only time it is shown
+type DropBoxResponse struct {
+	Status int    `json:"status"`
+	ID     uint   `json:"id"`
+	Token  string `json:"token"`
+}
+
+//DropBoxInfo - response payload, containing the details about a drop box without its token
+type DropBoxInfo struct {
+	ID          uint       `json:"id"`
+	FolderID    uint       `json:"folder_id"`
+	FolderName  string     `json:"folder_name"`
+	Creator     string     `json:"creator"`
+	MaxFileSize int64      `json:"max_file_size"`
+	MaxFiles    uint       `json:"max_files"`
+	Files       int64      `json:"files"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+//AdminUserInfo - response payload, containing the details about a user, which are seen only by the administrators
+type AdminUserInfo struct {
+	ID               uint       `json:"id"`
+	Username         string     `json:"username"`
+	IsAdmin          bool       `json:"is_admin"`
+	CreatedAt        time.Time  `json:"created_at"`
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"`
+	Groups           int64      `json:"groups"`
+	Files            int64      `json:"files"`
+	UsedBytes        int64      `json:"used_bytes"`
+}
+
+//StorageTotalsResponse - response, containing the system-wide number of users, groups and files and the storage, used by them
+type StorageTotalsResponse struct {
+	Status int   `json:"status"`
+	Users  int64 `json:"users"`
+	Groups int64 `json:"groups"`
+	Files  int64 `json:"files"`
+	//UsedBytes - the sum of the sizes of all files, while StoredBytes counts the deduplicated content only once
+	UsedBytes   int64 `json:"used_bytes"`
+	StoredBytes int64 `json:"stored_bytes"`
+}
+
+//AuditEventInfo - response payload, containing a recorded security or data event
+type AuditEventInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	//ActorID - 0 for the requests without an account. Actor is empty for them and for the deleted users
+	ActorID    uint   `json:"actor_id"`
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	GroupName  string `json:"group_name,omitempty"`
+	FileID     uint   `json:"file_id,omitempty"`
+	TargetUser string `json:"target_user,omitempty"`
+	IP         string `json:"ip"`
+	Outcome    string `json:"outcome"`
+	StatusCode int    `json:"status_code"`
+}
+
+//PasswordResetTokenResponse - when a password reset token is issued, it is sent to the administrator, who passes it to the user. This is the only time it is shown
+type PasswordResetTokenResponse struct {
+	Status    int       `json:"status"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -0,0 +1,300 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	//maxSuspensionReasonLength - the maximum length of the reason of a suspension
+	maxSuspensionReasonLength = 256
+
+	//DefaultPasswordResetExpiration - after how many hours a password reset token expires, unless specified otherwise
+	DefaultPasswordResetExpiration = 24
+	//maxPasswordResetExpiration - the maximum number of hours, for which a password reset token is valid
+	maxPasswordResetExpiration = 7 * 24
+)
+
+//AdminEndpoint - rest endpoint for the administration of the server. Accessible only by the administrators
+type AdminEndpoint interface {
+	GetUsers(*gin.Context)
+	SuspendUser(*gin.Context)
+	UnsuspendUser(*gin.Context)
+	DeleteUser(*gin.Context)
+	DeactivateGroup(*gin.Context)
+	ReassignGroupOwnership(*gin.Context)
+	GetStorageTotals(*gin.Context)
+	IssuePasswordResetToken(*gin.Context)
+}
+
+//AdminEndpointImpl - implementation of AdminEndpoint
+type AdminEndpointImpl struct {
+	uamDAO   dao.UamDAO
+	adminDAO dao.AdminDAO
+	tokenDAO dao.TokenDAO
+}
+
+//NewAdminEndpointImpl - function for creation an instance of AdminEndpointImpl
+func NewAdminEndpointImpl(uamDAO dao.UamDAO, adminDAO dao.AdminDAO, tokenDAO dao.TokenDAO) *AdminEndpointImpl {
+	return &AdminEndpointImpl{
+		uamDAO:   uamDAO,
+		adminDAO: adminDAO,
+		tokenDAO: tokenDAO,
+	}
+}
+
+//GetUsers - handler for fetching all users together with their role, status and used storage
+//returns 500, if there is a problem with the server
+//returns 200 + info about the users
+func (i *AdminEndpointImpl) GetUsers(c *gin.Context) {
+	users, err := i.adminDAO.GetUsers()
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with fetching all users."))
+		return
+	}
+
+	usersInfo := make([]common.AdminUserInfo, 0, len(users))
+	for _, user := range users {
+		usersInfo = append(usersInfo, common.AdminUserInfo{
+			ID:               user.ID,
+			Username:         user.Username,
+			IsAdmin:          user.IsAdmin,
+			CreatedAt:        user.CreatedAt,
+			SuspendedAt:      user.SuspendedAt,
+			SuspensionReason: user.SuspensionReason,
+			SuspendedUntil:   user.SuspendedUntil,
+			Groups:           user.Groups,
+			Files:            user.Files,
+			UsedBytes:        user.UsedBytes,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": http.StatusOK,
+		"users":  usersInfo,
+	})
+}
+
+//SuspendUser - handler for suspension of a user, who can no longer login or use the issued tokens. The groups of the user stay intact
+//the suspension is lifted automatically at its end, if such is specified
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user is an administrator
+//returns 404, if the user doesnt exist
+//returns 200, if the user is suspended
+func (i *AdminEndpointImpl) SuspendUser(c *gin.Context) {
+	var rq common.SuspensionPayload
+	if err := c.ShouldBindJSON(&rq); err != nil || rq.Username == "" || rq.Reason == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if len(rq.Reason) > maxSuspensionReasonLength {
+		common.SendErrorResponse(c, myerr.NewClientError(fmt.Sprintf("The reason of the suspension cannot be longer than %d characters", maxSuspensionReasonLength)))
+		return
+	} else if rq.Until != nil && !rq.Until.After(time.Now()) {
+		common.SendErrorResponse(c, myerr.NewClientError("The end of the suspension must be in the future"))
+		return
+	}
+
+	if err := i.adminDAO.SuspendUser(rq.Username, rq.Reason, rq.Until); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with the suspension of the user.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//UnsuspendUser - handler for lifting the suspension of a user
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user isnt suspended
+//returns 404, if the user doesnt exist
+//returns 200, if the suspension is lifted
+func (i *AdminEndpointImpl) UnsuspendUser(c *gin.Context) {
+	var rq common.UserPayload
+	if err := c.ShouldBindJSON(&rq); err != nil || rq.Username == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	if err := i.adminDAO.UnsuspendUser(rq.Username); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with lifting the suspension of the user.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//DeleteUser - handler for deletion of the account of a user by an administrator. The groups and the files of the user are handed over according to the policies
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user is an administrator
+//returns 404, if the user doesnt exist
+//returns 200, if the user is deleted
+func (i *AdminEndpointImpl) DeleteUser(c *gin.Context) {
+	var rq common.AdminDeleteUserPayload
+	if err := c.ShouldBindJSON(&rq); err != nil || rq.Username == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	user, err := i.uamDAO.GetUser(rq.Username)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	} else if user.ID == 0 {
+		common.SendErrorResponse(c, myerr.NewItemNotFoundError(fmt.Sprintf("User [%s] does not exist", rq.Username)))
+		return
+	}
+
+	policy := dao.AccountDeletionPolicy{
+		GroupPolicy: rq.GroupPolicy,
+		FilePolicy:  rq.FilePolicy,
+	}
+
+	rejectAdmin := func(user models.User) error {
+		if user.IsAdmin {
+			return myerr.NewClientError("Administrators cannot be deleted. Revoke the administrator role first")
+		}
+		return nil
+	}
+
+	if err = i.uamDAO.DeleteUser(user.ID, policy, rejectAdmin); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with deleting user")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//DeactivateGroup - handler for deactivation of a group regardless of its owner. Its resources are later erased
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the group is already being deleted
+//returns 404, if the group doesnt exist
+//returns 200, if the group is deactivated
+func (i *AdminEndpointImpl) DeactivateGroup(c *gin.Context) {
+	var rq common.GroupPayload
+	if err := c.ShouldBindJSON(&rq); err != nil || rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	if err := i.adminDAO.DeactivateGroup(rq.GroupName); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with the deactivation of the group.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//ReassignGroupOwnership - handler for making a user the owner of a group, even if the user isnt a member of it
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid, the user is already the owner or the group is being deleted
+//returns 404, if the group or the user doesnt exist
+//returns 200, if the ownership is reassigned
+func (i *AdminEndpointImpl) ReassignGroupOwnership(c *gin.Context) {
+	var rq common.GroupMembershipPayload
+	if err := c.ShouldBindJSON(&rq); err != nil || rq.GroupName == "" || rq.Username == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	if err := i.adminDAO.ReassignGroupOwnership(rq.GroupName, rq.Username); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with the reassignment of the group ownership.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//GetStorageTotals - handler for fetching the system-wide number of users, groups and files and the storage, used by them
+//returns 500, if there is a problem with the server
+//returns 200 + the totals
+func (i *AdminEndpointImpl) GetStorageTotals(c *gin.Context) {
+	totals, err := i.adminDAO.GetStorageTotals()
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with calculating the storage totals."))
+		return
+	}
+
+	c.JSON(http.StatusOK, common.StorageTotalsResponse{
+		Status:      http.StatusOK,
+		Users:       totals.Users,
+		Groups:      totals.Groups,
+		Files:       totals.Files,
+		UsedBytes:   totals.UsedBytes,
+		StoredBytes: totals.StoredBytes,
+	})
+}
+
+//IssuePasswordResetToken - handler for issuing a one-time token, with which a user, who has forgotten the password, sets a new one
+//the previous reset tokens of the user are replaced. The token is shown only in the response, while the server stores only its hash
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 404, if the user doesnt exist
+//returns 201 + the token and its expiration, if the token is issued
+func (i *AdminEndpointImpl) IssuePasswordResetToken(c *gin.Context) {
+	var rq common.PasswordResetTokenPayload
+	if err := c.ShouldBindJSON(&rq); err != nil || rq.Username == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.ExpiresInHours > maxPasswordResetExpiration {
+		common.SendErrorResponse(c, myerr.NewClientError(fmt.Sprintf("The token should expire in at most %d hours", maxPasswordResetExpiration)))
+		return
+	}
+
+	expiration := rq.ExpiresInHours
+	if expiration == 0 {
+		expiration = DefaultPasswordResetExpiration
+	}
+
+	token, tokenHash, err := auth.GeneratePasswordResetToken()
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(expiration) * time.Hour)
+	if err = i.tokenDAO.CreatePasswordResetToken(rq.Username, tokenHash, expiresAt); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with issuing the password reset token.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, common.PasswordResetTokenResponse{
+		Status:    http.StatusCreated,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
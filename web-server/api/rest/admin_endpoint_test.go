@@ -0,0 +1,367 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterAdmin(adminRest rest.AdminEndpoint) *gin.Engine {
+	r := gin.Default()
+
+	admin := r.Group("/admin")
+	{
+		admin.GET("/users", adminRest.GetUsers)
+		admin.PUT("/user/suspension", adminRest.SuspendUser)
+		admin.DELETE("/user/suspension", adminRest.UnsuspendUser)
+		admin.DELETE("/user", adminRest.DeleteUser)
+		admin.DELETE("/group", adminRest.DeactivateGroup)
+		admin.PUT("/group/ownership", adminRest.ReassignGroupOwnership)
+		admin.GET("/storage", adminRest.GetStorageTotals)
+		admin.POST("/user/password-reset", adminRest.IssuePasswordResetToken)
+	}
+	return r
+}
+
+var _ = Describe("AdminEndpoint", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		uamDAO   *dao_mocks.MockUamDAO
+		adminDAO *dao_mocks.MockAdminDAO
+		tokenDAO *dao_mocks.MockTokenDAO
+		req      *http.Request
+	)
+
+	const (
+		username  = "username"
+		groupName = "groupName"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		adminDAO = dao_mocks.NewMockAdminDAO(controller)
+		tokenDAO = dao_mocks.NewMockTokenDAO(controller)
+
+		router = setupRouterAdmin(rest.NewAdminEndpointImpl(uamDAO, adminDAO, tokenDAO))
+		recorder = httptest.NewRecorder()
+	})
+
+	Context("GetUsers", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", "/admin/users", nil)
+		})
+
+		When("the users are fetched", func() {
+			BeforeEach(func() {
+				suspendedAt := time.Now()
+				user := dao.UserInfo{
+					User:      models.User{ID: 1, Username: username, SuspendedAt: &suspendedAt, SuspensionReason: "spam"},
+					Groups:    2,
+					Files:     3,
+					UsedBytes: 1024,
+				}
+				adminDAO.EXPECT().
+					GetUsers().
+					Return([]dao.UserInfo{user}, nil)
+			})
+
+			It("returns the users with their status and used storage", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				var response struct {
+					Users []common.AdminUserInfo `json:"users"`
+				}
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.Users).To(HaveLen(1))
+				Expect(response.Users[0].Username).To(Equal(username))
+				Expect(response.Users[0].SuspendedAt).NotTo(BeNil())
+				Expect(response.Users[0].SuspensionReason).To(Equal("spam"))
+				Expect(response.Users[0].UsedBytes).To(Equal(int64(1024)))
+			})
+		})
+	})
+
+	Context("SuspendUser", func() {
+		When("the reason is missing", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "/admin/user/suspension", strings.NewReader(`{"username":"`+username+`"}`))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid json body")
+			})
+		})
+
+		When("the end of the suspension has passed", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "/admin/user/suspension", strings.NewReader(`{"username":"`+username+`","reason":"spam","until":"2020-01-01T00:00:00Z"}`))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "The end of the suspension must be in the future")
+			})
+		})
+
+		When("the user is an administrator", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "/admin/user/suspension", strings.NewReader(`{"username":"`+username+`","reason":"spam"}`))
+				adminDAO.EXPECT().
+					SuspendUser(username, "spam", nil).
+					Return(myerr.NewClientError("Administrators cannot be suspended"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Administrators cannot be suspended")
+			})
+		})
+
+		When("the user is suspended until a given moment", func() {
+			until := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+			BeforeEach(func() {
+				req, _ = http.NewRequest("PUT", "/admin/user/suspension", strings.NewReader(`{"username":"`+username+`","reason":"spam","until":"`+until.Format(time.RFC3339)+`"}`))
+				adminDAO.EXPECT().
+					SuspendUser(username, "spam", gomock.Any()).
+					DoAndReturn(func(_ string, _ string, suspendedUntil *time.Time) error {
+						Expect(suspendedUntil).NotTo(BeNil())
+						Expect(suspendedUntil.Equal(until)).To(BeTrue())
+						return nil
+					})
+			})
+
+			It("returns success", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("UnsuspendUser", func() {
+		When("the username is missing", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "/admin/user/suspension", strings.NewReader(`{}`))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid json body")
+			})
+		})
+	})
+
+	Context("DeleteUser", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("DELETE", "/admin/user", strings.NewReader(`{"username":"`+username+`","group_policy":"deactivate"}`))
+		})
+
+		When("the user doesnt exist", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					GetUser(username).
+					Return(models.User{}, nil)
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "User [username] does not exist")
+			})
+		})
+
+		When("the user exists", func() {
+			var confirm func(models.User) error
+
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					GetUser(username).
+					Return(models.User{ID: 2, Username: username}, nil)
+				uamDAO.EXPECT().
+					DeleteUser(uint(2), dao.AccountDeletionPolicy{GroupPolicy: dao.GroupPolicyDeactivate}, gomock.Any()).
+					DoAndReturn(func(_ uint, _ dao.AccountDeletionPolicy, confirmDeletion func(models.User) error) error {
+						confirm = confirmDeletion
+						return nil
+					})
+			})
+
+			It("deletes the user, unless the user is an administrator", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(confirm(models.User{})).To(Succeed())
+
+				err := confirm(models.User{IsAdmin: true})
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+			})
+		})
+	})
+
+	Context("DeactivateGroup", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("DELETE", "/admin/group", strings.NewReader(`{"group_name":"`+groupName+`"}`))
+		})
+
+		When("the group doesnt exist", func() {
+			BeforeEach(func() {
+				adminDAO.EXPECT().
+					DeactivateGroup(groupName).
+					Return(myerr.NewItemNotFoundError("Group [groupName] does not exist"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Group [groupName] does not exist")
+			})
+		})
+
+		When("the group is deactivated", func() {
+			BeforeEach(func() {
+				adminDAO.EXPECT().
+					DeactivateGroup(groupName).
+					Return(nil)
+			})
+
+			It("returns success", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("ReassignGroupOwnership", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("PUT", "/admin/group/ownership", strings.NewReader(`{"group_name":"`+groupName+`","username":"`+username+`"}`))
+		})
+
+		When("the ownership is reassigned", func() {
+			BeforeEach(func() {
+				adminDAO.EXPECT().
+					ReassignGroupOwnership(groupName, username).
+					Return(nil)
+			})
+
+			It("returns success", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("GetStorageTotals", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", "/admin/storage", nil)
+		})
+
+		When("the calculation fails", func() {
+			BeforeEach(func() {
+				adminDAO.EXPECT().
+					GetStorageTotals().
+					Return(dao.StorageTotals{}, errors.New("test-error"))
+			})
+
+			It("returns internal server error", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
+			})
+		})
+
+		When("the totals are calculated", func() {
+			BeforeEach(func() {
+				adminDAO.EXPECT().
+					GetStorageTotals().
+					Return(dao.StorageTotals{Users: 3, Groups: 2, Files: 5, UsedBytes: 2048, StoredBytes: 1024}, nil)
+			})
+
+			It("returns the totals", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				var response common.StorageTotalsResponse
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.Users).To(Equal(int64(3)))
+				Expect(response.StoredBytes).To(Equal(int64(1024)))
+			})
+		})
+	})
+
+	Context("IssuePasswordResetToken", func() {
+		When("the expiration is too long", func() {
+			BeforeEach(func() {
+				tokenDAO.EXPECT().
+					CreatePasswordResetToken(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("POST", "/admin/user/password-reset", strings.NewReader(`{"username":"username","expires_in_hours":1000}`))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "The token should expire in at most 168 hours")
+			})
+		})
+
+		When("the user doesnt exist", func() {
+			BeforeEach(func() {
+				tokenDAO.EXPECT().
+					CreatePasswordResetToken(username, gomock.Any(), gomock.Any()).
+					Return(myerr.NewItemNotFoundError("User with that username does not exist"))
+
+				req, _ = http.NewRequest("POST", "/admin/user/password-reset", strings.NewReader(`{"username":"username"}`))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "User with that username does not exist")
+			})
+		})
+
+		When("the token is issued", func() {
+			var (
+				tokenHash string
+				expiresAt time.Time
+			)
+
+			BeforeEach(func() {
+				tokenDAO.EXPECT().
+					CreatePasswordResetToken(username, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ string, hash string, expiration time.Time) error {
+						tokenHash = hash
+						expiresAt = expiration
+						return nil
+					})
+
+				req, _ = http.NewRequest("POST", "/admin/user/password-reset", strings.NewReader(`{"username":"username","expires_in_hours":2}`))
+			})
+
+			It("returns the token, whose hash is stored", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				var response common.PasswordResetTokenResponse
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.Token).NotTo(BeEmpty())
+				Expect(auth.HashRefreshToken(response.Token)).To(Equal(tokenHash))
+				Expect(expiresAt).To(BeTemporally("~", time.Now().Add(2*time.Hour), time.Minute))
+			})
+		})
+	})
+})
@@ -0,0 +1,208 @@
+package rest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	//defaultAuditEventsLimit - how many of the latest audit events are returned, if the limit isnt specified
+	defaultAuditEventsLimit = 100
+	//maxAuditEventsLimit - the maximum number of audit events, returned by one request
+	maxAuditEventsLimit = 10000
+)
+
+//auditCSVHeader - the columns of the exported audit events
+var auditCSVHeader = []string{"id", "created_at", "actor_id", "actor", "action", "group_name", "file_id", "target_user", "ip", "outcome", "status_code"}
+
+//AuditEndpoint - rest endpoint for querying the audit log
+type AuditEndpoint interface {
+	GetGroupEvents(*gin.Context)
+	GetEvents(*gin.Context)
+}
+
+//AuditEndpointImpl - implementation of AuditEndpoint
+type AuditEndpointImpl struct {
+	auditDAO dao.AuditDAO
+}
+
+//NewAuditEndpointImpl - function for creation an instance of AuditEndpointImpl
+func NewAuditEndpointImpl(auditDAO dao.AuditDAO) *AuditEndpointImpl {
+	return &AuditEndpointImpl{
+		auditDAO: auditDAO,
+	}
+}
+
+//GetGroupEvents - handler for fetching the audit events of a group, starting from the latest one. Only for the group owner
+//the events are filtered by the query parameters action, actor, target_user, outcome, from and to and exported as json or csv, depending on format
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the current user isnt the group owner
+//returns 404, if the group doesnt exist
+//returns 200 + the audit events
+func (i *AuditEndpointImpl) GetGroupEvents(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groupName := c.Query("group_name")
+	if groupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	events, err := i.auditDAO.GetGroupEvents(userID, groupName, filter)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with fetching the audit events of the group.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	sendAuditEvents(c, events)
+}
+
+//GetEvents - handler for fetching the audit events of the whole server, starting from the latest one. Only for the administrators
+//the events are filtered by the query parameters action, actor, group_name, target_user, outcome, from and to and exported as json or csv, depending on format
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 200 + the audit events
+func (i *AuditEndpointImpl) GetEvents(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+	filter.GroupName = c.Query("group_name")
+
+	events, err := i.auditDAO.GetEvents(filter)
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with fetching the audit events."))
+		return
+	}
+
+	sendAuditEvents(c, events)
+}
+
+//parseAuditFilter - extracts the criteria for the audit events from the query. The moments are in RFC 3339 format
+func parseAuditFilter(c *gin.Context) (dao.AuditFilter, error) {
+	filter := dao.AuditFilter{
+		Action:     c.Query("action"),
+		Actor:      c.Query("actor"),
+		TargetUser: c.Query("target_user"),
+		Outcome:    c.Query("outcome"),
+		Limit:      defaultAuditEventsLimit,
+	}
+
+	if filter.Outcome != "" && filter.Outcome != models.OutcomeSuccess && filter.Outcome != models.OutcomeFailure {
+		return filter, myerr.NewClientError(fmt.Sprintf("Invalid outcome [%s]. Valid outcomes are success and failure", filter.Outcome))
+	}
+
+	if format := c.Query("format"); format != "" && format != "json" && format != "csv" {
+		return filter, myerr.NewClientError(fmt.Sprintf("Invalid format [%s]. Valid formats are json and csv", format))
+	}
+
+	var err error
+	if filter.From, err = parseMoment(c.Query("from")); err != nil {
+		return filter, myerr.NewClientError("Invalid from. It should be in RFC 3339 format")
+	}
+	if filter.To, err = parseMoment(c.Query("to")); err != nil {
+		return filter, myerr.NewClientError("Invalid to. It should be in RFC 3339 format")
+	}
+
+	if limitString := c.Query("limit"); limitString != "" {
+		if filter.Limit, err = strconv.Atoi(limitString); err != nil || filter.Limit <= 0 || filter.Limit > maxAuditEventsLimit {
+			return filter, myerr.NewClientError(fmt.Sprintf("Invalid limit. It should be between 1 and %d", maxAuditEventsLimit))
+		}
+	}
+	return filter, nil
+}
+
+func parseMoment(moment string) (*time.Time, error) {
+	if moment == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, moment)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+//sendAuditEvents - sends the audit events as json or, if the csv format is requested, as a csv attachment
+func sendAuditEvents(c *gin.Context, events []dao.AuditEventInfo) {
+	if c.Query("format") == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename=audit.csv")
+		c.Status(http.StatusOK)
+
+		writer := csv.NewWriter(c.Writer)
+		writer.Write(auditCSVHeader)
+		for _, event := range events {
+			writer.Write([]string{
+				strconv.FormatUint(uint64(event.ID), 10),
+				event.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.FormatUint(uint64(event.ActorID), 10),
+				csvSafe(event.Actor),
+				event.Action,
+				csvSafe(event.GroupName),
+				strconv.FormatUint(uint64(event.FileID), 10),
+				csvSafe(event.TargetUser),
+				event.IP,
+				event.Outcome,
+				strconv.Itoa(event.StatusCode),
+			})
+		}
+		writer.Flush()
+		return
+	}
+
+	eventsInfo := make([]common.AuditEventInfo, 0, len(events))
+	for _, event := range events {
+		eventsInfo = append(eventsInfo, common.AuditEventInfo{
+			ID:         event.ID,
+			CreatedAt:  event.CreatedAt,
+			ActorID:    event.ActorID,
+			Actor:      event.Actor,
+			Action:     event.Action,
+			GroupName:  event.GroupName,
+			FileID:     event.FileID,
+			TargetUser: event.TargetUser,
+			IP:         event.IP,
+			Outcome:    event.Outcome,
+			StatusCode: event.StatusCode,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": http.StatusOK,
+		"events": eventsInfo,
+	})
+}
+
+//csvSafe - prevents the names, chosen by the users, from being interpreted as formulas, when the csv is opened in a spreadsheet
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@") {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,183 @@
+package rest_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterAudit(auditRest rest.AuditEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	protected.GET("/group/audit", auditRest.GetGroupEvents)
+
+	admin := r.Group("/admin")
+	admin.GET("/audit", auditRest.GetEvents)
+	return r
+}
+
+var _ = Describe("AuditEndpoint", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		auditDAO *dao_mocks.MockAuditDAO
+		req      *http.Request
+		event    dao.AuditEventInfo
+	)
+
+	const (
+		userID    = 1
+		groupName = "groupName"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		auditDAO = dao_mocks.NewMockAuditDAO(controller)
+
+		router = setupRouterAudit(rest.NewAuditEndpointImpl(auditDAO), userID)
+		recorder = httptest.NewRecorder()
+
+		event = dao.AuditEventInfo{
+			AuditEvent: models.AuditEvent{
+				ID:         3,
+				CreatedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				ActorID:    userID,
+				Action:     "file.download",
+				GroupName:  "=" + groupName,
+				FileID:     7,
+				IP:         "127.0.0.1",
+				Outcome:    models.OutcomeSuccess,
+				StatusCode: http.StatusOK,
+			},
+			Actor: "username",
+		}
+	})
+
+	Context("GetGroupEvents", func() {
+		When("the group isnt specified", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("GET", "/protected/group/audit", nil)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Groupname isnt specified")
+			})
+		})
+
+		When("the limit is invalid", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("GET", "/protected/group/audit?group_name="+groupName+"&limit=0", nil)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid limit")
+			})
+		})
+
+		When("the user isnt the group owner", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("GET", "/protected/group/audit?group_name="+groupName, nil)
+				auditDAO.EXPECT().
+					GetGroupEvents(uint(userID), groupName, dao.AuditFilter{Limit: 100}).
+					Return(nil, myerr.NewClientError("Your role [admin] in the group doesnt allow managing the group"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "doesnt allow managing the group")
+			})
+		})
+
+		When("the events are fetched", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("GET", "/protected/group/audit?group_name="+groupName+"&action=file.download&from=2026-01-01T00:00:00Z&limit=10", nil)
+				from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+				auditDAO.EXPECT().
+					GetGroupEvents(uint(userID), groupName, dao.AuditFilter{Action: "file.download", From: &from, Limit: 10}).
+					Return([]dao.AuditEventInfo{event}, nil)
+			})
+
+			It("returns the events as json", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				var response struct {
+					Events []common.AuditEventInfo `json:"events"`
+				}
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.Events).To(HaveLen(1))
+				Expect(response.Events[0].Actor).To(Equal("username"))
+				Expect(response.Events[0].FileID).To(Equal(uint(7)))
+			})
+		})
+	})
+
+	Context("GetEvents", func() {
+		When("the outcome is invalid", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("GET", "/admin/audit?outcome=unknown", nil)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid outcome [unknown]")
+			})
+		})
+
+		When("the fetching fails", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("GET", "/admin/audit", nil)
+				auditDAO.EXPECT().
+					GetEvents(dao.AuditFilter{Limit: 100}).
+					Return(nil, errors.New("test-error"))
+			})
+
+			It("returns internal server error", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
+			})
+		})
+
+		When("the events are exported as csv", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("GET", "/admin/audit?group_name="+groupName+"&outcome=success&format=csv", nil)
+				auditDAO.EXPECT().
+					GetEvents(dao.AuditFilter{GroupName: groupName, Outcome: models.OutcomeSuccess, Limit: 100}).
+					Return([]dao.AuditEventInfo{event}, nil)
+			})
+
+			It("returns a csv attachment with escaped names", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Header().Get("Content-Type")).To(Equal("text/csv"))
+
+				records, err := csv.NewReader(strings.NewReader(recorder.Body.String())).ReadAll()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(records).To(HaveLen(2))
+				Expect(records[0][0]).To(Equal("id"))
+				Expect(records[1]).To(Equal([]string{"3", "2026-01-02T03:04:05Z", "1", "username", "file.download", "'=" + groupName, "7", "", "127.0.0.1", "success", "200"}))
+			})
+		})
+	})
+})
@@ -0,0 +1,243 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	//maxUploaderNameLength - the maximum length of the name of someone, who sends a file through a drop box
+	maxUploaderNameLength = 128
+	//maxUploaderEmailLength - the maximum length of the email of someone, who sends a file through a drop box
+	maxUploaderEmailLength = 256
+	//maxMultipartOverhead - the bytes of the multipart form around the file, which are allowed above the maximum file size of a drop box
+	maxMultipartOverhead = 1 << 20
+)
+
+//CreateDropBox - handler for creation of an upload-only link, through which people without an account send files to a folder of the group
+//the drop box can limit the size and the number of the files and can expire. The files are uploaded on behalf of its creator
+//the token is shown only in the response, while the server stores only its hash
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user isnt the group owner
+//returns 404, if the folder doesnt exist
+//returns 201 + the id and the token of the drop box, if it is created
+func (i *FileManagementEndpointImpl) CreateDropBox(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.DropBoxPayload
+	if err = c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	} else if rq.MaxFileSize < 0 {
+		common.SendErrorResponse(c, myerr.NewClientError("The maximum file size cannot be negative"))
+		return
+	}
+
+	var expiresAt *time.Time
+	if rq.ExpiresInHours > 0 {
+		expiration := time.Now().Add(time.Duration(rq.ExpiresInHours) * time.Hour)
+		expiresAt = &expiration
+	}
+
+	token, tokenHash, err := auth.GenerateDropBoxToken()
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	dropBoxID, err := i.FmDAO.CreateDropBox(userID, rq.GroupName, rq.Path, tokenHash, rq.MaxFileSize, rq.MaxFiles, expiresAt)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with creation of drop box.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, common.DropBoxResponse{
+		Status: http.StatusCreated,
+		ID:     dropBoxID,
+		Token:  token,
+	})
+}
+
+//GetDropBoxes - handler for fetching the drop boxes of a group
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user isnt the group owner
+//returns 200 + info about the drop boxes
+func (i *FileManagementEndpointImpl) GetDropBoxes(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groupName := c.Query("group_name")
+	if groupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	dropBoxes, err := i.FmDAO.GetDropBoxes(userID, groupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	dropBoxesInfo := make([]common.DropBoxInfo, 0, len(dropBoxes))
+	for _, dropBox := range dropBoxes {
+		dropBoxesInfo = append(dropBoxesInfo, common.DropBoxInfo{
+			ID:          dropBox.ID,
+			FolderID:    dropBox.FolderID,
+			FolderName:  dropBox.FolderName,
+			Creator:     dropBox.Creator,
+			MaxFileSize: dropBox.MaxFileSize,
+			MaxFiles:    dropBox.MaxFiles,
+			Files:       dropBox.Files,
+			CreatedAt:   dropBox.CreatedAt,
+			ExpiresAt:   dropBox.ExpiresAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     http.StatusOK,
+		"drop_boxes": dropBoxesInfo,
+	})
+}
+
+//DeleteDropBox - handler for deletion of a drop box. The files, which were already received through it, are kept
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user isnt the group owner
+//returns 404, if the drop box doesnt exist
+//returns 200, if the drop box is deleted
+func (i *FileManagementEndpointImpl) DeleteDropBox(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.DropBoxRequestPayload
+	if err = c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	if err = i.FmDAO.RemoveDropBox(userID, rq.DropBoxID, rq.GroupName); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with deletion of drop box.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//UploadToDropBox - publicly accessible handler for sending a file through a drop box. The form contains the file and the name and optionally the email of the sender
+//the file is added to the folder of the drop box without an owner, so it counts only towards the quota of the group. The name and the email of the sender are saved on the file
+//the upload is rejected, if the optional checksum header doesnt match the received content
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or a file with the same name already exists in the folder
+//returns 404, if the drop box doesnt exist or has expired
+//returns 413, if the file is too big, the drop box doesnt accept more files or the group would exceed its quota
+//returns 201, if the file is received
+func (i *FileManagementEndpointImpl) UploadToDropBox(c *gin.Context) {
+	dropBox, err := i.FmDAO.GetDropBox(auth.HashRefreshToken(c.Param("token")))
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+	common.SetAuditTarget(c, dropBox.GroupName, 0)
+
+	//too big uploads are rejected before the file is received
+	if dropBox.MaxFileSize > 0 {
+		if c.Request.ContentLength > dropBox.MaxFileSize+maxMultipartOverhead {
+			common.SendErrorResponse(c, myerr.NewQuotaExceededError(fmt.Sprintf("The file exceeds the maximum size of %d bytes", dropBox.MaxFileSize)))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, dropBox.MaxFileSize+maxMultipartOverhead)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Problem with the file"))
+		return
+	} else if dropBox.MaxFileSize > 0 && file.Size > dropBox.MaxFileSize {
+		common.SendErrorResponse(c, myerr.NewQuotaExceededError(fmt.Sprintf("The file exceeds the maximum size of %d bytes", dropBox.MaxFileSize)))
+		return
+	}
+
+	uploaderName, uploaderEmail, err := getUploader(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	fileID, err := i.FmDAO.AddDropBoxFile(dropBox.ID, file.Filename, uploaderName, uploaderEmail)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+	common.SetAuditTarget(c, dropBox.GroupName, fileID)
+
+	removeFileInfo := func() {
+		i.FmDAO.RemoveDropBoxFile(dropBox.ID, fileID)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		removeFileInfo()
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Couldnt open the uploaded file"))
+		return
+	}
+	defer src.Close()
+
+	if err = i.storeContent(fileID, 0, dropBox.GroupName, src, c.GetHeader(common.ChecksumHeader), removeFileInfo); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  http.StatusCreated,
+		"file_id": fileID,
+	})
+}
+
+//getUploader - extracts the name and the optional email of the sender from the form
+func getUploader(c *gin.Context) (string, string, error) {
+	name := strings.TrimSpace(c.PostForm("name"))
+	if name == "" || len(name) > maxUploaderNameLength {
+		return "", "", myerr.NewClientError(fmt.Sprintf("The name of the sender should be between 1 and %d symbols", maxUploaderNameLength))
+	}
+
+	email := strings.TrimSpace(c.PostForm("email"))
+	if email == "" {
+		return name, "", nil
+	}
+
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email || len(email) > maxUploaderEmailLength {
+		return "", "", myerr.NewClientError("Invalid email of the sender")
+	}
+	return name, email, nil
+}
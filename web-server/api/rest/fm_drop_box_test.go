@@ -0,0 +1,364 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterDropBoxes(fmRest rest.FileManagementEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	r.POST("/public/dropbox/:token", fmRest.UploadToDropBox)
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.POST("/group/dropbox", fmRest.CreateDropBox)
+		protected.GET("/group/dropbox", fmRest.GetDropBoxes)
+		protected.DELETE("/group/dropbox", fmRest.DeleteDropBox)
+	}
+	return r
+}
+
+func createDropBoxForm(fileName, content string, fields map[string]string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	part, _ := writer.CreateFormFile("file", fileName)
+	part.Write([]byte(content))
+	writer.Close()
+
+	return body, writer.FormDataContentType()
+}
+
+var _ = Describe("Drop boxes", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		fmDAO    *dao_mocks.MockFmDAO
+		req      *http.Request
+		rootDir  string
+	)
+
+	const (
+		userID    = 1
+		groupName = "groupName"
+		folderID  = 4
+		dropBoxID = 5
+		fileID    = 6
+		token     = "drop-token"
+		fileName  = "report.pdf"
+		content   = "dropped-content"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+		rootDir, _ = ioutil.TempDir("", "drop-boxes")
+		fmRest := rest.NewFileManagementEndpointImpl(dao_mocks.NewMockUamDAO(controller), fmDAO, storage.NewLocalBackend(rootDir), dao.Quota{})
+
+		router = setupRouterDropBoxes(fmRest, userID)
+		recorder = httptest.NewRecorder()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	Context("CreateDropBox", func() {
+		var reqBody common.DropBoxPayload
+
+		BeforeEach(func() {
+			reqBody = common.DropBoxPayload{
+				FolderPayload: common.FolderPayload{
+					GroupPayload: common.GroupPayload{GroupName: groupName},
+					Path:         "/inbox",
+				},
+				MaxFileSize:    1024,
+				MaxFiles:       10,
+				ExpiresInHours: 48,
+			}
+		})
+
+		JustBeforeEach(func() {
+			body, _ := json.Marshal(reqBody)
+			req, _ = http.NewRequest("POST", "/protected/group/dropbox", strings.NewReader(string(body)))
+		})
+
+		When("the group isnt specified", func() {
+			BeforeEach(func() {
+				reqBody.GroupName = ""
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Groupname isnt specified")
+			})
+		})
+
+		When("the user isnt the group owner", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					CreateDropBox(uint(userID), groupName, "/inbox", gomock.Any(), int64(1024), uint(10), gomock.Any()).
+					Return(uint(0), myerr.NewClientError("Your role [editor] in the group doesnt allow managing the group"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Your role [editor] in the group doesnt allow managing the group")
+			})
+		})
+
+		When("the drop box is created", func() {
+			var tokenHash string
+
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					CreateDropBox(uint(userID), groupName, "/inbox", gomock.Any(), int64(1024), uint(10), gomock.Any()).
+					DoAndReturn(func(_ uint, _ string, _ string, hash string, _ int64, _ uint, expiresAt *time.Time) (uint, error) {
+						tokenHash = hash
+						Expect(*expiresAt).To(BeTemporally("~", time.Now().Add(48*time.Hour), time.Minute))
+						return dropBoxID, nil
+					})
+			})
+
+			It("returns the token, whose hash is stored", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				var response common.DropBoxResponse
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.ID).To(Equal(uint(dropBoxID)))
+				Expect(auth.HashRefreshToken(response.Token)).To(Equal(tokenHash))
+			})
+		})
+	})
+
+	Context("GetDropBoxes", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/dropbox?group_name=%s", groupName), nil)
+		})
+
+		When("the drop boxes are fetched", func() {
+			BeforeEach(func() {
+				dropBox := dao.DropBoxInfo{
+					DropBox:    models.DropBox{ID: dropBoxID, FolderID: folderID, MaxFiles: 10},
+					FolderName: "inbox",
+					Creator:    "user",
+					Files:      3,
+				}
+				fmDAO.EXPECT().
+					GetDropBoxes(uint(userID), groupName).
+					Return([]dao.DropBoxInfo{dropBox}, nil)
+			})
+
+			It("returns the drop boxes without their tokens", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				var response struct {
+					DropBoxes []common.DropBoxInfo `json:"drop_boxes"`
+				}
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.DropBoxes).To(HaveLen(1))
+				Expect(response.DropBoxes[0].FolderName).To(Equal("inbox"))
+				Expect(response.DropBoxes[0].Files).To(Equal(int64(3)))
+			})
+		})
+	})
+
+	Context("DeleteDropBox", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("DELETE", "/protected/group/dropbox", strings.NewReader(fmt.Sprintf(`{"group_name":"%s","drop_box_id":%d}`, groupName, dropBoxID)))
+		})
+
+		When("the drop box doesnt exist", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RemoveDropBox(uint(userID), uint(dropBoxID), groupName).
+					Return(myerr.NewItemNotFoundError("Drop box with id [5] doesnt exist"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Drop box with id [5] doesnt exist")
+			})
+		})
+
+		When("the drop box is deleted", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RemoveDropBox(uint(userID), uint(dropBoxID), groupName).
+					Return(nil)
+			})
+
+			It("returns success", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("UploadToDropBox", func() {
+		var (
+			dropBox dao.GroupDropBox
+			fields  map[string]string
+		)
+
+		BeforeEach(func() {
+			dropBox = dao.GroupDropBox{
+				DropBox:   models.DropBox{ID: dropBoxID, FolderID: folderID, UserID: userID, MaxFileSize: 1024},
+				GroupName: groupName,
+			}
+			fields = map[string]string{"name": "Partner", "email": "partner@example.com"}
+		})
+
+		JustBeforeEach(func() {
+			body, contentType := createDropBoxForm(fileName, content, fields)
+			req, _ = http.NewRequest("POST", "/public/dropbox/"+token, body)
+			req.Header.Set("Content-Type", contentType)
+		})
+
+		When("the drop box doesnt exist or has expired", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetDropBox(auth.HashRefreshToken(token)).
+					Return(dao.GroupDropBox{}, myerr.NewItemNotFoundError("Drop box does not exist or has expired"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Drop box does not exist or has expired")
+			})
+		})
+
+		When("the file is too big", func() {
+			BeforeEach(func() {
+				dropBox.MaxFileSize = 4
+				fmDAO.EXPECT().
+					GetDropBox(auth.HashRefreshToken(token)).
+					Return(dropBox, nil)
+			})
+
+			It("returns request entity too large", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusRequestEntityTooLarge, "The file exceeds the maximum size of 4 bytes")
+			})
+		})
+
+		When("the name of the sender is missing", func() {
+			BeforeEach(func() {
+				delete(fields, "name")
+				fmDAO.EXPECT().
+					GetDropBox(auth.HashRefreshToken(token)).
+					Return(dropBox, nil)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "The name of the sender should be between 1 and 128 symbols")
+			})
+		})
+
+		When("the email of the sender is invalid", func() {
+			BeforeEach(func() {
+				fields["email"] = "not-an-email"
+				fmDAO.EXPECT().
+					GetDropBox(auth.HashRefreshToken(token)).
+					Return(dropBox, nil)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid email of the sender")
+			})
+		})
+
+		When("the drop box doesnt accept more files", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetDropBox(auth.HashRefreshToken(token)).
+					Return(dropBox, nil)
+				fmDAO.EXPECT().
+					AddDropBoxFile(uint(dropBoxID), fileName, "Partner", "partner@example.com").
+					Return(uint(0), myerr.NewQuotaExceededError("The drop box doesnt accept more files"))
+			})
+
+			It("returns request entity too large", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusRequestEntityTooLarge, "The drop box doesnt accept more files")
+			})
+		})
+
+		When("the group would exceed its quota", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetDropBox(auth.HashRefreshToken(token)).
+					Return(dropBox, nil)
+				fmDAO.EXPECT().
+					AddDropBoxFile(uint(dropBoxID), fileName, "Partner", "partner@example.com").
+					Return(uint(fileID), nil)
+				fmDAO.EXPECT().
+					AttachFileBlob(uint(fileID), gomock.Any(), int64(len(content)), dao.Quota{}, uint(0), gomock.Any()).
+					Return(myerr.NewQuotaExceededError("The file exceeds the storage quota of the group"))
+				fmDAO.EXPECT().
+					RemoveDropBoxFile(uint(dropBoxID), uint(fileID)).
+					Return(nil)
+			})
+
+			It("removes the file and returns request entity too large", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusRequestEntityTooLarge, "The file exceeds the storage quota of the group")
+			})
+		})
+
+		When("the file is received", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetDropBox(auth.HashRefreshToken(token)).
+					Return(dropBox, nil)
+				fmDAO.EXPECT().
+					AddDropBoxFile(uint(dropBoxID), fileName, "Partner", "partner@example.com").
+					Return(uint(fileID), nil)
+				fmDAO.EXPECT().
+					AttachFileBlob(uint(fileID), gomock.Any(), int64(len(content)), dao.Quota{}, uint(0), gomock.Any()).
+					DoAndReturn(func(_ uint, _ string, _ int64, _ dao.Quota, _ uint, onAttach func(bool) error) error {
+						return onAttach(true)
+					})
+				fmDAO.EXPECT().
+					AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			})
+
+			It("stores the file without an owner", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+			})
+		})
+	})
+})
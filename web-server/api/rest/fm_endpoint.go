@@ -1,15 +1,19 @@
 package rest
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
-	"os"
 	"strconv"
+	"strings"
 
 	"github.com/danielpenchev98/UShare/web-server/api/common"
 	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
 	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,27 +23,60 @@ type FileManagementEndpoint interface {
 	DownloadFile(*gin.Context)
 	DeleteFile(*gin.Context)
 	RetrieveAllFilesInfo(c *gin.Context)
+	CreateUploadSession(*gin.Context)
+	GetUploadSession(*gin.Context)
+	UploadChunk(*gin.Context)
+	FinalizeUpload(*gin.Context)
+	AbortUpload(*gin.Context)
+	GetFileVersions(*gin.Context)
+	RestoreFileVersion(*gin.Context)
+	SetMaxFileVersions(*gin.Context)
+	GetTrashedFiles(*gin.Context)
+	RestoreTrashedFile(*gin.Context)
+	DeleteTrashedFile(*gin.Context)
+	GetUserUsage(*gin.Context)
+	GetGroupUsage(*gin.Context)
+	CreateFolder(*gin.Context)
+	RenameFolder(*gin.Context)
+	MoveFolder(*gin.Context)
+	DeleteFolder(*gin.Context)
+	MoveFile(*gin.Context)
+	CreateShareLink(*gin.Context)
+	GetShareLinks(*gin.Context)
+	RevokeShareLink(*gin.Context)
+	DownloadSharedFile(*gin.Context)
+	CreateDropBox(*gin.Context)
+	GetDropBoxes(*gin.Context)
+	DeleteDropBox(*gin.Context)
+	UploadToDropBox(*gin.Context)
 }
 
 //FileManagementEndpointImpl - implementation of FileManagementEndpoint interface
 type FileManagementEndpointImpl struct {
-	UamDAO    dao.UamDAO
-	groupsDir string
-	FmDAO     dao.FmDAO
+	UamDAO  dao.UamDAO
+	storage storage.Backend
+	FmDAO   dao.FmDAO
+	quota   dao.Quota
 }
 
 //NewFileManagementEndpointImpl - instance creation of FileManagementEndpointImpl
-func NewFileManagementEndpointImpl(uam dao.UamDAO, fm dao.FmDAO, groupsDir string) *FileManagementEndpointImpl {
+//quota limits the storage, used by the files of every user and every group
+func NewFileManagementEndpointImpl(uam dao.UamDAO, fm dao.FmDAO, backend storage.Backend, quota dao.Quota) *FileManagementEndpointImpl {
 	return &FileManagementEndpointImpl{
-		UamDAO:    uam,
-		FmDAO:     fm,
-		groupsDir: groupsDir,
+		UamDAO:  uam,
+		FmDAO:   fm,
+		storage: backend,
+		quota:   quota,
 	}
 }
 
 //UploadFile - handler for the upload of files from a user of specific group
+//the file is placed in the root of the group, unless the folder query parameter is specified
+//the upload is rejected, if the optional checksum header doesnt match the received content
 //returns 500, if there is a problem with the server
 //returns 400, if the user input is invalid
+//returns 404, if the folder doesnt exist
+//returns 413, if the file exceeds the storage quota of the user or the group
 //returns 201, if the file is uploaded
 func (i *FileManagementEndpointImpl) UploadFile(c *gin.Context) {
 	var (
@@ -52,20 +89,13 @@ func (i *FileManagementEndpointImpl) UploadFile(c *gin.Context) {
 		return
 	}
 
-	file, err := c.FormFile("file")
-	if err != nil {
-		common.SendErrorResponse(c, myerr.NewClientError("Problem with the file"))
-		return
-	}
-
-	var group models.Group
 	groupName := c.Query("group_name")
 	if groupName == "" {
 		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
 		return
 	}
 
-	group, err = i.UamDAO.GetGroup(groupName)
+	group, err := i.UamDAO.GetGroup(groupName)
 	switch err.(type) {
 	case nil:
 		break
@@ -85,16 +115,43 @@ func (i *FileManagementEndpointImpl) UploadFile(c *gin.Context) {
 		return
 	}
 
-	fileID, err := i.FmDAO.AddFileInfo(userID, file.Filename, groupName)
+	//the quota is checked before the file is received, so too big uploads are rejected early
+	if i.quota.IsLimited() && c.Request.ContentLength > 0 {
+		if err = i.FmDAO.CheckQuota(userID, groupName, c.Request.ContentLength, i.quota); err != nil {
+			common.SendErrorResponse(c, err)
+			return
+		}
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Problem with the file"))
+		return
+	}
+
+	folder, err := i.FmDAO.GetFolder(userID, groupName, c.Query("folder"))
 	if err != nil {
 		common.SendErrorResponse(c, err)
 		return
 	}
 
-	dst := fmt.Sprintf("%s/%s/%d", i.groupsDir, groupName, fileID)
-	if err = c.SaveUploadedFile(file, dst); err != nil {
+	fileID, err := i.FmDAO.AddFileInfo(userID, file.Filename, folder.ID, groupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+	common.SetAuditTarget(c, groupName, fileID)
+
+	src, err := file.Open()
+	if err != nil {
 		i.FmDAO.RemoveFileInfo(userID, fileID, groupName)
-		common.SendErrorResponse(c, myerr.NewServerError(fmt.Sprintf("Couldnt save the file in the group dir [%s]", groupName)))
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Couldnt open the uploaded file"))
+		return
+	}
+	defer src.Close()
+
+	if err = i.storeFileContent(userID, fileID, groupName, src, c.GetHeader(common.ChecksumHeader)); err != nil {
+		common.SendErrorResponse(c, err)
 		return
 	}
 
@@ -104,9 +161,64 @@ func (i *FileManagementEndpointImpl) UploadFile(c *gin.Context) {
 	})
 }
 
-//DownloadFile - downloads a file given group
+//storeFileContent - saves the content of a newly added file together with its checksum and size
+//the content is kept only once in the blob store, no matter how many files share it
+//if the content cannot be saved or doesnt match the expected checksum (when given), the file is removed
+func (i *FileManagementEndpointImpl) storeFileContent(userID uint, fileID uint, groupName string, content io.Reader, expectedChecksum string) error {
+	return i.storeContent(fileID, 0, groupName, content, expectedChecksum, func() {
+		i.FmDAO.RemoveFileInfo(userID, fileID, groupName)
+	})
+}
+
+//storeContent - saves the content of a newly added file like storeFileContent, but the file is removed with removeFileInfo
+//sessionID is the upload session, from which the content comes (0 if there is none), so its reserved bytes arent counted in the quota
+func (i *FileManagementEndpointImpl) storeContent(fileID uint, sessionID uint, groupName string, content io.Reader, expectedChecksum string, removeFileInfo func()) error {
+	key := storage.FileKey(groupName, fileID)
+	hash := sha256.New()
+	counter := &countingReader{reader: io.TeeReader(content, hash)}
+
+	if err := i.storage.Put(key, counter); err != nil {
+		removeFileInfo()
+		return myerr.NewServerErrorWrap(err, fmt.Sprintf("Couldnt save the file in the group [%s]", groupName))
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if expectedChecksum != "" && !strings.EqualFold(expectedChecksum, checksum) {
+		i.storage.Delete(key)
+		removeFileInfo()
+		return myerr.NewClientError(fmt.Sprintf("The checksum of the received file [%s] doesnt match the expected one", checksum))
+	}
+
+	err := i.FmDAO.AttachFileBlob(fileID, checksum, counter.count, i.quota, sessionID, func(isNewBlob bool) error {
+		if !isNewBlob {
+			return i.storage.Delete(key)
+		}
+		return i.storage.Move(key, storage.BlobKey(checksum))
+	})
+	if err != nil {
+		i.storage.Delete(key)
+		removeFileInfo()
+		return err
+	}
+	return nil
+}
+
+//contentKey - returns the key, under which the content of the file is stored
+//files, uploaded before the introduction of blobs, are still kept under the group
+func contentKey(groupName string, fileInfo models.FileInfo) string {
+	if fileInfo.Deduplicated {
+		return storage.BlobKey(fileInfo.Checksum)
+	}
+	return storage.FileKey(groupName, fileInfo.ID)
+}
+
+//DownloadFile - downloads a file given group. Supports partial and conditional requests
+//the latest version of the file is downloaded, unless the version query parameter is specified
 //returns 500, if an error occurs due to system failure
 //returns 400 - if the user doesnt have enough permissions
+//returns 404, if the file or the version doesnt exist
+//returns 304, if the file matches the ETag in If-None-Match
+//returns 206 + the requested part of the file, if the request contains a satisfiable Range
 //returns 200 + the downloaded file if the users has the permissions
 func (i *FileManagementEndpointImpl) DownloadFile(c *gin.Context) {
 	var (
@@ -135,6 +247,14 @@ func (i *FileManagementEndpointImpl) DownloadFile(c *gin.Context) {
 		return
 	}
 
+	var version uint64
+	if versionString := c.Query("version"); versionString != "" {
+		if version, err = strconv.ParseUint(versionString, 10, 32); err != nil || version == 0 {
+			common.SendErrorResponse(c, myerr.NewClientError("Invalid version"))
+			return
+		}
+	}
+
 	group, err := i.UamDAO.GetGroup(groupName)
 	if exists, err := i.UamDAO.MemberExists(userID, group.ID); err != nil {
 		common.SendErrorResponse(c, err)
@@ -144,17 +264,35 @@ func (i *FileManagementEndpointImpl) DownloadFile(c *gin.Context) {
 		return
 	}
 
-	fileInfo, err := i.FmDAO.GetFileInfo(userID, uint(fileID), groupName)
+	fileInfo, err := i.FmDAO.GetFileVersion(userID, uint(fileID), uint(version), groupName)
 	if err != nil {
 		common.SendErrorResponse(c, err)
+		return
 	}
 
+	content, err := i.storage.Get(contentKey(groupName, fileInfo))
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+	defer content.Close()
+
+	serveFileContent(c, fileInfo, content)
+}
+
+//serveFileContent - sends the content of the file as an attachment
+//ServeContent takes care of the Range, If-Range and If-None-Match headers, given the ETag
+func serveFileContent(c *gin.Context, fileInfo models.FileInfo, content io.ReadSeeker) {
 	c.Writer.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileInfo.Name))
-	filePath := fmt.Sprintf("%s/%s/%d", i.groupsDir, groupName, fileInfo.ID)
-	c.File(filePath)
+	if fileInfo.Checksum != "" {
+		c.Writer.Header().Set("ETag", fmt.Sprintf("\"%s\"", fileInfo.Checksum))
+		c.Writer.Header().Set(common.ChecksumHeader, fileInfo.Checksum)
+	}
+	http.ServeContent(c.Writer, c.Request, fileInfo.Name, fileInfo.CreatedAt, content)
 }
 
-//DeleteFile - deletes a file from the system
+//DeleteFile - moves a file together with all its versions to the trash of the group
+//the content is kept until the file is permanently deleted from the trash or purged after some time
 //returns 500, if an error occurs due to system failure
 //returns 400, if the user doesnt have enough permissions
 //returns 200, if the file is succesfully deleted
@@ -184,23 +322,22 @@ func (i *FileManagementEndpointImpl) DeleteFile(c *gin.Context) {
 		return
 	}
 
-	if err := i.FmDAO.RemoveFileInfo(userID, rq.FileID, rq.GroupName); err != nil {
+	if err = i.FmDAO.TrashFile(userID, rq.FileID, rq.GroupName); err != nil {
 		common.SendErrorResponse(c, err)
 		return
 	}
 
-	path := fmt.Sprintf("%s/%s/%d", i.groupsDir, rq.GroupName, rq.FileID)
-	os.Remove(path)
-
 	c.JSON(http.StatusOK, common.BasicResponse{
 		Status: http.StatusOK,
 	})
 }
 
-//RetrieveAllFilesInfo - retrieves info about all files owned by a particular group
+//RetrieveAllFilesInfo - retrieves info about the folders and the latest versions of the files in a folder of a particular group
+//the root of the group is listed, unless the path query parameter is specified
 //returns 500, if error occurrs due to system failure
 //returns 400, if the user doesnt have enough permissions
-//returns 200 + info about files
+//returns 404, if the folder doesnt exist
+//returns 200 + info about folders and files
 func (i *FileManagementEndpointImpl) RetrieveAllFilesInfo(c *gin.Context) {
 	var (
 		userID uint
@@ -218,7 +355,7 @@ func (i *FileManagementEndpointImpl) RetrieveAllFilesInfo(c *gin.Context) {
 		return
 	}
 
-	fileInfos, err := i.FmDAO.GetAllFilesInfo(userID, groupName)
+	folder, err := i.FmDAO.GetFolder(userID, groupName, c.Query("path"))
 	if _, ok := err.(*myerr.ClientError); ok {
 		common.SendErrorResponse(c, myerr.NewClientErrorWrap(err, "Problem with file retrieval"))
 		return
@@ -227,18 +364,53 @@ func (i *FileManagementEndpointImpl) RetrieveAllFilesInfo(c *gin.Context) {
 		return
 	}
 
+	folders, err := i.FmDAO.GetSubfolders(folder.GroupID, folder.ID)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	fileInfos, err := i.FmDAO.GetAllFilesInfo(userID, folder.ID, groupName)
+	if _, ok := err.(*myerr.ClientError); ok {
+		common.SendErrorResponse(c, myerr.NewClientErrorWrap(err, "Problem with file retrieval"))
+		return
+	} else if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	folderResponses := make([]common.FolderInfoResponse, 0, len(folders))
+	for _, folder := range folders {
+		folderResponses = append(folderResponses, common.FolderInfoResponse{
+			ID:        folder.ID,
+			Name:      folder.Name,
+			CreatedAt: folder.CreatedAt,
+			OwnerID:   folder.OwnerID,
+		})
+	}
+
 	fileResponses := make([]common.FileInfoResponse, 0, len(fileInfos))
 	for _, fileInfo := range fileInfos {
-		fileResponses = append(fileResponses, common.FileInfoResponse{
-			ID:         fileInfo.ID,
-			Name:       fileInfo.Name,
-			UploadedAt: fileInfo.CreatedAt,
-			OwnerID:    fileInfo.OwnerID,
-		})
+		fileResponses = append(fileResponses, newFileInfoResponse(fileInfo))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status": http.StatusOK,
-		"files":  fileResponses,
+		"status":  http.StatusOK,
+		"folders": folderResponses,
+		"files":   fileResponses,
 	})
 }
+
+func newFileInfoResponse(fileInfo models.FileInfo) common.FileInfoResponse {
+	return common.FileInfoResponse{
+		ID:            fileInfo.ID,
+		Name:          fileInfo.Name,
+		UploadedAt:    fileInfo.CreatedAt,
+		OwnerID:       fileInfo.OwnerID,
+		Checksum:      fileInfo.Checksum,
+		Size:          fileInfo.Size,
+		Version:       fileInfo.Version,
+		UploaderName:  fileInfo.UploaderName,
+		UploaderEmail: fileInfo.UploaderEmail,
+	}
+}
@@ -4,17 +4,22 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
+	"github.com/danielpenchev98/UShare/web-server/api/common"
 	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
 	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
 	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
@@ -67,6 +72,8 @@ var _ = Describe("UamEndpoint", func() {
 		groupsDir = "."
 		fileName  = "test"
 		fileID    = 3
+
+		emptyChecksum = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
 	)
 
 	var (
@@ -78,7 +85,7 @@ var _ = Describe("UamEndpoint", func() {
 		controller := gomock.NewController(GinkgoT())
 		uamDAO = dao_mocks.NewMockUamDAO(controller)
 		fmDAO = dao_mocks.NewMockFmDAO(controller)
-		fmRest := rest.NewFileManagementEndpointImpl(uamDAO, fmDAO, groupsDir)
+		fmRest := rest.NewFileManagementEndpointImpl(uamDAO, fmDAO, storage.NewLocalBackend(groupsDir), dao.Quota{})
 
 		router = setupRouterFmEndpoint(fmRest, userID)
 		recorder = httptest.NewRecorder()
@@ -96,6 +103,7 @@ var _ = Describe("UamEndpoint", func() {
 
 		AfterEach(func() {
 			os.RemoveAll(path.Join(groupsDir, groupName))
+			os.RemoveAll(path.Join(groupsDir, "blobs"))
 		})
 
 		When("upload request is sent and authentication passes", func() {
@@ -103,18 +111,18 @@ var _ = Describe("UamEndpoint", func() {
 			Context("and 'file' key not used for the file attachment", func() {
 				BeforeEach(func() {
 					uamDAO.EXPECT().
-						GetGroup(gomock.Any()).
-						Times(0)
+						GetGroup(groupName).
+						Return(models.Group{ID: groupID}, nil)
 
 					uamDAO.EXPECT().
-						MemberExists(gomock.Any(), gomock.Any()).
-						Times(0)
+						MemberExists(uint(userID), uint(groupID)).
+						Return(true, nil)
 
 					fmDAO.EXPECT().
-						AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any()).
+						AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 						Times(0)
 
-					req, _ = http.NewRequest("POST", "/protected/group/file/upload", nil)
+					req, _ = http.NewRequest("POST", fmt.Sprintf("/protected/group/file/upload?group_name=%s", groupName), nil)
 					req.Header.Set("Authorization", "Bearer sometoken")
 				})
 
@@ -145,7 +153,7 @@ var _ = Describe("UamEndpoint", func() {
 							Times(0)
 
 						fmDAO.EXPECT().
-							AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any()).
+							AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 							Times(0)
 
 						req, _ = http.NewRequest("POST", "/protected/group/file/upload", form)
@@ -177,7 +185,7 @@ var _ = Describe("UamEndpoint", func() {
 								Times(0)
 
 							fmDAO.EXPECT().
-								AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any()).
+								AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 								Times(0)
 						})
 
@@ -199,7 +207,7 @@ var _ = Describe("UamEndpoint", func() {
 									Times(0)
 
 								fmDAO.EXPECT().
-									AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any()).
+									AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 									Times(0)
 							})
 
@@ -231,7 +239,7 @@ var _ = Describe("UamEndpoint", func() {
 									)
 
 									fmDAO.EXPECT().
-										AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any()).
+										AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 										Times(0)
 								})
 
@@ -255,7 +263,7 @@ var _ = Describe("UamEndpoint", func() {
 										)
 
 										fmDAO.EXPECT().
-											AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any()).
+											AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 											Times(0)
 									})
 
@@ -278,7 +286,11 @@ var _ = Describe("UamEndpoint", func() {
 													Return(true, nil),
 
 												fmDAO.EXPECT().
-													AddFileInfo(uint(userID), fileName, groupName).
+													GetFolder(uint(userID), groupName, "").
+													Return(models.Folder{GroupID: groupID}, nil),
+
+												fmDAO.EXPECT().
+													AddFileInfo(uint(userID), fileName, uint(0), groupName).
 													Return(uint(fileID), myerr.NewServerError("test-error")),
 											)
 
@@ -290,6 +302,45 @@ var _ = Describe("UamEndpoint", func() {
 										})
 									})
 
+									Context("and checksum header doesnt match the file", func() {
+										BeforeEach(func() {
+											req.Header.Set(common.ChecksumHeader, "wrong-checksum")
+
+											gomock.InOrder(
+												uamDAO.EXPECT().
+													GetGroup(groupName).
+													Return(group, nil),
+
+												uamDAO.EXPECT().
+													MemberExists(uint(userID), uint(groupID)).
+													Return(true, nil),
+
+												fmDAO.EXPECT().
+													GetFolder(uint(userID), groupName, "").
+													Return(models.Folder{GroupID: groupID}, nil),
+
+												fmDAO.EXPECT().
+													AddFileInfo(uint(userID), fileName, uint(0), groupName).
+													Return(uint(fileID), nil),
+
+												fmDAO.EXPECT().
+													RemoveFileInfo(uint(userID), uint(fileID), groupName).
+													Return(nil),
+											)
+
+											fmDAO.EXPECT().
+												AttachFileBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+												Times(0)
+										})
+
+										It("returns bad request error response and removes the file", func() {
+											router.ServeHTTP(recorder, req)
+											assertErrorResponse(recorder, http.StatusBadRequest, "doesnt match the expected one")
+											_, err := os.Stat(outputFilePath)
+											Expect(os.IsNotExist(err)).To(BeTrue())
+										})
+									})
+
 									Context("and file is uploaded successfully", func() {
 										BeforeEach(func() {
 											gomock.InOrder(
@@ -302,17 +353,66 @@ var _ = Describe("UamEndpoint", func() {
 													Return(true, nil),
 
 												fmDAO.EXPECT().
-													AddFileInfo(uint(userID), fileName, groupName).
+													GetFolder(uint(userID), groupName, "").
+													Return(models.Folder{GroupID: groupID}, nil),
+
+												fmDAO.EXPECT().
+													AddFileInfo(uint(userID), fileName, uint(0), groupName).
 													Return(uint(fileID), nil),
+
+												fmDAO.EXPECT().
+													AttachFileBlob(uint(fileID), emptyChecksum, int64(0), dao.Quota{}, uint(0), gomock.Any()).
+													DoAndReturn(func(_ uint, _ string, _ int64, _ dao.Quota, _ uint, storeContent func(bool) error) error {
+														return storeContent(true)
+													}),
 											)
 
 										})
 
-										It("succeeds", func() {
+										It("succeeds and moves the content in the blob store", func() {
 											router.ServeHTTP(recorder, req)
 											Expect(recorder.Code).To(Equal(http.StatusCreated))
-											_, err := os.Stat(outputFilePath)
+											_, err := os.Stat(path.Join(groupsDir, storage.BlobKey(emptyChecksum)))
 											Expect(err).To(BeNil())
+											_, err = os.Stat(outputFilePath)
+											Expect(os.IsNotExist(err)).To(BeTrue())
+										})
+									})
+
+									Context("and a file with the same content is already stored", func() {
+										BeforeEach(func() {
+											gomock.InOrder(
+												uamDAO.EXPECT().
+													GetGroup(groupName).
+													Return(group, nil),
+
+												uamDAO.EXPECT().
+													MemberExists(uint(userID), uint(groupID)).
+													Return(true, nil),
+
+												fmDAO.EXPECT().
+													GetFolder(uint(userID), groupName, "").
+													Return(models.Folder{GroupID: groupID}, nil),
+
+												fmDAO.EXPECT().
+													AddFileInfo(uint(userID), fileName, uint(0), groupName).
+													Return(uint(fileID), nil),
+
+												fmDAO.EXPECT().
+													AttachFileBlob(uint(fileID), emptyChecksum, int64(0), dao.Quota{}, uint(0), gomock.Any()).
+													DoAndReturn(func(_ uint, _ string, _ int64, _ dao.Quota, _ uint, storeContent func(bool) error) error {
+														return storeContent(false)
+													}),
+											)
+										})
+
+										It("succeeds without keeping a second copy of the content", func() {
+											router.ServeHTTP(recorder, req)
+											Expect(recorder.Code).To(Equal(http.StatusCreated))
+											_, err := os.Stat(path.Join(groupsDir, storage.BlobKey(emptyChecksum)))
+											Expect(os.IsNotExist(err)).To(BeTrue())
+											_, err = os.Stat(outputFilePath)
+											Expect(os.IsNotExist(err)).To(BeTrue())
 										})
 									})
 								})
@@ -325,4 +425,116 @@ var _ = Describe("UamEndpoint", func() {
 
 		})
 	})
+	Context("DownloadFile", func() {
+		const content = "0123456789"
+
+		var (
+			group    models.Group
+			fileInfo models.FileInfo
+			version  uint
+		)
+
+		BeforeEach(func() {
+			os.Mkdir(path.Join(groupsDir, groupName), 0777)
+			ioutil.WriteFile(outputFilePath, []byte(content), 0644)
+
+			group = models.Group{Name: groupName, ID: groupID}
+			fileInfo = models.FileInfo{ID: fileID, Name: fileName, GroupID: groupID, Checksum: "checksum"}
+			version = 0
+
+			uamDAO.EXPECT().
+				GetGroup(groupName).
+				Return(group, nil)
+
+			uamDAO.EXPECT().
+				MemberExists(uint(userID), uint(groupID)).
+				Return(true, nil)
+
+			req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/file/download?group_name=%s&file_id=%d", groupName, fileID), nil)
+		})
+
+		JustBeforeEach(func() {
+			fmDAO.EXPECT().
+				GetFileVersion(uint(userID), uint(fileID), version, groupName).
+				Return(fileInfo, nil)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(path.Join(groupsDir, groupName))
+			os.RemoveAll(path.Join(groupsDir, "blobs"))
+		})
+
+		When("the content of the file is kept in a blob", func() {
+			const blobContent = "blob-content"
+
+			BeforeEach(func() {
+				fileInfo.Deduplicated = true
+				storage.NewLocalBackend(groupsDir).Put(storage.BlobKey(fileInfo.Checksum), strings.NewReader(blobContent))
+			})
+
+			It("returns the content of the blob", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(Equal(blobContent))
+			})
+		})
+
+		When("a particular version is requested", func() {
+			BeforeEach(func() {
+				version = 2
+				req.URL.RawQuery += "&version=2"
+			})
+
+			It("returns the content of that version", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(Equal(content))
+			})
+		})
+
+		When("the whole file is requested", func() {
+			It("returns the file with its ETag", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Header().Get("ETag")).To(Equal(`"checksum"`))
+				Expect(recorder.Body.String()).To(Equal(content))
+			})
+		})
+
+		When("a range of the file is requested", func() {
+			BeforeEach(func() {
+				req.Header.Set("Range", "bytes=4-")
+			})
+
+			It("returns partial content", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusPartialContent))
+				Expect(recorder.Body.String()).To(Equal(content[4:]))
+			})
+
+			Context("and If-Range doesnt match the ETag", func() {
+				BeforeEach(func() {
+					req.Header.Set("If-Range", `"other"`)
+				})
+
+				It("returns the whole file", func() {
+					router.ServeHTTP(recorder, req)
+					Expect(recorder.Code).To(Equal(http.StatusOK))
+					Expect(recorder.Body.String()).To(Equal(content))
+				})
+			})
+		})
+
+		When("If-None-Match matches the ETag", func() {
+			BeforeEach(func() {
+				req.Header.Set("If-None-Match", `"checksum"`)
+			})
+
+			It("returns not modified", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusNotModified))
+				Expect(recorder.Body.Len()).To(Equal(0))
+			})
+		})
+	})
 })
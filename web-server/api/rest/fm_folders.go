@@ -0,0 +1,166 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+//CreateFolder - handler for creating a folder in a group, together with all missing parent folders
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the folder already exists
+//returns 201 + the id of the folder, if the folder is created
+func (i *FileManagementEndpointImpl) CreateFolder(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.FolderPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Path == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname or path isnt specified"))
+		return
+	}
+
+	folderID, err := i.FmDAO.CreateFolder(userID, rq.GroupName, rq.Path)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":    http.StatusCreated,
+		"folder_id": folderID,
+	})
+}
+
+//RenameFolder - handler for giving a new name to a folder of a group
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user cannot change the folder
+//returns 404, if the folder doesnt exist
+//returns 200, if the folder is renamed
+func (i *FileManagementEndpointImpl) RenameFolder(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.RenameFolderPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Path == "" || rq.Name == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname, path or name isnt specified"))
+		return
+	}
+
+	if err = i.FmDAO.RenameFolder(userID, rq.GroupName, rq.Path, rq.Name); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//MoveFolder - handler for moving a folder of a group, together with its contents, inside another folder. The empty target is the root of the group
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user cannot change the folder
+//returns 404, if the folder or the target doesnt exist
+//returns 200, if the folder is moved
+func (i *FileManagementEndpointImpl) MoveFolder(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.MoveFolderPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Path == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname or path isnt specified"))
+		return
+	}
+
+	if err = i.FmDAO.MoveFolder(userID, rq.GroupName, rq.Path, rq.Target); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//DeleteFolder - handler for deleting an empty folder of a group
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid, the folder isnt empty or the user cannot change the folder
+//returns 404, if the folder doesnt exist
+//returns 200, if the folder is deleted
+func (i *FileManagementEndpointImpl) DeleteFolder(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.FolderPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Path == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname or path isnt specified"))
+		return
+	}
+
+	if err = i.FmDAO.RemoveFolder(userID, rq.GroupName, rq.Path); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//MoveFile - handler for moving a file, together with all its versions, in another folder of the group
+//the file keeps its name, unless a new one is specified
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid, the name is taken or the user cannot change the file
+//returns 404, if the file or the folder doesnt exist
+//returns 200, if the file is moved
+func (i *FileManagementEndpointImpl) MoveFile(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.MoveFilePayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	if err = i.FmDAO.MoveFile(userID, rq.FileID, rq.GroupName, rq.Folder, rq.FileName); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
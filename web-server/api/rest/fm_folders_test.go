@@ -0,0 +1,250 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterFolders(fmRest rest.FileManagementEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.GET("/group/files", fmRest.RetrieveAllFilesInfo)
+		protected.PUT("/group/file/move", fmRest.MoveFile)
+		protected.POST("/group/folder", fmRest.CreateFolder)
+		protected.PUT("/group/folder/rename", fmRest.RenameFolder)
+		protected.PUT("/group/folder/move", fmRest.MoveFolder)
+		protected.DELETE("/group/folder", fmRest.DeleteFolder)
+	}
+	return r
+}
+
+var _ = Describe("Folders", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		fmDAO    *dao_mocks.MockFmDAO
+		uamDAO   *dao_mocks.MockUamDAO
+		req      *http.Request
+	)
+
+	const (
+		userID    = 1
+		groupName = "groupName"
+		groupID   = 2
+		folderID  = 5
+		fileID    = 3
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+		fmRest := rest.NewFileManagementEndpointImpl(uamDAO, fmDAO, storage.NewLocalBackend("."), dao.Quota{})
+
+		router = setupRouterFolders(fmRest, userID)
+		recorder = httptest.NewRecorder()
+	})
+
+	Context("RetrieveAllFilesInfo", func() {
+		When("the folder doesnt exist", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetFolder(uint(userID), groupName, "reports/2026").
+					Return(models.Folder{}, myerr.NewItemNotFoundError("Folder [reports/2026] does not exist"))
+
+				fmDAO.EXPECT().
+					GetAllFilesInfo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/files?group_name=%s&path=reports/2026", groupName), nil)
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Folder [reports/2026] does not exist")
+			})
+		})
+
+		When("the folder exists", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetFolder(uint(userID), groupName, "reports").
+						Return(models.Folder{ID: folderID, Name: "reports", GroupID: groupID}, nil),
+
+					fmDAO.EXPECT().
+						GetSubfolders(uint(groupID), uint(folderID)).
+						Return([]models.Folder{{ID: 6, Name: "2026", GroupID: groupID, ParentID: folderID}}, nil),
+
+					fmDAO.EXPECT().
+						GetAllFilesInfo(uint(userID), uint(folderID), groupName).
+						Return([]models.FileInfo{{ID: fileID, Name: "q3.pdf", FolderID: folderID, Version: 1}}, nil),
+				)
+
+				req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/files?group_name=%s&path=reports", groupName), nil)
+			})
+
+			It("returns the contents of the folder", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				body := struct {
+					Folders []common.FolderInfoResponse `json:"folders"`
+					Files   []common.FileInfoResponse   `json:"files"`
+				}{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.Folders).To(HaveLen(1))
+				Expect(body.Folders[0].Name).To(Equal("2026"))
+				Expect(body.Files).To(HaveLen(1))
+				Expect(body.Files[0].Name).To(Equal("q3.pdf"))
+			})
+		})
+	})
+
+	Context("CreateFolder", func() {
+		When("path isnt specified", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					CreateFolder(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("POST", "/protected/group/folder", strings.NewReader(fmt.Sprintf(`{"group_name":"%s"}`, groupName)))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Groupname or path isnt specified")
+			})
+		})
+
+		When("the folder already exists", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					CreateFolder(uint(userID), groupName, "reports").
+					Return(uint(0), myerr.NewClientError("Folder [reports] already exists"))
+
+				req, _ = http.NewRequest("POST", "/protected/group/folder",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","path":"reports"}`, groupName)))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Folder [reports] already exists")
+			})
+		})
+
+		When("the folder is created", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					CreateFolder(uint(userID), groupName, "reports/2026").
+					Return(uint(folderID), nil)
+
+				req, _ = http.NewRequest("POST", "/protected/group/folder",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","path":"reports/2026"}`, groupName)))
+			})
+
+			It("returns the id of the folder", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				body := struct {
+					FolderID uint `json:"folder_id"`
+				}{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.FolderID).To(Equal(uint(folderID)))
+			})
+		})
+	})
+
+	Context("RenameFolder", func() {
+		When("the folder is renamed", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RenameFolder(uint(userID), groupName, "reports", "archive").
+					Return(nil)
+
+				req, _ = http.NewRequest("PUT", "/protected/group/folder/rename",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","path":"reports","name":"archive"}`, groupName)))
+			})
+
+			It("returns status ok", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("MoveFolder", func() {
+		When("the folder is moved inside itself", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					MoveFolder(uint(userID), groupName, "reports", "reports/2026").
+					Return(myerr.NewClientError("A folder cannot be moved inside itself"))
+
+				req, _ = http.NewRequest("PUT", "/protected/group/folder/move",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","path":"reports","target":"reports/2026"}`, groupName)))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "A folder cannot be moved inside itself")
+			})
+		})
+	})
+
+	Context("DeleteFolder", func() {
+		When("the folder isnt empty", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RemoveFolder(uint(userID), groupName, "reports").
+					Return(myerr.NewClientError("The folder isnt empty"))
+
+				req, _ = http.NewRequest("DELETE", "/protected/group/folder",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","path":"reports"}`, groupName)))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "The folder isnt empty")
+			})
+		})
+	})
+
+	Context("MoveFile", func() {
+		When("the file is moved", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					MoveFile(uint(userID), uint(fileID), groupName, "reports/2026", "q3.pdf").
+					Return(nil)
+
+				req, _ = http.NewRequest("PUT", "/protected/group/file/move",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","file_id":%d,"folder":"reports/2026","file_name":"q3.pdf"}`, groupName, fileID)))
+			})
+
+			It("returns status ok", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+})
@@ -0,0 +1,212 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	//maxSharePasswordLength - bcrypt uses only the first 72 bytes of the password
+	maxSharePasswordLength = 72
+	//maxSharePasswordAttempts - how many wrong passwords can be sent for a share link, before it is locked
+	maxSharePasswordAttempts = 10
+)
+
+//CreateShareLink - handler for creation of a link, through which a file of the group can be downloaded without an account
+//the link can be protected with a password, can expire and can be limited to a number of downloads
+//the token is shown only in the response, while the server stores only its hash
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the role of the user doesnt allow changing the file
+//returns 404, if the file doesnt exist
+//returns 201 + the id and the token of the link, if it is created
+func (i *FileManagementEndpointImpl) CreateShareLink(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.ShareLinkPayload
+	if err = c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	} else if len(rq.Password) > maxSharePasswordLength {
+		common.SendErrorResponse(c, myerr.NewClientError("The password of the share link is too long"))
+		return
+	}
+
+	var passwordHash string
+	if rq.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(rq.Password), bcrypt.DefaultCost)
+		if err != nil {
+			common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with hashing the password of the share link."))
+			return
+		}
+		passwordHash = string(hashedPassword)
+	}
+
+	var expiresAt *time.Time
+	if rq.ExpiresInHours > 0 {
+		expiration := time.Now().Add(time.Duration(rq.ExpiresInHours) * time.Hour)
+		expiresAt = &expiration
+	}
+
+	token, tokenHash, err := auth.GenerateShareLinkToken()
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	linkID, err := i.FmDAO.CreateShareLink(userID, rq.FileID, rq.GroupName, tokenHash, passwordHash, expiresAt, rq.MaxDownloads)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with creation of share link.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, common.ShareLinkResponse{
+		Status: http.StatusCreated,
+		ID:     linkID,
+		Token:  token,
+	})
+}
+
+//GetShareLinks - handler for fetching the share links of the files in a group
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 200 + info about the links
+func (i *FileManagementEndpointImpl) GetShareLinks(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groupName := c.Query("group_name")
+	if groupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	links, err := i.FmDAO.GetShareLinks(userID, groupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	linksInfo := make([]common.ShareLinkInfo, 0, len(links))
+	for _, link := range links {
+		linksInfo = append(linksInfo, common.ShareLinkInfo{
+			ID:                link.ID,
+			FileID:            link.FileID,
+			FileName:          link.FileName,
+			Version:           link.Version,
+			Creator:           link.Creator,
+			PasswordProtected: link.PasswordHash != "",
+			CreatedAt:         link.CreatedAt,
+			ExpiresAt:         link.ExpiresAt,
+			MaxDownloads:      link.MaxDownloads,
+			Downloads:         link.Downloads,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": http.StatusOK,
+		"links":  linksInfo,
+	})
+}
+
+//RevokeShareLink - handler for revocation of a share link, after which the file can no longer be downloaded through it
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the role of the user doesnt allow revoking the link
+//returns 404, if the link doesnt exist
+//returns 200, if the link is revoked
+func (i *FileManagementEndpointImpl) RevokeShareLink(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.ShareLinkRequestPayload
+	if err = c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	if err = i.FmDAO.RemoveShareLink(userID, rq.LinkID, rq.GroupName); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with revocation of share link.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//DownloadSharedFile - publicly accessible handler for downloading a file through a share link. Supports partial and conditional requests
+//the password of a password-protected link is sent in the X-Share-Password header. Every request counts as a download
+//the link is locked, after too many wrong passwords were sent for it
+//returns 500, if there is a problem with the server
+//returns 400, if the password is invalid
+//returns 429, if the link is locked
+//returns 404, if the link doesnt exist, has expired, its downloads are exhausted or the file was deleted
+//returns 206 + the requested part of the file, if the request contains a satisfiable Range
+//returns 200 + the shared file
+func (i *FileManagementEndpointImpl) DownloadSharedFile(c *gin.Context) {
+	link, fileInfo, err := i.FmDAO.GetSharedFile(auth.HashRefreshToken(c.Param("token")))
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+	common.SetAuditTarget(c, fileInfo.GroupName, fileInfo.ID)
+
+	if link.PasswordHash != "" {
+		if err = i.FmDAO.ReserveSharePasswordAttempt(link.ID, maxSharePasswordAttempts); err != nil {
+			common.SendErrorResponse(c, err)
+			return
+		}
+
+		password := c.GetHeader(common.SharePasswordHeader)
+		if err = bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+			common.SendErrorResponse(c, myerr.NewClientError("Invalid password for the share link"))
+			return
+		}
+
+		if err = i.FmDAO.ReleaseSharePasswordAttempt(link.ID); err != nil {
+			common.SendErrorResponse(c, err)
+			return
+		}
+	}
+
+	content, err := i.storage.Get(contentKey(fileInfo.GroupName, fileInfo.FileInfo))
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+	defer content.Close()
+
+	if err = i.FmDAO.UseShareLink(link.ID); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	serveFileContent(c, fileInfo.FileInfo, content)
+}
@@ -0,0 +1,346 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupRouterShareLinks(fmRest rest.FileManagementEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	r.GET("/public/share/:token", fmRest.DownloadSharedFile)
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.POST("/group/file/share", fmRest.CreateShareLink)
+		protected.GET("/group/file/share", fmRest.GetShareLinks)
+		protected.DELETE("/group/file/share", fmRest.RevokeShareLink)
+	}
+	return r
+}
+
+var _ = Describe("Share links", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		fmDAO    *dao_mocks.MockFmDAO
+		req      *http.Request
+		rootDir  string
+	)
+
+	const (
+		userID    = 1
+		groupName = "groupName"
+		fileID    = 3
+		linkID    = 5
+		token     = "share-token"
+		content   = "shared-content"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+		rootDir, _ = ioutil.TempDir("", "share-links")
+		fmRest := rest.NewFileManagementEndpointImpl(dao_mocks.NewMockUamDAO(controller), fmDAO, storage.NewLocalBackend(rootDir), dao.Quota{})
+
+		router = setupRouterShareLinks(fmRest, userID)
+		recorder = httptest.NewRecorder()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	Context("CreateShareLink", func() {
+		var reqBody common.ShareLinkPayload
+
+		BeforeEach(func() {
+			reqBody = common.ShareLinkPayload{
+				FileRequestPayload: common.FileRequestPayload{
+					GroupPayload: common.GroupPayload{GroupName: groupName},
+					FileID:       fileID,
+				},
+				Password:       "secret",
+				ExpiresInHours: 24,
+				MaxDownloads:   3,
+			}
+		})
+
+		JustBeforeEach(func() {
+			body, _ := json.Marshal(reqBody)
+			req, _ = http.NewRequest("POST", "/protected/group/file/share", strings.NewReader(string(body)))
+		})
+
+		When("the group isnt specified", func() {
+			BeforeEach(func() {
+				reqBody.GroupName = ""
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Groupname isnt specified")
+			})
+		})
+
+		When("the role of the user doesnt allow sharing the file", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					CreateShareLink(uint(userID), uint(fileID), groupName, gomock.Any(), gomock.Any(), gomock.Any(), uint(3)).
+					Return(uint(0), myerr.NewClientError("Your role [viewer] in the group doesnt allow changing files"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Your role [viewer] in the group doesnt allow changing files")
+			})
+		})
+
+		When("the link is created", func() {
+			var tokenHash, passwordHash string
+
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					CreateShareLink(uint(userID), uint(fileID), groupName, gomock.Any(), gomock.Any(), gomock.Any(), uint(3)).
+					DoAndReturn(func(_ uint, _ uint, _ string, hash string, password string, expiresAt *time.Time, _ uint) (uint, error) {
+						tokenHash, passwordHash = hash, password
+						Expect(*expiresAt).To(BeTemporally("~", time.Now().Add(24*time.Hour), time.Minute))
+						return linkID, nil
+					})
+			})
+
+			It("returns the token, whose hash is stored together with the hash of the password", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				var response common.ShareLinkResponse
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.ID).To(Equal(uint(linkID)))
+				Expect(auth.HashRefreshToken(response.Token)).To(Equal(tokenHash))
+				Expect(bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte("secret"))).To(Succeed())
+			})
+		})
+	})
+
+	Context("GetShareLinks", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/file/share?group_name=%s", groupName), nil)
+		})
+
+		When("the links are fetched", func() {
+			BeforeEach(func() {
+				link := dao.ShareLinkInfo{
+					ShareLink: models.ShareLink{ID: linkID, FileID: fileID, PasswordHash: "hash", MaxDownloads: 3, Downloads: 1},
+					FileName:  "report.pdf",
+					Version:   2,
+					Creator:   "user",
+				}
+				fmDAO.EXPECT().
+					GetShareLinks(uint(userID), groupName).
+					Return([]dao.ShareLinkInfo{link}, nil)
+			})
+
+			It("returns the links without their tokens", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				var response struct {
+					Links []common.ShareLinkInfo `json:"links"`
+				}
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.Links).To(HaveLen(1))
+				Expect(response.Links[0].FileName).To(Equal("report.pdf"))
+				Expect(response.Links[0].PasswordProtected).To(BeTrue())
+				Expect(response.Links[0].Downloads).To(Equal(uint(1)))
+			})
+		})
+	})
+
+	Context("RevokeShareLink", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("DELETE", "/protected/group/file/share", strings.NewReader(fmt.Sprintf(`{"group_name":"%s","link_id":%d}`, groupName, linkID)))
+		})
+
+		When("the link doesnt exist", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RemoveShareLink(uint(userID), uint(linkID), groupName).
+					Return(myerr.NewItemNotFoundError("Share link with id [5] doesnt exist"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Share link with id [5] doesnt exist")
+			})
+		})
+
+		When("the link is revoked", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RemoveShareLink(uint(userID), uint(linkID), groupName).
+					Return(nil)
+			})
+
+			It("returns success", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("DownloadSharedFile", func() {
+		var (
+			link     models.ShareLink
+			fileInfo dao.GroupFileInfo
+		)
+
+		BeforeEach(func() {
+			link = models.ShareLink{ID: linkID, FileID: fileID}
+			fileInfo = dao.GroupFileInfo{
+				FileInfo:  models.FileInfo{ID: fileID, Name: "report.pdf", Checksum: "checksum"},
+				GroupName: groupName,
+			}
+			storage.NewLocalBackend(rootDir).Put(storage.FileKey(groupName, fileID), strings.NewReader(content))
+
+			req, _ = http.NewRequest("GET", "/public/share/"+token, nil)
+		})
+
+		When("the link doesnt exist or has expired", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetSharedFile(auth.HashRefreshToken(token)).
+					Return(models.ShareLink{}, dao.GroupFileInfo{}, myerr.NewItemNotFoundError("Share link does not exist or has expired"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Share link does not exist or has expired")
+			})
+		})
+
+		When("the link is password-protected", func() {
+			BeforeEach(func() {
+				passwordHash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+				link.PasswordHash = string(passwordHash)
+				fmDAO.EXPECT().
+					GetSharedFile(auth.HashRefreshToken(token)).
+					Return(link, fileInfo, nil)
+			})
+
+			Context("and the link is locked after too many wrong passwords", func() {
+				BeforeEach(func() {
+					req.Header.Set(common.SharePasswordHeader, "secret")
+					fmDAO.EXPECT().
+						ReserveSharePasswordAttempt(uint(linkID), gomock.Any()).
+						Return(myerr.NewTooManyRequestsError("The share link is locked after too many wrong passwords", 0))
+					fmDAO.EXPECT().
+						UseShareLink(gomock.Any()).
+						Times(0)
+				})
+
+				It("returns too many requests", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusTooManyRequests, "The share link is locked after too many wrong passwords")
+					Expect(recorder.Header().Get("Retry-After")).To(BeEmpty())
+				})
+			})
+
+			Context("and the password is wrong", func() {
+				BeforeEach(func() {
+					req.Header.Set(common.SharePasswordHeader, "wrong")
+					fmDAO.EXPECT().
+						ReserveSharePasswordAttempt(uint(linkID), gomock.Any()).
+						Return(nil)
+					fmDAO.EXPECT().
+						ReleaseSharePasswordAttempt(gomock.Any()).
+						Times(0)
+					fmDAO.EXPECT().
+						UseShareLink(gomock.Any()).
+						Times(0)
+				})
+
+				It("returns bad request", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "Invalid password for the share link")
+				})
+			})
+
+			Context("and the password is correct", func() {
+				BeforeEach(func() {
+					req.Header.Set(common.SharePasswordHeader, "secret")
+					fmDAO.EXPECT().
+						ReserveSharePasswordAttempt(uint(linkID), gomock.Any()).
+						Return(nil)
+					fmDAO.EXPECT().
+						ReleaseSharePasswordAttempt(uint(linkID)).
+						Return(nil)
+					fmDAO.EXPECT().
+						UseShareLink(uint(linkID)).
+						Return(nil)
+				})
+
+				It("returns the file", func() {
+					router.ServeHTTP(recorder, req)
+					Expect(recorder.Code).To(Equal(http.StatusOK))
+					Expect(recorder.Body.String()).To(Equal(content))
+				})
+			})
+		})
+
+		When("the downloads are exhausted meanwhile", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetSharedFile(auth.HashRefreshToken(token)).
+					Return(link, fileInfo, nil)
+				fmDAO.EXPECT().
+					UseShareLink(uint(linkID)).
+					Return(myerr.NewItemNotFoundError("Share link does not exist or has expired"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Share link does not exist or has expired")
+			})
+		})
+
+		When("the link is valid", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetSharedFile(auth.HashRefreshToken(token)).
+					Return(link, fileInfo, nil)
+				fmDAO.EXPECT().
+					UseShareLink(uint(linkID)).
+					Return(nil)
+			})
+
+			It("returns the file with its ETag", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Header().Get("ETag")).To(Equal(`"checksum"`))
+				Expect(recorder.Body.String()).To(Equal(content))
+			})
+		})
+	})
+})
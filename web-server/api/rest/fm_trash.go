@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+//GetTrashedFiles - handler for fetching information about the files in the trash of a group
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user isnt a member of the group
+//returns 200 + info about the trashed files, starting from the most recently deleted one
+func (i *FileManagementEndpointImpl) GetTrashedFiles(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groupName := c.Query("group_name")
+	if groupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	fileInfos, err := i.FmDAO.GetTrashedFiles(userID, groupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	fileResponses := make([]common.TrashedFileInfoResponse, 0, len(fileInfos))
+	for _, fileInfo := range fileInfos {
+		fileResponses = append(fileResponses, common.TrashedFileInfoResponse{
+			FileInfoResponse: newFileInfoResponse(fileInfo),
+			DeletedAt:        fileInfo.DeletedAt.Time,
+			DeletedBy:        fileInfo.DeletedBy,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": http.StatusOK,
+		"files":  fileResponses,
+	})
+}
+
+//RestoreTrashedFile - handler for moving a file, together with all its versions, from the trash back to its folder
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid, the name is taken or the user doesnt have enough permissions
+//returns 404, if the file isnt in the trash
+//returns 200, if the file is restored
+func (i *FileManagementEndpointImpl) RestoreTrashedFile(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.FileRequestPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	if err = i.FmDAO.RestoreTrashedFile(userID, rq.FileID, rq.GroupName); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//DeleteTrashedFile - handler for permanently deleting a file, together with all its versions, from the trash
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user doesnt have enough permissions
+//returns 404, if the file isnt in the trash
+//returns 200, if the file is deleted
+func (i *FileManagementEndpointImpl) DeleteTrashedFile(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.FileRequestPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	versions, err := i.FmDAO.RemoveTrashedFile(userID, rq.FileID, rq.GroupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	//shared blobs are erased asynchronously, once the last file referencing them is removed
+	for _, version := range versions {
+		if version.Deduplicated {
+			continue
+		}
+		if err := i.storage.Delete(storage.FileKey(rq.GroupName, version.ID)); err != nil {
+			log.Printf("Couldnt delete the content of file [%d] in group [%s]. Reason: %v\n", version.ID, rq.GroupName, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
@@ -0,0 +1,205 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+func setupRouterTrash(fmRest rest.FileManagementEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.GET("/group/trash", fmRest.GetTrashedFiles)
+		protected.POST("/group/trash/restoration", fmRest.RestoreTrashedFile)
+		protected.DELETE("/group/trash/deletion", fmRest.DeleteTrashedFile)
+	}
+	return r
+}
+
+var _ = Describe("Trash", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		fmDAO    *dao_mocks.MockFmDAO
+		uamDAO   *dao_mocks.MockUamDAO
+		req      *http.Request
+		rootDir  string
+		backend  storage.Backend
+	)
+
+	const (
+		userID    = 1
+		groupName = "groupName"
+		fileID    = 3
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+		rootDir, _ = ioutil.TempDir("", "trash")
+		backend = storage.NewLocalBackend(rootDir)
+		fmRest := rest.NewFileManagementEndpointImpl(uamDAO, fmDAO, backend, dao.Quota{})
+
+		router = setupRouterTrash(fmRest, userID)
+		recorder = httptest.NewRecorder()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	Context("GetTrashedFiles", func() {
+		When("the user isnt a member of the group", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetTrashedFiles(uint(userID), groupName).
+					Return(nil, myerr.NewClientError("You arent a member of the group."))
+
+				req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/trash?group_name=%s", groupName), nil)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "You arent a member of the group.")
+			})
+		})
+
+		When("the trash is fetched", func() {
+			deletedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetTrashedFiles(uint(userID), groupName).
+					Return([]models.FileInfo{{
+						ID:        fileID,
+						Name:      "q3.pdf",
+						Version:   2,
+						DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true},
+						DeletedBy: userID,
+					}}, nil)
+
+				req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/trash?group_name=%s", groupName), nil)
+			})
+
+			It("returns the trashed files together with the time of their deletion", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				body := struct {
+					Files []common.TrashedFileInfoResponse `json:"files"`
+				}{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.Files).To(HaveLen(1))
+				Expect(body.Files[0].ID).To(Equal(uint(fileID)))
+				Expect(body.Files[0].DeletedAt.Equal(deletedAt)).To(BeTrue())
+				Expect(body.Files[0].DeletedBy).To(Equal(uint(userID)))
+			})
+		})
+	})
+
+	Context("RestoreTrashedFile", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("POST", "/protected/group/trash/restoration",
+				strings.NewReader(fmt.Sprintf(`{"group_name":"%s","file_id":%d}`, groupName, fileID)))
+		})
+
+		When("a file with the same name already exists", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RestoreTrashedFile(uint(userID), uint(fileID), groupName).
+					Return(myerr.NewClientError("A file with name [q3.pdf] already exists in the folder"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "already exists")
+			})
+		})
+
+		When("the file is restored", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RestoreTrashedFile(uint(userID), uint(fileID), groupName).
+					Return(nil)
+			})
+
+			It("returns status ok", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("DeleteTrashedFile", func() {
+		BeforeEach(func() {
+			backend.Put(storage.FileKey(groupName, fileID), strings.NewReader("content"))
+			backend.Put(storage.BlobKey("abcdef"), strings.NewReader("content"))
+
+			req, _ = http.NewRequest("DELETE", "/protected/group/trash/deletion",
+				strings.NewReader(fmt.Sprintf(`{"group_name":"%s","file_id":%d}`, groupName, fileID)))
+		})
+
+		When("the file isnt in the trash", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RemoveTrashedFile(uint(userID), uint(fileID), groupName).
+					Return(nil, myerr.NewItemNotFoundError("File does not exist in the trash"))
+			})
+
+			It("returns not found and keeps the content", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "File does not exist in the trash")
+
+				_, err := os.Stat(path.Join(rootDir, storage.FileKey(groupName, fileID)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("the file is deleted", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					RemoveTrashedFile(uint(userID), uint(fileID), groupName).
+					Return([]models.FileInfo{
+						{ID: fileID},
+						{ID: fileID + 1, Checksum: "abcdef", Deduplicated: true},
+					}, nil)
+			})
+
+			It("deletes the contents kept under the group, but leaves the blobs to the blob eraser", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				_, err := os.Stat(path.Join(rootDir, storage.FileKey(groupName, fileID)))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+
+				_, err = os.Stat(path.Join(rootDir, storage.BlobKey("abcdef")))
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+})
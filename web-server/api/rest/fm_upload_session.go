@@ -0,0 +1,370 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+//MaxChunkSize - the biggest chunk (in bytes), which can be sent in a single request of an upload session
+const MaxChunkSize = 64 << 20
+
+//CreateUploadSession - handler for starting an upload of a file, which will be sent in chunks
+//the file is placed in the root of the group, unless a folder is specified
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 404, if the folder doesnt exist
+//returns 413, if the file exceeds the storage quota of the user or the group
+//returns 201 + the id of the session, if the session is created
+func (i *FileManagementEndpointImpl) CreateUploadSession(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.UploadSessionPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	if rq.GroupName == "" || rq.FileName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname or filename isnt specified"))
+		return
+	} else if rq.Size < 0 {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid file size"))
+		return
+	}
+
+	folder, err := i.FmDAO.GetFolder(userID, rq.GroupName, rq.Folder)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	sessionID, err := i.FmDAO.CreateUploadSession(userID, rq.FileName, rq.Size, folder.ID, rq.GroupName, i.quota)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, common.UploadSessionResponse{
+		Status:         http.StatusCreated,
+		SessionID:      sessionID,
+		FileName:       rq.FileName,
+		Size:           rq.Size,
+		ReceivedRanges: make([]common.ByteRange, 0),
+	})
+}
+
+//GetUploadSession - handler for fetching the ranges of the file, which were already received
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 404, if the session doesnt exist
+//returns 200 + the received ranges
+func (i *FileManagementEndpointImpl) GetUploadSession(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	_, session, err := i.getUploadSessionFromQuery(c, userID)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	chunks, err := i.FmDAO.GetUploadChunks(session.ID)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.UploadSessionResponse{
+		Status:         http.StatusOK,
+		SessionID:      session.ID,
+		FileName:       session.FileName,
+		Size:           session.Size,
+		ReceivedRanges: mergeChunks(chunks),
+	})
+}
+
+//UploadChunk - handler for receiving a part of a file, starting at a given offset. The body of the request is the raw content of the chunk
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 404, if the session doesnt exist
+//returns 200, if the chunk is received
+func (i *FileManagementEndpointImpl) UploadChunk(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groupName, session, err := i.getUploadSessionFromQuery(c, userID)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.Query("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid offset"))
+		return
+	}
+
+	size := c.Request.ContentLength
+	if size <= 0 || size > MaxChunkSize {
+		common.SendErrorResponse(c, myerr.NewClientError(fmt.Sprintf("The chunk size should be between 1 and %d bytes", MaxChunkSize)))
+		return
+	} else if offset+size > session.Size {
+		common.SendErrorResponse(c, myerr.NewClientError("The chunk exceeds the size of the file"))
+		return
+	}
+
+	//the incomplete chunk fails the write, so it doesnt replace the same chunk, if it was already received
+	key := storage.ChunkKey(groupName, session.ID, offset, size)
+	body := &exactReader{reader: io.LimitReader(c.Request.Body, size), remaining: size}
+	if err = i.storage.Put(key, body); err != nil && body.remaining > 0 {
+		common.SendErrorResponse(c, myerr.NewClientError("The chunk is incomplete"))
+		return
+	} else if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Couldnt save the chunk"))
+		return
+	}
+
+	if err = i.FmDAO.AddUploadChunk(session.ID, offset, size); err != nil {
+		//only the chunk, rejected for overlapping other chunks, is discarded. After a server error it might have been recorded
+		if _, ok := err.(*myerr.ClientError); ok {
+			i.storage.Delete(key)
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//FinalizeUpload - handler for assembling the received chunks into a file of the group
+//the file is rejected, if the optional checksum header doesnt match the assembled content
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or there are missing chunks
+//returns 404, if the session doesnt exist
+//returns 201 + the id of the file, if the file is created
+func (i *FileManagementEndpointImpl) FinalizeUpload(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.UploadSessionRequestPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	session, err := i.FmDAO.GetUploadSession(userID, rq.SessionID, rq.GroupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	chunks, err := i.FmDAO.GetUploadChunks(session.ID)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	received := mergeChunks(chunks)
+	if session.Size != 0 && (len(received) != 1 || received[0].Start != 0 || received[0].End != session.Size) {
+		common.SendErrorResponse(c, myerr.NewClientError("The upload is incomplete"))
+		return
+	}
+
+	fileID, err := i.FmDAO.AddFileInfo(userID, session.FileName, session.FolderID, rq.GroupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+	common.SetAuditTarget(c, rq.GroupName, fileID)
+
+	content := &chunksReader{
+		backend: i.storage,
+		keys:    chunkKeys(rq.GroupName, session.ID, chunks),
+	}
+	err = i.storeContent(fileID, session.ID, rq.GroupName, content, c.GetHeader(common.ChecksumHeader), func() {
+		i.FmDAO.RemoveFileInfo(userID, fileID, rq.GroupName)
+	})
+	content.Close()
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	i.removeUploadSession(rq.GroupName, session.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  http.StatusCreated,
+		"file_id": fileID,
+	})
+}
+
+//AbortUpload - handler for cancelling an upload session and discarding the received chunks
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 404, if the session doesnt exist
+//returns 200, if the session is cancelled
+func (i *FileManagementEndpointImpl) AbortUpload(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.UploadSessionRequestPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	session, err := i.FmDAO.GetUploadSession(userID, rq.SessionID, rq.GroupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	if err = i.removeUploadSession(rq.GroupName, session.ID); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+func (i *FileManagementEndpointImpl) getUploadSessionFromQuery(c *gin.Context, userID uint) (string, models.UploadSession, error) {
+	groupName := c.Query("group_name")
+	if groupName == "" {
+		return "", models.UploadSession{}, myerr.NewClientError("Groupname isnt specified")
+	}
+
+	sessionID, err := strconv.ParseUint(c.Query("session_id"), 10, 32)
+	if err != nil {
+		return "", models.UploadSession{}, myerr.NewClientError("Invalid format of session id")
+	}
+
+	session, err := i.FmDAO.GetUploadSession(userID, uint(sessionID), groupName)
+	return groupName, session, err
+}
+
+func (i *FileManagementEndpointImpl) removeUploadSession(groupName string, sessionID uint) error {
+	if err := i.storage.DeletePrefix(storage.UploadPrefix(groupName, sessionID)); err != nil {
+		log.Printf("Couldnt delete the chunks of upload session [%d]. Reason: %v\n", sessionID, err)
+	}
+	return i.FmDAO.RemoveUploadSession(sessionID)
+}
+
+//mergeChunks - merges the adjacent chunks, ordered by offset, into continuous ranges
+func mergeChunks(chunks []models.UploadChunk) []common.ByteRange {
+	ranges := make([]common.ByteRange, 0, len(chunks))
+	for _, chunk := range chunks {
+		last := len(ranges) - 1
+		if last >= 0 && ranges[last].End == chunk.Offset {
+			ranges[last].End += chunk.Size
+			continue
+		}
+		ranges = append(ranges, common.ByteRange{Start: chunk.Offset, End: chunk.Offset + chunk.Size})
+	}
+	return ranges
+}
+
+func chunkKeys(groupName string, sessionID uint, chunks []models.UploadChunk) []string {
+	keys := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		keys = append(keys, storage.ChunkKey(groupName, sessionID, chunk.Offset, chunk.Size))
+	}
+	return keys
+}
+
+//countingReader - counts the bytes, which were read from the underlying reader
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+//exactReader - fails with io.ErrUnexpectedEOF, if the underlying reader ends before the expected number of bytes
+type exactReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *exactReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if err == io.EOF && r.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+//chunksReader - reads the stored chunks one after another, opening only one of them at a time
+type chunksReader struct {
+	backend storage.Backend
+	keys    []string
+	current storage.Object
+}
+
+func (r *chunksReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+
+			object, err := r.backend.Get(r.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current = object
+			r.keys = r.keys[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *chunksReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
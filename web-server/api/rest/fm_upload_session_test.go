@@ -0,0 +1,367 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterUploadSession(fmRest rest.FileManagementEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.POST("/group/file/upload/session", fmRest.CreateUploadSession)
+		protected.GET("/group/file/upload/session", fmRest.GetUploadSession)
+		protected.PUT("/group/file/upload/session/chunk", fmRest.UploadChunk)
+		protected.POST("/group/file/upload/session/finalization", fmRest.FinalizeUpload)
+		protected.DELETE("/group/file/upload/session", fmRest.AbortUpload)
+	}
+	return r
+}
+
+var _ = Describe("Upload sessions", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		fmDAO    *dao_mocks.MockFmDAO
+		uamDAO   *dao_mocks.MockUamDAO
+		req      *http.Request
+		rootDir  string
+		session  models.UploadSession
+	)
+
+	const (
+		userID    = 1
+		groupName = "groupName"
+		groupID   = 2
+		fileName  = "test"
+		fileID    = 3
+		sessionID = 4
+		checksum  = "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+		rootDir, _ = ioutil.TempDir("", "upload-session")
+		fmRest := rest.NewFileManagementEndpointImpl(uamDAO, fmDAO, storage.NewLocalBackend(rootDir), dao.Quota{})
+
+		router = setupRouterUploadSession(fmRest, userID)
+		recorder = httptest.NewRecorder()
+
+		session = models.UploadSession{
+			ID:       sessionID,
+			FileName: fileName,
+			Size:     10,
+			OwnerID:  userID,
+			GroupID:  groupID,
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	Context("CreateUploadSession", func() {
+		When("request body is invalid", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					CreateUploadSession(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("POST", "/protected/group/file/upload/session", strings.NewReader("test"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid json body")
+			})
+		})
+
+		When("the user isnt a member of the group", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetFolder(uint(userID), groupName, "").
+						Return(models.Folder{GroupID: groupID}, nil),
+
+					fmDAO.EXPECT().
+						CreateUploadSession(uint(userID), fileName, int64(10), uint(0), groupName, dao.Quota{}).
+						Return(uint(0), myerr.NewClientError("test-error")),
+				)
+
+				req, _ = http.NewRequest("POST", "/protected/group/file/upload/session",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","file_name":"%s","size":10}`, groupName, fileName)))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "test-error")
+			})
+		})
+
+		When("the session is created", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetFolder(uint(userID), groupName, "").
+						Return(models.Folder{GroupID: groupID}, nil),
+
+					fmDAO.EXPECT().
+						CreateUploadSession(uint(userID), fileName, int64(10), uint(0), groupName, dao.Quota{}).
+						Return(uint(sessionID), nil),
+				)
+
+				req, _ = http.NewRequest("POST", "/protected/group/file/upload/session",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","file_name":"%s","size":10}`, groupName, fileName)))
+			})
+
+			It("returns the id of the session", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				body := common.UploadSessionResponse{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.SessionID).To(Equal(uint(sessionID)))
+			})
+		})
+	})
+
+	Context("UploadChunk", func() {
+		var url string
+
+		BeforeEach(func() {
+			url = fmt.Sprintf("/protected/group/file/upload/session/chunk?group_name=%s&session_id=%d", groupName, sessionID)
+		})
+
+		When("the session doesnt exist", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetUploadSession(uint(userID), uint(sessionID), groupName).
+					Return(models.UploadSession{}, myerr.NewItemNotFoundError("Upload session does not exist"))
+
+				req, _ = http.NewRequest("PUT", url+"&offset=0", strings.NewReader("01234"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Upload session does not exist")
+			})
+		})
+
+		When("the chunk exceeds the size of the file", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetUploadSession(uint(userID), uint(sessionID), groupName).
+					Return(session, nil)
+
+				fmDAO.EXPECT().
+					AddUploadChunk(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("PUT", url+"&offset=8", strings.NewReader("01234"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "The chunk exceeds the size of the file")
+			})
+		})
+
+		When("the chunk is valid", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetUploadSession(uint(userID), uint(sessionID), groupName).
+						Return(session, nil),
+
+					fmDAO.EXPECT().
+						AddUploadChunk(uint(sessionID), int64(5), int64(5)).
+						Return(nil),
+				)
+
+				req, _ = http.NewRequest("PUT", url+"&offset=5", strings.NewReader("56789"))
+			})
+
+			It("stores the chunk under the group directory", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				data, err := ioutil.ReadFile(path.Join(rootDir, storage.ChunkKey(groupName, sessionID, 5, 5)))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(Equal("56789"))
+			})
+		})
+
+		When("the chunk overlaps already received data", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetUploadSession(uint(userID), uint(sessionID), groupName).
+						Return(session, nil),
+
+					fmDAO.EXPECT().
+						AddUploadChunk(uint(sessionID), int64(3), int64(5)).
+						Return(myerr.NewClientError("The chunk overlaps with already received data")),
+				)
+
+				req, _ = http.NewRequest("PUT", url+"&offset=3", strings.NewReader("34567"))
+			})
+
+			It("returns bad request and discards the chunk", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "overlaps")
+
+				_, err := os.Stat(path.Join(rootDir, storage.ChunkKey(groupName, sessionID, 3, 5)))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+
+		When("the chunk was already received", func() {
+			BeforeEach(func() {
+				storage.NewLocalBackend(rootDir).Put(storage.ChunkKey(groupName, sessionID, 5, 5), strings.NewReader("56789"))
+				fmDAO.EXPECT().
+					GetUploadSession(uint(userID), uint(sessionID), groupName).
+					Return(session, nil)
+			})
+
+			Context("and the repeated chunk is incomplete", func() {
+				BeforeEach(func() {
+					fmDAO.EXPECT().
+						AddUploadChunk(gomock.Any(), gomock.Any(), gomock.Any()).
+						Times(0)
+
+					req, _ = http.NewRequest("PUT", url+"&offset=5", strings.NewReader("567"))
+					req.ContentLength = 5
+				})
+
+				It("returns bad request and keeps the received chunk", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "The chunk is incomplete")
+
+					data, err := ioutil.ReadFile(path.Join(rootDir, storage.ChunkKey(groupName, sessionID, 5, 5)))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(data)).To(Equal("56789"))
+				})
+			})
+
+			Context("and recording the repeated chunk fails", func() {
+				BeforeEach(func() {
+					fmDAO.EXPECT().
+						AddUploadChunk(uint(sessionID), int64(5), int64(5)).
+						Return(myerr.NewServerError("test-error"))
+
+					req, _ = http.NewRequest("PUT", url+"&offset=5", strings.NewReader("56789"))
+				})
+
+				It("returns internal server error and keeps the received chunk", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server")
+
+					_, err := os.Stat(path.Join(rootDir, storage.ChunkKey(groupName, sessionID, 5, 5)))
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	Context("FinalizeUpload", func() {
+		BeforeEach(func() {
+			backend := storage.NewLocalBackend(rootDir)
+			backend.Put(storage.ChunkKey(groupName, sessionID, 0, 5), strings.NewReader("01234"))
+			backend.Put(storage.ChunkKey(groupName, sessionID, 5, 5), strings.NewReader("56789"))
+
+			body, _ := json.Marshal(common.UploadSessionRequestPayload{
+				GroupPayload: common.GroupPayload{GroupName: groupName},
+				SessionID:    sessionID,
+			})
+			req, _ = http.NewRequest("POST", "/protected/group/file/upload/session/finalization", bytes.NewReader(body))
+		})
+
+		When("there are missing chunks", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetUploadSession(uint(userID), uint(sessionID), groupName).
+						Return(session, nil),
+
+					fmDAO.EXPECT().
+						GetUploadChunks(uint(sessionID)).
+						Return([]models.UploadChunk{{Offset: 5, Size: 5}}, nil),
+				)
+
+				fmDAO.EXPECT().
+					AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "The upload is incomplete")
+			})
+		})
+
+		When("all chunks are received", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetUploadSession(uint(userID), uint(sessionID), groupName).
+						Return(session, nil),
+
+					fmDAO.EXPECT().
+						GetUploadChunks(uint(sessionID)).
+						Return([]models.UploadChunk{{Offset: 0, Size: 5}, {Offset: 5, Size: 5}}, nil),
+
+					fmDAO.EXPECT().
+						AddFileInfo(uint(userID), fileName, uint(0), groupName).
+						Return(uint(fileID), nil),
+
+					fmDAO.EXPECT().
+						AttachFileBlob(uint(fileID), checksum, int64(10), dao.Quota{}, uint(sessionID), gomock.Any()).
+						DoAndReturn(func(_ uint, _ string, _ int64, _ dao.Quota, _ uint, storeContent func(bool) error) error {
+							return storeContent(true)
+						}),
+
+					fmDAO.EXPECT().
+						RemoveUploadSession(uint(sessionID)).
+						Return(nil),
+				)
+			})
+
+			It("assembles the file and removes the chunks", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				data, err := ioutil.ReadFile(path.Join(rootDir, storage.BlobKey(checksum)))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(Equal("0123456789"))
+
+				_, err = os.Stat(path.Join(rootDir, storage.UploadPrefix(groupName, sessionID)))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+	})
+})
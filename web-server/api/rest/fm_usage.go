@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+//GetUserUsage - handler for fetching the storage, used by the files of the current user. Quota 0 means unlimited
+//returns 500, if there is a problem with the server
+//returns 200 + the used storage and the quota
+func (i *FileManagementEndpointImpl) GetUserUsage(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	usage, err := i.FmDAO.GetUserUsage(userID)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newStorageUsageResponse(usage, i.quota.UserBytes))
+}
+
+//GetGroupUsage - handler for fetching the storage, used by the files of a group. Quota 0 means unlimited
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user isnt a member of the group
+//returns 200 + the used storage and the quota
+func (i *FileManagementEndpointImpl) GetGroupUsage(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groupName := c.Query("group_name")
+	if groupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	usage, err := i.FmDAO.GetGroupUsage(userID, groupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newStorageUsageResponse(usage, i.quota.GroupBytes))
+}
+
+func newStorageUsageResponse(usage dao.StorageUsage, quotaBytes int64) common.StorageUsageResponse {
+	return common.StorageUsageResponse{
+		Status:     http.StatusOK,
+		Files:      usage.Files,
+		UsedBytes:  usage.UsedBytes,
+		QuotaBytes: quotaBytes,
+	}
+}
@@ -0,0 +1,208 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterUsage(fmRest rest.FileManagementEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.GET("/user/usage", fmRest.GetUserUsage)
+		protected.GET("/group/usage", fmRest.GetGroupUsage)
+		protected.POST("/group/file/upload", fmRest.UploadFile)
+		protected.POST("/group/file/upload/session", fmRest.CreateUploadSession)
+	}
+	return r
+}
+
+var _ = Describe("Storage quotas", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		fmDAO    *dao_mocks.MockFmDAO
+		uamDAO   *dao_mocks.MockUamDAO
+		req      *http.Request
+		quota    dao.Quota
+	)
+
+	const (
+		userID    = 1
+		groupName = "groupName"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+		quota = dao.Quota{UserBytes: 100, GroupBytes: 1000}
+		fmRest := rest.NewFileManagementEndpointImpl(uamDAO, fmDAO, storage.NewLocalBackend("."), quota)
+
+		router = setupRouterUsage(fmRest, userID)
+		recorder = httptest.NewRecorder()
+	})
+
+	Context("GetUserUsage", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				GetUserUsage(uint(userID)).
+				Return(dao.StorageUsage{UsedBytes: 40, Files: 2}, nil)
+
+			req, _ = http.NewRequest("GET", "/protected/user/usage", nil)
+		})
+
+		It("returns the used storage together with the quota of the user", func() {
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			body := common.StorageUsageResponse{}
+			json.Unmarshal(recorder.Body.Bytes(), &body)
+			Expect(body.UsedBytes).To(Equal(int64(40)))
+			Expect(body.Files).To(Equal(int64(2)))
+			Expect(body.QuotaBytes).To(Equal(quota.UserBytes))
+		})
+	})
+
+	Context("GetGroupUsage", func() {
+		When("the user isnt a member of the group", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetGroupUsage(uint(userID), groupName).
+					Return(dao.StorageUsage{}, myerr.NewClientError("You arent a member of the group."))
+
+				req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/usage?group_name=%s", groupName), nil)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "You arent a member of the group.")
+			})
+		})
+
+		When("the user is a member of the group", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetGroupUsage(uint(userID), groupName).
+					Return(dao.StorageUsage{UsedBytes: 500, Files: 7}, nil)
+
+				req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/usage?group_name=%s", groupName), nil)
+			})
+
+			It("returns the used storage together with the quota of the group", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				body := common.StorageUsageResponse{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.UsedBytes).To(Equal(int64(500)))
+				Expect(body.QuotaBytes).To(Equal(quota.GroupBytes))
+			})
+		})
+	})
+
+	Context("UploadFile", func() {
+		const groupID = 2
+
+		When("the user isnt a member of the group", func() {
+			BeforeEach(func() {
+				body := strings.Repeat("a", 200)
+				gomock.InOrder(
+					uamDAO.EXPECT().
+						GetGroup(groupName).
+						Return(models.Group{ID: groupID}, nil),
+
+					uamDAO.EXPECT().
+						MemberExists(uint(userID), uint(groupID)).
+						Return(false, nil),
+				)
+
+				fmDAO.EXPECT().
+					CheckQuota(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("POST", fmt.Sprintf("/protected/group/file/upload?group_name=%s", groupName), strings.NewReader(body))
+				req.Header.Add("Content-Type", "multipart/form-data; boundary=test")
+			})
+
+			It("rejects the upload without revealing the quota", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid user input")
+			})
+		})
+
+		When("the declared size of the request exceeds the quota", func() {
+			BeforeEach(func() {
+				body := strings.Repeat("a", 200)
+				gomock.InOrder(
+					uamDAO.EXPECT().
+						GetGroup(groupName).
+						Return(models.Group{ID: groupID}, nil),
+
+					uamDAO.EXPECT().
+						MemberExists(uint(userID), uint(groupID)).
+						Return(true, nil),
+
+					fmDAO.EXPECT().
+						CheckQuota(uint(userID), groupName, int64(len(body)), quota).
+						Return(myerr.NewQuotaExceededError("The file exceeds your storage quota. Used 0 of 100 bytes")),
+				)
+
+				fmDAO.EXPECT().
+					AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("POST", fmt.Sprintf("/protected/group/file/upload?group_name=%s", groupName), strings.NewReader(body))
+				req.Header.Add("Content-Type", "multipart/form-data; boundary=test")
+			})
+
+			It("rejects the upload before receiving the file", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusRequestEntityTooLarge, "The file exceeds your storage quota")
+			})
+		})
+	})
+
+	Context("CreateUploadSession", func() {
+		When("the size of the file exceeds the quota", func() {
+			BeforeEach(func() {
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetFolder(uint(userID), groupName, "").
+						Return(models.Folder{}, nil),
+
+					fmDAO.EXPECT().
+						CreateUploadSession(uint(userID), "test", int64(5000), uint(0), groupName, quota).
+						Return(uint(0), myerr.NewQuotaExceededError("The file exceeds the storage quota of the group. Used 500 of 1000 bytes")),
+				)
+
+				req, _ = http.NewRequest("POST", "/protected/group/file/upload/session",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","file_name":"test","size":5000}`, groupName)))
+			})
+
+			It("returns request entity too large", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusRequestEntityTooLarge, "storage quota of the group")
+			})
+		})
+	})
+})
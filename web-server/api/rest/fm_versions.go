@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+//GetFileVersions - handler for fetching information about all versions of a file, starting from the latest one
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 404, if the file doesnt exist
+//returns 200 + info about the versions
+func (i *FileManagementEndpointImpl) GetFileVersions(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groupName := c.Query("group_name")
+	if groupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	fileID, err := strconv.ParseUint(c.Query("file_id"), 10, 32)
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid format of file id"))
+		return
+	}
+
+	versions, err := i.FmDAO.GetFileVersions(userID, uint(fileID), groupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	versionResponses := make([]common.FileInfoResponse, 0, len(versions))
+	for _, version := range versions {
+		versionResponses = append(versionResponses, newFileInfoResponse(version))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   http.StatusOK,
+		"versions": versionResponses,
+	})
+}
+
+//RestoreFileVersion - handler for making an old version of a file the latest one. The restored content becomes a new version
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid
+//returns 404, if the file or the version doesnt exist
+//returns 201 + the id of the new version, if the version is restored
+func (i *FileManagementEndpointImpl) RestoreFileVersion(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.FileVersionPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Version == 0 {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname or version isnt specified"))
+		return
+	}
+
+	source, err := i.FmDAO.GetFileVersion(userID, rq.FileID, rq.Version, rq.GroupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	fileID, err := i.FmDAO.AddFileInfo(userID, source.Name, source.FolderID, rq.GroupName)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	if err = i.copyFileContent(userID, fileID, rq.GroupName, source); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  http.StatusCreated,
+		"file_id": fileID,
+	})
+}
+
+//SetMaxFileVersions - handler for changing how many versions of each file are kept in a group. The excess versions are removed in the background
+//returns 500, if there is a problem with the server
+//returns 400, if the user input is invalid or the user isnt the owner of the group
+//returns 200, if the limit is changed
+func (i *FileManagementEndpointImpl) SetMaxFileVersions(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.MaxFileVersionsPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	if err = i.FmDAO.SetMaxFileVersions(userID, rq.GroupName, rq.MaxVersions); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//copyFileContent - gives a newly added file the content of another file of the group
+//contents in the blob store are only referenced once again, while the older ones are copied
+func (i *FileManagementEndpointImpl) copyFileContent(userID uint, fileID uint, groupName string, source models.FileInfo) error {
+	if !source.Deduplicated {
+		content, err := i.storage.Get(contentKey(groupName, source))
+		if err != nil {
+			i.FmDAO.RemoveFileInfo(userID, fileID, groupName)
+			return err
+		}
+		defer content.Close()
+
+		return i.storeFileContent(userID, fileID, groupName, content, source.Checksum)
+	}
+
+	err := i.FmDAO.AttachFileBlob(fileID, source.Checksum, source.Size, i.quota, 0, func(isNewBlob bool) error {
+		if isNewBlob {
+			return myerr.NewItemNotFoundError(fmt.Sprintf("The content of file [%d] no longer exists", source.ID))
+		}
+		return nil
+	})
+	if err != nil {
+		i.FmDAO.RemoveFileInfo(userID, fileID, groupName)
+		return err
+	}
+	return nil
+}
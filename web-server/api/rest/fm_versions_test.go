@@ -0,0 +1,255 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterFileVersions(fmRest rest.FileManagementEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.GET("/group/file/versions", fmRest.GetFileVersions)
+		protected.POST("/group/file/versions/restoration", fmRest.RestoreFileVersion)
+		protected.PUT("/group/file/versions/limit", fmRest.SetMaxFileVersions)
+	}
+	return r
+}
+
+var _ = Describe("File versions", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		fmDAO    *dao_mocks.MockFmDAO
+		uamDAO   *dao_mocks.MockUamDAO
+		req      *http.Request
+		rootDir  string
+	)
+
+	const (
+		userID    = 1
+		groupName = "groupName"
+		fileName  = "test"
+		fileID    = 3
+		newFileID = 4
+		checksum  = "84d89877f0d4041efb6bf91a16f0248f2fd573e6af05c19f96bedb9f882f7882"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+		rootDir, _ = ioutil.TempDir("", "file-versions")
+		fmRest := rest.NewFileManagementEndpointImpl(uamDAO, fmDAO, storage.NewLocalBackend(rootDir), dao.Quota{})
+
+		router = setupRouterFileVersions(fmRest, userID)
+		recorder = httptest.NewRecorder()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	Context("GetFileVersions", func() {
+		When("file id is invalid", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetFileVersions(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/file/versions?group_name=%s&file_id=abc", groupName), nil)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid format of file id")
+			})
+		})
+
+		When("the file exists", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetFileVersions(uint(userID), uint(fileID), groupName).
+					Return([]models.FileInfo{
+						{ID: newFileID, Name: fileName, Version: 2},
+						{ID: fileID, Name: fileName, Version: 1},
+					}, nil)
+
+				req, _ = http.NewRequest("GET", fmt.Sprintf("/protected/group/file/versions?group_name=%s&file_id=%d", groupName, fileID), nil)
+			})
+
+			It("returns all versions, starting from the latest one", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				body := struct {
+					Versions []common.FileInfoResponse `json:"versions"`
+				}{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.Versions).To(HaveLen(2))
+				Expect(body.Versions[0].ID).To(Equal(uint(newFileID)))
+				Expect(body.Versions[0].Version).To(Equal(uint(2)))
+			})
+		})
+	})
+
+	Context("RestoreFileVersion", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("POST", "/protected/group/file/versions/restoration",
+				strings.NewReader(fmt.Sprintf(`{"group_name":"%s","file_id":%d,"version":1}`, groupName, fileID)))
+		})
+
+		When("version isnt specified", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetFileVersion(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("POST", "/protected/group/file/versions/restoration",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","file_id":%d}`, groupName, fileID)))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Groupname or version isnt specified")
+			})
+		})
+
+		When("the version doesnt exist", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					GetFileVersion(uint(userID), uint(fileID), uint(1), groupName).
+					Return(models.FileInfo{}, myerr.NewItemNotFoundError("File version does not exist"))
+
+				fmDAO.EXPECT().
+					AddFileInfo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "File version does not exist")
+			})
+		})
+
+		When("the content of the version is kept in a blob", func() {
+			BeforeEach(func() {
+				source := models.FileInfo{ID: fileID, Name: fileName, Version: 1, Checksum: checksum, Size: 10, Deduplicated: true}
+
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetFileVersion(uint(userID), uint(fileID), uint(1), groupName).
+						Return(source, nil),
+
+					fmDAO.EXPECT().
+						AddFileInfo(uint(userID), fileName, uint(0), groupName).
+						Return(uint(newFileID), nil),
+
+					fmDAO.EXPECT().
+						AttachFileBlob(uint(newFileID), checksum, int64(10), dao.Quota{}, uint(0), gomock.Any()).
+						DoAndReturn(func(_ uint, _ string, _ int64, _ dao.Quota, _ uint, storeContent func(bool) error) error {
+							return storeContent(false)
+						}),
+				)
+			})
+
+			It("references the same blob from the new version", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				body := struct {
+					FileID uint `json:"file_id"`
+				}{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.FileID).To(Equal(uint(newFileID)))
+			})
+		})
+
+		When("the content of the version is kept under the group", func() {
+			BeforeEach(func() {
+				source := models.FileInfo{ID: fileID, Name: fileName, Version: 1, Checksum: checksum, Size: 10}
+				storage.NewLocalBackend(rootDir).Put(storage.FileKey(groupName, fileID), strings.NewReader("0123456789"))
+
+				gomock.InOrder(
+					fmDAO.EXPECT().
+						GetFileVersion(uint(userID), uint(fileID), uint(1), groupName).
+						Return(source, nil),
+
+					fmDAO.EXPECT().
+						AddFileInfo(uint(userID), fileName, uint(0), groupName).
+						Return(uint(newFileID), nil),
+
+					fmDAO.EXPECT().
+						AttachFileBlob(uint(newFileID), checksum, int64(10), dao.Quota{}, uint(0), gomock.Any()).
+						DoAndReturn(func(_ uint, _ string, _ int64, _ dao.Quota, _ uint, storeContent func(bool) error) error {
+							return storeContent(true)
+						}),
+				)
+			})
+
+			It("copies the content in the blob store", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				data, err := ioutil.ReadFile(path.Join(rootDir, storage.BlobKey(checksum)))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(Equal("0123456789"))
+			})
+		})
+	})
+
+	Context("SetMaxFileVersions", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("PUT", "/protected/group/file/versions/limit",
+				strings.NewReader(fmt.Sprintf(`{"group_name":"%s","max_versions":3}`, groupName)))
+		})
+
+		When("the user isnt the owner of the group", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					SetMaxFileVersions(uint(userID), groupName, uint(3)).
+					Return(myerr.NewClientError("Only the group owner can change the number of kept file versions"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Only the group owner")
+			})
+		})
+
+		When("the user is the owner of the group", func() {
+			BeforeEach(func() {
+				fmDAO.EXPECT().
+					SetMaxFileVersions(uint(userID), groupName, uint(3)).
+					Return(nil)
+			})
+
+			It("succeeds", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+})
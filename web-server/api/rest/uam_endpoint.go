@@ -1,15 +1,14 @@
 package rest
 
 import (
-	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"path"
+	"time"
 
 	"github.com/danielpenchev98/UShare/web-server/api/common"
 	"github.com/danielpenchev98/UShare/web-server/internal/auth"
 	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
 	val "github.com/danielpenchev98/UShare/web-server/internal/validator"
 	"github.com/gin-gonic/gin"
@@ -21,28 +20,59 @@ type UamEndpoint interface {
 	CreateUser(*gin.Context)
 	DeleteUser(*gin.Context)
 	Login(*gin.Context)
+	RefreshToken(*gin.Context)
+	Logout(*gin.Context)
+	GetJWKS(*gin.Context)
+	CreatePersonalAccessToken(*gin.Context)
+	GetPersonalAccessTokens(*gin.Context)
+	DeletePersonalAccessToken(*gin.Context)
+	VerifyLogin(*gin.Context)
+	EnrollTwoFactor(*gin.Context)
+	ConfirmTwoFactor(*gin.Context)
+	DisableTwoFactor(*gin.Context)
+	ChangePassword(*gin.Context)
+	ResetPassword(*gin.Context)
 
 	CreateGroup(*gin.Context)
-	AddMember(*gin.Context)
+	InviteMember(*gin.Context)
+	GetInvitations(*gin.Context)
+	AcceptInvitation(*gin.Context)
+	DeclineInvitation(*gin.Context)
+	SetGroupVisibility(*gin.Context)
+	JoinGroup(*gin.Context)
+	GetJoinRequests(*gin.Context)
+	ResolveJoinRequest(*gin.Context)
 	RevokeMembership(*gin.Context)
+	ChangeMemberRole(*gin.Context)
+	TransferOwnership(*gin.Context)
 	DeleteGroup(*gin.Context)
+	GetAllGroupsInfo(*gin.Context)
+	GetAllUsersInfo(*gin.Context)
+	GetAllUsersInGroup(*gin.Context)
 }
 
 //UamEndpointImpl - implementation of UamEndpoint
 type UamEndpointImpl struct {
-	uamDAO     dao.UamDAO
-	jwtCreator auth.JwtCreator
-	validator  val.Validator
-	groupsDir  string
+	uamDAO          dao.UamDAO
+	tokenDAO        dao.TokenDAO
+	twoFactorDAO    dao.TwoFactorDAO
+	loginFailureDAO dao.LoginFailureDAO
+	lockoutPolicy   dao.LockoutPolicy
+	jwtCreator      auth.JwtCreator
+	validator       val.Validator
 }
 
 //NewUamEndPointImpl - function for creation an instance of UamEndpointImpl
-func NewUamEndPointImpl(uamDAO dao.UamDAO, creator auth.JwtCreator, validator val.Validator, groupsDir string) *UamEndpointImpl {
+func NewUamEndPointImpl(uamDAO dao.UamDAO, tokenDAO dao.TokenDAO, twoFactorDAO dao.TwoFactorDAO, loginFailureDAO dao.LoginFailureDAO,
+	lockoutPolicy dao.LockoutPolicy, creator auth.JwtCreator, validator val.Validator) *UamEndpointImpl {
 	return &UamEndpointImpl{
-		uamDAO:     uamDAO,
-		jwtCreator: creator,
-		validator:  validator,
-		groupsDir:  groupsDir,
+		uamDAO:          uamDAO,
+		tokenDAO:        tokenDAO,
+		twoFactorDAO:    twoFactorDAO,
+		loginFailureDAO: loginFailureDAO,
+		lockoutPolicy:   lockoutPolicy,
+		jwtCreator:      creator,
+		validator:       validator,
 	}
 }
 
@@ -91,18 +121,41 @@ func (i *UamEndpointImpl) CreateUser(c *gin.Context) {
 }
 
 //DeleteUser - handler for user deletion request
+//the password of the user has to be confirmed
 //returns 500, if error occurrs due to system failure
 //returns 400 if the user input was invalid
+//returns 404 if the user doesnt exist
 //returns 200 if the user was successfully deleted
 func (i *UamEndpointImpl) DeleteUser(c *gin.Context) {
 	userID, err := common.GetIDFromContext(c)
 	if err != nil {
 		common.SendErrorResponse(c, err)
+		return
 	}
 
-	if err = i.uamDAO.DeleteUser(uint(userID)); err != nil {
-		err = myerr.NewServerErrorWrap(err, "Problem with deletion of user.")
-		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with deleting user"))
+	var request common.DeleteAccountPayload
+	if err = c.ShouldBindJSON(&request); err != nil || request.Password == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	policy := dao.AccountDeletionPolicy{
+		GroupPolicy: request.GroupPolicy,
+		FilePolicy:  request.FilePolicy,
+	}
+
+	confirmPassword := func(user models.User) error {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+			return myerr.NewClientError("Invalid password")
+		}
+		return nil
+	}
+
+	if err = i.uamDAO.DeleteUser(uint(userID), policy, confirmPassword); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with deleting user")
+		}
+		common.SendErrorResponse(c, err)
 		return
 	}
 
@@ -112,8 +165,11 @@ func (i *UamEndpointImpl) DeleteUser(c *gin.Context) {
 }
 
 //Login - handler for user login request
+//if the user has enabled two-factor authentication, a short-lived challenge is returned instead of the tokens, which is completed with VerifyLogin
 //returns 500, if error occurrs due to system failure
-//returns 400 if the user input was invalid
+//returns 400 if the user input was invalid or the account is suspended
+//returns 429 if there were too many failed logins with the username or from the client IP
+//returns 201 and the challenge if the password was confirmed and a TOTP code is required
 //returns 201 if the login was successfull
 func (i *UamEndpointImpl) Login(c *gin.Context) {
 	var request common.RequestWithCredentials
@@ -122,10 +178,16 @@ func (i *UamEndpointImpl) Login(c *gin.Context) {
 		return
 	}
 
+	attempt, err := i.reserveLoginAttempt(request.Username, common.GetClientIP(c))
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
 	user, err := i.uamDAO.GetUser(request.Username)
 	if err != nil {
 		if _, ok := err.(*myerr.ItemNotFoundError); ok {
-			common.SendErrorResponse(c, myerr.NewClientError("Invalid credentials"))
+			i.sendLoginFailure(c, request.Username, attempt, myerr.NewClientError("Invalid credentials"))
 		} else {
 			err = myerr.NewServerErrorWrap(err, "Problem with Login.")
 			common.SendErrorResponse(c, err)
@@ -135,34 +197,183 @@ func (i *UamEndpointImpl) Login(c *gin.Context) {
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
 	if err != nil {
-		common.SendErrorResponse(c, myerr.NewClientError("Invalid credentials"))
+		i.sendLoginFailure(c, request.Username, attempt, myerr.NewClientError("Invalid credentials"))
+		return
+	}
+
+	if err = i.releaseLoginAttempt(attempt); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	if user.SuspendedAt != nil && (user.SuspendedUntil == nil || user.SuspendedUntil.After(time.Now())) {
+		common.SendErrorResponse(c, myerr.NewClientError(common.SuspensionMessage(user.SuspensionReason, user.SuspendedUntil)))
 		return
 	}
 
-	signedToken, err := i.jwtCreator.GenerateToken(user.ID)
+	twoFactor, err := i.twoFactorDAO.GetTwoFactor(user.ID)
+	if _, ok := err.(*myerr.ItemNotFoundError); err != nil && !ok {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with checking the two-factor authentication in the login logic."))
+		return
+	} else if err == nil && twoFactor.Enabled {
+		i.sendLoginChallenge(c, user.ID)
+		return
+	}
+
+	i.completeLogin(c, user)
+}
+
+//completeLogin - issues the tokens to the user, whose credentials were confirmed, and then forgets the failed logins with the username
+func (i *UamEndpointImpl) completeLogin(c *gin.Context, user models.User) {
+	response, err := i.issueTokens(user.ID)
 	if err != nil {
-		err = myerr.NewServerErrorWrap(err, "Problem with generating Jwt token in the login logic.")
 		common.SendErrorResponse(c, err)
 		return
 	}
 
+	if err = i.resetLoginFailures(user.Username); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+//sendTokens - issues a new pair of access and refresh tokens to the user
+func (i *UamEndpointImpl) sendTokens(c *gin.Context, userID uint) {
+	response, err := i.issueTokens(userID)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (i *UamEndpointImpl) issueTokens(userID uint) (common.LoginResponse, error) {
+	refreshToken, err := i.jwtCreator.GenerateRefreshToken()
+	if err != nil {
+		return common.LoginResponse{}, myerr.NewServerErrorWrap(err, "Problem with generating refresh token in the login logic.")
+	}
+
+	if err = i.tokenDAO.CreateRefreshToken(userID, refreshToken.Hash, refreshToken.ExpiresAt); err != nil {
+		return common.LoginResponse{}, myerr.NewServerErrorWrap(err, "Problem with storing refresh token in the login logic.")
+	}
+
+	signedToken, err := i.jwtCreator.GenerateToken(userID)
+	if err != nil {
+		return common.LoginResponse{}, myerr.NewServerErrorWrap(err, "Problem with generating Jwt token in the login logic.")
+	}
+
+	return common.LoginResponse{
+		Status:       http.StatusCreated,
+		Token:        signedToken,
+		RefreshToken: refreshToken.Token,
+	}, nil
+}
+
+//RefreshToken - handler for request for a new access token
+//the refresh token can be used only once, so a new refresh token is also issued
+//returns 500, if error occurrs due to system failure
+//returns 400 if the refresh token is invalid, expired or already used
+//returns 201 if the tokens were successfully issued
+func (i *UamEndpointImpl) RefreshToken(c *gin.Context) {
+	var request common.RefreshTokenPayload
+	if err := c.ShouldBindJSON(&request); err != nil || request.RefreshToken == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	refreshToken, err := i.jwtCreator.GenerateRefreshToken()
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with generating refresh token."))
+		return
+	}
+
+	userID, err := i.tokenDAO.RotateRefreshToken(auth.HashRefreshToken(request.RefreshToken), refreshToken.Hash, refreshToken.ExpiresAt)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with refreshing the token.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	signedToken, err := i.jwtCreator.GenerateToken(userID)
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with generating Jwt token."))
+		return
+	}
+
 	c.JSON(http.StatusCreated, common.LoginResponse{
-		Status: http.StatusCreated,
-		Token:  signedToken,
+		Status:       http.StatusCreated,
+		Token:        signedToken,
+		RefreshToken: refreshToken.Token,
 	})
 }
 
-//CreateGroup - handler for group creation request
+//Logout - handler for user logout request
+//the current access token and the refresh token, if it is sent, are revoked
 //returns 500, if error occurrs due to system failure
 //returns 400 if the user input was invalid
+//returns 200 if the user was successfully logged out
+func (i *UamEndpointImpl) Logout(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	tokenID, expiresAt, err := common.GetTokenFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var request common.RefreshTokenPayload
+	if c.Request.ContentLength != 0 {
+		if err = c.ShouldBindJSON(&request); err != nil {
+			common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+			return
+		}
+	}
+
+	if request.RefreshToken != "" {
+		if err = i.tokenDAO.RevokeRefreshToken(userID, auth.HashRefreshToken(request.RefreshToken)); err != nil {
+			common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with revoking the refresh token."))
+			return
+		}
+	}
+
+	if err = i.tokenDAO.RevokeAccessToken(tokenID, expiresAt); err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with revoking the access token."))
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//GetJWKS - handler for request for the public keys, which validate the issued tokens
+//returns 200 and the keys in the JSON Web Key Set format
+func (i *UamEndpointImpl) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, i.jwtCreator.JWKS())
+}
+
+//CreateGroup - handler for group creation request
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid or a group with the same name exists
 //returns 201 if the group was successfully created
 func (i *UamEndpointImpl) CreateGroup(c *gin.Context) {
 	userID, err := common.GetIDFromContext(c)
 	if err != nil {
 		common.SendErrorResponse(c, err)
+		return
 	}
 
-	var rq common.GroupPayload
+	var rq common.GroupVisibilityPayload
 	if err := c.ShouldBindJSON(&rq); err != nil {
 		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
 		return
@@ -175,23 +386,50 @@ func (i *UamEndpointImpl) CreateGroup(c *gin.Context) {
 		return
 	}
 
-	groupDir := path.Join(i.groupsDir, rq.GroupName)
-	fmt.Println(groupDir)
-	if _, err := os.Stat(groupDir); !os.IsNotExist(err) {
-		common.SendErrorResponse(c, myerr.NewClientError("Problem with creation of group. Reason: Group already exists"))
-		return
-	} else if err = os.Mkdir(groupDir, 0755); err != nil {
-		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with creation of directory"))
+	err = i.uamDAO.CreateGroup(userID, rq.GroupName, rq.Visibility)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with creation of group.")
+		}
+		common.SendErrorResponse(c, err)
 		return
 	}
 
-	err = i.uamDAO.CreateGroup(userID, rq.GroupName)
-	if _, ok := err.(*myerr.ClientError); ok {
+	c.JSON(http.StatusCreated, common.BasicResponse{
+		Status: http.StatusCreated,
+	})
+}
+
+//InviteMember - handler for invitation creation request. The invited user is an editor, unless another role is specified
+//the user becomes a member of the group only after accepting the invitation
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid, the role of the current user doesnt allow the invitation or the user is already invited
+//returns 404 if the group or the user doesnt exist
+//returns 201 if the invitation was successfully created
+func (i *UamEndpointImpl) InviteMember(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
 		common.SendErrorResponse(c, err)
 		return
-	} else if err != nil {
-		os.RemoveAll(groupDir)
-		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with creation of group."))
+	}
+
+	var rq common.InvitationPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	var expiresAt *time.Time
+	if rq.ExpiresInHours > 0 {
+		expiration := time.Now().Add(time.Duration(rq.ExpiresInHours) * time.Hour)
+		expiresAt = &expiration
+	}
+
+	if err = i.uamDAO.InviteUserToGroup(userID, rq.Username, rq.GroupName, rq.Role, expiresAt); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with creation of invitation.")
+		}
+		common.SendErrorResponse(c, err)
 		return
 	}
 
@@ -200,34 +438,84 @@ func (i *UamEndpointImpl) CreateGroup(c *gin.Context) {
 	})
 }
 
-//AddMember - handler for membership creation request
+//GetInvitations - handler for fetching the pending invitations of the current user
+//returns 500, if error occurrs due to system failure
+//returns 200 otherwise
+func (i *UamEndpointImpl) GetInvitations(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	invitations, err := i.uamDAO.GetInvitations(userID)
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with fetching the invitations."))
+		return
+	}
+
+	invitationsInfo := make([]common.InvitationInfo, 0, len(invitations))
+	for _, invitation := range invitations {
+		invitationsInfo = append(invitationsInfo, common.InvitationInfo{
+			ID:        invitation.ID,
+			GroupName: invitation.GroupName,
+			Inviter:   invitation.Inviter,
+			Role:      invitation.Role,
+			CreatedAt: invitation.CreatedAt,
+			ExpiresAt: invitation.ExpiresAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      http.StatusOK,
+		"invitations": invitationsInfo,
+	})
+}
+
+//AcceptInvitation - handler for accepting an invitation to a group
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid or the group is being deleted
+//returns 404 if the group or the invitation doesnt exist or the invitation expired
+//returns 200 if the current user became a member of the group
+func (i *UamEndpointImpl) AcceptInvitation(c *gin.Context) {
+	i.respondToInvitation(c, i.uamDAO.AcceptInvitation, "Couldnt accept the invitation")
+}
+
+//DeclineInvitation - handler for declining an invitation to a group
 //returns 500, if error occurrs due to system failure
 //returns 400 if the user input was invalid
-//returns 201 if the user was successfully added to the group
-func (i *UamEndpointImpl) AddMember(c *gin.Context) {
+//returns 404 if the group or the invitation doesnt exist
+//returns 200 if the invitation was declined
+func (i *UamEndpointImpl) DeclineInvitation(c *gin.Context) {
+	i.respondToInvitation(c, i.uamDAO.DeclineInvitation, "Couldnt decline the invitation")
+}
+
+func (i *UamEndpointImpl) respondToInvitation(c *gin.Context, respond func(uint, string) error, errMsg string) {
 	userID, err := common.GetIDFromContext(c)
 	if err != nil {
 		common.SendErrorResponse(c, err)
+		return
 	}
 
-	var rq common.GroupMembershipPayload
+	var rq common.GroupPayload
 	if err := c.ShouldBindJSON(&rq); err != nil {
 		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
 		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
 	}
 
-	err = i.uamDAO.AddUserToGroup(userID, rq.Username, rq.GroupName)
-	if _, ok := err.(*myerr.ClientError); ok {
-		common.SendErrorResponse(c, err)
-		return
-	} else if err != nil {
-		err = myerr.NewServerErrorWrap(err, "Problem with creation of group.")
+	if err = respond(userID, rq.GroupName); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, errMsg)
+		}
 		common.SendErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, common.BasicResponse{
-		Status: http.StatusCreated,
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
 	})
 }
 
@@ -263,6 +551,74 @@ func (i *UamEndpointImpl) RevokeMembership(c *gin.Context) {
 	})
 }
 
+//ChangeMemberRole - handler for changing the role of a member of a group
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid or the role of the current user doesnt allow the change
+//returns 404 if the user or its membership doesnt exist
+//returns 200 if the role was successfully changed
+func (i *UamEndpointImpl) ChangeMemberRole(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.MemberRolePayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Username == "" || rq.Role == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname, username or role isnt specified"))
+		return
+	}
+
+	if err = i.uamDAO.ChangeMemberRole(userID, rq.Username, rq.GroupName, rq.Role); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Couldnt change the role of the member")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//TransferOwnership - handler for making another member the owner of a group. The former owner becomes an admin and can leave the group
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid, the current user isnt the owner or the user isnt a member of the group
+//returns 404 if the group or the user doesnt exist
+//returns 200 if the ownership was successfully transferred
+func (i *UamEndpointImpl) TransferOwnership(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.GroupMembershipPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Username == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname or username isnt specified"))
+		return
+	}
+
+	if err = i.uamDAO.TransferOwnership(userID, rq.Username, rq.GroupName); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Couldnt transfer the ownership of the group")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
 //DeleteGroup - handler for group deletion request
 //returns 500, if error occurrs due to system failure
 //returns 400 if the user input was invalid
@@ -295,12 +651,18 @@ func (i *UamEndpointImpl) DeleteGroup(c *gin.Context) {
 	})
 }
 
-//GetAllGroupsInfo - handler for fetching info about every active group
+//GetAllGroupsInfo - handler for fetching info about every active group, which the current user can see
 //returns 500, if error occurrs due to system failure
 //returns 400 if the user input was invalid
 //returns 200 otherwise
 func (i *UamEndpointImpl) GetAllGroupsInfo(c *gin.Context) {
-	groups, err := i.uamDAO.GetAllGroups()
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groups, err := i.uamDAO.GetAllGroups(userID)
 	if _, ok := err.(*myerr.ServerError); ok {
 		err = myerr.NewServerErrorWrap(err, "Problem with fetching all groups.")
 		common.SendErrorResponse(c, err)
@@ -310,9 +672,10 @@ func (i *UamEndpointImpl) GetAllGroupsInfo(c *gin.Context) {
 	groupsInfo := make([]common.GroupInfo, 0, len(groups))
 	for _, group := range groups {
 		groupsInfo = append(groupsInfo, common.GroupInfo{
-			ID:      group.ID,
-			Name:    group.Name,
-			OwnerID: group.OwnerID,
+			ID:         group.ID,
+			Name:       group.Name,
+			OwnerID:    group.OwnerID,
+			Visibility: group.Visibility,
 		})
 	}
 
@@ -322,6 +685,152 @@ func (i *UamEndpointImpl) GetAllGroupsInfo(c *gin.Context) {
 	})
 }
 
+//SetGroupVisibility - handler for changing who can see and join a group
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid or the current user isnt the owner of the group
+//returns 404 if the group doesnt exist
+//returns 200 if the visibility was changed
+func (i *UamEndpointImpl) SetGroupVisibility(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.GroupVisibilityPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Visibility == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname or visibility isnt specified"))
+		return
+	}
+
+	if err = i.uamDAO.SetGroupVisibility(userID, rq.GroupName, rq.Visibility); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Couldnt change the visibility of the group")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//JoinGroup - handler for joining an open group or requesting to join a discoverable group
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid, the user is already a member or already requested to join
+//returns 404 if the group doesnt exist or is private
+//returns 200 with joined set to true if the user became a member and false if a join request was created
+func (i *UamEndpointImpl) JoinGroup(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.GroupPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	joined, err := i.uamDAO.RequestToJoinGroup(userID, rq.GroupName)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Couldnt join the group")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": http.StatusOK,
+		"joined": joined,
+	})
+}
+
+//GetJoinRequests - handler for fetching the pending join requests of a group
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid or the role of the current user doesnt allow managing members
+//returns 404 if the group doesnt exist
+//returns 200 otherwise
+func (i *UamEndpointImpl) GetJoinRequests(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	groupName := c.Query("group_name")
+	if groupName == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname isnt specified"))
+		return
+	}
+
+	joinRequests, err := i.uamDAO.GetJoinRequests(userID, groupName)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with fetching the join requests.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	joinRequestsInfo := make([]common.JoinRequestInfo, 0, len(joinRequests))
+	for _, joinRequest := range joinRequests {
+		joinRequestsInfo = append(joinRequestsInfo, common.JoinRequestInfo{
+			ID:        joinRequest.ID,
+			Username:  joinRequest.Username,
+			CreatedAt: joinRequest.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        http.StatusOK,
+		"join_requests": joinRequestsInfo,
+	})
+}
+
+//ResolveJoinRequest - handler for approving or rejecting a join request. The approved user is an editor, unless another role is specified
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid or the role of the current user doesnt allow managing members
+//returns 404 if the group, the user or the join request doesnt exist
+//returns 200 if the join request was resolved
+func (i *UamEndpointImpl) ResolveJoinRequest(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.JoinRequestResolutionPayload
+	if err := c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.GroupName == "" || rq.Username == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Groupname or username isnt specified"))
+		return
+	}
+
+	if err = i.uamDAO.ResolveJoinRequest(userID, rq.GroupName, rq.Username, rq.Approve, rq.Role); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Couldnt resolve the join request")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
 //GetAllUsersInfo - handler for fetching info about every user
 //returns 500, if error occurrs due to system failure
 //returns 400 if the user input was invalid
@@ -381,6 +890,7 @@ func (i *UamEndpointImpl) GetAllUsersInGroup(c *gin.Context) {
 		usersInfo = append(usersInfo, common.UserInfo{
 			ID:       user.ID,
 			Username: user.Username,
+			Role:     user.Role,
 		})
 	}
 
@@ -4,15 +4,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
-	"path"
 	"strings"
+	"time"
 
 	"github.com/danielpenchev98/UShare/web-server/api/common"
 	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
 	"github.com/danielpenchev98/UShare/web-server/internal/auth/auth_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
 	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
 	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
@@ -24,6 +26,10 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+const tokenID = "token-id"
+
+var tokenExpiresAt = time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+
 func setupRouter(uamRest rest.UamEndpoint, userID uint) *gin.Engine {
 	r := gin.Default()
 
@@ -31,17 +37,31 @@ func setupRouter(uamRest rest.UamEndpoint, userID uint) *gin.Engine {
 	{
 		public.POST("/user/registration", uamRest.CreateUser)
 		public.POST("/user/login", uamRest.Login)
+		public.POST("/user/token/refresh", uamRest.RefreshToken)
 	}
+	r.GET("/.well-known/jwks.json", uamRest.GetJWKS)
 	protected := r.Group("/protected").Use(func(c *gin.Context) {
 		c.Set("userID", userID)
+		c.Set("tokenID", tokenID)
+		c.Set("tokenExpiresAt", tokenExpiresAt)
 		c.Next()
 	})
 	{
+		protected.DELETE("/user/logout", uamRest.Logout)
 		protected.DELETE("/user/deletion", uamRest.DeleteUser)
 		protected.DELETE("/group/deletion", uamRest.DeleteGroup)
 		protected.POST("/group/creation", uamRest.CreateGroup)
 		protected.POST("/group/membership/revocation", uamRest.RevokeMembership)
-		protected.POST("/group/membership/invitation", uamRest.AddMember)
+		protected.POST("/group/membership/invitation", uamRest.InviteMember)
+		protected.GET("/user/invitations", uamRest.GetInvitations)
+		protected.POST("/group/invitation/acceptance", uamRest.AcceptInvitation)
+		protected.DELETE("/group/invitation/declination", uamRest.DeclineInvitation)
+		protected.GET("/groups", uamRest.GetAllGroupsInfo)
+		protected.PUT("/group/visibility", uamRest.SetGroupVisibility)
+		protected.POST("/group/join", uamRest.JoinGroup)
+		protected.POST("/group/join/requests/resolution", uamRest.ResolveJoinRequest)
+		protected.PUT("/group/membership/role", uamRest.ChangeMemberRole)
+		protected.PUT("/group/ownership", uamRest.TransferOwnership)
 	}
 	return r
 }
@@ -56,12 +76,14 @@ func assertErrorResponse(recorder *httptest.ResponseRecorder, expStatusCode int,
 
 var _ = Describe("UamEndpoint", func() {
 	var (
-		router     *gin.Engine
-		recorder   *httptest.ResponseRecorder
-		jwtCreator *auth_mocks.MockJwtCreator
-		uamDAO     *dao_mocks.MockUamDAO
-		validator  *validator_mocks.MockValidator
-		req        *http.Request
+		router       *gin.Engine
+		recorder     *httptest.ResponseRecorder
+		jwtCreator   *auth_mocks.MockJwtCreator
+		uamDAO       *dao_mocks.MockUamDAO
+		tokenDAO     *dao_mocks.MockTokenDAO
+		twoFactorDAO *dao_mocks.MockTwoFactorDAO
+		validator    *validator_mocks.MockValidator
+		req          *http.Request
 	)
 
 	const (
@@ -69,15 +91,16 @@ var _ = Describe("UamEndpoint", func() {
 		password  = "password"
 		userID    = 1
 		groupName = "groupName"
-		groupsDir = "."
 	)
 
 	BeforeEach(func() {
 		controller := gomock.NewController(GinkgoT())
 		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		tokenDAO = dao_mocks.NewMockTokenDAO(controller)
+		twoFactorDAO = dao_mocks.NewMockTwoFactorDAO(controller)
 		jwtCreator = auth_mocks.NewMockJwtCreator(controller)
 		validator = validator_mocks.NewMockValidator(controller)
-		uamRest := rest.NewUamEndPointImpl(uamDAO, jwtCreator, validator, groupsDir)
+		uamRest := rest.NewUamEndPointImpl(uamDAO, tokenDAO, twoFactorDAO, dao_mocks.NewMockLoginFailureDAO(controller), dao.LockoutPolicy{}, jwtCreator, validator)
 
 		router = setupRouter(uamRest, userID)
 		recorder = httptest.NewRecorder()
@@ -349,6 +372,12 @@ var _ = Describe("UamEndpoint", func() {
 					Context("and user exist", func() {
 						var user models.User
 
+						refreshToken := auth.RefreshToken{
+							Token:     "refresh-token",
+							Hash:      "refresh-token-hash",
+							ExpiresAt: time.Now().Add(time.Hour),
+						}
+
 						BeforeEach(func() {
 							encryptedPass, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 
@@ -359,49 +388,150 @@ var _ = Describe("UamEndpoint", func() {
 							user.ID = 1
 						})
 
-						Context("and token generation fails", func() {
+						Context("and the account is suspended", func() {
 							BeforeEach(func() {
+								suspendedAt := time.Now()
+								user.SuspendedAt = &suspendedAt
+								user.SuspensionReason = "spam"
+								uamDAO.EXPECT().
+									GetUser(user.Username).
+									Return(user, nil)
 
-								gomock.InOrder(
-									uamDAO.EXPECT().
-										GetUser(user.Username).
-										Return(user, nil),
-
-									jwtCreator.EXPECT().
-										GenerateToken(user.ID).
-										Return("", errors.New("test-error")),
-								)
+								jwtCreator.EXPECT().
+									GenerateToken(gomock.Any()).
+									Times(0)
 							})
 
-							It("returns internal server error response", func() {
+							It("returns bad request error response", func() {
 								router.ServeHTTP(recorder, req)
-								assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
+								assertErrorResponse(recorder, http.StatusBadRequest, "The account is suspended. Reason: spam")
 							})
 						})
 
-						Context("and token generation succeeds", func() {
-							const token = "token"
-
+						Context("and two-factor authentication is enabled", func() {
 							BeforeEach(func() {
 								gomock.InOrder(
 									uamDAO.EXPECT().
 										GetUser(user.Username).
 										Return(user, nil),
 
-									jwtCreator.EXPECT().
-										GenerateToken(user.ID).
-										Return(token, nil),
+									twoFactorDAO.EXPECT().
+										GetTwoFactor(user.ID).
+										Return(models.TwoFactor{UserID: user.ID, Enabled: true}, nil),
+
+									twoFactorDAO.EXPECT().
+										CreateLoginChallenge(user.ID, gomock.Any(), gomock.Any()).
+										Return(nil),
 								)
+
+								jwtCreator.EXPECT().
+									GenerateToken(gomock.Any()).
+									Times(0)
 							})
 
-							It("returns successful response", func() {
+							It("returns a challenge instead of the tokens", func() {
 								router.ServeHTTP(recorder, req)
 
 								Expect(recorder.Code).To(Equal(http.StatusCreated))
 								body := common.LoginResponse{}
 								json.Unmarshal([]byte(recorder.Body.String()), &body)
-								Expect(body.Status).To(Equal(http.StatusCreated))
-								Expect(body.Token).To(Equal(token))
+								Expect(body.TwoFactorRequired).To(BeTrue())
+								Expect(body.Challenge).NotTo(BeEmpty())
+								Expect(body.Token).To(BeEmpty())
+							})
+						})
+
+						Context("and two-factor authentication isnt enabled", func() {
+							BeforeEach(func() {
+								twoFactorDAO.EXPECT().
+									GetTwoFactor(user.ID).
+									Return(models.TwoFactor{}, myerr.NewItemNotFoundError("test-error"))
+							})
+
+							Context("and storing the refresh token fails", func() {
+								BeforeEach(func() {
+									gomock.InOrder(
+										uamDAO.EXPECT().
+											GetUser(user.Username).
+											Return(user, nil),
+
+										jwtCreator.EXPECT().
+											GenerateRefreshToken().
+											Return(refreshToken, nil),
+
+										tokenDAO.EXPECT().
+											CreateRefreshToken(user.ID, refreshToken.Hash, refreshToken.ExpiresAt).
+											Return(myerr.NewServerError("test-error")),
+									)
+								})
+
+								It("returns internal server error response", func() {
+									router.ServeHTTP(recorder, req)
+									assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
+								})
+							})
+
+							Context("and token generation fails", func() {
+								BeforeEach(func() {
+
+									gomock.InOrder(
+										uamDAO.EXPECT().
+											GetUser(user.Username).
+											Return(user, nil),
+
+										jwtCreator.EXPECT().
+											GenerateRefreshToken().
+											Return(refreshToken, nil),
+
+										tokenDAO.EXPECT().
+											CreateRefreshToken(user.ID, refreshToken.Hash, refreshToken.ExpiresAt).
+											Return(nil),
+
+										jwtCreator.EXPECT().
+											GenerateToken(user.ID).
+											Return("", errors.New("test-error")),
+									)
+								})
+
+								It("returns internal server error response", func() {
+									router.ServeHTTP(recorder, req)
+									assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
+								})
+							})
+
+							Context("and token generation succeeds", func() {
+								const token = "token"
+
+								BeforeEach(func() {
+									gomock.InOrder(
+										uamDAO.EXPECT().
+											GetUser(user.Username).
+											Return(user, nil),
+
+										jwtCreator.EXPECT().
+											GenerateRefreshToken().
+											Return(refreshToken, nil),
+
+										tokenDAO.EXPECT().
+											CreateRefreshToken(user.ID, refreshToken.Hash, refreshToken.ExpiresAt).
+											Return(nil),
+
+										jwtCreator.EXPECT().
+											GenerateToken(user.ID).
+											Return(token, nil),
+									)
+								})
+
+								It("returns successful response", func() {
+									router.ServeHTTP(recorder, req)
+
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+									body := common.LoginResponse{}
+									json.Unmarshal([]byte(recorder.Body.String()), &body)
+									Expect(body.Status).To(Equal(http.StatusCreated))
+									Expect(body.Token).To(Equal(token))
+									Expect(body.RefreshToken).To(Equal(refreshToken.Token))
+								})
 							})
 						})
 					})
@@ -410,18 +540,180 @@ var _ = Describe("UamEndpoint", func() {
 		})
 	})
 
+	Context("RefreshToken", func() {
+		newRefreshToken := auth.RefreshToken{
+			Token:     "new-refresh-token",
+			Hash:      "new-refresh-token-hash",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		When("the request body is invalid", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("POST", "/public/user/token/refresh", strings.NewReader("{}"))
+			})
+
+			It("returns bad request response", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid json body")
+			})
+		})
+
+		When("the request body is valid", func() {
+			BeforeEach(func() {
+				jsonBody, _ := json.Marshal(common.RefreshTokenPayload{RefreshToken: "refresh-token"})
+				req, _ = http.NewRequest("POST", "/public/user/token/refresh", bytes.NewBuffer(jsonBody))
+
+				jwtCreator.EXPECT().
+					GenerateRefreshToken().
+					Return(newRefreshToken, nil)
+			})
+
+			Context("and the refresh token is invalid", func() {
+				BeforeEach(func() {
+					tokenDAO.EXPECT().
+						RotateRefreshToken(auth.HashRefreshToken("refresh-token"), newRefreshToken.Hash, newRefreshToken.ExpiresAt).
+						Return(uint(0), myerr.NewClientError("Invalid refresh token"))
+				})
+
+				It("returns bad request response", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "Invalid refresh token")
+				})
+			})
+
+			Context("and the refresh token is valid", func() {
+				BeforeEach(func() {
+					gomock.InOrder(
+						tokenDAO.EXPECT().
+							RotateRefreshToken(auth.HashRefreshToken("refresh-token"), newRefreshToken.Hash, newRefreshToken.ExpiresAt).
+							Return(uint(userID), nil),
+
+						jwtCreator.EXPECT().
+							GenerateToken(uint(userID)).
+							Return("token", nil),
+					)
+				})
+
+				It("returns the new tokens", func() {
+					router.ServeHTTP(recorder, req)
+
+					Expect(recorder.Code).To(Equal(http.StatusCreated))
+					body := common.LoginResponse{}
+					json.Unmarshal([]byte(recorder.Body.String()), &body)
+					Expect(body.Token).To(Equal("token"))
+					Expect(body.RefreshToken).To(Equal(newRefreshToken.Token))
+				})
+			})
+		})
+	})
+
+	Context("GetJWKS", func() {
+		BeforeEach(func() {
+			jwtCreator.EXPECT().
+				JWKS().
+				Return(auth.JWKSet{Keys: []auth.JWK{{Kty: "OKP", Kid: "key-id", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: "key"}}})
+
+			req, _ = http.NewRequest("GET", "/.well-known/jwks.json", nil)
+		})
+
+		It("returns the public keys", func() {
+			router.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			body := auth.JWKSet{}
+			json.Unmarshal([]byte(recorder.Body.String()), &body)
+			Expect(body.Keys).To(HaveLen(1))
+			Expect(body.Keys[0].Kid).To(Equal("key-id"))
+		})
+	})
+
+	Context("Logout", func() {
+		When("the refresh token isnt sent", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "/protected/user/logout", nil)
+
+				tokenDAO.EXPECT().
+					RevokeRefreshToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			})
+
+			Context("and revoking the access token fails", func() {
+				BeforeEach(func() {
+					tokenDAO.EXPECT().
+						RevokeAccessToken(tokenID, tokenExpiresAt).
+						Return(myerr.NewServerError("test-error"))
+				})
+
+				It("returns internal server error response", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
+				})
+			})
+
+			Context("and revoking the access token succeeds", func() {
+				BeforeEach(func() {
+					tokenDAO.EXPECT().
+						RevokeAccessToken(tokenID, tokenExpiresAt).
+						Return(nil)
+				})
+
+				It("returns success response", func() {
+					router.ServeHTTP(recorder, req)
+					Expect(recorder.Code).To(Equal(http.StatusOK))
+				})
+			})
+		})
+
+		When("the refresh token is sent", func() {
+			BeforeEach(func() {
+				jsonBody, _ := json.Marshal(common.RefreshTokenPayload{RefreshToken: "refresh-token"})
+				req, _ = http.NewRequest("DELETE", "/protected/user/logout", bytes.NewBuffer(jsonBody))
+
+				gomock.InOrder(
+					tokenDAO.EXPECT().
+						RevokeRefreshToken(uint(userID), auth.HashRefreshToken("refresh-token")).
+						Return(nil),
+
+					tokenDAO.EXPECT().
+						RevokeAccessToken(tokenID, tokenExpiresAt).
+						Return(nil),
+				)
+			})
+
+			It("revokes both tokens", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
 	Context("DeleteUser", func() {
-		When("login request is sent and authentication passes", func() {
+		When("the request body is invalid", func() {
+			BeforeEach(func() {
+				req, _ = http.NewRequest("DELETE", "/protected/user/deletion", strings.NewReader("test"))
+				req.Header.Set("Authorization", "Bearer sometoken")
+			})
+
+			It("returns bad request response", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid json body")
+			})
+		})
 
+		When("the request body is valid", func() {
 			BeforeEach(func() {
-				req, _ = http.NewRequest("DELETE", "/protected/user/deletion", nil)
+				jsonBody, _ := json.Marshal(common.DeleteAccountPayload{
+					Password:    password,
+					GroupPolicy: dao.GroupPolicyDeactivate,
+				})
+				req, _ = http.NewRequest("DELETE", "/protected/user/deletion", bytes.NewBuffer(jsonBody))
 				req.Header.Set("Authorization", "Bearer sometoken")
 			})
 
 			Context("operation of deleting user from db fails", func() {
 				BeforeEach(func() {
 					uamDAO.EXPECT().
-						DeleteUser(uint(userID)).
+						DeleteUser(uint(userID), dao.AccountDeletionPolicy{GroupPolicy: dao.GroupPolicyDeactivate}, gomock.Any()).
 						Return(myerr.NewServerError("test-error"))
 				})
 
@@ -431,37 +723,53 @@ var _ = Describe("UamEndpoint", func() {
 				})
 			})
 
-			Context("operation of deleting user from db succeeds", func() {
-				Context("and user doesnt exist", func() {
-					BeforeEach(func() {
-						uamDAO.EXPECT().
-							DeleteUser(uint(userID)).
-							Return(myerr.NewItemNotFoundError("test-error"))
-					})
+			Context("and user doesnt exist", func() {
+				BeforeEach(func() {
+					uamDAO.EXPECT().
+						DeleteUser(uint(userID), gomock.Any(), gomock.Any()).
+						Return(myerr.NewItemNotFoundError("test-error"))
+				})
 
-					It("returns internal server error response", func() {
-						router.ServeHTTP(recorder, req)
-						assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
-					})
+				It("returns not found response", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusNotFound, "test-error")
 				})
+			})
 
-				Context("and user exists", func() {
-					BeforeEach(func() {
-						uamDAO.EXPECT().
-							DeleteUser(uint(userID)).
-							Return(nil)
-					})
+			Context("and the password is wrong", func() {
+				BeforeEach(func() {
+					uamDAO.EXPECT().
+						DeleteUser(uint(userID), gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ uint, _ dao.AccountDeletionPolicy, confirm func(models.User) error) error {
+							encryptedPass, _ := bcrypt.GenerateFromPassword([]byte("different-password"), bcrypt.DefaultCost)
+							return confirm(models.User{Password: string(encryptedPass)})
+						})
+				})
 
-					It("returns success response", func() {
-						router.ServeHTTP(recorder, req)
+				It("returns bad request response", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "Invalid password")
+				})
+			})
 
-						Expect(recorder.Code).To(Equal(http.StatusOK))
-						body := common.BasicResponse{}
-						json.Unmarshal([]byte(recorder.Body.String()), &body)
-						Expect(body.Status).To(Equal(http.StatusOK))
-					})
+			Context("and the password is confirmed", func() {
+				BeforeEach(func() {
+					uamDAO.EXPECT().
+						DeleteUser(uint(userID), gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ uint, _ dao.AccountDeletionPolicy, confirm func(models.User) error) error {
+							encryptedPass, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+							return confirm(models.User{Password: string(encryptedPass)})
+						})
 				})
 
+				It("returns success response", func() {
+					router.ServeHTTP(recorder, req)
+
+					Expect(recorder.Code).To(Equal(http.StatusOK))
+					body := common.BasicResponse{}
+					json.Unmarshal([]byte(recorder.Body.String()), &body)
+					Expect(body.Status).To(Equal(http.StatusOK))
+				})
 			})
 		})
 	})
@@ -476,7 +784,7 @@ var _ = Describe("UamEndpoint", func() {
 						Times(0)
 
 					uamDAO.EXPECT().
-						CreateGroup(gomock.Any(), gomock.Any()).
+						CreateGroup(gomock.Any(), gomock.Any(), gomock.Any()).
 						Times(0)
 
 					req, _ = http.NewRequest("POST", "/protected/group/creation", strings.NewReader("test"))
@@ -505,7 +813,7 @@ var _ = Describe("UamEndpoint", func() {
 							Return(myerr.NewClientError("test-error"))
 
 						uamDAO.EXPECT().
-							CreateGroup(gomock.Any(), gomock.Any()).
+							CreateGroup(gomock.Any(), gomock.Any(), gomock.Any()).
 							Times(0)
 					})
 
@@ -517,10 +825,6 @@ var _ = Describe("UamEndpoint", func() {
 				})
 
 				Context("and group name passes the validation", func() {
-					AfterEach(func() {
-						os.RemoveAll(path.Join(groupsDir, groupName))
-					})
-
 					Context("and operation of creation group from db fails", func() {
 						Context("because connection to db fails", func() {
 							BeforeEach(func() {
@@ -529,7 +833,7 @@ var _ = Describe("UamEndpoint", func() {
 										ValidateUsername(rqBody.GroupName).
 										Return(nil),
 									uamDAO.EXPECT().
-										CreateGroup(uint(userID), rqBody.GroupName).
+										CreateGroup(uint(userID), rqBody.GroupName, "").
 										Return(myerr.NewServerError("test-error")),
 								)
 							})
@@ -547,7 +851,7 @@ var _ = Describe("UamEndpoint", func() {
 										ValidateUsername(rqBody.GroupName).
 										Return(nil),
 									uamDAO.EXPECT().
-										CreateGroup(uint(userID), rqBody.GroupName).
+										CreateGroup(uint(userID), rqBody.GroupName, "").
 										Return(myerr.NewClientError("test-error")),
 								)
 							})
@@ -567,7 +871,7 @@ var _ = Describe("UamEndpoint", func() {
 									ValidateUsername(rqBody.GroupName).
 									Return(nil),
 								uamDAO.EXPECT().
-									CreateGroup(uint(userID), rqBody.GroupName).
+									CreateGroup(uint(userID), rqBody.GroupName, "").
 									Return(nil),
 							)
 						})
@@ -586,13 +890,13 @@ var _ = Describe("UamEndpoint", func() {
 		})
 	})
 
-	Context("AddMember", func() {
+	Context("InviteMember", func() {
 		When("request a user to be added to group is sent and authentication passes", func() {
 			Context("with non-json body", func() {
 
 				BeforeEach(func() {
 					uamDAO.EXPECT().
-						AddUserToGroup(uint(userID), username, groupName).
+						InviteUserToGroup(uint(userID), username, groupName, "", nil).
 						Times(0)
 
 					req, _ = http.NewRequest("POST", "/protected/group/membership/invitation", strings.NewReader("test"))
@@ -615,11 +919,11 @@ var _ = Describe("UamEndpoint", func() {
 					req.Header.Set("Authorization", "Bearer sometoken")
 				})
 
-				Context("and membership creation fails", func() {
+				Context("and invitation creation fails", func() {
 					Context("and request fails due to problem with the server", func() {
 						BeforeEach(func() {
 							uamDAO.EXPECT().
-								AddUserToGroup(uint(userID), username, groupName).
+								InviteUserToGroup(uint(userID), username, groupName, "", nil).
 								Return(myerr.NewServerError("some-error"))
 						})
 
@@ -632,7 +936,7 @@ var _ = Describe("UamEndpoint", func() {
 					Context("and username or group doesnt exist", func() {
 						BeforeEach(func() {
 							uamDAO.EXPECT().
-								AddUserToGroup(uint(userID), username, groupName).
+								InviteUserToGroup(uint(userID), username, groupName, "", nil).
 								Return(myerr.NewClientError("some-error"))
 						})
 
@@ -643,10 +947,10 @@ var _ = Describe("UamEndpoint", func() {
 					})
 				})
 
-				Context("and membership creation succeeds", func() {
+				Context("and invitation creation succeeds", func() {
 					BeforeEach(func() {
 						uamDAO.EXPECT().
-							AddUserToGroup(uint(userID), username, groupName).
+							InviteUserToGroup(uint(userID), username, groupName, "", nil).
 							Return(nil)
 					})
 
@@ -663,6 +967,384 @@ var _ = Describe("UamEndpoint", func() {
 		})
 	})
 
+	Context("InviteMember with expiration", func() {
+		BeforeEach(func() {
+			uamDAO.EXPECT().
+				InviteUserToGroup(uint(userID), username, groupName, models.RoleViewer, gomock.Any()).
+				DoAndReturn(func(_ uint, _, _, _ string, expiresAt *time.Time) error {
+					Expect(expiresAt).NotTo(BeNil())
+					Expect(*expiresAt).To(BeTemporally("~", time.Now().Add(48*time.Hour), time.Minute))
+					return nil
+				})
+
+			req, _ = http.NewRequest("POST", "/protected/group/membership/invitation",
+				strings.NewReader(fmt.Sprintf(`{"group_name":"%s","username":"%s","role":"viewer","expires_in_hours":48}`, groupName, username)))
+		})
+
+		It("passes the expiration moment of the invitation", func() {
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+	})
+
+	Context("GetInvitations", func() {
+		When("request to fetch the invitations fails", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					GetInvitations(uint(userID)).
+					Return(nil, myerr.NewServerError("some-error"))
+
+				req, _ = http.NewRequest("GET", "/protected/user/invitations", nil)
+			})
+
+			It("returns internal server error", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
+			})
+		})
+
+		When("the invitations are fetched", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					GetInvitations(uint(userID)).
+					Return([]dao.InvitationInfo{{ID: 3, GroupName: groupName, Inviter: "inviter", Role: models.RoleEditor}}, nil)
+
+				req, _ = http.NewRequest("GET", "/protected/user/invitations", nil)
+			})
+
+			It("returns the invitations", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				body := struct {
+					Invitations []common.InvitationInfo `json:"invitations"`
+				}{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.Invitations).To(HaveLen(1))
+				Expect(body.Invitations[0].GroupName).To(Equal(groupName))
+				Expect(body.Invitations[0].Inviter).To(Equal("inviter"))
+				Expect(body.Invitations[0].ExpiresAt).To(BeNil())
+			})
+		})
+	})
+
+	Context("AcceptInvitation", func() {
+		When("group name isnt specified", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					AcceptInvitation(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("POST", "/protected/group/invitation/acceptance", strings.NewReader(`{}`))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Groupname isnt specified")
+			})
+		})
+
+		When("the invitation doesnt exist", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					AcceptInvitation(uint(userID), groupName).
+					Return(myerr.NewItemNotFoundError("Invitation not found"))
+
+				req, _ = http.NewRequest("POST", "/protected/group/invitation/acceptance",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s"}`, groupName)))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Invitation not found")
+			})
+		})
+
+		When("the invitation is accepted", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					AcceptInvitation(uint(userID), groupName).
+					Return(nil)
+
+				req, _ = http.NewRequest("POST", "/protected/group/invitation/acceptance",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s"}`, groupName)))
+			})
+
+			It("returns status ok", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("DeclineInvitation", func() {
+		When("the invitation is declined", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					DeclineInvitation(uint(userID), groupName).
+					Return(nil)
+
+				req, _ = http.NewRequest("DELETE", "/protected/group/invitation/declination",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s"}`, groupName)))
+			})
+
+			It("returns status ok", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("GetAllGroupsInfo", func() {
+		When("the groups are fetched", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					GetAllGroups(uint(userID)).
+					Return([]models.Group{{ID: 2, Name: groupName, OwnerID: userID, Visibility: models.VisibilityOpen}}, nil)
+
+				req, _ = http.NewRequest("GET", "/protected/groups", nil)
+			})
+
+			It("returns the groups, which the user can see", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				body := struct {
+					Groups []common.GroupInfo `json:"groups"`
+				}{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.Groups).To(HaveLen(1))
+				Expect(body.Groups[0].Visibility).To(Equal(models.VisibilityOpen))
+			})
+		})
+	})
+
+	Context("SetGroupVisibility", func() {
+		When("visibility isnt specified", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					SetGroupVisibility(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("PUT", "/protected/group/visibility",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s"}`, groupName)))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Groupname or visibility isnt specified")
+			})
+		})
+
+		When("the visibility is changed", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					SetGroupVisibility(uint(userID), groupName, models.VisibilityDiscoverable).
+					Return(nil)
+
+				req, _ = http.NewRequest("PUT", "/protected/group/visibility",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","visibility":"discoverable"}`, groupName)))
+			})
+
+			It("returns status ok", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("JoinGroup", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("POST", "/protected/group/join",
+				strings.NewReader(fmt.Sprintf(`{"group_name":"%s"}`, groupName)))
+		})
+
+		When("the group is private", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					RequestToJoinGroup(uint(userID), groupName).
+					Return(false, myerr.NewItemNotFoundError("Group does not exist"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Group does not exist")
+			})
+		})
+
+		When("a join request is created", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					RequestToJoinGroup(uint(userID), groupName).
+					Return(false, nil)
+			})
+
+			It("returns that the user didnt join yet", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				body := struct {
+					Joined bool `json:"joined"`
+				}{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.Joined).To(BeFalse())
+			})
+		})
+	})
+
+	Context("ResolveJoinRequest", func() {
+		When("username isnt specified", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					ResolveJoinRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+
+				req, _ = http.NewRequest("POST", "/protected/group/join/requests/resolution",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","approve":true}`, groupName)))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Groupname or username isnt specified")
+			})
+		})
+
+		When("the join request is approved", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					ResolveJoinRequest(uint(userID), groupName, username, true, models.RoleViewer).
+					Return(nil)
+
+				req, _ = http.NewRequest("POST", "/protected/group/join/requests/resolution",
+					strings.NewReader(fmt.Sprintf(`{"group_name":"%s","username":"%s","approve":true,"role":"viewer"}`, groupName, username)))
+			})
+
+			It("returns status ok", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Context("ChangeMemberRole", func() {
+		When("request for changing the role of a member is sent and authentication passes", func() {
+			Context("without role", func() {
+				BeforeEach(func() {
+					uamDAO.EXPECT().
+						ChangeMemberRole(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						Times(0)
+
+					req, _ = http.NewRequest("PUT", "/protected/group/membership/role",
+						strings.NewReader(fmt.Sprintf(`{"group_name":"%s","username":"%s"}`, groupName, username)))
+				})
+
+				It("returns bad request", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "Groupname, username or role isnt specified")
+				})
+			})
+
+			Context("with role", func() {
+				BeforeEach(func() {
+					req, _ = http.NewRequest("PUT", "/protected/group/membership/role",
+						strings.NewReader(fmt.Sprintf(`{"group_name":"%s","username":"%s","role":"admin"}`, groupName, username)))
+				})
+
+				Context("and the role of the current user doesnt allow the change", func() {
+					BeforeEach(func() {
+						uamDAO.EXPECT().
+							ChangeMemberRole(uint(userID), username, groupName, "admin").
+							Return(myerr.NewClientError("Your role [admin] in the group doesnt allow changing the role from [editor] to [admin]"))
+					})
+
+					It("returns bad request", func() {
+						router.ServeHTTP(recorder, req)
+						assertErrorResponse(recorder, http.StatusBadRequest, "doesnt allow changing the role")
+					})
+				})
+
+				Context("and the user isnt a member of the group", func() {
+					BeforeEach(func() {
+						uamDAO.EXPECT().
+							ChangeMemberRole(uint(userID), username, groupName, "admin").
+							Return(myerr.NewItemNotFoundError("Membership not found"))
+					})
+
+					It("returns not found", func() {
+						router.ServeHTTP(recorder, req)
+						assertErrorResponse(recorder, http.StatusNotFound, "Membership not found")
+					})
+				})
+
+				Context("and the role is changed", func() {
+					BeforeEach(func() {
+						uamDAO.EXPECT().
+							ChangeMemberRole(uint(userID), username, groupName, "admin").
+							Return(nil)
+					})
+
+					It("returns status ok", func() {
+						router.ServeHTTP(recorder, req)
+						Expect(recorder.Code).To(Equal(http.StatusOK))
+					})
+				})
+			})
+		})
+	})
+
+	Context("TransferOwnership", func() {
+		When("request for transferring the ownership of a group is sent and authentication passes", func() {
+			Context("without username", func() {
+				BeforeEach(func() {
+					uamDAO.EXPECT().
+						TransferOwnership(gomock.Any(), gomock.Any(), gomock.Any()).
+						Times(0)
+
+					req, _ = http.NewRequest("PUT", "/protected/group/ownership",
+						strings.NewReader(fmt.Sprintf(`{"group_name":"%s"}`, groupName)))
+				})
+
+				It("returns bad request", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "Groupname or username isnt specified")
+				})
+			})
+
+			Context("with username", func() {
+				BeforeEach(func() {
+					req, _ = http.NewRequest("PUT", "/protected/group/ownership",
+						strings.NewReader(fmt.Sprintf(`{"group_name":"%s","username":"%s"}`, groupName, username)))
+				})
+
+				Context("and the user isnt a member of the group", func() {
+					BeforeEach(func() {
+						uamDAO.EXPECT().
+							TransferOwnership(uint(userID), username, groupName).
+							Return(myerr.NewClientError("The ownership can be transferred only to a member of the group"))
+					})
+
+					It("returns bad request", func() {
+						router.ServeHTTP(recorder, req)
+						assertErrorResponse(recorder, http.StatusBadRequest, "only to a member of the group")
+					})
+				})
+
+				Context("and the ownership is transferred", func() {
+					BeforeEach(func() {
+						uamDAO.EXPECT().
+							TransferOwnership(uint(userID), username, groupName).
+							Return(nil)
+					})
+
+					It("returns status ok", func() {
+						router.ServeHTTP(recorder, req)
+						Expect(recorder.Code).To(Equal(http.StatusOK))
+					})
+				})
+			})
+		})
+	})
+
 	Context("RevokeMembership", func() {
 		When("request a user to be added to group is sent and authentication passes", func() {
 			Context("with non-json body", func() {
@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+//reserveLoginAttempt - counts the login attempt with the username and from the client IP as failed until its credentials are confirmed
+//returns TooManyRequestsError if the logins with the username or from the client IP are blocked after failed logins
+func (i *UamEndpointImpl) reserveLoginAttempt(username, ip string) (dao.LoginAttempt, error) {
+	if !i.lockoutPolicy.IsLimited() {
+		return dao.LoginAttempt{}, nil
+	}
+
+	attempt, err := i.loginFailureDAO.ReserveLoginAttempt(username, ip, i.lockoutPolicy)
+	if err != nil {
+		return attempt, myerr.NewServerErrorWrap(err, "Problem with checking the failed logins in the login logic.")
+	}
+
+	if wait := time.Until(attempt.BlockedUntil); wait > 0 {
+		return attempt, myerr.NewTooManyRequestsError(fmt.Sprintf("Too many failed logins. Please try again in %d seconds", int(math.Ceil(wait.Seconds()))), wait)
+	}
+	return attempt, nil
+}
+
+//sendLoginFailure - responds with the error of the failed login, which was already counted, when the attempt was reserved
+//the lockouts, caused by the failed login, are logged for audit
+func (i *UamEndpointImpl) sendLoginFailure(c *gin.Context, username string, attempt dao.LoginAttempt, err error) {
+	for _, lockout := range attempt.Lockouts {
+		log.Printf("Login lockout of %s [%s] until %s after [%d] failed logins. Last attempt with username [%s] from IP [%s]\n",
+			lockout.Kind, lockout.Identifier, lockout.BlockedUntil.Format(time.RFC3339), lockout.Failures, username, common.GetClientIP(c))
+	}
+
+	common.SendErrorResponse(c, err)
+}
+
+//releaseLoginAttempt - stops counting the attempt as failed, after its credentials were confirmed
+//the previous failed logins are still counted until the login is complete
+func (i *UamEndpointImpl) releaseLoginAttempt(attempt dao.LoginAttempt) error {
+	if !i.lockoutPolicy.IsLimited() {
+		return nil
+	}
+
+	if err := i.loginFailureDAO.ReleaseLoginAttempt(attempt); err != nil {
+		return myerr.NewServerErrorWrap(err, "Problem with releasing the login attempt in the login logic.")
+	}
+	return nil
+}
+
+//resetLoginFailures - forgets the failed logins with the username after the login is complete, including the second factor
+func (i *UamEndpointImpl) resetLoginFailures(username string) error {
+	if !i.lockoutPolicy.IsLimited() {
+		return nil
+	}
+
+	if err := i.loginFailureDAO.ResetLoginFailures(username); err != nil {
+		return myerr.NewServerErrorWrap(err, "Problem with resetting the failed logins in the login logic.")
+	}
+	return nil
+}
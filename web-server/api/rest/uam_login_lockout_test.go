@@ -0,0 +1,295 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth/auth_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/validator/validator_mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("Login lockout", func() {
+	var (
+		router          *gin.Engine
+		recorder        *httptest.ResponseRecorder
+		req             *http.Request
+		uamDAO          *dao_mocks.MockUamDAO
+		twoFactorDAO    *dao_mocks.MockTwoFactorDAO
+		loginFailureDAO *dao_mocks.MockLoginFailureDAO
+		tokenDAO        *dao_mocks.MockTokenDAO
+		jwtCreator      *auth_mocks.MockJwtCreator
+		uamRest         *rest.UamEndpointImpl
+		policy          dao.LockoutPolicy
+	)
+
+	const (
+		username = "username"
+		password = "password"
+		clientIP = "10.0.0.1"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		twoFactorDAO = dao_mocks.NewMockTwoFactorDAO(controller)
+		loginFailureDAO = dao_mocks.NewMockLoginFailureDAO(controller)
+		tokenDAO = dao_mocks.NewMockTokenDAO(controller)
+		jwtCreator = auth_mocks.NewMockJwtCreator(controller)
+		policy = dao.LockoutPolicy{
+			MaxUserFailures: 5,
+			MaxIPFailures:   20,
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Minute,
+			LockoutDuration: 15 * time.Minute,
+		}
+		uamRest = rest.NewUamEndPointImpl(uamDAO, tokenDAO, twoFactorDAO, loginFailureDAO, policy,
+			jwtCreator, validator_mocks.NewMockValidator(controller))
+
+		router = setupRouter(uamRest, 1)
+		recorder = httptest.NewRecorder()
+
+		jsonBody, _ := json.Marshal(common.RequestWithCredentials{Username: username, Password: password})
+		req, _ = http.NewRequest("POST", "/public/user/login", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = clientIP + ":40000"
+	})
+
+	When("the logins are blocked", func() {
+		BeforeEach(func() {
+			loginFailureDAO.EXPECT().
+				ReserveLoginAttempt(username, clientIP, policy).
+				Return(dao.LoginAttempt{BlockedUntil: time.Now().Add(30 * time.Second)}, nil)
+
+			uamDAO.EXPECT().
+				GetUser(gomock.Any()).
+				Times(0)
+		})
+
+		It("returns too many requests with the wait", func() {
+			router.ServeHTTP(recorder, req)
+			assertErrorResponse(recorder, http.StatusTooManyRequests, "Too many failed logins")
+			Expect(recorder.Header().Get("Retry-After")).To(Equal("30"))
+		})
+	})
+
+	When("the client sends a spoofed forwarded header", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-Forwarded-For", "1.2.3.4")
+			req.Header.Set("X-Real-Ip", "1.2.3.4")
+
+			loginFailureDAO.EXPECT().
+				ReserveLoginAttempt(username, clientIP, policy).
+				Return(dao.LoginAttempt{BlockedUntil: time.Now().Add(30 * time.Second)}, nil)
+		})
+
+		It("counts the attempt against the address of the connection", func() {
+			router.ServeHTTP(recorder, req)
+			assertErrorResponse(recorder, http.StatusTooManyRequests, "Too many failed logins")
+		})
+	})
+
+	When("the reservation of the attempt fails", func() {
+		BeforeEach(func() {
+			loginFailureDAO.EXPECT().
+				ReserveLoginAttempt(username, clientIP, policy).
+				Return(dao.LoginAttempt{}, myerr.NewServerError("test-error"))
+		})
+
+		It("returns internal server error", func() {
+			router.ServeHTTP(recorder, req)
+			assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server, please try again later")
+		})
+	})
+
+	When("the logins arent blocked", func() {
+		var attempt dao.LoginAttempt
+
+		BeforeEach(func() {
+			attempt = dao.LoginAttempt{Reserved: []models.LoginFailure{{ID: 1, Kind: models.LoginFailureUsername, Identifier: username, Failures: 1}}}
+			loginFailureDAO.EXPECT().
+				ReserveLoginAttempt(username, clientIP, policy).
+				DoAndReturn(func(string, string, dao.LockoutPolicy) (dao.LoginAttempt, error) {
+					return attempt, nil
+				})
+		})
+
+		Context("and the user doesnt exist", func() {
+			BeforeEach(func() {
+				uamDAO.EXPECT().
+					GetUser(username).
+					Return(models.User{}, myerr.NewItemNotFoundError("test-error"))
+
+				loginFailureDAO.EXPECT().
+					ReleaseLoginAttempt(gomock.Any()).
+					Times(0)
+			})
+
+			It("keeps the attempt counted as failed", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid credentials")
+			})
+		})
+
+		Context("and the password is wrong", func() {
+			BeforeEach(func() {
+				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("other-password"), bcrypt.MinCost)
+				uamDAO.EXPECT().
+					GetUser(username).
+					Return(models.User{Username: username, Password: string(hashedPassword)}, nil)
+
+				attempt.Lockouts = []models.LoginFailure{{Kind: models.LoginFailureUsername, Identifier: username, Failures: 5, BlockedUntil: time.Now().Add(policy.LockoutDuration)}}
+				loginFailureDAO.EXPECT().
+					ReleaseLoginAttempt(gomock.Any()).
+					Times(0)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid credentials")
+			})
+		})
+
+		Context("and the password is correct", func() {
+			var user models.User
+
+			BeforeEach(func() {
+				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+				user = models.User{ID: 1, Username: username, Password: string(hashedPassword)}
+				uamDAO.EXPECT().
+					GetUser(username).
+					Return(user, nil)
+
+				loginFailureDAO.EXPECT().
+					ReleaseLoginAttempt(attempt).
+					Return(nil)
+			})
+
+			Context("and the second factor is required", func() {
+				BeforeEach(func() {
+					twoFactorDAO.EXPECT().
+						GetTwoFactor(user.ID).
+						Return(models.TwoFactor{UserID: user.ID, Enabled: true}, nil)
+
+					twoFactorDAO.EXPECT().
+						CreateLoginChallenge(user.ID, gomock.Any(), gomock.Any()).
+						Return(nil)
+
+					loginFailureDAO.EXPECT().
+						ResetLoginFailures(gomock.Any()).
+						Times(0)
+				})
+
+				It("keeps the previous failed logins until the login is complete", func() {
+					router.ServeHTTP(recorder, req)
+					Expect(recorder.Code).To(Equal(http.StatusCreated))
+				})
+			})
+
+			Context("and the second factor isnt required", func() {
+				BeforeEach(func() {
+					twoFactorDAO.EXPECT().
+						GetTwoFactor(user.ID).
+						Return(models.TwoFactor{}, myerr.NewItemNotFoundError("test-error"))
+
+					refreshToken := auth.RefreshToken{Token: "refresh-token", Hash: "refresh-token-hash", ExpiresAt: time.Now().Add(time.Hour)}
+					gomock.InOrder(
+						jwtCreator.EXPECT().
+							GenerateRefreshToken().
+							Return(refreshToken, nil),
+						tokenDAO.EXPECT().
+							CreateRefreshToken(user.ID, refreshToken.Hash, refreshToken.ExpiresAt).
+							Return(nil),
+						jwtCreator.EXPECT().
+							GenerateToken(user.ID).
+							Return("token", nil),
+						loginFailureDAO.EXPECT().
+							ResetLoginFailures(username).
+							Return(nil),
+					)
+				})
+
+				It("resets the failed logins with the username after the tokens are issued", func() {
+					router.ServeHTTP(recorder, req)
+					Expect(recorder.Code).To(Equal(http.StatusCreated))
+				})
+			})
+		})
+	})
+
+	When("the second factor is verified", func() {
+		const challenge = "challenge"
+
+		BeforeEach(func() {
+			router = setupRouterTwoFactor(uamRest, 1)
+			jsonBody, _ := json.Marshal(common.LoginVerificationPayload{
+				TwoFactorCodePayload: common.TwoFactorCodePayload{Code: "wrong"},
+				Challenge:            challenge,
+			})
+			req, _ = http.NewRequest("POST", "/public/user/login/verification", bytes.NewBuffer(jsonBody))
+			req.RemoteAddr = clientIP + ":40000"
+
+			twoFactorDAO.EXPECT().
+				UseLoginChallenge(auth.HashRefreshToken(challenge), uint(5)).
+				Return(models.LoginChallenge{ID: 3, UserID: 1}, nil)
+			uamDAO.EXPECT().
+				GetUserByID(uint(1)).
+				Return(models.User{ID: 1, Username: username}, nil)
+		})
+
+		Context("and the logins are blocked", func() {
+			BeforeEach(func() {
+				loginFailureDAO.EXPECT().
+					ReserveLoginAttempt(username, clientIP, policy).
+					Return(dao.LoginAttempt{BlockedUntil: time.Now().Add(30 * time.Second)}, nil)
+
+				twoFactorDAO.EXPECT().
+					GetTwoFactor(gomock.Any()).
+					Times(0)
+			})
+
+			It("returns too many requests without checking the code", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusTooManyRequests, "Too many failed logins")
+			})
+		})
+
+		Context("and the code is wrong", func() {
+			BeforeEach(func() {
+				loginFailureDAO.EXPECT().
+					ReserveLoginAttempt(username, clientIP, policy).
+					Return(dao.LoginAttempt{Reserved: []models.LoginFailure{{ID: 1, Failures: 2}}}, nil)
+
+				twoFactorDAO.EXPECT().
+					GetTwoFactor(uint(1)).
+					Return(models.TwoFactor{UserID: 1, Secret: "secret", Enabled: true}, nil)
+				twoFactorDAO.EXPECT().
+					UseRecoveryCode(uint(1), auth.HashRecoveryCode("wrong")).
+					Return(false, nil)
+
+				loginFailureDAO.EXPECT().
+					ReleaseLoginAttempt(gomock.Any()).
+					Times(0)
+			})
+
+			It("counts the code as a failed login", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid code")
+			})
+		})
+	})
+})
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+//ChangePassword - handler for changing the password of the current user, after the current password is confirmed
+//all tokens of the user are invalidated and a new pair of tokens is issued
+//wrong current passwords are counted as failed logins, so a stolen access token cannot be used for guessing the password
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid or the current password is wrong
+//returns 404 if the user doesnt exist
+//returns 429 if there were too many failed logins with the username or from the client IP
+//returns 201 and the new tokens if the password was changed
+func (i *UamEndpointImpl) ChangePassword(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.ChangePasswordPayload
+	if err = c.ShouldBindJSON(&rq); err != nil || rq.Password == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	hashedPassword, err := i.hashNewPassword(rq.NewPassword)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	user, err := i.uamDAO.GetUserByID(userID)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with changing the password")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	attempt, err := i.reserveLoginAttempt(user.Username, common.GetClientIP(c))
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	confirmPassword := func(user models.User) error {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(rq.Password)); err != nil {
+			return myerr.NewClientError("Invalid password")
+		}
+		return i.releaseLoginAttempt(attempt)
+	}
+
+	if err = i.tokenDAO.ChangePassword(userID, hashedPassword, confirmPassword); err != nil {
+		if _, ok := err.(*myerr.ClientError); ok {
+			i.sendLoginFailure(c, user.Username, attempt, err)
+			return
+		} else if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with changing the password")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	i.sendTokens(c, userID)
+}
+
+//ResetPassword - handler for setting a new password with a one-time reset token, issued by an administrator
+//all tokens of the user are invalidated, so the user has to login again
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid or the reset token is invalid or expired
+//returns 200 if the password was reset
+func (i *UamEndpointImpl) ResetPassword(c *gin.Context) {
+	var rq common.ResetPasswordPayload
+	if err := c.ShouldBindJSON(&rq); err != nil || rq.Token == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	hashedPassword, err := i.hashNewPassword(rq.NewPassword)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	if err = i.tokenDAO.ResetPassword(auth.HashRefreshToken(rq.Token), hashedPassword); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with resetting the password")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//hashNewPassword - validates the new password in the same way as during the registration and hashes it
+func (i *UamEndpointImpl) hashNewPassword(password string) (string, error) {
+	if err := i.validator.ValidatePassword(password); err != nil {
+		return "", myerr.NewClientErrorWrap(err, "Problem with the new password")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", myerr.NewServerErrorWrap(err, "Problem encryption of the new password.")
+	}
+	return string(hashedPassword), nil
+}
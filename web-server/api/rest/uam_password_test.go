@@ -0,0 +1,273 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth/auth_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/validator/validator_mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupRouterPassword(uamRest rest.UamEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	r.PUT("/public/user/password/reset", uamRest.ResetPassword)
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.POST("/user/password", uamRest.ChangePassword)
+	}
+	return r
+}
+
+var _ = Describe("Passwords", func() {
+	var (
+		router          *gin.Engine
+		recorder        *httptest.ResponseRecorder
+		uamDAO          *dao_mocks.MockUamDAO
+		tokenDAO        *dao_mocks.MockTokenDAO
+		loginFailureDAO *dao_mocks.MockLoginFailureDAO
+		jwtCreator      *auth_mocks.MockJwtCreator
+		validator       *validator_mocks.MockValidator
+		policy          dao.LockoutPolicy
+		req             *http.Request
+	)
+
+	const (
+		userID      = 1
+		username    = "username"
+		clientIP    = "10.0.0.1"
+		password    = "password"
+		newPassword = "new-password"
+		resetToken  = "reset-token"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		tokenDAO = dao_mocks.NewMockTokenDAO(controller)
+		loginFailureDAO = dao_mocks.NewMockLoginFailureDAO(controller)
+		jwtCreator = auth_mocks.NewMockJwtCreator(controller)
+		validator = validator_mocks.NewMockValidator(controller)
+		policy = dao.LockoutPolicy{
+			MaxUserFailures: 5,
+			MaxIPFailures:   20,
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Minute,
+			LockoutDuration: 15 * time.Minute,
+		}
+		uamRest := rest.NewUamEndPointImpl(uamDAO, tokenDAO, dao_mocks.NewMockTwoFactorDAO(controller), loginFailureDAO, policy,
+			jwtCreator, validator)
+
+		router = setupRouterPassword(uamRest, userID)
+		recorder = httptest.NewRecorder()
+	})
+
+	Context("ChangePassword", func() {
+		var reqBody common.ChangePasswordPayload
+
+		BeforeEach(func() {
+			reqBody = common.ChangePasswordPayload{
+				Password:    password,
+				NewPassword: newPassword,
+			}
+		})
+
+		JustBeforeEach(func() {
+			body, _ := json.Marshal(reqBody)
+			req, _ = http.NewRequest("POST", "/protected/user/password", strings.NewReader(string(body)))
+			req.RemoteAddr = clientIP + ":40000"
+		})
+
+		When("the new password is invalid", func() {
+			BeforeEach(func() {
+				validator.EXPECT().
+					ValidatePassword(newPassword).
+					Return(errors.New("too short"))
+
+				tokenDAO.EXPECT().
+					ChangePassword(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Problem with the new password")
+			})
+		})
+
+		When("the new password is valid", func() {
+			BeforeEach(func() {
+				validator.EXPECT().
+					ValidatePassword(newPassword).
+					Return(nil)
+
+				uamDAO.EXPECT().
+					GetUserByID(uint(userID)).
+					Return(models.User{ID: userID, Username: username}, nil)
+			})
+
+			Context("and the password checks are blocked after failed logins", func() {
+				BeforeEach(func() {
+					loginFailureDAO.EXPECT().
+						ReserveLoginAttempt(username, clientIP, policy).
+						Return(dao.LoginAttempt{BlockedUntil: time.Now().Add(30 * time.Second)}, nil)
+
+					tokenDAO.EXPECT().
+						ChangePassword(gomock.Any(), gomock.Any(), gomock.Any()).
+						Times(0)
+				})
+
+				It("returns too many requests", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusTooManyRequests, "Too many failed logins")
+				})
+			})
+
+			Context("and the current password is wrong", func() {
+				BeforeEach(func() {
+					loginFailureDAO.EXPECT().
+						ReserveLoginAttempt(username, clientIP, policy).
+						Return(dao.LoginAttempt{Reserved: []models.LoginFailure{{ID: 1, Failures: 1}}}, nil)
+
+					loginFailureDAO.EXPECT().
+						ReleaseLoginAttempt(gomock.Any()).
+						Times(0)
+
+					hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("other-password"), bcrypt.MinCost)
+					tokenDAO.EXPECT().
+						ChangePassword(uint(userID), gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ uint, _ string, confirm func(models.User) error) error {
+							return confirm(models.User{Password: string(hashedPassword)})
+						})
+				})
+
+				It("returns bad request", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "Invalid password")
+				})
+			})
+
+			Context("and the current password is confirmed", func() {
+				BeforeEach(func() {
+					attempt := dao.LoginAttempt{Reserved: []models.LoginFailure{{ID: 1, Failures: 1}}}
+					loginFailureDAO.EXPECT().
+						ReserveLoginAttempt(username, clientIP, policy).
+						Return(attempt, nil)
+
+					loginFailureDAO.EXPECT().
+						ReleaseLoginAttempt(attempt).
+						Return(nil)
+
+					hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+					tokenDAO.EXPECT().
+						ChangePassword(uint(userID), gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ uint, passwordHash string, confirm func(models.User) error) error {
+							Expect(bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(newPassword))).To(Succeed())
+							return confirm(models.User{Password: string(hashedPassword)})
+						})
+
+					refreshToken := auth.RefreshToken{Token: "refresh-token", Hash: "refresh-hash", ExpiresAt: time.Now().Add(time.Hour)}
+					jwtCreator.EXPECT().
+						GenerateRefreshToken().
+						Return(refreshToken, nil)
+					tokenDAO.EXPECT().
+						CreateRefreshToken(uint(userID), refreshToken.Hash, refreshToken.ExpiresAt).
+						Return(nil)
+					jwtCreator.EXPECT().
+						GenerateToken(uint(userID)).
+						Return("access-token", nil)
+				})
+
+				It("returns new tokens", func() {
+					router.ServeHTTP(recorder, req)
+
+					Expect(recorder.Code).To(Equal(http.StatusCreated))
+					body := common.LoginResponse{}
+					json.Unmarshal(recorder.Body.Bytes(), &body)
+					Expect(body.Token).To(Equal("access-token"))
+					Expect(body.RefreshToken).To(Equal("refresh-token"))
+				})
+			})
+		})
+	})
+
+	Context("ResetPassword", func() {
+		var reqBody common.ResetPasswordPayload
+
+		BeforeEach(func() {
+			reqBody = common.ResetPasswordPayload{
+				Token:       resetToken,
+				NewPassword: newPassword,
+			}
+		})
+
+		JustBeforeEach(func() {
+			body, _ := json.Marshal(reqBody)
+			req, _ = http.NewRequest("PUT", "/public/user/password/reset", strings.NewReader(string(body)))
+		})
+
+		When("the token is missing", func() {
+			BeforeEach(func() {
+				reqBody.Token = ""
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid json body")
+			})
+		})
+
+		When("the token is invalid", func() {
+			BeforeEach(func() {
+				validator.EXPECT().
+					ValidatePassword(newPassword).
+					Return(nil)
+
+				tokenDAO.EXPECT().
+					ResetPassword(auth.HashRefreshToken(resetToken), gomock.Any()).
+					Return(myerr.NewClientError("Invalid password reset token"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid password reset token")
+			})
+		})
+
+		When("the token is valid", func() {
+			BeforeEach(func() {
+				validator.EXPECT().
+					ValidatePassword(newPassword).
+					Return(nil)
+
+				tokenDAO.EXPECT().
+					ResetPassword(auth.HashRefreshToken(resetToken), gomock.Any()).
+					Return(nil)
+			})
+
+			It("resets the password", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+})
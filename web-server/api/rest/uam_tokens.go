@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	//maxTokenNameLength - the maximum length of the name of a personal access token
+	maxTokenNameLength = 64
+	//maxTokenExpirationDays - the maximum number of days, for which a personal access token is valid
+	maxTokenExpirationDays = 365
+)
+
+//CreatePersonalAccessToken - handler for creation of a personal access token, which is used by scripts instead of a password
+//the token is shown only in the response, while the server stores only its hash
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid, there is a token with the same name or the user isnt a member of some of the groups
+//returns 201 and the token if it was successfully created
+func (i *UamEndpointImpl) CreatePersonalAccessToken(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.PersonalAccessTokenPayload
+	if err = c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	} else if rq.Name == "" || len(rq.Name) > maxTokenNameLength {
+		common.SendErrorResponse(c, myerr.NewClientError(fmt.Sprintf("The name of the token should be between 1 and %d symbols", maxTokenNameLength)))
+		return
+	} else if rq.ExpiresInDays == 0 || rq.ExpiresInDays > maxTokenExpirationDays {
+		common.SendErrorResponse(c, myerr.NewClientError(fmt.Sprintf("The token should expire in 1 to %d days", maxTokenExpirationDays)))
+		return
+	}
+
+	token, tokenHash, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with the generation of personal access token."))
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(rq.ExpiresInDays) * 24 * time.Hour)
+	tokenID, err := i.tokenDAO.CreatePersonalAccessToken(userID, rq.Name, tokenHash, rq.Scopes, rq.Groups, expiresAt)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with creation of personal access token.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, common.PersonalAccessTokenResponse{
+		Status: http.StatusCreated,
+		ID:     tokenID,
+		Token:  token,
+	})
+}
+
+//GetPersonalAccessTokens - handler for fetching the personal access tokens of the current user
+//returns 500, if error occurrs due to system failure
+//returns 200 otherwise
+func (i *UamEndpointImpl) GetPersonalAccessTokens(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	tokens, err := i.tokenDAO.GetPersonalAccessTokens(userID)
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with fetching the personal access tokens."))
+		return
+	}
+
+	tokensInfo := make([]common.PersonalAccessTokenInfo, 0, len(tokens))
+	for _, token := range tokens {
+		tokensInfo = append(tokensInfo, common.PersonalAccessTokenInfo{
+			ID:         token.ID,
+			Name:       token.Name,
+			Scopes:     token.ScopeList,
+			Groups:     token.Groups,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			LastUsedAt: token.LastUsedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": http.StatusOK,
+		"tokens": tokensInfo,
+	})
+}
+
+//DeletePersonalAccessToken - handler for deletion of a personal access token, after which it can no longer be used
+//returns 500, if error occurrs due to system failure
+//returns 400 if the user input was invalid
+//returns 404 if the user doesnt have such token
+//returns 200 if the token was successfully deleted
+func (i *UamEndpointImpl) DeletePersonalAccessToken(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.PersonalAccessTokenRequestPayload
+	if err = c.ShouldBindJSON(&rq); err != nil {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	if err = i.tokenDAO.DeletePersonalAccessToken(userID, rq.TokenID); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with deletion of personal access token.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
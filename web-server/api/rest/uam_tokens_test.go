@@ -0,0 +1,209 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth/auth_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/validator/validator_mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterTokens(uamRest rest.UamEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.POST("/user/tokens", uamRest.CreatePersonalAccessToken)
+		protected.GET("/user/tokens", uamRest.GetPersonalAccessTokens)
+		protected.DELETE("/user/tokens", uamRest.DeletePersonalAccessToken)
+	}
+	return r
+}
+
+var _ = Describe("PersonalAccessTokens", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		tokenDAO *dao_mocks.MockTokenDAO
+		req      *http.Request
+	)
+
+	const (
+		userID    = 1
+		tokenID   = 4
+		tokenName = "ci"
+	)
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		tokenDAO = dao_mocks.NewMockTokenDAO(controller)
+		uamRest := rest.NewUamEndPointImpl(dao_mocks.NewMockUamDAO(controller), tokenDAO, dao_mocks.NewMockTwoFactorDAO(controller), dao_mocks.NewMockLoginFailureDAO(controller), dao.LockoutPolicy{},
+			auth_mocks.NewMockJwtCreator(controller), validator_mocks.NewMockValidator(controller))
+
+		router = setupRouterTokens(uamRest, userID)
+		recorder = httptest.NewRecorder()
+	})
+
+	Context("CreatePersonalAccessToken", func() {
+		var reqBody common.PersonalAccessTokenPayload
+
+		BeforeEach(func() {
+			reqBody = common.PersonalAccessTokenPayload{
+				Name:          tokenName,
+				Scopes:        []string{models.ScopeRead},
+				Groups:        []string{"first"},
+				ExpiresInDays: 30,
+			}
+		})
+
+		JustBeforeEach(func() {
+			body, _ := json.Marshal(reqBody)
+			req, _ = http.NewRequest("POST", "/protected/user/tokens", strings.NewReader(string(body)))
+		})
+
+		When("the expiration isnt specified", func() {
+			BeforeEach(func() {
+				reqBody.ExpiresInDays = 0
+				tokenDAO.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "The token should expire in 1 to 365 days")
+			})
+		})
+
+		When("the name is missing", func() {
+			BeforeEach(func() {
+				reqBody.Name = ""
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "The name of the token should be between 1 and 64 symbols")
+			})
+		})
+
+		When("the token cannot be created", func() {
+			BeforeEach(func() {
+				tokenDAO.EXPECT().
+					CreatePersonalAccessToken(uint(userID), tokenName, gomock.Any(), reqBody.Scopes, reqBody.Groups, gomock.Any()).
+					Return(uint(0), myerr.NewClientError("Token with name [ci] already exists"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Token with name [ci] already exists")
+			})
+		})
+
+		When("the token is created", func() {
+			var tokenHash string
+
+			BeforeEach(func() {
+				tokenDAO.EXPECT().
+					CreatePersonalAccessToken(uint(userID), tokenName, gomock.Any(), reqBody.Scopes, reqBody.Groups, gomock.Any()).
+					DoAndReturn(func(_ uint, _ string, hash string, _ []string, _ []string, expiresAt time.Time) (uint, error) {
+						tokenHash = hash
+						Expect(expiresAt).To(BeTemporally("~", time.Now().Add(30*24*time.Hour), time.Minute))
+						return tokenID, nil
+					})
+			})
+
+			It("returns the token, whose hash is stored", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+				var response common.PersonalAccessTokenResponse
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.ID).To(Equal(uint(tokenID)))
+				Expect(auth.IsPersonalAccessToken(response.Token)).To(BeTrue())
+				Expect(auth.HashRefreshToken(response.Token)).To(Equal(tokenHash))
+			})
+		})
+	})
+
+	Context("GetPersonalAccessTokens", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", "/protected/user/tokens", nil)
+		})
+
+		When("the tokens are fetched", func() {
+			BeforeEach(func() {
+				token := dao.PersonalAccessTokenInfo{
+					PersonalAccessToken: models.PersonalAccessToken{ID: tokenID, Name: tokenName, ExpiresAt: time.Now()},
+					ScopeList:           []string{models.ScopeRead},
+					Groups:              []string{"first"},
+				}
+				tokenDAO.EXPECT().
+					GetPersonalAccessTokens(uint(userID)).
+					Return([]dao.PersonalAccessTokenInfo{token}, nil)
+			})
+
+			It("returns the tokens without their values", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+
+				var response struct {
+					Tokens []common.PersonalAccessTokenInfo `json:"tokens"`
+				}
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				Expect(response.Tokens).To(HaveLen(1))
+				Expect(response.Tokens[0].Name).To(Equal(tokenName))
+				Expect(response.Tokens[0].Scopes).To(Equal([]string{models.ScopeRead}))
+				Expect(response.Tokens[0].Groups).To(Equal([]string{"first"}))
+			})
+		})
+	})
+
+	Context("DeletePersonalAccessToken", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("DELETE", "/protected/user/tokens", strings.NewReader(`{"token_id":4}`))
+		})
+
+		When("the token doesnt exist", func() {
+			BeforeEach(func() {
+				tokenDAO.EXPECT().
+					DeletePersonalAccessToken(uint(userID), uint(tokenID)).
+					Return(myerr.NewItemNotFoundError("Token with id [4] doesnt exist"))
+			})
+
+			It("returns not found", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusNotFound, "Token with id [4] doesnt exist")
+			})
+		})
+
+		When("the token is deleted", func() {
+			BeforeEach(func() {
+				tokenDAO.EXPECT().
+					DeletePersonalAccessToken(uint(userID), uint(tokenID)).
+					Return(nil)
+			})
+
+			It("returns success", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+})
@@ -0,0 +1,245 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	//loginChallengeExpiration - how long the user has for entering the TOTP code after the password was confirmed
+	loginChallengeExpiration = 5 * time.Minute
+	//maxLoginChallengeAttempts - how many codes can be checked with a single login challenge
+	maxLoginChallengeAttempts = 5
+)
+
+//VerifyLogin - handler for completing the login of a user with two-factor authentication
+//the challenge from the login is completed with a TOTP code or a recovery code
+//the wrong codes are counted as failed logins with the username, like the wrong passwords
+//returns 500, if error occurrs due to system failure
+//returns 400 if the challenge is invalid or expired or the code is wrong
+//returns 429 if there were too many failed logins with the username or from the client IP
+//returns 201 if the login was successfull
+func (i *UamEndpointImpl) VerifyLogin(c *gin.Context) {
+	var rq common.LoginVerificationPayload
+	if err := c.ShouldBindJSON(&rq); err != nil || rq.Challenge == "" || rq.Code == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	challenge, err := i.twoFactorDAO.UseLoginChallenge(auth.HashRefreshToken(rq.Challenge), maxLoginChallengeAttempts)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with the verification of the login.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	user, err := i.uamDAO.GetUserByID(challenge.UserID)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with the verification of the login.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	attempt, err := i.reserveLoginAttempt(user.Username, common.GetClientIP(c))
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	if err = i.verifySecondFactor(user.ID, rq.Code); err != nil {
+		if _, ok := err.(*myerr.ClientError); ok {
+			i.sendLoginFailure(c, user.Username, attempt, err)
+		} else {
+			common.SendErrorResponse(c, err)
+		}
+		return
+	}
+
+	if err = i.releaseLoginAttempt(attempt); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	if err = i.twoFactorDAO.DeleteLoginChallenge(challenge.ID); err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with the verification of the login."))
+		return
+	}
+
+	i.completeLogin(c, user)
+}
+
+//EnrollTwoFactor - handler for starting the enrollment of two-factor authentication
+//the secret is added to an authenticator app and the enrollment is confirmed with ConfirmTwoFactor
+//returns 500, if error occurrs due to system failure
+//returns 400 if the two-factor authentication is already enabled
+//returns 201 and the secret together with its otpauth URI
+func (i *UamEndpointImpl) EnrollTwoFactor(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with the enrollment of two-factor authentication."))
+		return
+	}
+
+	user, err := i.twoFactorDAO.EnrollTwoFactor(userID, secret)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with the enrollment of two-factor authentication.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, common.TwoFactorEnrollmentResponse{
+		Status: http.StatusCreated,
+		Secret: secret,
+		URI:    auth.TOTPURI(user.Username, secret),
+	})
+}
+
+//ConfirmTwoFactor - handler for confirming the enrollment of two-factor authentication with a TOTP code
+//after the confirmation a code is required at every login
+//returns 500, if error occurrs due to system failure
+//returns 400 if the code is wrong or the two-factor authentication is already enabled
+//returns 404 if the two-factor authentication isnt enrolled
+//returns 201 and the recovery codes, which are shown only once
+func (i *UamEndpointImpl) ConfirmTwoFactor(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.TwoFactorCodePayload
+	if err = c.ShouldBindJSON(&rq); err != nil || rq.Code == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	twoFactor, err := i.twoFactorDAO.GetTwoFactor(userID)
+	if err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with the confirmation of two-factor authentication.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	} else if twoFactor.Enabled {
+		common.SendErrorResponse(c, myerr.NewClientError("Two-factor authentication is already enabled"))
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(twoFactor.Secret, rq.Code, time.Now())
+	if !ok {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid code"))
+		return
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with the confirmation of two-factor authentication."))
+		return
+	}
+
+	if err = i.twoFactorDAO.EnableTwoFactor(userID, step, recoveryCodeHashes); err != nil {
+		if _, ok := err.(*myerr.ServerError); ok {
+			err = myerr.NewServerErrorWrap(err, "Problem with the confirmation of two-factor authentication.")
+		}
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, common.RecoveryCodesResponse{
+		Status:        http.StatusCreated,
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+//DisableTwoFactor - handler for disabling the two-factor authentication, which has to be confirmed with a TOTP code or a recovery code
+//returns 500, if error occurrs due to system failure
+//returns 400 if the code is wrong or the two-factor authentication isnt enabled
+//returns 200 if the two-factor authentication was disabled
+func (i *UamEndpointImpl) DisableTwoFactor(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	var rq common.TwoFactorCodePayload
+	if err = c.ShouldBindJSON(&rq); err != nil || rq.Code == "" {
+		common.SendErrorResponse(c, myerr.NewClientError("Invalid json body"))
+		return
+	}
+
+	if err = i.verifySecondFactor(userID, rq.Code); err != nil {
+		common.SendErrorResponse(c, err)
+		return
+	}
+
+	if err = i.twoFactorDAO.DisableTwoFactor(userID); err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with disabling the two-factor authentication."))
+		return
+	}
+
+	c.JSON(http.StatusOK, common.BasicResponse{
+		Status: http.StatusOK,
+	})
+}
+
+//sendLoginChallenge - issues a challenge to the user, whose password was confirmed, which is completed with a TOTP code
+func (i *UamEndpointImpl) sendLoginChallenge(c *gin.Context, userID uint) {
+	challenge, challengeHash, err := auth.GenerateLoginChallenge()
+	if err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with generating login challenge in the login logic."))
+		return
+	}
+
+	if err = i.twoFactorDAO.CreateLoginChallenge(userID, challengeHash, time.Now().Add(loginChallengeExpiration)); err != nil {
+		common.SendErrorResponse(c, myerr.NewServerErrorWrap(err, "Problem with storing login challenge in the login logic."))
+		return
+	}
+
+	c.JSON(http.StatusCreated, common.LoginResponse{
+		Status:            http.StatusCreated,
+		TwoFactorRequired: true,
+		Challenge:         challenge,
+	})
+}
+
+//verifySecondFactor - checks the TOTP code or the recovery code of the user. Every code can be used only once
+func (i *UamEndpointImpl) verifySecondFactor(userID uint, code string) error {
+	twoFactor, err := i.twoFactorDAO.GetTwoFactor(userID)
+	if _, ok := err.(*myerr.ItemNotFoundError); ok || (err == nil && !twoFactor.Enabled) {
+		return myerr.NewClientError("Two-factor authentication isnt enabled")
+	} else if err != nil {
+		return myerr.NewServerErrorWrap(err, "Problem with the verification of the code.")
+	}
+
+	var used bool
+	if step, ok := auth.ValidateTOTP(twoFactor.Secret, code, time.Now()); ok {
+		used, err = i.twoFactorDAO.UseTOTPStep(userID, step)
+	} else {
+		used, err = i.twoFactorDAO.UseRecoveryCode(userID, auth.HashRecoveryCode(code))
+	}
+
+	if err != nil {
+		return myerr.NewServerErrorWrap(err, "Problem with the verification of the code.")
+	} else if !used {
+		return myerr.NewClientError("Invalid code")
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/api/rest"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth/auth_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/validator/validator_mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func setupRouterTwoFactor(uamRest rest.UamEndpoint, userID uint) *gin.Engine {
+	r := gin.Default()
+
+	r.POST("/public/user/login/verification", uamRest.VerifyLogin)
+	protected := r.Group("/protected").Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	{
+		protected.POST("/user/2fa/enrollment", uamRest.EnrollTwoFactor)
+		protected.POST("/user/2fa/confirmation", uamRest.ConfirmTwoFactor)
+		protected.DELETE("/user/2fa", uamRest.DisableTwoFactor)
+	}
+	return r
+}
+
+var _ = Describe("TwoFactor", func() {
+	var (
+		router       *gin.Engine
+		recorder     *httptest.ResponseRecorder
+		uamDAO       *dao_mocks.MockUamDAO
+		twoFactorDAO *dao_mocks.MockTwoFactorDAO
+		tokenDAO     *dao_mocks.MockTokenDAO
+		jwtCreator   *auth_mocks.MockJwtCreator
+		req          *http.Request
+		secret       string
+	)
+
+	const (
+		userID      = 1
+		challengeID = 3
+		challenge   = "challenge"
+	)
+
+	currentCode := func() string {
+		code, err := auth.TOTPCode(secret, time.Now().Unix()/30)
+		Expect(err).NotTo(HaveOccurred())
+		return code
+	}
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		uamDAO = dao_mocks.NewMockUamDAO(controller)
+		twoFactorDAO = dao_mocks.NewMockTwoFactorDAO(controller)
+		tokenDAO = dao_mocks.NewMockTokenDAO(controller)
+		jwtCreator = auth_mocks.NewMockJwtCreator(controller)
+		uamRest := rest.NewUamEndPointImpl(uamDAO, tokenDAO, twoFactorDAO, dao_mocks.NewMockLoginFailureDAO(controller), dao.LockoutPolicy{},
+			jwtCreator, validator_mocks.NewMockValidator(controller))
+
+		router = setupRouterTwoFactor(uamRest, userID)
+		recorder = httptest.NewRecorder()
+		secret, _ = auth.GenerateTOTPSecret()
+	})
+
+	Context("VerifyLogin", func() {
+		var code string
+
+		JustBeforeEach(func() {
+			body, _ := json.Marshal(common.LoginVerificationPayload{
+				TwoFactorCodePayload: common.TwoFactorCodePayload{Code: code},
+				Challenge:            challenge,
+			})
+			req, _ = http.NewRequest("POST", "/public/user/login/verification", strings.NewReader(string(body)))
+		})
+
+		When("the challenge is invalid", func() {
+			BeforeEach(func() {
+				code = "123456"
+				twoFactorDAO.EXPECT().
+					UseLoginChallenge(auth.HashRefreshToken(challenge), uint(5)).
+					Return(models.LoginChallenge{}, myerr.NewClientError("Invalid or expired login challenge"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid or expired login challenge")
+			})
+		})
+
+		When("the challenge is valid", func() {
+			BeforeEach(func() {
+				twoFactorDAO.EXPECT().
+					UseLoginChallenge(auth.HashRefreshToken(challenge), uint(5)).
+					Return(models.LoginChallenge{ID: challengeID, UserID: userID}, nil)
+				uamDAO.EXPECT().
+					GetUserByID(uint(userID)).
+					Return(models.User{ID: userID, Username: "username"}, nil)
+				twoFactorDAO.EXPECT().
+					GetTwoFactor(uint(userID)).
+					Return(models.TwoFactor{UserID: userID, Secret: secret, Enabled: true}, nil)
+			})
+
+			Context("and the code is wrong", func() {
+				BeforeEach(func() {
+					code = "wrong"
+					twoFactorDAO.EXPECT().
+						UseRecoveryCode(uint(userID), auth.HashRecoveryCode(code)).
+						Return(false, nil)
+				})
+
+				It("returns bad request", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "Invalid code")
+				})
+			})
+
+			Context("and the TOTP code was already used", func() {
+				BeforeEach(func() {
+					code = currentCode()
+					twoFactorDAO.EXPECT().
+						UseTOTPStep(uint(userID), gomock.Any()).
+						Return(false, nil)
+				})
+
+				It("returns bad request", func() {
+					router.ServeHTTP(recorder, req)
+					assertErrorResponse(recorder, http.StatusBadRequest, "Invalid code")
+				})
+			})
+
+			Context("and the TOTP code is correct", func() {
+				refreshToken := auth.RefreshToken{Token: "refresh-token", Hash: "refresh-token-hash", ExpiresAt: time.Now().Add(time.Hour)}
+
+				BeforeEach(func() {
+					code = currentCode()
+					gomock.InOrder(
+						twoFactorDAO.EXPECT().
+							UseTOTPStep(uint(userID), gomock.Any()).
+							Return(true, nil),
+						twoFactorDAO.EXPECT().
+							DeleteLoginChallenge(uint(challengeID)).
+							Return(nil),
+						jwtCreator.EXPECT().
+							GenerateRefreshToken().
+							Return(refreshToken, nil),
+						tokenDAO.EXPECT().
+							CreateRefreshToken(uint(userID), refreshToken.Hash, refreshToken.ExpiresAt).
+							Return(nil),
+						jwtCreator.EXPECT().
+							GenerateToken(uint(userID)).
+							Return("token", nil),
+					)
+				})
+
+				It("returns the tokens", func() {
+					router.ServeHTTP(recorder, req)
+
+					Expect(recorder.Code).To(Equal(http.StatusCreated))
+					body := common.LoginResponse{}
+					json.Unmarshal(recorder.Body.Bytes(), &body)
+					Expect(body.Token).To(Equal("token"))
+					Expect(body.RefreshToken).To(Equal(refreshToken.Token))
+				})
+			})
+		})
+	})
+
+	Context("EnrollTwoFactor", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("POST", "/protected/user/2fa/enrollment", nil)
+		})
+
+		When("the two-factor authentication is already enabled", func() {
+			BeforeEach(func() {
+				twoFactorDAO.EXPECT().
+					EnrollTwoFactor(uint(userID), gomock.Any()).
+					Return(models.User{}, myerr.NewClientError("Two-factor authentication is already enabled"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Two-factor authentication is already enabled")
+			})
+		})
+
+		When("the secret is stored", func() {
+			BeforeEach(func() {
+				twoFactorDAO.EXPECT().
+					EnrollTwoFactor(uint(userID), gomock.Any()).
+					Return(models.User{ID: userID, Username: "username"}, nil)
+			})
+
+			It("returns the secret and its otpauth URI", func() {
+				router.ServeHTTP(recorder, req)
+
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+				body := common.TwoFactorEnrollmentResponse{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.Secret).NotTo(BeEmpty())
+				Expect(body.URI).To(HavePrefix("otpauth://totp/UShare:username?"))
+				Expect(body.URI).To(ContainSubstring("secret=" + body.Secret))
+			})
+		})
+	})
+
+	Context("ConfirmTwoFactor", func() {
+		var code string
+
+		JustBeforeEach(func() {
+			body, _ := json.Marshal(common.TwoFactorCodePayload{Code: code})
+			req, _ = http.NewRequest("POST", "/protected/user/2fa/confirmation", strings.NewReader(string(body)))
+		})
+
+		BeforeEach(func() {
+			twoFactorDAO.EXPECT().
+				GetTwoFactor(uint(userID)).
+				Return(models.TwoFactor{UserID: userID, Secret: secret}, nil)
+		})
+
+		When("the code is wrong", func() {
+			BeforeEach(func() {
+				code = "000000"
+				if currentCode() == code {
+					code = "111111"
+				}
+				twoFactorDAO.EXPECT().
+					EnableTwoFactor(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Invalid code")
+			})
+		})
+
+		When("the code is correct", func() {
+			var hashes []string
+
+			BeforeEach(func() {
+				step := time.Now().Unix() / 30
+				code, _ = auth.TOTPCode(secret, step)
+				twoFactorDAO.EXPECT().
+					EnableTwoFactor(uint(userID), step, gomock.Any()).
+					DoAndReturn(func(_ uint, _ int64, codeHashes []string) error {
+						hashes = codeHashes
+						return nil
+					})
+			})
+
+			It("returns the recovery codes, whose hashes are stored", func() {
+				router.ServeHTTP(recorder, req)
+
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+				body := common.RecoveryCodesResponse{}
+				json.Unmarshal(recorder.Body.Bytes(), &body)
+				Expect(body.RecoveryCodes).To(HaveLen(10))
+				Expect(hashes).To(ContainElement(auth.HashRecoveryCode(body.RecoveryCodes[0])))
+			})
+		})
+	})
+
+	Context("DisableTwoFactor", func() {
+		const recoveryCode = "abcde-12345"
+
+		BeforeEach(func() {
+			req, _ = http.NewRequest("DELETE", "/protected/user/2fa", strings.NewReader(`{"code":"`+recoveryCode+`"}`))
+		})
+
+		When("the two-factor authentication isnt enabled", func() {
+			BeforeEach(func() {
+				twoFactorDAO.EXPECT().
+					GetTwoFactor(uint(userID)).
+					Return(models.TwoFactor{}, myerr.NewItemNotFoundError("Two-factor authentication isnt enrolled"))
+			})
+
+			It("returns bad request", func() {
+				router.ServeHTTP(recorder, req)
+				assertErrorResponse(recorder, http.StatusBadRequest, "Two-factor authentication isnt enabled")
+			})
+		})
+
+		When("the recovery code is correct", func() {
+			BeforeEach(func() {
+				twoFactorDAO.EXPECT().
+					GetTwoFactor(uint(userID)).
+					Return(models.TwoFactor{UserID: userID, Secret: secret, Enabled: true}, nil)
+				twoFactorDAO.EXPECT().
+					UseRecoveryCode(uint(userID), auth.HashRecoveryCode(recoveryCode)).
+					Return(true, nil)
+				twoFactorDAO.EXPECT().
+					DisableTwoFactor(uint(userID)).
+					Return(nil)
+			})
+
+			It("returns success", func() {
+				router.ServeHTTP(recorder, req)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+})
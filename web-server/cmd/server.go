@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,6 +21,7 @@ import (
 	"github.com/danielpenchev98/UShare/web-server/internal/db/dbconn"
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
 	"github.com/danielpenchev98/UShare/web-server/internal/middleware"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
 	val "github.com/danielpenchev98/UShare/web-server/internal/validator"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -28,6 +32,38 @@ const (
 	hostParamName     = "HOST"
 	portParamName     = "PORT"
 	groupDirParamName = "GROUP_DIR"
+
+	maxFileVersionsParamName = "MAX_FILE_VERSIONS"
+	defaultMaxFileVersions   = 10
+
+	trashRetentionParamName = "TRASH_RETENTION_DAYS"
+	defaultTrashRetention   = 30
+
+	uploadSessionTTLParamName = "UPLOAD_SESSION_TTL_HOURS"
+	defaultUploadSessionTTL   = 24
+
+	userQuotaParamName  = "USER_QUOTA_BYTES"
+	groupQuotaParamName = "GROUP_QUOTA_BYTES"
+
+	maxUserLoginFailuresParamName = "LOGIN_MAX_USER_FAILURES"
+	defaultMaxUserLoginFailures   = 10
+	maxIPLoginFailuresParamName   = "LOGIN_MAX_IP_FAILURES"
+	defaultMaxIPLoginFailures     = 100
+	loginBaseDelayParamName       = "LOGIN_BASE_DELAY_SECONDS"
+	defaultLoginBaseDelay         = 1
+	loginMaxDelayParamName        = "LOGIN_MAX_DELAY_SECONDS"
+	defaultLoginMaxDelay          = 60
+	loginLockoutParamName         = "LOGIN_LOCKOUT_MINUTES"
+	defaultLoginLockout           = 15
+
+	trustedProxiesParamName = "TRUSTED_PROXIES"
+
+	storageParamName     = "STORAGE_BACKEND"
+	s3EndpointParamName  = "S3_ENDPOINT"
+	s3RegionParamName    = "S3_REGION"
+	s3BucketParamName    = "S3_BUCKET"
+	s3AccessKeyParamName = "S3_ACCESS_KEY"
+	s3SecretKeyParamName = "S3_SECRET_KEY"
 )
 
 type ServerConfig struct {
@@ -38,6 +74,14 @@ type ServerConfig struct {
 var groupDirPath string
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "reset-password" {
+		issuePasswordResetToken(os.Args[2:])
+		return
+	} else if len(os.Args) > 1 && os.Args[1] == "set-admin" {
+		setAdmin(os.Args[2:])
+		return
+	}
+
 	serverCfg, err := getServerConfig()
 	if err != nil {
 		log.Fatalf("Proble with the server config. Reason %s", err)
@@ -47,8 +91,44 @@ func main() {
 		log.Fatal(err)
 	}
 
-	httpServer := createHttpServer(serverCfg.Host, serverCfg.Port)
-	asyncJob := createCronJob()
+	backend, err := createStorageBackend()
+	if err != nil {
+		log.Fatalf("Problem with the storage config. Reason %s", err)
+	}
+
+	maxFileVersions, err := getMaxFileVersions()
+	if err != nil {
+		log.Fatalf("Problem with the versioning config. Reason %s", err)
+	}
+
+	trashRetention, err := getTrashRetention()
+	if err != nil {
+		log.Fatalf("Problem with the trash config. Reason %s", err)
+	}
+
+	uploadSessionTTL, err := getUploadSessionTTL()
+	if err != nil {
+		log.Fatalf("Problem with the upload session config. Reason %s", err)
+	}
+
+	quota, err := getQuota()
+	if err != nil {
+		log.Fatalf("Problem with the quota config. Reason %s", err)
+	}
+
+	lockoutPolicy, err := getLockoutPolicy()
+	if err != nil {
+		log.Fatalf("Problem with the login lockout config. Reason %s", err)
+	}
+
+	trustedProxies, err := getTrustedProxies()
+	if err != nil {
+		log.Fatalf("Problem with the trusted proxies config. Reason %s", err)
+	}
+
+	daos := createDAOs()
+	httpServer := createHttpServer(daos, serverCfg.Host, serverCfg.Port, backend, quota, lockoutPolicy, trustedProxies)
+	asyncJob := createCronJob(daos, backend, maxFileVersions, trashRetention, uploadSessionTTL)
 	asyncJob.Start()
 	defer asyncJob.Stop()
 
@@ -72,6 +152,57 @@ func main() {
 	<-ctx.Done()
 }
 
+//issuePasswordResetToken - issues a one-time token, with which a user, who has forgotten the password, sets a new one
+//it is run by the operator of the server, who passes the token to the user. The administrators issue such tokens through the admin api as well
+func issuePasswordResetToken(args []string) {
+	resetCommand := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	username := resetCommand.String("usr", "", "username")
+	expiration := resetCommand.Uint("exp", rest.DefaultPasswordResetExpiration, "after how many hours the token expires")
+	resetCommand.Parse(args)
+
+	if *username == "" || *expiration == 0 {
+		resetCommand.PrintDefaults()
+		os.Exit(1)
+	}
+
+	token, tokenHash, err := auth.GeneratePasswordResetToken()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(time.Duration(*expiration) * time.Hour)
+	if err = createDAOs().token.CreatePasswordResetToken(*username, tokenHash, expiresAt); err != nil {
+		log.Fatalf("Couldnt issue a password reset token. Reason %s", err)
+	}
+
+	log.Printf("Issued a password reset token for user [%s], which expires at %s\n", *username, expiresAt.Format(time.RFC3339))
+	fmt.Println(token)
+}
+
+//setAdmin - grants or revokes the administrator role of a user
+//it is run by the operator of the server, for instance to bootstrap the first administrator
+func setAdmin(args []string) {
+	adminCommand := flag.NewFlagSet("set-admin", flag.ExitOnError)
+	username := adminCommand.String("usr", "", "username")
+	revoke := adminCommand.Bool("revoke", false, "revoke the administrator role instead of granting it")
+	adminCommand.Parse(args)
+
+	if *username == "" {
+		adminCommand.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if err := createDAOs().admin.SetAdmin(*username, !*revoke); err != nil {
+		log.Fatalf("Couldnt change the administrator role. Reason %s", err)
+	}
+
+	if *revoke {
+		log.Printf("User [%s] is no longer an administrator\n", *username)
+	} else {
+		log.Printf("User [%s] is now an administrator\n", *username)
+	}
+}
+
 func getServerConfig() (ServerConfig, error) {
 	portStr := os.Getenv(portParamName)
 	if portStr == "" {
@@ -89,6 +220,129 @@ func getServerConfig() (ServerConfig, error) {
 	}, nil
 }
 
+func getMaxFileVersions() (uint, error) {
+	maxVersionsStr := os.Getenv(maxFileVersionsParamName)
+	if maxVersionsStr == "" {
+		return defaultMaxFileVersions, nil
+	}
+
+	maxVersions, err := strconv.ParseUint(maxVersionsStr, 10, 32)
+	if err != nil || maxVersions == 0 {
+		return 0, errors.Errorf("The env variable %s should be a positive number", maxFileVersionsParamName)
+	}
+	return uint(maxVersions), nil
+}
+
+func getTrashRetention() (time.Duration, error) {
+	retentionStr := os.Getenv(trashRetentionParamName)
+	if retentionStr == "" {
+		return defaultTrashRetention * 24 * time.Hour, nil
+	}
+
+	retentionDays, err := strconv.ParseUint(retentionStr, 10, 32)
+	if err != nil {
+		return 0, errors.Errorf("The env variable %s should be a non-negative number of days", trashRetentionParamName)
+	}
+	return time.Duration(retentionDays) * 24 * time.Hour, nil
+}
+
+func getUploadSessionTTL() (time.Duration, error) {
+	ttlStr := os.Getenv(uploadSessionTTLParamName)
+	if ttlStr == "" {
+		return defaultUploadSessionTTL * time.Hour, nil
+	}
+
+	ttlHours, err := strconv.ParseUint(ttlStr, 10, 32)
+	if err != nil || ttlHours == 0 {
+		return 0, errors.Errorf("The env variable %s should be a positive number of hours", uploadSessionTTLParamName)
+	}
+	return time.Duration(ttlHours) * time.Hour, nil
+}
+
+func getQuota() (dao.Quota, error) {
+	var quota dao.Quota
+	for paramName, limit := range map[string]*int64{userQuotaParamName: &quota.UserBytes, groupQuotaParamName: &quota.GroupBytes} {
+		limitStr := os.Getenv(paramName)
+		if limitStr == "" {
+			continue
+		}
+
+		value, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || value < 0 {
+			return dao.Quota{}, errors.Errorf("The env variable %s should be a non-negative number of bytes", paramName)
+		}
+		*limit = value
+	}
+	return quota, nil
+}
+
+func getLockoutPolicy() (dao.LockoutPolicy, error) {
+	params := []struct {
+		name         string
+		defaultValue uint64
+		value        uint64
+	}{
+		{name: maxUserLoginFailuresParamName, defaultValue: defaultMaxUserLoginFailures},
+		{name: maxIPLoginFailuresParamName, defaultValue: defaultMaxIPLoginFailures},
+		{name: loginBaseDelayParamName, defaultValue: defaultLoginBaseDelay},
+		{name: loginMaxDelayParamName, defaultValue: defaultLoginMaxDelay},
+		{name: loginLockoutParamName, defaultValue: defaultLoginLockout},
+	}
+
+	for idx := range params {
+		valueStr := os.Getenv(params[idx].name)
+		if valueStr == "" {
+			params[idx].value = params[idx].defaultValue
+			continue
+		}
+
+		value, err := strconv.ParseUint(valueStr, 10, 32)
+		if err != nil {
+			return dao.LockoutPolicy{}, errors.Errorf("The env variable %s should be a non-negative number", params[idx].name)
+		}
+		params[idx].value = value
+	}
+
+	policy := dao.LockoutPolicy{
+		MaxUserFailures: uint(params[0].value),
+		MaxIPFailures:   uint(params[1].value),
+		BaseDelay:       time.Duration(params[2].value) * time.Second,
+		MaxDelay:        time.Duration(params[3].value) * time.Second,
+		LockoutDuration: time.Duration(params[4].value) * time.Minute,
+	}
+
+	if policy.IsLimited() && (policy.MaxDelay < policy.BaseDelay || policy.LockoutDuration < policy.MaxDelay) {
+		return dao.LockoutPolicy{}, errors.Errorf("The env variables should satisfy %s <= %s <= %s", loginBaseDelayParamName, loginMaxDelayParamName, loginLockoutParamName)
+	}
+	return policy, nil
+}
+
+func getTrustedProxies() ([]*net.IPNet, error) {
+	proxiesStr := os.Getenv(trustedProxiesParamName)
+	if proxiesStr == "" {
+		return nil, nil
+	}
+
+	var proxies []*net.IPNet
+	for _, proxy := range strings.Split(proxiesStr, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, errors.Errorf("The env variable %s should be a comma separated list of IPs or CIDRs", trustedProxiesParamName)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
 func createGroupsDir() error {
 	currDir := os.Getenv("GROUP_DIR")
 	if currDir == "" {
@@ -107,69 +361,169 @@ func createGroupsDir() error {
 	return nil
 }
 
-func createUamDAO() dao.UamDAO {
-	dbConn, err := dbconn.GetDBConn(dbconn.PostgresDialectorCreator)
-	if err != nil {
-		log.Fatal(myerr.NewServerErrorWrap(err, "Couldnt create a connection to the database"))
-	}
-
-	uamDAO := dao.NewUamDAOImpl(dbConn)
-	if err = uamDAO.Migrate(); err != nil {
-		log.Fatal(myerr.NewServerErrorWrap(err, "Couldnt migrate the database schemas"))
+func createStorageBackend() (storage.Backend, error) {
+	switch backendType := os.Getenv(storageParamName); backendType {
+	case "", "local":
+		return storage.NewLocalBackend(groupDirPath), nil
+	case "s3":
+		config := storage.S3Config{
+			Endpoint:  os.Getenv(s3EndpointParamName),
+			Region:    os.Getenv(s3RegionParamName),
+			Bucket:    os.Getenv(s3BucketParamName),
+			AccessKey: os.Getenv(s3AccessKeyParamName),
+			SecretKey: os.Getenv(s3SecretKeyParamName),
+		}
+		if config.Endpoint == "" || config.Region == "" || config.Bucket == "" {
+			return nil, errors.Errorf("Please set %s, %s and %s env variables", s3EndpointParamName, s3RegionParamName, s3BucketParamName)
+		}
+		return storage.NewS3Backend(config, nil), nil
+	default:
+		return nil, errors.Errorf("The env variable %s has unknown storage backend [%s]", storageParamName, backendType)
 	}
+}
 
-	return uamDAO
+//dataAccessObjects - the data access objects of the server, which share a single connection pool to the database
+type dataAccessObjects struct {
+	uam          dao.UamDAO
+	fm           dao.FmDAO
+	token        dao.TokenDAO
+	twoFactor    dao.TwoFactorDAO
+	loginFailure dao.LoginFailureDAO
+	admin        dao.AdminDAO
+	audit        dao.AuditDAO
 }
 
-func createFmDAO() dao.FmDAO {
+//createDAOs - opens the connection to the database, creates all data access objects with it and migrates their schemas
+func createDAOs() dataAccessObjects {
 	dbConn, err := dbconn.GetDBConn(dbconn.PostgresDialectorCreator)
 	if err != nil {
 		log.Fatal(myerr.NewServerErrorWrap(err, "Couldnt create a connection to the database"))
 	}
 
-	fmDAO := dao.NewFmDAOImpl(dbConn)
-	if err = fmDAO.Migrate(); err != nil {
-		log.Fatal(myerr.NewServerErrorWrap(err, "Couldnt migrate the database schemas"))
+	daos := dataAccessObjects{
+		uam:          dao.NewUamDAOImpl(dbConn),
+		fm:           dao.NewFmDAOImpl(dbConn),
+		token:        dao.NewTokenDAOImpl(dbConn),
+		twoFactor:    dao.NewTwoFactorDAOImpl(dbConn),
+		loginFailure: dao.NewLoginFailureDAOImpl(dbConn),
+		admin:        dao.NewAdminDAOImpl(dbConn),
+		audit:        dao.NewAuditDAOImpl(dbConn),
+	}
+
+	migrators := []interface{ Migrate() error }{daos.uam, daos.fm, daos.token, daos.twoFactor, daos.loginFailure, daos.admin, daos.audit}
+	for _, migrator := range migrators {
+		if err = migrator.Migrate(); err != nil {
+			log.Fatal(myerr.NewServerErrorWrap(err, "Couldnt migrate the database schemas"))
+		}
 	}
 
-	return fmDAO
+	return daos
 }
 
-func createHttpServer(host string, port int) *http.Server {
+func createHttpServer(daos dataAccessObjects, host string, port int, backend storage.Backend, quota dao.Quota, lockoutPolicy dao.LockoutPolicy, trustedProxies []*net.IPNet) *http.Server {
 	var router = gin.Default()
+	router.ForwardedByClientIP = false
+	router.Use(middleware.NewClientIPResolverImpl(trustedProxies).ResolveClientIP)
 
 	jwtCreator, err := auth.NewJwtCreatorImpl()
 	if err != nil {
 		log.Fatal(myerr.NewServerErrorWrap(err, "Couldnt create a new Jwt Creator"))
 	}
 
-	filter := middleware.NewAuthzFilterImpl(jwtCreator)
-	uamEndpoint := rest.NewUamEndPointImpl(createUamDAO(), jwtCreator, val.NewBasicValidator(), groupDirPath)
-	fmEndpoint := rest.NewFileManagementEndpointImpl(createUamDAO(), createFmDAO(), groupDirPath)
+	filter := middleware.NewAuthzFilterImpl(jwtCreator, daos.token, daos.admin)
+	uamEndpoint := rest.NewUamEndPointImpl(daos.uam, daos.token, daos.twoFactor, daos.loginFailure, lockoutPolicy,
+		jwtCreator, val.NewBasicValidator())
+	fmEndpoint := rest.NewFileManagementEndpointImpl(daos.uam, daos.fm, backend, quota)
+	adminFilter := middleware.NewAdminFilterImpl(daos.admin)
+	adminEndpoint := rest.NewAdminEndpointImpl(daos.uam, daos.admin, daos.token)
+	auditor := middleware.NewAuditorImpl(daos.audit)
+	auditEndpoint := rest.NewAuditEndpointImpl(daos.audit)
+
+	router.GET("/.well-known/jwks.json", uamEndpoint.GetJWKS)
 
 	v1 := router.Group("/v1")
 	{
 		public := v1.Group("/public")
 		{
 			public.GET("/healthcheck", rest.CheckHealth)
-			public.POST("/user/registration", uamEndpoint.CreateUser)
-			public.POST("/user/login", uamEndpoint.Login)
+			public.POST("/user/registration", auditor.Audit("user.register"), uamEndpoint.CreateUser)
+			public.POST("/user/login", auditor.Audit("user.login"), uamEndpoint.Login)
+			public.POST("/user/login/verification", auditor.Audit("user.login_verification"), uamEndpoint.VerifyLogin)
+			public.POST("/user/token/refresh", uamEndpoint.RefreshToken)
+			public.PUT("/user/password/reset", auditor.Audit("user.password_reset"), uamEndpoint.ResetPassword)
+			public.GET("/share/:token", auditor.Audit("file.shared_download"), fmEndpoint.DownloadSharedFile)
+			public.POST("/dropbox/:token", auditor.Audit("file.dropbox_upload"), fmEndpoint.UploadToDropBox)
 		}
 
 		protected := v1.Group("/protected").Use(filter.Authz)
 		{
-			protected.DELETE("/group/membership/revocation", uamEndpoint.RevokeMembership)
-			protected.POST("/group/creation", uamEndpoint.CreateGroup)
-			protected.POST("/group/invitation", uamEndpoint.AddMember)
-			protected.DELETE("/group/user/deletion", uamEndpoint.DeleteUser)
-			protected.DELETE("/group/deletion", uamEndpoint.DeleteGroup)
-			protected.POST("/group/file/upload", fmEndpoint.UploadFile)
-			protected.GET("/group/file/download", fmEndpoint.DownloadFile)
-			protected.DELETE("/group/file/deletion", fmEndpoint.DeleteFile)
+			protected.DELETE("/user/logout", auditor.Audit("user.logout"), uamEndpoint.Logout)
+			protected.POST("/user/tokens", auditor.Audit("token.create"), uamEndpoint.CreatePersonalAccessToken)
+			protected.GET("/user/tokens", uamEndpoint.GetPersonalAccessTokens)
+			protected.DELETE("/user/tokens", auditor.Audit("token.delete"), uamEndpoint.DeletePersonalAccessToken)
+			protected.POST("/user/2fa/enrollment", uamEndpoint.EnrollTwoFactor)
+			protected.POST("/user/2fa/confirmation", auditor.Audit("2fa.enable"), uamEndpoint.ConfirmTwoFactor)
+			protected.DELETE("/user/2fa", auditor.Audit("2fa.disable"), uamEndpoint.DisableTwoFactor)
+			protected.POST("/user/password", auditor.Audit("user.password_change"), uamEndpoint.ChangePassword)
+			protected.DELETE("/group/membership/revocation", auditor.Audit("member.remove"), uamEndpoint.RevokeMembership)
+			protected.POST("/group/creation", auditor.Audit("group.create"), uamEndpoint.CreateGroup)
+			protected.POST("/group/invitation", auditor.Audit("member.invite"), uamEndpoint.InviteMember)
+			protected.POST("/group/invitation/acceptance", auditor.Audit("invitation.accept"), uamEndpoint.AcceptInvitation)
+			protected.DELETE("/group/invitation/declination", auditor.Audit("invitation.decline"), uamEndpoint.DeclineInvitation)
+			protected.GET("/user/invitations", uamEndpoint.GetInvitations)
+			protected.PUT("/group/visibility", auditor.Audit("group.visibility"), uamEndpoint.SetGroupVisibility)
+			protected.POST("/group/join", auditor.Audit("group.join"), uamEndpoint.JoinGroup)
+			protected.GET("/group/join/requests", uamEndpoint.GetJoinRequests)
+			protected.POST("/group/join/requests/resolution", auditor.Audit("join_request.resolve"), uamEndpoint.ResolveJoinRequest)
+			protected.PUT("/group/membership/role", auditor.Audit("member.role"), uamEndpoint.ChangeMemberRole)
+			protected.PUT("/group/ownership", auditor.Audit("group.transfer"), uamEndpoint.TransferOwnership)
+			protected.DELETE("/group/user/deletion", auditor.Audit("user.delete"), uamEndpoint.DeleteUser)
+			protected.DELETE("/group/deletion", auditor.Audit("group.delete"), uamEndpoint.DeleteGroup)
+			protected.POST("/group/file/upload", auditor.Audit("file.upload"), fmEndpoint.UploadFile)
+			protected.GET("/group/file/download", auditor.Audit("file.download"), fmEndpoint.DownloadFile)
+			protected.DELETE("/group/file/deletion", auditor.Audit("file.delete"), fmEndpoint.DeleteFile)
 			protected.GET("/group/files", fmEndpoint.RetrieveAllFilesInfo)
+			protected.GET("/group/file/versions", fmEndpoint.GetFileVersions)
+			protected.POST("/group/file/versions/restoration", auditor.Audit("file.version_restore"), fmEndpoint.RestoreFileVersion)
+			protected.PUT("/group/file/versions/limit", auditor.Audit("group.max_versions"), fmEndpoint.SetMaxFileVersions)
+			protected.GET("/group/usage", fmEndpoint.GetGroupUsage)
+			protected.GET("/user/usage", fmEndpoint.GetUserUsage)
+			protected.GET("/group/trash", fmEndpoint.GetTrashedFiles)
+			protected.POST("/group/trash/restoration", auditor.Audit("file.restore"), fmEndpoint.RestoreTrashedFile)
+			protected.DELETE("/group/trash/deletion", auditor.Audit("file.purge"), fmEndpoint.DeleteTrashedFile)
+			protected.PUT("/group/file/move", auditor.Audit("file.move"), fmEndpoint.MoveFile)
+			protected.POST("/group/file/share", auditor.Audit("share.create"), fmEndpoint.CreateShareLink)
+			protected.GET("/group/file/share", fmEndpoint.GetShareLinks)
+			protected.DELETE("/group/file/share", auditor.Audit("share.revoke"), fmEndpoint.RevokeShareLink)
+			protected.POST("/group/dropbox", auditor.Audit("dropbox.create"), fmEndpoint.CreateDropBox)
+			protected.GET("/group/dropbox", fmEndpoint.GetDropBoxes)
+			protected.DELETE("/group/dropbox", auditor.Audit("dropbox.delete"), fmEndpoint.DeleteDropBox)
+			protected.POST("/group/folder", auditor.Audit("folder.create"), fmEndpoint.CreateFolder)
+			protected.PUT("/group/folder/rename", auditor.Audit("folder.rename"), fmEndpoint.RenameFolder)
+			protected.PUT("/group/folder/move", auditor.Audit("folder.move"), fmEndpoint.MoveFolder)
+			protected.DELETE("/group/folder", auditor.Audit("folder.delete"), fmEndpoint.DeleteFolder)
+			protected.POST("/group/file/upload/session", fmEndpoint.CreateUploadSession)
+			protected.GET("/group/file/upload/session", fmEndpoint.GetUploadSession)
+			protected.PUT("/group/file/upload/session/chunk", fmEndpoint.UploadChunk)
+			protected.POST("/group/file/upload/session/finalization", auditor.Audit("file.upload"), fmEndpoint.FinalizeUpload)
+			protected.DELETE("/group/file/upload/session", fmEndpoint.AbortUpload)
 			protected.GET("/groups", uamEndpoint.GetAllGroupsInfo)
 			protected.GET("/users", uamEndpoint.GetAllUsersInfo)
 			protected.GET("/group/users", uamEndpoint.GetAllUsersInGroup)
+			protected.GET("/group/audit", auditEndpoint.GetGroupEvents)
+		}
+
+		admin := v1.Group("/admin").Use(filter.Authz, adminFilter.Admin)
+		{
+			admin.GET("/users", adminEndpoint.GetUsers)
+			admin.PUT("/user/suspension", auditor.Audit("admin.user_suspend"), adminEndpoint.SuspendUser)
+			admin.DELETE("/user/suspension", auditor.Audit("admin.user_unsuspend"), adminEndpoint.UnsuspendUser)
+			admin.DELETE("/user", auditor.Audit("admin.user_delete"), adminEndpoint.DeleteUser)
+			admin.POST("/user/password-reset", auditor.Audit("admin.password_reset"), adminEndpoint.IssuePasswordResetToken)
+			admin.DELETE("/group", auditor.Audit("admin.group_delete"), adminEndpoint.DeactivateGroup)
+			admin.PUT("/group/ownership", auditor.Audit("admin.group_transfer"), adminEndpoint.ReassignGroupOwnership)
+			admin.GET("/storage", adminEndpoint.GetStorageTotals)
+			admin.GET("/audit", auditEndpoint.GetEvents)
 		}
 	}
 
@@ -181,9 +535,30 @@ func createHttpServer(host string, port int) *http.Server {
 	return httpServer
 }
 
-func createCronJob() *cron.Cron {
-	groupDeleter := cronJob.NewGroupEraserJobImpl(createUamDAO(), groupDirPath)
+func createCronJob(daos dataAccessObjects, backend storage.Backend, maxFileVersions uint, trashRetention time.Duration, uploadSessionTTL time.Duration) *cron.Cron {
+	fmDAO := daos.fm
+	groupDeleter := cronJob.NewGroupEraserJobImpl(daos.uam, fmDAO, backend)
+	blobDeleter := cronJob.NewBlobEraserJobImpl(fmDAO, backend)
+	versionPruner := cronJob.NewVersionPrunerJobImpl(fmDAO, backend, maxFileVersions)
+	trashPurger := cronJob.NewTrashPurgerJobImpl(fmDAO, backend, trashRetention)
+	invitationExpirer := cronJob.NewExpirerJobImpl("expired invitations", daos.uam.DeleteExpiredInvitations)
+	uploadSessionCleaner := cronJob.NewUploadSessionCleanerJobImpl(fmDAO, backend, uploadSessionTTL)
+	tokenExpirer := cronJob.NewExpirerJobImpl("expired tokens", daos.token.DeleteExpiredTokens)
+	loginFailureExpirer := cronJob.NewExpirerJobImpl("expired failed logins", daos.loginFailure.DeleteExpiredLoginFailures)
+	shareLinkExpirer := cronJob.NewExpirerJobImpl("stale share links", fmDAO.DeleteStaleShareLinks)
+	dropBoxExpirer := cronJob.NewExpirerJobImpl("expired drop boxes", fmDAO.DeleteExpiredDropBoxes)
+	suspensionLifter := cronJob.NewExpirerJobImpl("expired suspensions", daos.admin.LiftExpiredSuspensions)
 	asyncJob := cron.New()
 	asyncJob.AddFunc("@every 1m", groupDeleter.DeleteGroups)
+	asyncJob.AddFunc("@every 1m", versionPruner.PruneVersions)
+	asyncJob.AddFunc("@every 1h", trashPurger.PurgeTrash)
+	asyncJob.AddFunc("@every 1m", blobDeleter.DeleteBlobs)
+	asyncJob.AddFunc("@every 1h", invitationExpirer.Expire)
+	asyncJob.AddFunc("@every 1h", uploadSessionCleaner.CleanUploadSessions)
+	asyncJob.AddFunc("@every 1h", tokenExpirer.Expire)
+	asyncJob.AddFunc("@every 1h", loginFailureExpirer.Expire)
+	asyncJob.AddFunc("@every 1h", shareLinkExpirer.Expire)
+	asyncJob.AddFunc("@every 1h", dropBoxExpirer.Expire)
+	asyncJob.AddFunc("@every 1m", suspensionLifter.Expire)
 	return asyncJob
 }
@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
@@ -10,9 +15,14 @@ import (
 )
 
 const (
-	secretKey     = "SECRET"
-	issuerKey     = "ISSUER"
-	expirationKey = "EXPIRATION"
+	secretKey            = "SECRET"
+	issuerKey            = "ISSUER"
+	expirationKey        = "EXPIRATION_MINUTES"
+	refreshExpirationKey = "REFRESH_EXPIRATION_HOURS"
+	signingKeyFileKey    = "SIGNING_KEY_FILE"
+	previousKeyFilesKey  = "PREVIOUS_KEY_FILES"
+	keyGracePeriodKey    = "KEY_GRACE_PERIOD_MINUTES"
+	keyRotatedAtKey      = "KEY_ROTATED_AT"
 )
 
 //go:generate mockgen --source=auth.go --destination auth_mocks/auth.go --package auth_mocks
@@ -21,20 +31,37 @@ const (
 type JwtCreator interface {
 	GenerateToken(uint) (string, error)
 	ValidateToken(string) (*JwtClaim, error)
+	GenerateRefreshToken() (RefreshToken, error)
+	JWKS() JWKSet
 }
 
 //JwtCreatorImpl - implementation of JwtCreator
+//the tokens are signed with SigningKey, if it is set, otherwise with Secret (HS256)
 type JwtCreatorImpl struct {
-	Secret          string
-	Issuer          string
-	ExpirationHours int64
+	Secret                 string
+	Issuer                 string
+	ExpirationMinutes      int64
+	RefreshExpirationHours int64
+	SigningKey             *SigningKey
+	//PreviousKeys - keys, which were used for signing before the rotation and still validate the tokens until their grace period ends
+	PreviousKeys []SigningKey
+	//SecretValidUntil - the end of the grace period, in which Secret still validates the tokens, while SigningKey is set
+	SecretValidUntil *time.Time
+}
+
+//RefreshToken - opaque token, used for obtaining a new access token. Only its hash is stored by the server
+type RefreshToken struct {
+	Token     string
+	Hash      string
+	ExpiresAt time.Time
 }
 
 //NewJwtCreatorImpl - creates an instance of JwtCreatorImpl
 func NewJwtCreatorImpl() (*JwtCreatorImpl, error) {
 	secret := os.Getenv(secretKey)
-	if len(secret) == 0 {
-		return nil, myerr.NewServerError("Missing value for \"secret\" jwt config")
+	signingKeyFile := os.Getenv(signingKeyFileKey)
+	if len(secret) == 0 && len(signingKeyFile) == 0 {
+		return nil, myerr.NewServerError("Missing value for \"secret\" or \"signingKeyFile\" jwt config")
 	}
 
 	issuer := os.Getenv(issuerKey)
@@ -44,18 +71,89 @@ func NewJwtCreatorImpl() (*JwtCreatorImpl, error) {
 
 	expirationStr := os.Getenv(expirationKey)
 	if len(expirationStr) == 0 {
-		return nil, myerr.NewServerError("Missing value for \"expirationHours\" jwt config")
+		return nil, myerr.NewServerError("Missing value for \"expirationMinutes\" jwt config")
 	}
-	expirationHours, err := strconv.ParseInt(expirationStr, 10, 64)
+	expirationMinutes, err := strconv.ParseInt(expirationStr, 10, 64)
 	if err != nil {
-		return nil, myerr.NewServerErrorWrap(err, "Wrong typeof value for \"expiration\" jwt config")
+		return nil, myerr.NewServerErrorWrap(err, "Wrong typeof value for \"expirationMinutes\" jwt config")
 	}
 
-	return &JwtCreatorImpl{
-		Secret:          secret,
-		Issuer:          issuer,
-		ExpirationHours: int64(expirationHours),
-	}, nil
+	refreshExpirationStr := os.Getenv(refreshExpirationKey)
+	if len(refreshExpirationStr) == 0 {
+		return nil, myerr.NewServerError("Missing value for \"refreshExpirationHours\" jwt config")
+	}
+	refreshExpirationHours, err := strconv.ParseInt(refreshExpirationStr, 10, 64)
+	if err != nil {
+		return nil, myerr.NewServerErrorWrap(err, "Wrong typeof value for \"refreshExpirationHours\" jwt config")
+	}
+
+	creator := &JwtCreatorImpl{
+		Secret:                 secret,
+		Issuer:                 issuer,
+		ExpirationMinutes:      expirationMinutes,
+		RefreshExpirationHours: refreshExpirationHours,
+	}
+
+	if len(signingKeyFile) == 0 {
+		return creator, nil
+	}
+
+	if err = creator.loadKeys(signingKeyFile); err != nil {
+		return nil, err
+	}
+	return creator, nil
+}
+
+//loadKeys - loads the signing key and the previous keys, whose grace period starts at the moment of the rotation
+//by default the grace period is as long as the expiration of the tokens, so the tokens, signed before the rotation, stay valid
+//the moment of the rotation is fixed in the config, so restarting the server doesnt extend the grace period
+func (j *JwtCreatorImpl) loadKeys(signingKeyFile string) error {
+	signingKey, err := LoadSigningKey(signingKeyFile)
+	if err != nil {
+		return err
+	} else if signingKey.PrivateKey == nil {
+		return myerr.NewServerError(fmt.Sprintf("The signing key file [%s] doesnt contain a private key", signingKeyFile))
+	}
+	j.SigningKey = &signingKey
+
+	gracePeriod := j.ExpirationMinutes
+	if gracePeriodStr := os.Getenv(keyGracePeriodKey); len(gracePeriodStr) != 0 {
+		if gracePeriod, err = strconv.ParseInt(gracePeriodStr, 10, 64); err != nil {
+			return myerr.NewServerErrorWrap(err, "Wrong typeof value for \"keyGracePeriodMinutes\" jwt config")
+		}
+	}
+
+	var previousKeyFiles []string
+	for _, keyFile := range strings.Split(os.Getenv(previousKeyFilesKey), ",") {
+		if keyFile = strings.TrimSpace(keyFile); len(keyFile) != 0 {
+			previousKeyFiles = append(previousKeyFiles, keyFile)
+		}
+	}
+
+	if len(j.Secret) == 0 && len(previousKeyFiles) == 0 {
+		return nil
+	}
+
+	rotatedAtStr := os.Getenv(keyRotatedAtKey)
+	if len(rotatedAtStr) == 0 {
+		return myerr.NewServerError("Missing value for \"keyRotatedAt\" jwt config, which is required by \"secret\" or \"previousKeyFiles\"")
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, rotatedAtStr)
+	if err != nil {
+		return myerr.NewServerErrorWrap(err, "Wrong typeof value for \"keyRotatedAt\" jwt config")
+	}
+	validUntil := rotatedAt.Add(time.Minute * time.Duration(gracePeriod))
+	j.SecretValidUntil = &validUntil
+
+	for _, keyFile := range previousKeyFiles {
+		previousKey, err := LoadSigningKey(keyFile)
+		if err != nil {
+			return err
+		}
+		previousKey.ValidUntil = &validUntil
+		j.PreviousKeys = append(j.PreviousKeys, previousKey)
+	}
+	return nil
 }
 
 // JwtClaim adds email as a claim to the token
@@ -64,30 +162,45 @@ type JwtClaim struct {
 	jwt.StandardClaims
 }
 
-//GenerateToken - generates a token, encrypting the userID in it
+//GenerateToken - generates a short-lived access token, encrypting the userID in it
+//every token has an unique id (jti), so it can be revoked, and the moment of issuing (iat), so it can be rejected after a password change
 //returns the token and error
 func (j *JwtCreatorImpl) GenerateToken(userID uint) (string, error) {
+	tokenID, err := randomHex(16)
+	if err != nil {
+		return "", myerr.NewServerErrorWrap(err, "Couldnt generate an id of the token")
+	}
+
+	now := time.Now().Local()
 	claims := &JwtClaim{
 		UserID: userID,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(j.ExpirationHours)).Unix(),
+			Id:        tokenID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Minute * time.Duration(j.ExpirationMinutes)).Unix(),
 			Issuer:    j.Issuer,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.Secret))
+	if j.SigningKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.Secret))
+	}
+
+	token := jwt.NewWithClaims(j.SigningKey.Method, claims)
+	token.Header["kid"] = j.SigningKey.ID
+	return token.SignedString(j.SigningKey.PrivateKey)
 }
 
 //ValidateToken - validates a given JWT token
+//the tokens with kid are validated with the signing key or a previous key in its grace period, the ones without kid - with the secret
+//the secret validates tokens only until the end of its grace period, once the tokens are signed with the signing key
 //returns the encrypted data in the token and error if the token is invalid
 func (j *JwtCreatorImpl) ValidateToken(signedToken string) (*JwtClaim, error) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
 		&JwtClaim{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(j.Secret), nil
-		},
+		j.validationKey,
 	)
 
 	if err != nil {
@@ -97,3 +210,77 @@ func (j *JwtCreatorImpl) ValidateToken(signedToken string) (*JwtClaim, error) {
 	claims, _ := token.Claims.(*JwtClaim)
 	return claims, nil
 }
+
+//JWKS - returns the public keys, which currently validate the tokens
+func (j *JwtCreatorImpl) JWKS() JWKSet {
+	keySet := JWKSet{Keys: []JWK{}}
+	for _, key := range j.activeKeys() {
+		keySet.Keys = append(keySet.Keys, key.JWK())
+	}
+	return keySet
+}
+
+func (j *JwtCreatorImpl) validationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if len(kid) == 0 {
+		if len(j.Secret) == 0 || token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method [%s]", token.Method.Alg())
+		} else if j.SigningKey != nil && (j.SecretValidUntil == nil || !time.Now().Before(*j.SecretValidUntil)) {
+			return nil, fmt.Errorf("the grace period of the secret has ended")
+		}
+		return []byte(j.Secret), nil
+	}
+
+	for _, key := range j.activeKeys() {
+		if key.ID != kid {
+			continue
+		} else if key.Method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method [%s] for key [%s]", token.Method.Alg(), kid)
+		}
+		return key.PublicKey, nil
+	}
+	return nil, fmt.Errorf("unknown key [%s]", kid)
+}
+
+func (j *JwtCreatorImpl) activeKeys() []SigningKey {
+	var keys []SigningKey
+	if j.SigningKey != nil {
+		keys = append(keys, *j.SigningKey)
+	}
+
+	now := time.Now()
+	for _, key := range j.PreviousKeys {
+		if key.ValidUntil == nil || now.Before(*key.ValidUntil) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+//GenerateRefreshToken - generates a random refresh token
+func (j *JwtCreatorImpl) GenerateRefreshToken() (RefreshToken, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return RefreshToken{}, myerr.NewServerErrorWrap(err, "Couldnt generate a refresh token")
+	}
+
+	return RefreshToken{
+		Token:     token,
+		Hash:      HashRefreshToken(token),
+		ExpiresAt: time.Now().Add(time.Hour * time.Duration(j.RefreshExpirationHours)),
+	}, nil
+}
+
+//HashRefreshToken - returns the hex encoded SHA-256 hash of the refresh or personal access token, under which it is stored
+func HashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomHex(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
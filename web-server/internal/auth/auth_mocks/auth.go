@@ -62,3 +62,32 @@ func (mr *MockJwtCreatorMockRecorder) ValidateToken(arg0 interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateToken", reflect.TypeOf((*MockJwtCreator)(nil).ValidateToken), arg0)
 }
+
+// GenerateRefreshToken mocks base method
+func (m *MockJwtCreator) GenerateRefreshToken() (auth.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateRefreshToken")
+	ret0, _ := ret[0].(auth.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRefreshToken indicates an expected call of GenerateRefreshToken
+func (mr *MockJwtCreatorMockRecorder) GenerateRefreshToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRefreshToken", reflect.TypeOf((*MockJwtCreator)(nil).GenerateRefreshToken))
+}
+
+// JWKS mocks base method
+func (m *MockJwtCreator) JWKS() auth.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(auth.JWKSet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS
+func (mr *MockJwtCreatorMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockJwtCreator)(nil).JWKS))
+}
@@ -14,12 +14,14 @@ import (
 var _ = Describe("Auth module", func() {
 
 	const (
-		secretKey     = "SECRET"
-		secretVal     = "secret"
-		issuerKey     = "ISSUER"
-		issuerVal     = "issuer"
-		expirationKey = "EXPIRATION"
-		expirationVal = 24
+		secretKey            = "SECRET"
+		secretVal            = "secret"
+		issuerKey            = "ISSUER"
+		issuerVal            = "issuer"
+		expirationKey        = "EXPIRATION_MINUTES"
+		expirationVal        = 15
+		refreshExpirationKey = "REFRESH_EXPIRATION_HOURS"
+		refreshExpirationVal = 720
 	)
 
 	BeforeEach(func() {
@@ -99,15 +101,44 @@ var _ = Describe("Auth module", func() {
 								os.Unsetenv(expirationKey)
 							})
 
-							It("succeeds", func() {
-								actualResult, err := auth.NewJwtCreatorImpl()
-								Expect(err).NotTo(HaveOccurred())
-								expectedResult := &auth.JwtCreatorImpl{
-									Secret:          secretVal,
-									Issuer:          issuerVal,
-									ExpirationHours: expirationVal,
-								}
-								Expect(actualResult).To(Equal(expectedResult))
+							Context("and refresh expiration env variable is missing", func() {
+								It("returns error", func() {
+									_, err := auth.NewJwtCreatorImpl()
+									Expect(err).To(HaveOccurred())
+									_, ok := err.(*myerr.ServerError)
+									Expect(ok).To(Equal(true))
+								})
+							})
+
+							Context("and refresh expiration variable is in illegal format", func() {
+								BeforeEach(func() {
+									os.Setenv(refreshExpirationKey, "wrong-format")
+								})
+
+								It("returns error", func() {
+									_, err := auth.NewJwtCreatorImpl()
+									Expect(err).To(HaveOccurred())
+									_, ok := err.(*myerr.ServerError)
+									Expect(ok).To(Equal(true))
+								})
+							})
+
+							Context("and refresh expiration variable is in legal format", func() {
+								BeforeEach(func() {
+									os.Setenv(refreshExpirationKey, strconv.Itoa(refreshExpirationVal))
+								})
+
+								It("succeeds", func() {
+									actualResult, err := auth.NewJwtCreatorImpl()
+									Expect(err).NotTo(HaveOccurred())
+									expectedResult := &auth.JwtCreatorImpl{
+										Secret:                 secretVal,
+										Issuer:                 issuerVal,
+										ExpirationMinutes:      expirationVal,
+										RefreshExpirationHours: refreshExpirationVal,
+									}
+									Expect(actualResult).To(Equal(expectedResult))
+								})
 							})
 						})
 					})
@@ -119,9 +150,10 @@ var _ = Describe("Auth module", func() {
 		var jwtCreator auth.JwtCreator
 		BeforeEach(func() {
 			jwtCreator = &auth.JwtCreatorImpl{
-				Secret:          secretVal,
-				Issuer:          issuerVal,
-				ExpirationHours: expirationVal,
+				Secret:                 secretVal,
+				Issuer:                 issuerVal,
+				ExpirationMinutes:      expirationVal,
+				RefreshExpirationHours: refreshExpirationVal,
 			}
 		})
 
@@ -134,6 +166,20 @@ var _ = Describe("Auth module", func() {
 			})
 		})
 
+		Context("GenerateRefreshToken", func() {
+			It("generates a random token and its hash", func() {
+				refreshToken, err := jwtCreator.GenerateRefreshToken()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(refreshToken.Token).NotTo(BeEmpty())
+				Expect(refreshToken.Hash).To(Equal(auth.HashRefreshToken(refreshToken.Token)))
+				Expect(refreshToken.Hash).NotTo(Equal(refreshToken.Token))
+				Expect(refreshToken.ExpiresAt).To(BeTemporally("~", time.Now().Add(refreshExpirationVal*time.Hour), time.Minute))
+
+				otherToken, _ := jwtCreator.GenerateRefreshToken()
+				Expect(otherToken.Token).NotTo(Equal(refreshToken.Token))
+			})
+		})
+
 		Context("ValidateToken", func() {
 			var token string
 			const userID = 1
@@ -148,6 +194,16 @@ var _ = Describe("Auth module", func() {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(claims.UserID).To(Equal(uint(userID)))
 					Expect(claims.Issuer).To(Equal(issuerVal))
+					Expect(claims.ExpiresAt).To(BeNumerically("~", time.Now().Add(expirationVal*time.Minute).Unix(), 5))
+					Expect(claims.IssuedAt).To(BeNumerically("~", time.Now().Unix(), 5))
+				})
+
+				It("gives every token an unique id", func() {
+					otherToken, _ := jwtCreator.GenerateToken(userID)
+					claims, _ := jwtCreator.ValidateToken(token)
+					otherClaims, _ := jwtCreator.ValidateToken(otherToken)
+					Expect(claims.Id).NotTo(BeEmpty())
+					Expect(claims.Id).NotTo(Equal(otherClaims.Id))
 				})
 			})
 
@@ -155,9 +211,9 @@ var _ = Describe("Auth module", func() {
 
 				BeforeEach(func() {
 					jwtCreator = &auth.JwtCreatorImpl{
-						Secret:          secretVal,
-						Issuer:          issuerVal,
-						ExpirationHours: 0,
+						Secret:            secretVal,
+						Issuer:            issuerVal,
+						ExpirationMinutes: 0,
 					}
 
 					token, _ = jwtCreator.GenerateToken(userID)
@@ -0,0 +1,13 @@
+package auth
+
+import myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+
+//GenerateDropBoxToken - generates a random token, through which people without an account send files to a group
+//returns the token and its hash, under which it is stored
+func GenerateDropBoxToken() (string, string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", "", myerr.NewServerErrorWrap(err, "Couldnt generate a drop box token")
+	}
+	return token, HashRefreshToken(token), nil
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+//SigningKey - asymmetric key, used for signing and validation of the tokens
+type SigningKey struct {
+	//ID - the id of the key (kid), which is the JWK thumbprint of its public key
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+	//ValidUntil - the end of the grace period of a previous key. Nil means that the key doesnt expire
+	ValidUntil *time.Time
+}
+
+//JWK - public key in the JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+//JWKSet - set of public keys in the JSON Web Key Set format
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+//LoadSigningKey - loads a RSA or Ed25519 key from a PEM file
+//private keys in PKCS#8 or PKCS#1 format and public keys in PKIX format are supported. Public keys can only validate tokens
+func LoadSigningKey(path string) (SigningKey, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return SigningKey{}, myerr.NewServerErrorWrap(err, fmt.Sprintf("Couldnt read the key file [%s]", path))
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return SigningKey{}, myerr.NewServerError(fmt.Sprintf("The key file [%s] isnt in PEM format", path))
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block [%s]", block.Type)
+	}
+
+	if err != nil {
+		return SigningKey{}, myerr.NewServerErrorWrap(err, fmt.Sprintf("Couldnt parse the key file [%s]", path))
+	}
+	return newSigningKey(key, path)
+}
+
+func newSigningKey(key interface{}, path string) (SigningKey, error) {
+	signingKey := SigningKey{}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signingKey.Method, signingKey.PrivateKey, signingKey.PublicKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		signingKey.Method, signingKey.PublicKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		signingKey.Method, signingKey.PrivateKey, signingKey.PublicKey = EdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		signingKey.Method, signingKey.PublicKey = EdDSA, k
+	default:
+		return SigningKey{}, myerr.NewServerError(fmt.Sprintf("The key in file [%s] isnt a RSA or Ed25519 key", path))
+	}
+
+	signingKey.ID = thumbprint(signingKey.JWK())
+	return signingKey, nil
+}
+
+//JWK - returns the public key in the JSON Web Key format
+func (k SigningKey) JWK() JWK {
+	jwk := JWK{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Method.Alg(),
+	}
+
+	switch publicKey := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	}
+	return jwk
+}
+
+//thumbprint - computes the JWK thumbprint (RFC 7638) of a public key, which contains only its required members
+func thumbprint(jwk JWK) string {
+	var members interface{}
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	content, _ := json.Marshal(members)
+	hash := sha256.Sum256(content)
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
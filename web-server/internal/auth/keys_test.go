@@ -0,0 +1,217 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	jwt "github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Signing keys", func() {
+	const (
+		issuerVal = "issuer"
+		userID    = 1
+	)
+
+	var keysDir string
+
+	writeKey := func(name string, blockType string, der []byte) string {
+		keyPath := path.Join(keysDir, name)
+		content := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+		Expect(ioutil.WriteFile(keyPath, content, 0600)).To(Succeed())
+		return keyPath
+	}
+
+	writeRSAKey := func(name string) string {
+		key, _ := rsa.GenerateKey(rand.Reader, 2048)
+		return writeKey(name, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	}
+
+	writeEd25519Key := func(name string) string {
+		_, key, _ := ed25519.GenerateKey(rand.Reader)
+		der, _ := x509.MarshalPKCS8PrivateKey(key)
+		return writeKey(name, "PRIVATE KEY", der)
+	}
+
+	newCreator := func(keyPath string) *auth.JwtCreatorImpl {
+		signingKey, err := auth.LoadSigningKey(keyPath)
+		Expect(err).NotTo(HaveOccurred())
+		return &auth.JwtCreatorImpl{
+			Issuer:            issuerVal,
+			ExpirationMinutes: 15,
+			SigningKey:        &signingKey,
+		}
+	}
+
+	BeforeEach(func() {
+		keysDir, _ = ioutil.TempDir("", "signing-keys")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(keysDir)
+	})
+
+	Context("LoadSigningKey", func() {
+		It("returns error for files, which arent keys", func() {
+			keyPath := path.Join(keysDir, "wrong.pem")
+			ioutil.WriteFile(keyPath, []byte("wrong-format"), 0600)
+
+			_, err := auth.LoadSigningKey(keyPath)
+			Expect(err).To(HaveOccurred())
+			_, ok := err.(*myerr.ServerError)
+			Expect(ok).To(Equal(true))
+		})
+
+		It("identifies the keys by the thumbprint of their public key", func() {
+			keyPath := writeRSAKey("rsa.pem")
+			privateKey, err := auth.LoadSigningKey(keyPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			der, _ := x509.MarshalPKIXPublicKey(privateKey.PublicKey)
+			publicKey, err := auth.LoadSigningKey(writeKey("rsa.pub", "PUBLIC KEY", der))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(privateKey.ID).NotTo(BeEmpty())
+			Expect(publicKey.ID).To(Equal(privateKey.ID))
+			Expect(publicKey.PrivateKey).To(BeNil())
+		})
+	})
+
+	DescribeTable("signing and validation of tokens",
+		func(writeKeyFile func(string) string, alg string) {
+			jwtCreator := newCreator(writeKeyFile("key.pem"))
+
+			token, err := jwtCreator.GenerateToken(userID)
+			Expect(err).NotTo(HaveOccurred())
+
+			parsed, _, _ := new(jwt.Parser).ParseUnverified(token, &auth.JwtClaim{})
+			Expect(parsed.Header["alg"]).To(Equal(alg))
+			Expect(parsed.Header["kid"]).To(Equal(jwtCreator.SigningKey.ID))
+
+			claims, err := jwtCreator.ValidateToken(token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims.UserID).To(Equal(uint(userID)))
+
+			keySet := jwtCreator.JWKS()
+			Expect(keySet.Keys).To(HaveLen(1))
+			Expect(keySet.Keys[0].Kid).To(Equal(jwtCreator.SigningKey.ID))
+			Expect(keySet.Keys[0].Alg).To(Equal(alg))
+		},
+		Entry("RS256", writeRSAKey, "RS256"),
+		Entry("EdDSA", writeEd25519Key, "EdDSA"),
+	)
+
+	Context("key rotation", func() {
+		var (
+			oldCreator *auth.JwtCreatorImpl
+			newKeyPath string
+			oldToken   string
+		)
+
+		BeforeEach(func() {
+			oldKeyPath := writeEd25519Key("old.pem")
+			oldCreator = newCreator(oldKeyPath)
+			oldToken, _ = oldCreator.GenerateToken(userID)
+			newKeyPath = writeRSAKey("new.pem")
+		})
+
+		It("accepts the tokens, signed with the previous key, during the grace period", func() {
+			validUntil := time.Now().Add(time.Minute)
+			previousKey := *oldCreator.SigningKey
+			previousKey.ValidUntil = &validUntil
+
+			jwtCreator := newCreator(newKeyPath)
+			jwtCreator.PreviousKeys = []auth.SigningKey{previousKey}
+
+			_, err := jwtCreator.ValidateToken(oldToken)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jwtCreator.JWKS().Keys).To(HaveLen(2))
+		})
+
+		It("rejects the tokens, signed with the previous key, after the grace period", func() {
+			validUntil := time.Now().Add(-time.Minute)
+			previousKey := *oldCreator.SigningKey
+			previousKey.ValidUntil = &validUntil
+
+			jwtCreator := newCreator(newKeyPath)
+			jwtCreator.PreviousKeys = []auth.SigningKey{previousKey}
+
+			_, err := jwtCreator.ValidateToken(oldToken)
+			Expect(err).To(HaveOccurred())
+			Expect(jwtCreator.JWKS().Keys).To(HaveLen(1))
+		})
+
+		It("rejects the tokens, signed with unknown keys", func() {
+			_, err := newCreator(newKeyPath).ValidateToken(oldToken)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("switch from the secret to a signing key", func() {
+		var (
+			hmacToken string
+			keyPath   string
+		)
+
+		BeforeEach(func() {
+			hmacCreator := &auth.JwtCreatorImpl{Secret: "secret", Issuer: issuerVal, ExpirationMinutes: 15}
+			hmacToken, _ = hmacCreator.GenerateToken(userID)
+			keyPath = writeRSAKey("key.pem")
+
+			os.Clearenv()
+			os.Setenv("SECRET", "secret")
+			os.Setenv("ISSUER", issuerVal)
+			os.Setenv("EXPIRATION_MINUTES", "15")
+			os.Setenv("REFRESH_EXPIRATION_HOURS", "720")
+			os.Setenv("SIGNING_KEY_FILE", keyPath)
+		})
+
+		AfterEach(func() {
+			os.Clearenv()
+		})
+
+		It("requires the moment of the rotation", func() {
+			_, err := auth.NewJwtCreatorImpl()
+			_, ok := err.(*myerr.ServerError)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("accepts the tokens without kid during the grace period", func() {
+			os.Setenv("KEY_ROTATED_AT", time.Now().Add(-time.Minute).Format(time.RFC3339))
+
+			jwtCreator, err := auth.NewJwtCreatorImpl()
+			Expect(err).NotTo(HaveOccurred())
+			_, err = jwtCreator.ValidateToken(hmacToken)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects the tokens without kid after the grace period, even if the server is restarted", func() {
+			os.Setenv("KEY_ROTATED_AT", time.Now().Add(-time.Hour).Format(time.RFC3339))
+
+			jwtCreator, err := auth.NewJwtCreatorImpl()
+			Expect(err).NotTo(HaveOccurred())
+			_, err = jwtCreator.ValidateToken(hmacToken)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("rejects tokens without kid, when there is no secret", func() {
+		hmacCreator := &auth.JwtCreatorImpl{Secret: "", Issuer: issuerVal, ExpirationMinutes: 15}
+		token, _ := hmacCreator.GenerateToken(userID)
+
+		_, err := newCreator(writeRSAKey("key.pem")).ValidateToken(token)
+		Expect(err).To(HaveOccurred())
+	})
+})
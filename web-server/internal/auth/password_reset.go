@@ -0,0 +1,13 @@
+package auth
+
+import myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+
+//GeneratePasswordResetToken - generates a random one-time token, with which a user sets a new password
+//returns the token and its hash, under which it is stored
+func GeneratePasswordResetToken() (string, string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", "", myerr.NewServerErrorWrap(err, "Couldnt generate a password reset token")
+	}
+	return token, HashRefreshToken(token), nil
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"strings"
+
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+)
+
+//PersonalAccessTokenPrefix - prefix of the personal access tokens, which distinguishes them from the JWTs
+const PersonalAccessTokenPrefix = "ushare_pat_"
+
+//GeneratePersonalAccessToken - generates a random personal access token
+//returns the token and its hash, under which it is stored
+func GeneratePersonalAccessToken() (string, string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", "", myerr.NewServerErrorWrap(err, "Couldnt generate a personal access token")
+	}
+
+	token = PersonalAccessTokenPrefix + token
+	return token, HashRefreshToken(token), nil
+}
+
+//IsPersonalAccessToken - checks if the token is a personal access token instead of a JWT
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
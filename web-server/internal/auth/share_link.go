@@ -0,0 +1,13 @@
+package auth
+
+import myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+
+//GenerateShareLinkToken - generates a random token, through which a shared file is downloaded without an account
+//returns the token and its hash, under which it is stored
+func GenerateShareLinkToken() (string, string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", "", myerr.NewServerErrorWrap(err, "Couldnt generate a share link token")
+	}
+	return token, HashRefreshToken(token), nil
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+)
+
+const (
+	//TOTPIssuer - the issuer, under which the authenticator apps show the codes
+	TOTPIssuer = "UShare"
+	totpDigits = 6
+	totpPeriod = 30
+	//totpSkew - how many time steps before and after the current one are accepted, because of clock drift
+	totpSkew          = 1
+	totpSecretSize    = 20
+	recoveryCodeCount = 10
+	recoveryCodeSize  = 5
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+//GenerateTOTPSecret - generates a random base32 encoded secret for TOTP (RFC 6238)
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", myerr.NewServerErrorWrap(err, "Couldnt generate a TOTP secret")
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+//TOTPURI - returns the otpauth URI of the secret, which can be added to an authenticator app, usually by a QR code
+func TOTPURI(account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", TOTPIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(totpDigits))
+	query.Set("period", strconv.Itoa(totpPeriod))
+
+	label := url.PathEscape(TOTPIssuer + ":" + account)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+//TOTPCode - generates the code of the secret for the given time step
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", myerr.NewServerErrorWrap(err, "Invalid TOTP secret")
+	}
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%modulo), nil
+}
+
+//ValidateTOTP - checks if the code matches the secret at the given moment
+//returns the time step of the matched code, so the code cannot be used again
+func ValidateTOTP(secret string, code string, at time.Time) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := at.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := TOTPCode(secret, step)
+		if err == nil && hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+//GenerateRecoveryCodes - generates one-time codes, which replace the TOTP codes, when the authenticator app is lost
+//returns the codes and their hashes, under which they are stored
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := randomHex(recoveryCodeSize)
+		if err != nil {
+			return nil, nil, myerr.NewServerErrorWrap(err, "Couldnt generate recovery codes")
+		}
+
+		code = code[:recoveryCodeSize] + "-" + code[recoveryCodeSize:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+//HashRecoveryCode - returns the hash of the recovery code, ignoring the dashes, the spaces and the case
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return HashRefreshToken(code)
+}
+
+//GenerateLoginChallenge - generates a random challenge, which is completed with a TOTP code instead of the password
+//returns the challenge and its hash, under which it is stored
+func GenerateLoginChallenge() (string, string, error) {
+	challenge, err := randomHex(32)
+	if err != nil {
+		return "", "", myerr.NewServerErrorWrap(err, "Couldnt generate a login challenge")
+	}
+	return challenge, HashRefreshToken(challenge), nil
+}
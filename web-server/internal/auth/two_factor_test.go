@@ -0,0 +1,81 @@
+package auth_test
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Two-factor authentication", func() {
+	//secret "12345678901234567890" from the test vectors of RFC 6238
+	const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	DescribeTable("TOTPCode matches the RFC 6238 test vectors",
+		func(unixTime int64, expectedCode string) {
+			code, err := auth.TOTPCode(rfcSecret, unixTime/30)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code).To(Equal(expectedCode))
+		},
+		Entry("at 59", int64(59), "287082"),
+		Entry("at 1111111109", int64(1111111109), "081804"),
+		Entry("at 1234567890", int64(1234567890), "005924"),
+		Entry("at 2000000000", int64(2000000000), "279037"),
+	)
+
+	Context("ValidateTOTP", func() {
+		moment := time.Unix(1111111109, 0)
+
+		It("accepts the code of the current time step", func() {
+			step, ok := auth.ValidateTOTP(rfcSecret, "081804", moment)
+			Expect(ok).To(BeTrue())
+			Expect(step).To(Equal(int64(1111111109 / 30)))
+		})
+
+		It("accepts the code of the previous time step", func() {
+			_, ok := auth.ValidateTOTP(rfcSecret, "081804", moment.Add(30*time.Second))
+			Expect(ok).To(BeTrue())
+		})
+
+		It("rejects the code of an older time step", func() {
+			_, ok := auth.ValidateTOTP(rfcSecret, "081804", moment.Add(90*time.Second))
+			Expect(ok).To(BeFalse())
+		})
+
+		It("rejects a wrong code", func() {
+			_, ok := auth.ValidateTOTP(rfcSecret, "123456", moment)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("GenerateTOTPSecret", func() {
+		It("generates a secret, which can be used in an otpauth URI", func() {
+			secret, err := auth.GenerateTOTPSecret()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secret).To(HaveLen(32))
+
+			uri, err := url.Parse(auth.TOTPURI("username", secret))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uri.Scheme).To(Equal("otpauth"))
+			Expect(uri.Host).To(Equal("totp"))
+			Expect(uri.Path).To(Equal("/UShare:username"))
+			Expect(uri.Query().Get("secret")).To(Equal(secret))
+			Expect(uri.Query().Get("issuer")).To(Equal("UShare"))
+		})
+	})
+
+	Context("GenerateRecoveryCodes", func() {
+		It("returns the codes together with their hashes", func() {
+			codes, hashes, err := auth.GenerateRecoveryCodes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(codes).To(HaveLen(10))
+			Expect(hashes).To(HaveLen(10))
+			Expect(codes[0]).To(MatchRegexp(`^[0-9a-f]{5}-[0-9a-f]{5}$`))
+			Expect(auth.HashRecoveryCode(strings.ToUpper(strings.Replace(codes[0], "-", "", 1)))).To(Equal(hashes[0]))
+		})
+	})
+})
@@ -2,11 +2,10 @@ package cron
 
 import (
 	"log"
-	"os"
-	"path"
 	"sync"
 
 	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
 )
 
 //GroupEraserJob - interface for group erase job
@@ -16,19 +15,22 @@ type GroupEraserJob interface {
 
 //GroupEraserJobImpl - implementation of GroupEraserJob
 type GroupEraserJobImpl struct {
-	uamDAO    dao.UamDAO
-	groupsDir string
+	uamDAO  dao.UamDAO
+	fmDAO   dao.FmDAO
+	storage storage.Backend
 }
 
 //NewGroupEraserJobImpl - creates an instance of GroupEraserJobImpl
-func NewGroupEraserJobImpl(uamDAO dao.UamDAO, groupsDir string) *GroupEraserJobImpl {
+func NewGroupEraserJobImpl(uamDAO dao.UamDAO, fmDAO dao.FmDAO, backend storage.Backend) *GroupEraserJobImpl {
 	return &GroupEraserJobImpl{
-		uamDAO:    uamDAO,
-		groupsDir: groupsDir,
+		uamDAO:  uamDAO,
+		fmDAO:   fmDAO,
+		storage: backend,
 	}
 }
 
 //DeleteGroups - deletes all deactivated job resources
+//the blobs, referenced by the files of the groups, are only released and later erased by BlobEraserJob
 func (i *GroupEraserJobImpl) DeleteGroups() {
 	groupNames, err := i.uamDAO.GetDeactivatedGroupNames()
 	if err != nil {
@@ -36,7 +38,12 @@ func (i *GroupEraserJobImpl) DeleteGroups() {
 		return
 	}
 
-	deleteGroups(i.groupsDir, groupNames)
+	if err = i.fmDAO.RemoveGroupFiles(groupNames); err != nil {
+		log.Printf("Couldnt remove the files of the groups in deleted state. Reason: %v\n", err)
+		return
+	}
+
+	deleteGroups(i.storage, groupNames)
 
 	err = i.uamDAO.EraseDeactivatedGroups(groupNames)
 	if err != nil {
@@ -44,14 +51,16 @@ func (i *GroupEraserJobImpl) DeleteGroups() {
 	}
 }
 
-func deleteGroups(groupsDir string, groupNames []string) {
+func deleteGroups(backend storage.Backend, groupNames []string) {
 	var wg sync.WaitGroup
 	for _, name := range groupNames {
-		groupDir := path.Join(groupsDir, name)
+		prefix := storage.GroupPrefix(name)
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			os.RemoveAll(groupDir)
+			if err := backend.DeletePrefix(prefix); err != nil {
+				log.Printf("Couldnt delete the files with prefix [%s]. Reason: %v\n", prefix, err)
+			}
 		}()
 	}
 	wg.Wait()
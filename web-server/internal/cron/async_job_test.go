@@ -8,6 +8,7 @@ import (
 	"github.com/danielpenchev98/UShare/web-server/internal/cron"
 	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -17,6 +18,7 @@ var _ = Describe("GroupEraserJobImpl", func() {
 	var (
 		groupEraser cron.GroupEraserJob
 		uamDAO      *dao_mocks.MockUamDAO
+		fmDAO       *dao_mocks.MockFmDAO
 		testDir     string
 	)
 
@@ -24,7 +26,8 @@ var _ = Describe("GroupEraserJobImpl", func() {
 		testDir, _ = os.Getwd()
 		controller := gomock.NewController(GinkgoT())
 		uamDAO = dao_mocks.NewMockUamDAO(controller)
-		groupEraser = cron.NewGroupEraserJobImpl(uamDAO, testDir)
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+		groupEraser = cron.NewGroupEraserJobImpl(uamDAO, fmDAO, storage.NewLocalBackend(testDir))
 	})
 
 	When("deleting the deactivated groups", func() {
@@ -72,12 +75,40 @@ var _ = Describe("GroupEraserJobImpl", func() {
 				groupsToDelete = []string{groupDirName}
 			})
 
+			Context("and request to remove the files of the groups fails", func() {
+				BeforeEach(func() {
+					uamDAO.EXPECT().
+						GetDeactivatedGroupNames().
+						Return(groupsToDelete, nil)
+
+					fmDAO.EXPECT().
+						RemoveGroupFiles(groupsToDelete).
+						Return(myerr.NewServerError("test-error"))
+
+					uamDAO.EXPECT().
+						EraseDeactivatedGroups(gomock.Any()).
+						Times(0)
+				})
+
+				It("shoudnt delete resources", func() {
+					groupEraser.DeleteGroups()
+
+					_, err := os.Stat(groupDirPath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(getCountFiles(groupDirPath)).To(Equal(1))
+				})
+			})
+
 			Context("and request to erase group records in db fails", func() {
 				BeforeEach(func() {
 					uamDAO.EXPECT().
 						GetDeactivatedGroupNames().
 						Return(groupsToDelete, nil)
 
+					fmDAO.EXPECT().
+						RemoveGroupFiles(groupsToDelete).
+						Return(nil)
+
 					uamDAO.EXPECT().
 						EraseDeactivatedGroups(groupsToDelete).
 						Return(myerr.NewServerError("test-error"))
@@ -98,6 +129,10 @@ var _ = Describe("GroupEraserJobImpl", func() {
 						GetDeactivatedGroupNames().
 						Return(groupsToDelete, nil)
 
+					fmDAO.EXPECT().
+						RemoveGroupFiles(groupsToDelete).
+						Return(nil)
+
 					uamDAO.EXPECT().
 						EraseDeactivatedGroups(groupsToDelete).
 						Return(nil)
@@ -0,0 +1,46 @@
+package cron
+
+import (
+	"log"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+)
+
+//BlobEraserJob - interface for the job, erasing the contents, which arent referenced by any file
+type BlobEraserJob interface {
+	DeleteBlobs()
+}
+
+//BlobEraserJobImpl - implementation of BlobEraserJob
+type BlobEraserJobImpl struct {
+	fmDAO   dao.FmDAO
+	storage storage.Backend
+}
+
+//NewBlobEraserJobImpl - creates an instance of BlobEraserJobImpl
+func NewBlobEraserJobImpl(fmDAO dao.FmDAO, backend storage.Backend) *BlobEraserJobImpl {
+	return &BlobEraserJobImpl{
+		fmDAO:   fmDAO,
+		storage: backend,
+	}
+}
+
+//DeleteBlobs - deletes the contents of all blobs, whose last reference has disappeared
+func (i *BlobEraserJobImpl) DeleteBlobs() {
+	checksums, err := i.fmDAO.GetUnreferencedBlobs()
+	if err != nil {
+		log.Printf("Couldnt fetch the unreferenced blobs. Reason: %v\n", err)
+		return
+	}
+
+	for _, checksum := range checksums {
+		key := storage.BlobKey(checksum)
+		err = i.fmDAO.EraseBlob(checksum, func() error {
+			return i.storage.Delete(key)
+		})
+		if err != nil {
+			log.Printf("Couldnt erase the blob [%s]. Reason: %v\n", checksum, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cron_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/cron"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BlobEraserJobImpl", func() {
+	const (
+		checksum      = "abcdef"
+		otherChecksum = "fedcba"
+	)
+
+	var (
+		blobEraser cron.BlobEraserJob
+		fmDAO      *dao_mocks.MockFmDAO
+		rootDir    string
+	)
+
+	BeforeEach(func() {
+		rootDir, _ = ioutil.TempDir("", "blob-eraser")
+		controller := gomock.NewController(GinkgoT())
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+
+		backend := storage.NewLocalBackend(rootDir)
+		blobEraser = cron.NewBlobEraserJobImpl(fmDAO, backend)
+
+		backend.Put(storage.BlobKey(checksum), strings.NewReader("content"))
+		backend.Put(storage.BlobKey(otherChecksum), strings.NewReader("content"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	When("request to fetch unreferenced blobs fails", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				GetUnreferencedBlobs().
+				Return(nil, myerr.NewServerError("test-error"))
+
+			fmDAO.EXPECT().
+				EraseBlob(gomock.Any(), gomock.Any()).
+				Times(0)
+		})
+
+		It("shouldnt delete any content", func() {
+			blobEraser.DeleteBlobs()
+
+			_, err := os.Stat(path.Join(rootDir, storage.BlobKey(checksum)))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("there are unreferenced blobs", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				GetUnreferencedBlobs().
+				Return([]string{checksum}, nil)
+
+			fmDAO.EXPECT().
+				EraseBlob(checksum, gomock.Any()).
+				DoAndReturn(func(_ string, eraseContent func() error) error {
+					return eraseContent()
+				})
+		})
+
+		It("deletes only their content", func() {
+			blobEraser.DeleteBlobs()
+
+			_, err := os.Stat(path.Join(rootDir, storage.BlobKey(checksum)))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			_, err = os.Stat(path.Join(rootDir, storage.BlobKey(otherChecksum)))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("a blob is referenced again before it is erased", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				GetUnreferencedBlobs().
+				Return([]string{checksum}, nil)
+
+			fmDAO.EXPECT().
+				EraseBlob(checksum, gomock.Any()).
+				Return(nil)
+		})
+
+		It("keeps its content", func() {
+			blobEraser.DeleteBlobs()
+
+			_, err := os.Stat(path.Join(rootDir, storage.BlobKey(checksum)))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"log"
+	"time"
+)
+
+//ExpireFunc - removes the records, which expired before the given moment, and returns their number
+type ExpireFunc func(expiredBefore time.Time) (int64, error)
+
+//ExpirerJob - interface for the jobs, removing the records, which have expired
+type ExpirerJob interface {
+	Expire()
+}
+
+//ExpirerJobImpl - implementation of ExpirerJob
+type ExpirerJobImpl struct {
+	name   string
+	expire ExpireFunc
+}
+
+//NewExpirerJobImpl - creates an instance of ExpirerJobImpl
+//name describes the removed records in the logs, e.g. "expired invitations"
+func NewExpirerJobImpl(name string, expire ExpireFunc) *ExpirerJobImpl {
+	return &ExpirerJobImpl{
+		name:   name,
+		expire: expire,
+	}
+}
+
+//Expire - removes the records, whose expiration moment has passed
+func (i *ExpirerJobImpl) Expire() {
+	count, err := i.expire(time.Now())
+	if err != nil {
+		log.Printf("Couldnt remove the %s. Reason: %v\n", i.name, err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("Removed [%d] %s\n", count, i.name)
+	}
+}
@@ -0,0 +1,65 @@
+package cron_test
+
+import (
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/cron"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExpirerJobImpl", func() {
+	//newExpire - creates a mock of the DAO, which expects two calls of its expire method returning the results of result
+	type newExpire func(controller *gomock.Controller, result cron.ExpireFunc) cron.ExpireFunc
+
+	DescribeTable("removes the expired records with the current moment and survives the failures",
+		func(newExpire newExpire) {
+			errs := []error{myerr.NewServerError("test-error"), nil}
+			result := func(expiredBefore time.Time) (int64, error) {
+				Expect(expiredBefore).To(BeTemporally("~", time.Now(), time.Minute))
+				err := errs[0]
+				errs = errs[1:]
+				return 2, err
+			}
+
+			expirer := cron.NewExpirerJobImpl("expired records", newExpire(gomock.NewController(GinkgoT()), result))
+			Expect(expirer.Expire).NotTo(Panic())
+			Expect(expirer.Expire).NotTo(Panic())
+			Expect(errs).To(BeEmpty())
+		},
+		Entry("invitations", func(controller *gomock.Controller, result cron.ExpireFunc) cron.ExpireFunc {
+			uamDAO := dao_mocks.NewMockUamDAO(controller)
+			uamDAO.EXPECT().DeleteExpiredInvitations(gomock.Any()).DoAndReturn(result).Times(2)
+			return uamDAO.DeleteExpiredInvitations
+		}),
+		Entry("tokens", func(controller *gomock.Controller, result cron.ExpireFunc) cron.ExpireFunc {
+			tokenDAO := dao_mocks.NewMockTokenDAO(controller)
+			tokenDAO.EXPECT().DeleteExpiredTokens(gomock.Any()).DoAndReturn(result).Times(2)
+			return tokenDAO.DeleteExpiredTokens
+		}),
+		Entry("failed logins", func(controller *gomock.Controller, result cron.ExpireFunc) cron.ExpireFunc {
+			loginFailureDAO := dao_mocks.NewMockLoginFailureDAO(controller)
+			loginFailureDAO.EXPECT().DeleteExpiredLoginFailures(gomock.Any()).DoAndReturn(result).Times(2)
+			return loginFailureDAO.DeleteExpiredLoginFailures
+		}),
+		Entry("share links", func(controller *gomock.Controller, result cron.ExpireFunc) cron.ExpireFunc {
+			fmDAO := dao_mocks.NewMockFmDAO(controller)
+			fmDAO.EXPECT().DeleteStaleShareLinks(gomock.Any()).DoAndReturn(result).Times(2)
+			return fmDAO.DeleteStaleShareLinks
+		}),
+		Entry("drop boxes", func(controller *gomock.Controller, result cron.ExpireFunc) cron.ExpireFunc {
+			fmDAO := dao_mocks.NewMockFmDAO(controller)
+			fmDAO.EXPECT().DeleteExpiredDropBoxes(gomock.Any()).DoAndReturn(result).Times(2)
+			return fmDAO.DeleteExpiredDropBoxes
+		}),
+		Entry("suspensions", func(controller *gomock.Controller, result cron.ExpireFunc) cron.ExpireFunc {
+			adminDAO := dao_mocks.NewMockAdminDAO(controller)
+			adminDAO.EXPECT().LiftExpiredSuspensions(gomock.Any()).DoAndReturn(result).Times(2)
+			return adminDAO.LiftExpiredSuspensions
+		}),
+	)
+})
@@ -0,0 +1,52 @@
+package cron
+
+import (
+	"log"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+)
+
+//TrashPurgerJob - interface for the job, permanently removing the files, which were in the trash for too long
+type TrashPurgerJob interface {
+	PurgeTrash()
+}
+
+//TrashPurgerJobImpl - implementation of TrashPurgerJob
+type TrashPurgerJobImpl struct {
+	fmDAO   dao.FmDAO
+	storage storage.Backend
+	maxAge  time.Duration
+}
+
+//NewTrashPurgerJobImpl - creates an instance of TrashPurgerJobImpl
+//maxAge is how long the files are kept in the trash before being purged
+func NewTrashPurgerJobImpl(fmDAO dao.FmDAO, backend storage.Backend, maxAge time.Duration) *TrashPurgerJobImpl {
+	return &TrashPurgerJobImpl{
+		fmDAO:   fmDAO,
+		storage: backend,
+		maxAge:  maxAge,
+	}
+}
+
+//PurgeTrash - permanently removes the files, which were moved to the trash more than maxAge ago
+//the shared blobs are only released and later erased by BlobEraserJob
+func (i *TrashPurgerJobImpl) PurgeTrash() {
+	fileInfos, err := i.fmDAO.PurgeTrashedFiles(time.Now().Add(-i.maxAge))
+	if err != nil {
+		log.Printf("Couldnt purge the trashed files. Reason: %v\n", err)
+		return
+	}
+
+	for _, fileInfo := range fileInfos {
+		if fileInfo.Deduplicated {
+			continue
+		}
+
+		key := storage.FileKey(fileInfo.GroupName, fileInfo.ID)
+		if err = i.storage.Delete(key); err != nil {
+			log.Printf("Couldnt delete the content of file [%d] in group [%s]. Reason: %v\n", fileInfo.ID, fileInfo.GroupName, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cron_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/cron"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TrashPurgerJobImpl", func() {
+	const (
+		groupName = "test-group"
+		maxAge    = 24 * time.Hour
+	)
+
+	var (
+		trashPurger cron.TrashPurgerJob
+		fmDAO       *dao_mocks.MockFmDAO
+		rootDir     string
+	)
+
+	BeforeEach(func() {
+		rootDir, _ = ioutil.TempDir("", "trash-purger")
+		controller := gomock.NewController(GinkgoT())
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+
+		backend := storage.NewLocalBackend(rootDir)
+		trashPurger = cron.NewTrashPurgerJobImpl(fmDAO, backend, maxAge)
+
+		backend.Put(storage.FileKey(groupName, 1), strings.NewReader("content"))
+		backend.Put(storage.BlobKey("abcdef"), strings.NewReader("content"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	When("request to purge the trashed files fails", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				PurgeTrashedFiles(gomock.Any()).
+				Return(nil, myerr.NewServerError("test-error"))
+		})
+
+		It("shouldnt delete any content", func() {
+			trashPurger.PurgeTrash()
+
+			_, err := os.Stat(path.Join(rootDir, storage.FileKey(groupName, 1)))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("trashed files are purged", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				PurgeTrashedFiles(gomock.Any()).
+				DoAndReturn(func(trashedBefore time.Time) ([]dao.GroupFileInfo, error) {
+					Expect(trashedBefore).To(BeTemporally("~", time.Now().Add(-maxAge), time.Minute))
+					return []dao.GroupFileInfo{
+						{FileInfo: models.FileInfo{ID: 1}, GroupName: groupName},
+						{FileInfo: models.FileInfo{ID: 2, Checksum: "abcdef", Deduplicated: true}, GroupName: groupName},
+					}, nil
+				})
+		})
+
+		It("deletes the contents kept under the group, but leaves the blobs to the blob eraser", func() {
+			trashPurger.PurgeTrash()
+
+			_, err := os.Stat(path.Join(rootDir, storage.FileKey(groupName, 1)))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			_, err = os.Stat(path.Join(rootDir, storage.BlobKey("abcdef")))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
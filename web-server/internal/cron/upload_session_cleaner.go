@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"log"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+)
+
+//UploadSessionCleanerJob - interface for the job, removing the upload sessions of deleted users and the abandoned ones
+type UploadSessionCleanerJob interface {
+	CleanUploadSessions()
+}
+
+//UploadSessionCleanerJobImpl - implementation of UploadSessionCleanerJob
+type UploadSessionCleanerJobImpl struct {
+	fmDAO   dao.FmDAO
+	storage storage.Backend
+	maxAge  time.Duration
+}
+
+//NewUploadSessionCleanerJobImpl - creates an instance of UploadSessionCleanerJobImpl
+//maxAge is how long an upload session is kept without receiving any chunk
+func NewUploadSessionCleanerJobImpl(fmDAO dao.FmDAO, backend storage.Backend, maxAge time.Duration) *UploadSessionCleanerJobImpl {
+	return &UploadSessionCleanerJobImpl{
+		fmDAO:   fmDAO,
+		storage: backend,
+		maxAge:  maxAge,
+	}
+}
+
+//CleanUploadSessions - removes the received chunks and the upload sessions, whose owners were deleted
+//or which didnt receive any chunk for more than maxAge
+//the session is kept, if its chunks couldnt be removed, so the removal is retried on the next run
+func (i *UploadSessionCleanerJobImpl) CleanUploadSessions() {
+	sessions, err := i.fmDAO.GetAbandonedUploadSessions(time.Now().Add(-i.maxAge))
+	if err != nil {
+		log.Printf("Couldnt fetch the abandoned upload sessions. Reason: %v\n", err)
+		return
+	}
+
+	for _, session := range sessions {
+		prefix := storage.UploadPrefix(session.GroupName, session.ID)
+		if err = i.storage.DeletePrefix(prefix); err != nil {
+			log.Printf("Couldnt delete the chunks of upload session [%d] in group [%s]. Reason: %v\n", session.ID, session.GroupName, err)
+			continue
+		}
+
+		if err = i.fmDAO.RemoveUploadSession(session.ID); err != nil {
+			log.Printf("Couldnt delete upload session [%d]. Reason: %v\n", session.ID, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cron_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/cron"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UploadSessionCleanerJobImpl", func() {
+	const (
+		groupName = "test-group"
+		sessionID = uint(3)
+		maxAge    = 24 * time.Hour
+	)
+
+	var (
+		cleaner cron.UploadSessionCleanerJob
+		fmDAO   *dao_mocks.MockFmDAO
+		rootDir string
+	)
+
+	BeforeEach(func() {
+		rootDir, _ = ioutil.TempDir("", "upload-session-cleaner")
+		controller := gomock.NewController(GinkgoT())
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+
+		backend := storage.NewLocalBackend(rootDir)
+		cleaner = cron.NewUploadSessionCleanerJobImpl(fmDAO, backend, maxAge)
+
+		backend.Put(storage.ChunkKey(groupName, sessionID, 0, 7), strings.NewReader("content"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	When("request to fetch the abandoned upload sessions fails", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				GetAbandonedUploadSessions(gomock.Any()).
+				Return(nil, myerr.NewServerError("test-error"))
+		})
+
+		It("shouldnt delete any chunks", func() {
+			cleaner.CleanUploadSessions()
+
+			_, err := os.Stat(path.Join(rootDir, storage.ChunkKey(groupName, sessionID, 0, 7)))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("there are abandoned upload sessions", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				GetAbandonedUploadSessions(gomock.Any()).
+				DoAndReturn(func(updatedBefore time.Time) ([]dao.GroupUploadSession, error) {
+					Expect(updatedBefore).To(BeTemporally("~", time.Now().Add(-maxAge), time.Minute))
+					return []dao.GroupUploadSession{
+						{UploadSession: models.UploadSession{ID: sessionID, UpdatedAt: updatedBefore.Add(-time.Hour)}, GroupName: groupName},
+					}, nil
+				})
+
+			fmDAO.EXPECT().
+				RemoveUploadSession(sessionID).
+				Return(nil)
+		})
+
+		It("deletes the chunks and the sessions, which didnt receive chunks for more than the max age", func() {
+			cleaner.CleanUploadSessions()
+
+			_, err := os.Stat(path.Join(rootDir, storage.ChunkKey(groupName, sessionID, 0, 7)))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
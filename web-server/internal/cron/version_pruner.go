@@ -0,0 +1,51 @@
+package cron
+
+import (
+	"log"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+)
+
+//VersionPrunerJob - interface for the job, removing the oldest versions of the files
+type VersionPrunerJob interface {
+	PruneVersions()
+}
+
+//VersionPrunerJobImpl - implementation of VersionPrunerJob
+type VersionPrunerJobImpl struct {
+	fmDAO              dao.FmDAO
+	storage            storage.Backend
+	defaultMaxVersions uint
+}
+
+//NewVersionPrunerJobImpl - creates an instance of VersionPrunerJobImpl
+//defaultMaxVersions is used for the groups, which havent set their own limit
+func NewVersionPrunerJobImpl(fmDAO dao.FmDAO, backend storage.Backend, defaultMaxVersions uint) *VersionPrunerJobImpl {
+	return &VersionPrunerJobImpl{
+		fmDAO:              fmDAO,
+		storage:            backend,
+		defaultMaxVersions: defaultMaxVersions,
+	}
+}
+
+//PruneVersions - removes the versions of the files, which exceed the limit of their group
+//the shared blobs are only released and later erased by BlobEraserJob
+func (i *VersionPrunerJobImpl) PruneVersions() {
+	versions, err := i.fmDAO.RemoveExcessFileVersions(i.defaultMaxVersions)
+	if err != nil {
+		log.Printf("Couldnt remove the excess file versions. Reason: %v\n", err)
+		return
+	}
+
+	for _, version := range versions {
+		if version.Deduplicated {
+			continue
+		}
+
+		key := storage.FileKey(version.GroupName, version.ID)
+		if err = i.storage.Delete(key); err != nil {
+			log.Printf("Couldnt delete the content of file [%d] in group [%s]. Reason: %v\n", version.ID, version.GroupName, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cron_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/cron"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VersionPrunerJobImpl", func() {
+	const (
+		groupName          = "test-group"
+		defaultMaxVersions = 3
+	)
+
+	var (
+		versionPruner cron.VersionPrunerJob
+		fmDAO         *dao_mocks.MockFmDAO
+		rootDir       string
+	)
+
+	BeforeEach(func() {
+		rootDir, _ = ioutil.TempDir("", "version-pruner")
+		controller := gomock.NewController(GinkgoT())
+		fmDAO = dao_mocks.NewMockFmDAO(controller)
+
+		backend := storage.NewLocalBackend(rootDir)
+		versionPruner = cron.NewVersionPrunerJobImpl(fmDAO, backend, defaultMaxVersions)
+
+		backend.Put(storage.FileKey(groupName, 1), strings.NewReader("content"))
+		backend.Put(storage.BlobKey("abcdef"), strings.NewReader("content"))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	When("request to remove the excess versions fails", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				RemoveExcessFileVersions(uint(defaultMaxVersions)).
+				Return(nil, myerr.NewServerError("test-error"))
+		})
+
+		It("shouldnt delete any content", func() {
+			versionPruner.PruneVersions()
+
+			_, err := os.Stat(path.Join(rootDir, storage.FileKey(groupName, 1)))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	When("excess versions are removed", func() {
+		BeforeEach(func() {
+			fmDAO.EXPECT().
+				RemoveExcessFileVersions(uint(defaultMaxVersions)).
+				Return([]dao.GroupFileInfo{
+					{FileInfo: models.FileInfo{ID: 1}, GroupName: groupName},
+					{FileInfo: models.FileInfo{ID: 2, Checksum: "abcdef", Deduplicated: true}, GroupName: groupName},
+				}, nil)
+		})
+
+		It("deletes the contents kept under the group, but leaves the blobs to the blob eraser", func() {
+			versionPruner.PruneVersions()
+
+			_, err := os.Stat(path.Join(rootDir, storage.FileKey(groupName, 1)))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			_, err = os.Stat(path.Join(rootDir, storage.BlobKey("abcdef")))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
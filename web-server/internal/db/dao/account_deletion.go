@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+)
+
+const (
+	//GroupPolicyTransfer - the owned groups are transferred to the member with the highest role or deactivated, if there are no other members
+	GroupPolicyTransfer = "transfer"
+	//GroupPolicyDeactivate - the owned groups are deactivated and their resources are later erased
+	GroupPolicyDeactivate = "deactivate"
+	//FilePolicyReassign - the files of the user in the remaining groups are given to the owners of the groups
+	FilePolicyReassign = "reassign"
+	//FilePolicyDelete - the files of the user in the remaining groups are moved to the trash of the groups
+	FilePolicyDelete = "delete"
+)
+
+//AccountDeletionPolicy - determines what happens with the groups and the files of a deleted user
+//the empty policies mean transfer and reassign, so no data is lost by default
+type AccountDeletionPolicy struct {
+	GroupPolicy string
+	FilePolicy  string
+}
+
+func (p AccountDeletionPolicy) withDefaults() (AccountDeletionPolicy, error) {
+	if p.GroupPolicy == "" {
+		p.GroupPolicy = GroupPolicyTransfer
+	} else if p.GroupPolicy != GroupPolicyTransfer && p.GroupPolicy != GroupPolicyDeactivate {
+		return p, myerr.NewClientError(fmt.Sprintf("Invalid group policy [%s]. Valid policies are transfer and deactivate", p.GroupPolicy))
+	}
+
+	if p.FilePolicy == "" {
+		p.FilePolicy = FilePolicyReassign
+	} else if p.FilePolicy != FilePolicyReassign && p.FilePolicy != FilePolicyDelete {
+		return p, myerr.NewClientError(fmt.Sprintf("Invalid file policy [%s]. Valid policies are reassign and delete", p.FilePolicy))
+	}
+	return p, nil
+}
+
+//handOverGroupWithConn - transfers the group of a deleted user to the member with the highest role or deactivates it
+func handOverGroupWithConn(tx *gorm.DB, group models.Group, groupPolicy string) error {
+	if groupPolicy == GroupPolicyDeactivate {
+		return deactivateGroupWithConn(tx, group)
+	}
+
+	var successor models.Membership
+	result := tx.Where("group_id = ?", group.ID).
+		Where("user_id <> ?", group.OwnerID).
+		Order(fmt.Sprintf("CASE role WHEN '%s' THEN 1 WHEN '%s' THEN 2 ELSE 3 END, created_at", models.RoleAdmin, models.RoleEditor)).
+		Take(&successor)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return deactivateGroupWithConn(tx, group)
+	} else if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the members of the group")
+	}
+	return transferOwnershipWithConn(tx, group, successor)
+}
+
+//handOverFilesWithConn - reassigns or trashes the files of a deleted user in the active groups. The folders are always reassigned
+//the files in the deactivated groups are left to the group eraser
+func handOverFilesWithConn(tx *gorm.DB, userID uint, filePolicy string) error {
+	activeGroups := tx.Table("groups").Select("id").Where("active = ?", true)
+
+	if filePolicy == FilePolicyDelete {
+		result := tx.Table("file_infos").
+			Where("owner_id = ?", userID).
+			Where("deleted_at IS NULL").
+			Where("group_id IN (?)", activeGroups).
+			Updates(map[string]interface{}{"deleted_at": time.Now(), "deleted_by": userID})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with moving the files of the user to the trash")
+		}
+	} else {
+		result := tx.Exec(`UPDATE file_infos SET owner_id = groups.owner_id FROM groups
+			WHERE file_infos.group_id = groups.id AND groups.active = ? AND file_infos.owner_id = ?`, true, userID)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with reassigning the files of the user")
+		}
+	}
+
+	result := tx.Exec(`UPDATE folders SET owner_id = groups.owner_id FROM groups
+		WHERE folders.group_id = groups.id AND groups.active = ? AND folders.owner_id = ?`, true, userID)
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with reassigning the folders of the user")
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen --source=admin_dao.go --destination dao_mocks/admin_dao.go --package dao_mocks
+
+//AdminDAO - interface for working with the Database in regards to the administration of the server
+type AdminDAO interface {
+	Migrate() error
+	SetAdmin(username string, isAdmin bool) error
+	IsAdmin(userID uint) (bool, error)
+	GetUsers() ([]UserInfo, error)
+	SuspendUser(username string, reason string, until *time.Time) error
+	UnsuspendUser(username string) error
+	GetSuspension(userID uint) (*Suspension, error)
+	LiftExpiredSuspensions(now time.Time) (int64, error)
+	DeactivateGroup(groupName string) error
+	ReassignGroupOwnership(groupName string, username string) error
+	GetStorageTotals() (StorageTotals, error)
+}
+
+//UserInfo - user together with the number of its groups and the storage, used by its files
+type UserInfo struct {
+	models.User
+	Groups    int64
+	Files     int64
+	UsedBytes int64
+}
+
+//StorageTotals - system-wide number of users, active groups and files and the storage, used by them
+//UsedBytes is the sum of the sizes of all files, while StoredBytes counts the deduplicated content only once
+type StorageTotals struct {
+	Users       int64
+	Groups      int64
+	Files       int64
+	UsedBytes   int64
+	StoredBytes int64
+}
+
+//Suspension - the reason and the end of the suspension of a user. Until is nil, if the suspension lasts until an administrator lifts it
+type Suspension struct {
+	Reason string
+	Until  *time.Time
+}
+
+//AdminDAOImpl - implementation of AdminDAO
+type AdminDAOImpl struct {
+	dbConn *gorm.DB
+}
+
+//NewAdminDAOImpl - function for creation an instance of AdminDAOImpl
+func NewAdminDAOImpl(dbConn *gorm.DB) *AdminDAOImpl {
+	return &AdminDAOImpl{dbConn: dbConn}
+}
+
+//Migrate - function which updates the models(table structure) in db
+func (i *AdminDAOImpl) Migrate() error {
+	return i.dbConn.AutoMigrate(models.User{})
+}
+
+//SetAdmin - grants or revokes the administrator role of the user
+func (i *AdminDAOImpl) SetAdmin(username string, isAdmin bool) error {
+	result := i.dbConn.Model(&models.User{}).
+		Where("username = ?", username).
+		Update("is_admin", isAdmin)
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the administrator role")
+	} else if result.RowsAffected == 0 {
+		return myerr.NewItemNotFoundError(fmt.Sprintf("User [%s] does not exist", username))
+	}
+	log.Printf("Administrator role of user [%s] is set to [%t]\n", username, isAdmin)
+	return nil
+}
+
+//IsAdmin - checks if the user is an administrator of the server
+func (i *AdminDAOImpl) IsAdmin(userID uint) (bool, error) {
+	var count int64
+	result := i.dbConn.Model(&models.User{}).
+		Where("id = ?", userID).
+		Where("is_admin").
+		Count(&count)
+
+	if result.Error != nil {
+		return false, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the administrator role")
+	}
+	return count > 0, nil
+}
+
+//GetUsers - retrieves all users together with the number of their groups and files and the storage, used by their files
+func (i *AdminDAOImpl) GetUsers() ([]UserInfo, error) {
+	var users []UserInfo
+	result := i.dbConn.Table("users").
+		Select("users.*, (?) AS groups, (?) AS files, (?) AS used_bytes",
+			i.dbConn.Table("memberships").Select("COUNT(*)").Where("memberships.user_id = users.id"),
+			i.dbConn.Table("file_infos").Select("COUNT(*)").Where("file_infos.owner_id = users.id"),
+			i.dbConn.Table("file_infos").Select("COALESCE(SUM(size), 0)").Where("file_infos.owner_id = users.id")).
+		Order("users.id").
+		Find(&users)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching all users")
+	}
+	return users, nil
+}
+
+//SuspendUser - suspends the user, who can no longer login or use the issued tokens. The groups and the files of the user are kept
+//if the user is already suspended, the reason and the end of the suspension are replaced
+//the administrators cannot be suspended, so their role has to be revoked first
+func (i *AdminDAOImpl) SuspendUser(username string, reason string, until *time.Time) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		user, err := lockUserWithConn(tx, username)
+		if err != nil {
+			return err
+		} else if user.IsAdmin {
+			return myerr.NewClientError("Administrators cannot be suspended")
+		}
+
+		suspension := map[string]interface{}{
+			"suspended_at":      time.Now(),
+			"suspension_reason": reason,
+			"suspended_until":   until,
+		}
+		if result := tx.Model(&user).Updates(suspension); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the suspension of the user")
+		}
+
+		if err = revokeRefreshTokensWithConn(tx.Where("user_id = ?", user.ID)); err != nil {
+			return err
+		}
+		log.Printf("User [%s] is suspended\n", username)
+		return nil
+	})
+}
+
+//UnsuspendUser - lifts the suspension of the user
+func (i *AdminDAOImpl) UnsuspendUser(username string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		user, err := lockUserWithConn(tx, username)
+		if err != nil {
+			return err
+		} else if user.SuspendedAt == nil {
+			return myerr.NewClientError(fmt.Sprintf("User [%s] isnt suspended", username))
+		}
+
+		if result := tx.Model(&user).Updates(liftedSuspension()); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with lifting the suspension of the user")
+		}
+		log.Printf("Suspension of user [%s] is lifted\n", username)
+		return nil
+	})
+}
+
+//GetSuspension - retrieves the suspension of the user, which hasnt ended yet. Returns nil if the user isnt suspended
+func (i *AdminDAOImpl) GetSuspension(userID uint) (*Suspension, error) {
+	var user models.User
+	result := i.dbConn.Select("suspension_reason", "suspended_until").
+		Where("id = ? AND suspended_at IS NOT NULL", userID).
+		Where("suspended_until IS NULL OR suspended_until > ?", time.Now()).
+		Take(&user)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the suspension of the user")
+	}
+	return &Suspension{Reason: user.SuspensionReason, Until: user.SuspendedUntil}, nil
+}
+
+//LiftExpiredSuspensions - lifts the suspensions, which ended before the given moment
+func (i *AdminDAOImpl) LiftExpiredSuspensions(now time.Time) (int64, error) {
+	result := i.dbConn.Model(&models.User{}).
+		Where("suspended_at IS NOT NULL AND suspended_until <= ?", now).
+		Updates(liftedSuspension())
+
+	if result.Error != nil {
+		return 0, myerr.NewServerErrorWrap(result.Error, "Problem with lifting the expired suspensions")
+	}
+	return result.RowsAffected, nil
+}
+
+func liftedSuspension() map[string]interface{} {
+	return map[string]interface{}{
+		"suspended_at":      nil,
+		"suspension_reason": "",
+		"suspended_until":   nil,
+	}
+}
+
+//DeactivateGroup - deactivates the group regardless of its owner. Its resources are later erased by the cron jobs
+func (i *AdminDAOImpl) DeactivateGroup(groupName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := lockGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		}
+		return deactivateGroupWithConn(tx, group)
+	})
+}
+
+//ReassignGroupOwnership - makes the user the owner of the group, even if the user isnt a member of it
+//the former owner stays in the group as an admin, if still a member
+func (i *AdminDAOImpl) ReassignGroupOwnership(groupName string, username string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := lockGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		}
+
+		user, err := lockUserWithConn(tx, username)
+		if err != nil {
+			return err
+		} else if user.ID == group.OwnerID {
+			return myerr.NewClientError(fmt.Sprintf("User [%s] is already the owner of the group", username))
+		}
+
+		result := tx.Model(&models.Membership{}).
+			Where("group_id = ?", group.ID).
+			Where("user_id = ?", group.OwnerID).
+			Update("role", models.RoleAdmin)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the membership in db")
+		}
+
+		target, err := getMembershipWithConn(tx, user.ID, group.ID)
+		if _, ok := err.(*myerr.ItemNotFoundError); ok {
+			target = models.Membership{UserID: user.ID, GroupID: group.ID, Role: models.RoleOwner}
+			if result = tx.Create(&target); result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of the membership in db")
+			}
+		} else if err != nil {
+			return err
+		}
+		return transferOwnershipWithConn(tx, group, target)
+	})
+}
+
+//GetStorageTotals - returns the system-wide number of users, active groups and files and the storage, used by them
+//the trashed files are also included, as their content is still stored
+func (i *AdminDAOImpl) GetStorageTotals() (StorageTotals, error) {
+	var totals StorageTotals
+	result := i.dbConn.Raw(`SELECT (SELECT COUNT(*) FROM users) AS users,
+		(SELECT COUNT(*) FROM groups WHERE active) AS groups,
+		(SELECT COUNT(*) FROM file_infos) AS files,
+		(SELECT COALESCE(SUM(size), 0) FROM file_infos) AS used_bytes,
+		(SELECT COALESCE(SUM(size), 0) FROM blobs) + (SELECT COALESCE(SUM(size), 0) FROM file_infos WHERE NOT deduplicated) AS stored_bytes`).
+		Scan(&totals)
+
+	if result.Error != nil {
+		return StorageTotals{}, myerr.NewServerErrorWrap(result.Error, "Problem with calculating the storage totals")
+	}
+	return totals, nil
+}
+
+//lockUserWithConn - fetches the user with the given username and locks it until the end of the transaction
+func lockUserWithConn(tx *gorm.DB, username string) (models.User, error) {
+	var user models.User
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("username = ?", username).
+		Take(&user)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return user, myerr.NewItemNotFoundError(fmt.Sprintf("User [%s] does not exist", username))
+	} else if result.Error != nil {
+		return user, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if user exists")
+	}
+	return user, nil
+}
+
+//lockGroupWithConn - fetches the active group with the given name and locks it until the end of the transaction
+func lockGroupWithConn(tx *gorm.DB, groupName string) (models.Group, error) {
+	var group models.Group
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("name = ?", groupName).
+		Take(&group)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return group, myerr.NewItemNotFoundError(fmt.Sprintf("Group [%s] does not exist", groupName))
+	} else if result.Error != nil {
+		return group, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if group exists")
+	} else if !group.Active {
+		return group, myerr.NewClientError("The group is currently being deleted")
+	}
+	return group, nil
+}
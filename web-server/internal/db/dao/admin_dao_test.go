@@ -0,0 +1,267 @@
+package dao
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("AdminDAO", func() {
+	var (
+		adminDao AdminDAO
+		mock     sqlmock.Sqlmock
+	)
+
+	const (
+		userID    = 3
+		ownerID   = 4
+		groupID   = 5
+		username  = "username"
+		groupName = "group"
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		adminDao = NewAdminDAOImpl(gdb)
+	})
+
+	Context("SetAdmin", func() {
+		When("the user doesnt exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "is_admin"=$1,"updated_at"=$2 WHERE username = $3`)).
+					WithArgs(true, sqlmock.AnyArg(), username).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			})
+
+			It("returns not found error", func() {
+				err := adminDao.SetAdmin(username, true)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("IsAdmin", func() {
+		BeforeEach(func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users" WHERE id = $1 AND is_admin`)).
+				WithArgs(userID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		})
+
+		It("returns whether the user is an administrator", func() {
+			isAdmin, err := adminDao.IsAdmin(userID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(isAdmin).To(BeTrue())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Context("SuspendUser", func() {
+		const lookupQuery = `SELECT * FROM "users" WHERE username = $1 LIMIT 1 FOR UPDATE`
+
+		When("the user is an administrator", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lookupQuery)).
+					WithArgs(username).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "username", "is_admin"}).AddRow(userID, username, true))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := adminDao.SuspendUser(username, "spam", nil)
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the user can be suspended", func() {
+			until := time.Now().Add(time.Hour)
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lookupQuery)).
+					WithArgs(username).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "username", "is_admin"}).AddRow(userID, username, false))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "suspended_at"=$1,"suspended_until"=$2,"suspension_reason"=$3,"updated_at"=$4 WHERE "id" = $5`)).
+					WithArgs(sqlmock.AnyArg(), until, "spam", sqlmock.AnyArg(), userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "revoked_at"=$1 WHERE user_id = $2 AND revoked_at IS NULL`)).
+					WithArgs(sqlmock.AnyArg(), userID).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			})
+
+			It("suspends the user and revokes the refresh tokens", func() {
+				Expect(adminDao.SuspendUser(username, "spam", &until)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("UnsuspendUser", func() {
+		When("the user isnt suspended", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE username = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(username).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(userID, username))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := adminDao.UnsuspendUser(username)
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("User [username] isnt suspended"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("GetSuspension", func() {
+		const suspensionQuery = `SELECT "suspension_reason","suspended_until" FROM "users" WHERE (id = $1 AND suspended_at IS NOT NULL) AND (suspended_until IS NULL OR suspended_until > $2) LIMIT 1`
+
+		When("the user isnt suspended", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(suspensionQuery)).
+					WithArgs(userID, sqlmock.AnyArg()).
+					WillReturnError(gorm.ErrRecordNotFound)
+			})
+
+			It("returns no suspension", func() {
+				suspension, err := adminDao.GetSuspension(userID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(suspension).To(BeNil())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the user is suspended", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(suspensionQuery)).
+					WithArgs(userID, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"suspension_reason", "suspended_until"}).AddRow("spam", nil))
+			})
+
+			It("returns the suspension", func() {
+				suspension, err := adminDao.GetSuspension(userID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(suspension).To(Equal(&Suspension{Reason: "spam"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("LiftExpiredSuspensions", func() {
+		now := time.Now()
+
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "suspended_at"=$1,"suspended_until"=$2,"suspension_reason"=$3,"updated_at"=$4 WHERE suspended_at IS NOT NULL AND suspended_until <= $5`)).
+				WithArgs(nil, nil, "", sqlmock.AnyArg(), now).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectCommit()
+		})
+
+		It("lifts the suspensions, which have ended", func() {
+			count, err := adminDao.LiftExpiredSuspensions(now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(2)))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Context("DeactivateGroup", func() {
+		When("the group is already being deleted", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(groupName).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(groupID, groupName, false))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := adminDao.DeactivateGroup(groupName)
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("ReassignGroupOwnership", func() {
+		When("the user isnt a member of the group", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(groupName).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "owner_id", "active"}).AddRow(groupID, groupName, ownerID, true))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE username = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(username).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(userID, username))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "memberships" SET "role"=$1,"updated_at"=$2 WHERE group_id = $3 AND user_id = $4`)).
+					WithArgs(models.RoleAdmin, sqlmock.AnyArg(), groupID, ownerID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+					WithArgs(userID, groupID).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "memberships"`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), groupID, userID, models.RoleOwner).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "groups" SET "owner_id"=$1,"updated_at"=$2 WHERE "id" = $3`)).
+					WithArgs(userID, sqlmock.AnyArg(), groupID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "memberships" SET "role"=$1,"updated_at"=$2 WHERE "id" = $3`)).
+					WithArgs(models.RoleOwner, sqlmock.AnyArg(), 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("makes the user a member and the owner of the group", func() {
+				Expect(adminDao.ReassignGroupOwnership(groupName, username)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("GetStorageTotals", func() {
+		BeforeEach(func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT (SELECT COUNT(*) FROM users) AS users`)).
+				WillReturnRows(sqlmock.NewRows([]string{"users", "groups", "files", "used_bytes", "stored_bytes"}).AddRow(3, 2, 5, 2048, 1024))
+		})
+
+		It("returns the totals", func() {
+			totals, err := adminDao.GetStorageTotals()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(totals).To(Equal(StorageTotals{Users: 3, Groups: 2, Files: 5, UsedBytes: 2048, StoredBytes: 1024}))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen --source=audit_dao.go --destination dao_mocks/audit_dao.go --package dao_mocks
+
+//AuditDAO - interface for working with the Database in regards to the audit log
+//the audit log is append-only, so there are no operations for changing or deleting events
+type AuditDAO interface {
+	Migrate() error
+	RecordEvent(event models.AuditEvent) error
+	GetGroupEvents(currUserID uint, groupName string, filter AuditFilter) ([]AuditEventInfo, error)
+	GetEvents(filter AuditFilter) ([]AuditEventInfo, error)
+}
+
+//AuditFilter - criteria, which the fetched audit events match. The empty criteria are ignored
+type AuditFilter struct {
+	Action string
+	//Actor - the username of the user, who made the request
+	Actor      string
+	GroupName  string
+	TargetUser string
+	Outcome    string
+	From       *time.Time
+	To         *time.Time
+	//Limit - the maximum number of fetched events, starting from the latest one
+	Limit int
+}
+
+//AuditEventInfo - audit event together with the username of its actor. The username is empty, if the actor is anonymous or was deleted
+type AuditEventInfo struct {
+	models.AuditEvent
+	Actor string
+}
+
+//AuditDAOImpl - implementation of AuditDAO
+type AuditDAOImpl struct {
+	dbConn *gorm.DB
+}
+
+//NewAuditDAOImpl - function for creation an instance of AuditDAOImpl
+func NewAuditDAOImpl(dbConn *gorm.DB) *AuditDAOImpl {
+	return &AuditDAOImpl{dbConn: dbConn}
+}
+
+//Migrate - function which updates the models(table structure) in db
+func (i *AuditDAOImpl) Migrate() error {
+	return i.dbConn.AutoMigrate(models.AuditEvent{})
+}
+
+//RecordEvent - appends the event to the audit log
+func (i *AuditDAOImpl) RecordEvent(event models.AuditEvent) error {
+	if result := i.dbConn.Create(&event); result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with recording the audit event")
+	}
+	return nil
+}
+
+//GetGroupEvents - retrieves the audit events of the group, which match the filter. Only for the group owner
+//the events of previously deleted groups with the same name arent returned
+func (i *AuditDAOImpl) GetGroupEvents(currUserID uint, groupName string, filter AuditFilter) ([]AuditEventInfo, error) {
+	group, err := getGroupWithConn(i.dbConn, groupName)
+	if err != nil {
+		return nil, err
+	} else if group.ID == 0 {
+		return nil, myerr.NewItemNotFoundError(fmt.Sprintf("Group [%s] does not exist", groupName))
+	}
+
+	if _, err = checkPermissionWithConn(i.dbConn, currUserID, group.ID, ManageGroup); err != nil {
+		return nil, err
+	}
+
+	filter.GroupName = groupName
+	var events []AuditEventInfo
+	result := filterAuditEventsWithConn(i.dbConn, filter).
+		Where("audit_events.created_at >= ?", group.CreatedAt).
+		Scan(&events)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the audit events of the group")
+	}
+	return events, nil
+}
+
+//GetEvents - retrieves the audit events of the whole server, which match the filter
+func (i *AuditDAOImpl) GetEvents(filter AuditFilter) ([]AuditEventInfo, error) {
+	var events []AuditEventInfo
+	if result := filterAuditEventsWithConn(i.dbConn, filter).Scan(&events); result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the audit events")
+	}
+	return events, nil
+}
+
+//filterAuditEventsWithConn - builds the query for the audit events, which match the filter, ordered from the latest one
+func filterAuditEventsWithConn(dbConn *gorm.DB, filter AuditFilter) *gorm.DB {
+	query := dbConn.Table("audit_events").
+		Select("audit_events.*, COALESCE(users.username, '') AS actor").
+		Joins("left join users on users.id = audit_events.actor_id")
+
+	if filter.Action != "" {
+		query = query.Where("audit_events.action = ?", filter.Action)
+	}
+	if filter.Actor != "" {
+		query = query.Where("users.username = ?", filter.Actor)
+	}
+	if filter.GroupName != "" {
+		query = query.Where("audit_events.group_name = ?", filter.GroupName)
+	}
+	if filter.TargetUser != "" {
+		query = query.Where("audit_events.target_user = ?", filter.TargetUser)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("audit_events.outcome = ?", filter.Outcome)
+	}
+	if filter.From != nil {
+		query = query.Where("audit_events.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("audit_events.created_at < ?", *filter.To)
+	}
+	return query.Order("audit_events.id DESC").Limit(filter.Limit)
+}
@@ -0,0 +1,132 @@
+package dao
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("AuditDAO", func() {
+	var (
+		auditDao AuditDAO
+		mock     sqlmock.Sqlmock
+	)
+
+	const (
+		userID    = 3
+		groupID   = 5
+		groupName = "group"
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		auditDao = NewAuditDAOImpl(gdb)
+	})
+
+	Context("RecordEvent", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "audit_events" ("created_at","actor_id","action","group_name","file_id","target_user","ip","outcome","status_code") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "id"`)).
+				WithArgs(sqlmock.AnyArg(), userID, "file.upload", groupName, 7, "", "127.0.0.1", models.OutcomeSuccess, 201).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectCommit()
+		})
+
+		It("appends the event", func() {
+			event := models.AuditEvent{
+				ActorID:    userID,
+				Action:     "file.upload",
+				GroupName:  groupName,
+				FileID:     7,
+				IP:         "127.0.0.1",
+				Outcome:    models.OutcomeSuccess,
+				StatusCode: 201,
+			}
+			Expect(auditDao.RecordEvent(event)).To(Succeed())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Context("GetGroupEvents", func() {
+		createdAt := time.Now().Add(-time.Hour)
+
+		BeforeEach(func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1`)).
+				WithArgs(groupName).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).AddRow(groupID, groupName, createdAt))
+		})
+
+		When("the user isnt the group owner", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "role"}).AddRow(1, models.RoleAdmin))
+			})
+
+			It("returns client error", func() {
+				_, err := auditDao.GetGroupEvents(userID, groupName, AuditFilter{Limit: 10})
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the user is the group owner", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "role"}).AddRow(1, models.RoleOwner))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT audit_events.*, COALESCE(users.username, '') AS actor FROM "audit_events" left join users on users.id = audit_events.actor_id WHERE audit_events.action = $1 AND audit_events.group_name = $2 AND audit_events.created_at >= $3 ORDER BY audit_events.id DESC LIMIT 10`)).
+					WithArgs("file.download", groupName, createdAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "action", "group_name", "actor"}).AddRow(4, "file.download", groupName, "username"))
+			})
+
+			It("returns the events of the group since its creation", func() {
+				events, err := auditDao.GetGroupEvents(userID, groupName, AuditFilter{Action: "file.download", Limit: 10})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(HaveLen(1))
+				Expect(events[0].Actor).To(Equal("username"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("GetEvents", func() {
+		from := time.Now().Add(-24 * time.Hour)
+
+		BeforeEach(func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT audit_events.*, COALESCE(users.username, '') AS actor FROM "audit_events" left join users on users.id = audit_events.actor_id WHERE users.username = $1 AND audit_events.outcome = $2 AND audit_events.created_at >= $3 ORDER BY audit_events.id DESC LIMIT 100`)).
+				WithArgs("username", models.OutcomeFailure, from).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "action", "outcome", "actor"}).AddRow(2, "user.login", models.OutcomeFailure, "username"))
+		})
+
+		It("returns the events, matching the filter", func() {
+			events, err := auditDao.GetEvents(AuditFilter{Actor: "username", Outcome: models.OutcomeFailure, From: &from, Limit: 100})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal("user.login"))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,194 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin_dao.go
+
+// Package dao_mocks is a generated GoMock package.
+package dao_mocks
+
+import (
+	dao "github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockAdminDAO is a mock of AdminDAO interface
+type MockAdminDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminDAOMockRecorder
+}
+
+// MockAdminDAOMockRecorder is the mock recorder for MockAdminDAO
+type MockAdminDAOMockRecorder struct {
+	mock *MockAdminDAO
+}
+
+// NewMockAdminDAO creates a new mock instance
+func NewMockAdminDAO(ctrl *gomock.Controller) *MockAdminDAO {
+	mock := &MockAdminDAO{ctrl: ctrl}
+	mock.recorder = &MockAdminDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAdminDAO) EXPECT() *MockAdminDAOMockRecorder {
+	return m.recorder
+}
+
+// Migrate mocks base method
+func (m *MockAdminDAO) Migrate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Migrate indicates an expected call of Migrate
+func (mr *MockAdminDAOMockRecorder) Migrate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockAdminDAO)(nil).Migrate))
+}
+
+// SetAdmin mocks base method
+func (m *MockAdminDAO) SetAdmin(username string, isAdmin bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAdmin", username, isAdmin)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAdmin indicates an expected call of SetAdmin
+func (mr *MockAdminDAOMockRecorder) SetAdmin(username, isAdmin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAdmin", reflect.TypeOf((*MockAdminDAO)(nil).SetAdmin), username, isAdmin)
+}
+
+// IsAdmin mocks base method
+func (m *MockAdminDAO) IsAdmin(userID uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAdmin", userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAdmin indicates an expected call of IsAdmin
+func (mr *MockAdminDAOMockRecorder) IsAdmin(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAdmin", reflect.TypeOf((*MockAdminDAO)(nil).IsAdmin), userID)
+}
+
+// GetUsers mocks base method
+func (m *MockAdminDAO) GetUsers() ([]dao.UserInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers")
+	ret0, _ := ret[0].([]dao.UserInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers
+func (mr *MockAdminDAOMockRecorder) GetUsers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockAdminDAO)(nil).GetUsers))
+}
+
+// SuspendUser mocks base method
+func (m *MockAdminDAO) SuspendUser(username, reason string, until *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendUser", username, reason, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SuspendUser indicates an expected call of SuspendUser
+func (mr *MockAdminDAOMockRecorder) SuspendUser(username, reason, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendUser", reflect.TypeOf((*MockAdminDAO)(nil).SuspendUser), username, reason, until)
+}
+
+// UnsuspendUser mocks base method
+func (m *MockAdminDAO) UnsuspendUser(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsuspendUser", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsuspendUser indicates an expected call of UnsuspendUser
+func (mr *MockAdminDAOMockRecorder) UnsuspendUser(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsuspendUser", reflect.TypeOf((*MockAdminDAO)(nil).UnsuspendUser), username)
+}
+
+// GetSuspension mocks base method
+func (m *MockAdminDAO) GetSuspension(userID uint) (*dao.Suspension, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSuspension", userID)
+	ret0, _ := ret[0].(*dao.Suspension)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSuspension indicates an expected call of GetSuspension
+func (mr *MockAdminDAOMockRecorder) GetSuspension(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSuspension", reflect.TypeOf((*MockAdminDAO)(nil).GetSuspension), userID)
+}
+
+// LiftExpiredSuspensions mocks base method
+func (m *MockAdminDAO) LiftExpiredSuspensions(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LiftExpiredSuspensions", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LiftExpiredSuspensions indicates an expected call of LiftExpiredSuspensions
+func (mr *MockAdminDAOMockRecorder) LiftExpiredSuspensions(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LiftExpiredSuspensions", reflect.TypeOf((*MockAdminDAO)(nil).LiftExpiredSuspensions), now)
+}
+
+// DeactivateGroup mocks base method
+func (m *MockAdminDAO) DeactivateGroup(groupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateGroup", groupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateGroup indicates an expected call of DeactivateGroup
+func (mr *MockAdminDAOMockRecorder) DeactivateGroup(groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateGroup", reflect.TypeOf((*MockAdminDAO)(nil).DeactivateGroup), groupName)
+}
+
+// ReassignGroupOwnership mocks base method
+func (m *MockAdminDAO) ReassignGroupOwnership(groupName, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReassignGroupOwnership", groupName, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReassignGroupOwnership indicates an expected call of ReassignGroupOwnership
+func (mr *MockAdminDAOMockRecorder) ReassignGroupOwnership(groupName, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReassignGroupOwnership", reflect.TypeOf((*MockAdminDAO)(nil).ReassignGroupOwnership), groupName, username)
+}
+
+// GetStorageTotals mocks base method
+func (m *MockAdminDAO) GetStorageTotals() (dao.StorageTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStorageTotals")
+	ret0, _ := ret[0].(dao.StorageTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStorageTotals indicates an expected call of GetStorageTotals
+func (mr *MockAdminDAOMockRecorder) GetStorageTotals() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorageTotals", reflect.TypeOf((*MockAdminDAO)(nil).GetStorageTotals))
+}
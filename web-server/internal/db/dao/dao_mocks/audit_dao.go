@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: audit_dao.go
+
+// Package dao_mocks is a generated GoMock package.
+package dao_mocks
+
+import (
+	dao "github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	models "github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockAuditDAO is a mock of AuditDAO interface
+type MockAuditDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditDAOMockRecorder
+}
+
+// MockAuditDAOMockRecorder is the mock recorder for MockAuditDAO
+type MockAuditDAOMockRecorder struct {
+	mock *MockAuditDAO
+}
+
+// NewMockAuditDAO creates a new mock instance
+func NewMockAuditDAO(ctrl *gomock.Controller) *MockAuditDAO {
+	mock := &MockAuditDAO{ctrl: ctrl}
+	mock.recorder = &MockAuditDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditDAO) EXPECT() *MockAuditDAOMockRecorder {
+	return m.recorder
+}
+
+// Migrate mocks base method
+func (m *MockAuditDAO) Migrate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Migrate indicates an expected call of Migrate
+func (mr *MockAuditDAOMockRecorder) Migrate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockAuditDAO)(nil).Migrate))
+}
+
+// RecordEvent mocks base method
+func (m *MockAuditDAO) RecordEvent(event models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordEvent indicates an expected call of RecordEvent
+func (mr *MockAuditDAOMockRecorder) RecordEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordEvent", reflect.TypeOf((*MockAuditDAO)(nil).RecordEvent), event)
+}
+
+// GetGroupEvents mocks base method
+func (m *MockAuditDAO) GetGroupEvents(currUserID uint, groupName string, filter dao.AuditFilter) ([]dao.AuditEventInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupEvents", currUserID, groupName, filter)
+	ret0, _ := ret[0].([]dao.AuditEventInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupEvents indicates an expected call of GetGroupEvents
+func (mr *MockAuditDAOMockRecorder) GetGroupEvents(currUserID, groupName, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupEvents", reflect.TypeOf((*MockAuditDAO)(nil).GetGroupEvents), currUserID, groupName, filter)
+}
+
+// GetEvents mocks base method
+func (m *MockAuditDAO) GetEvents(filter dao.AuditFilter) ([]dao.AuditEventInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvents", filter)
+	ret0, _ := ret[0].([]dao.AuditEventInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvents indicates an expected call of GetEvents
+func (mr *MockAuditDAOMockRecorder) GetEvents(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockAuditDAO)(nil).GetEvents), filter)
+}
@@ -5,9 +5,11 @@
 package dao_mocks
 
 import (
+	dao "github.com/danielpenchev98/UShare/web-server/internal/db/dao"
 	models "github.com/danielpenchev98/UShare/web-server/internal/db/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockFmDAO is a mock of FmDAO interface
@@ -34,48 +36,63 @@ func (m *MockFmDAO) EXPECT() *MockFmDAOMockRecorder {
 }
 
 // AddFileInfo mocks base method
-func (m *MockFmDAO) AddFileInfo(userID uint, fileName, groupName string) (uint, error) {
+func (m *MockFmDAO) AddFileInfo(userID uint, fileName string, folderID uint, groupName string) (uint, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddFileInfo", userID, fileName, groupName)
+	ret := m.ctrl.Call(m, "AddFileInfo", userID, fileName, folderID, groupName)
 	ret0, _ := ret[0].(uint)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddFileInfo indicates an expected call of AddFileInfo
-func (mr *MockFmDAOMockRecorder) AddFileInfo(userID, fileName, groupName interface{}) *gomock.Call {
+func (mr *MockFmDAOMockRecorder) AddFileInfo(userID, fileName, folderID, groupName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFileInfo", reflect.TypeOf((*MockFmDAO)(nil).AddFileInfo), userID, fileName, groupName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFileInfo", reflect.TypeOf((*MockFmDAO)(nil).AddFileInfo), userID, fileName, folderID, groupName)
 }
 
-// GetFileInfo mocks base method
-func (m *MockFmDAO) GetFileInfo(userID, fileID uint, groupName string) (models.FileInfo, error) {
+// GetFileVersion mocks base method
+func (m *MockFmDAO) GetFileVersion(userID, fileID, version uint, groupName string) (models.FileInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFileInfo", userID, fileID, groupName)
+	ret := m.ctrl.Call(m, "GetFileVersion", userID, fileID, version, groupName)
 	ret0, _ := ret[0].(models.FileInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetFileInfo indicates an expected call of GetFileInfo
-func (mr *MockFmDAOMockRecorder) GetFileInfo(userID, fileID, groupName interface{}) *gomock.Call {
+// GetFileVersion indicates an expected call of GetFileVersion
+func (mr *MockFmDAOMockRecorder) GetFileVersion(userID, fileID, version, groupName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileInfo", reflect.TypeOf((*MockFmDAO)(nil).GetFileInfo), userID, fileID, groupName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileVersion", reflect.TypeOf((*MockFmDAO)(nil).GetFileVersion), userID, fileID, version, groupName)
+}
+
+// GetFileVersions mocks base method
+func (m *MockFmDAO) GetFileVersions(userID, fileID uint, groupName string) ([]models.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileVersions", userID, fileID, groupName)
+	ret0, _ := ret[0].([]models.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileVersions indicates an expected call of GetFileVersions
+func (mr *MockFmDAOMockRecorder) GetFileVersions(userID, fileID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileVersions", reflect.TypeOf((*MockFmDAO)(nil).GetFileVersions), userID, fileID, groupName)
 }
 
 // GetAllFilesInfo mocks base method
-func (m *MockFmDAO) GetAllFilesInfo(userID uint, groupName string) ([]models.FileInfo, error) {
+func (m *MockFmDAO) GetAllFilesInfo(userID, folderID uint, groupName string) ([]models.FileInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllFilesInfo", userID, groupName)
+	ret := m.ctrl.Call(m, "GetAllFilesInfo", userID, folderID, groupName)
 	ret0, _ := ret[0].([]models.FileInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllFilesInfo indicates an expected call of GetAllFilesInfo
-func (mr *MockFmDAOMockRecorder) GetAllFilesInfo(userID, groupName interface{}) *gomock.Call {
+func (mr *MockFmDAOMockRecorder) GetAllFilesInfo(userID, folderID, groupName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllFilesInfo", reflect.TypeOf((*MockFmDAO)(nil).GetAllFilesInfo), userID, groupName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllFilesInfo", reflect.TypeOf((*MockFmDAO)(nil).GetAllFilesInfo), userID, folderID, groupName)
 }
 
 // RemoveFileInfo mocks base method
@@ -92,6 +109,618 @@ func (mr *MockFmDAOMockRecorder) RemoveFileInfo(userID, fileID, groupName interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFileInfo", reflect.TypeOf((*MockFmDAO)(nil).RemoveFileInfo), userID, fileID, groupName)
 }
 
+// TrashFile mocks base method
+func (m *MockFmDAO) TrashFile(userID, fileID uint, groupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashFile", userID, fileID, groupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrashFile indicates an expected call of TrashFile
+func (mr *MockFmDAOMockRecorder) TrashFile(userID, fileID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashFile", reflect.TypeOf((*MockFmDAO)(nil).TrashFile), userID, fileID, groupName)
+}
+
+// GetTrashedFiles mocks base method
+func (m *MockFmDAO) GetTrashedFiles(userID uint, groupName string) ([]models.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashedFiles", userID, groupName)
+	ret0, _ := ret[0].([]models.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrashedFiles indicates an expected call of GetTrashedFiles
+func (mr *MockFmDAOMockRecorder) GetTrashedFiles(userID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashedFiles", reflect.TypeOf((*MockFmDAO)(nil).GetTrashedFiles), userID, groupName)
+}
+
+// RestoreTrashedFile mocks base method
+func (m *MockFmDAO) RestoreTrashedFile(userID, fileID uint, groupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTrashedFile", userID, fileID, groupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTrashedFile indicates an expected call of RestoreTrashedFile
+func (mr *MockFmDAOMockRecorder) RestoreTrashedFile(userID, fileID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTrashedFile", reflect.TypeOf((*MockFmDAO)(nil).RestoreTrashedFile), userID, fileID, groupName)
+}
+
+// RemoveTrashedFile mocks base method
+func (m *MockFmDAO) RemoveTrashedFile(userID, fileID uint, groupName string) ([]models.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTrashedFile", userID, fileID, groupName)
+	ret0, _ := ret[0].([]models.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTrashedFile indicates an expected call of RemoveTrashedFile
+func (mr *MockFmDAOMockRecorder) RemoveTrashedFile(userID, fileID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTrashedFile", reflect.TypeOf((*MockFmDAO)(nil).RemoveTrashedFile), userID, fileID, groupName)
+}
+
+// PurgeTrashedFiles mocks base method
+func (m *MockFmDAO) PurgeTrashedFiles(trashedBefore time.Time) ([]dao.GroupFileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrashedFiles", trashedBefore)
+	ret0, _ := ret[0].([]dao.GroupFileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrashedFiles indicates an expected call of PurgeTrashedFiles
+func (mr *MockFmDAOMockRecorder) PurgeTrashedFiles(trashedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrashedFiles", reflect.TypeOf((*MockFmDAO)(nil).PurgeTrashedFiles), trashedBefore)
+}
+
+// MoveFile mocks base method
+func (m *MockFmDAO) MoveFile(userID, fileID uint, groupName, folderPath, fileName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFile", userID, fileID, groupName, folderPath, fileName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveFile indicates an expected call of MoveFile
+func (mr *MockFmDAOMockRecorder) MoveFile(userID, fileID, groupName, folderPath, fileName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFile", reflect.TypeOf((*MockFmDAO)(nil).MoveFile), userID, fileID, groupName, folderPath, fileName)
+}
+
+// GetFolder mocks base method
+func (m *MockFmDAO) GetFolder(userID uint, groupName, path string) (models.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFolder", userID, groupName, path)
+	ret0, _ := ret[0].(models.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFolder indicates an expected call of GetFolder
+func (mr *MockFmDAOMockRecorder) GetFolder(userID, groupName, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFolder", reflect.TypeOf((*MockFmDAO)(nil).GetFolder), userID, groupName, path)
+}
+
+// GetSubfolders mocks base method
+func (m *MockFmDAO) GetSubfolders(groupID, folderID uint) ([]models.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubfolders", groupID, folderID)
+	ret0, _ := ret[0].([]models.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubfolders indicates an expected call of GetSubfolders
+func (mr *MockFmDAOMockRecorder) GetSubfolders(groupID, folderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubfolders", reflect.TypeOf((*MockFmDAO)(nil).GetSubfolders), groupID, folderID)
+}
+
+// CreateFolder mocks base method
+func (m *MockFmDAO) CreateFolder(userID uint, groupName, path string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFolder", userID, groupName, path)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFolder indicates an expected call of CreateFolder
+func (mr *MockFmDAOMockRecorder) CreateFolder(userID, groupName, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFolder", reflect.TypeOf((*MockFmDAO)(nil).CreateFolder), userID, groupName, path)
+}
+
+// RenameFolder mocks base method
+func (m *MockFmDAO) RenameFolder(userID uint, groupName, path, newName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameFolder", userID, groupName, path, newName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameFolder indicates an expected call of RenameFolder
+func (mr *MockFmDAOMockRecorder) RenameFolder(userID, groupName, path, newName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFolder", reflect.TypeOf((*MockFmDAO)(nil).RenameFolder), userID, groupName, path, newName)
+}
+
+// MoveFolder mocks base method
+func (m *MockFmDAO) MoveFolder(userID uint, groupName, path, parentPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFolder", userID, groupName, path, parentPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveFolder indicates an expected call of MoveFolder
+func (mr *MockFmDAOMockRecorder) MoveFolder(userID, groupName, path, parentPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFolder", reflect.TypeOf((*MockFmDAO)(nil).MoveFolder), userID, groupName, path, parentPath)
+}
+
+// RemoveFolder mocks base method
+func (m *MockFmDAO) RemoveFolder(userID uint, groupName, path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFolder", userID, groupName, path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFolder indicates an expected call of RemoveFolder
+func (mr *MockFmDAOMockRecorder) RemoveFolder(userID, groupName, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFolder", reflect.TypeOf((*MockFmDAO)(nil).RemoveFolder), userID, groupName, path)
+}
+
+// SetMaxFileVersions mocks base method
+func (m *MockFmDAO) SetMaxFileVersions(userID uint, groupName string, maxVersions uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMaxFileVersions", userID, groupName, maxVersions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMaxFileVersions indicates an expected call of SetMaxFileVersions
+func (mr *MockFmDAOMockRecorder) SetMaxFileVersions(userID, groupName, maxVersions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxFileVersions", reflect.TypeOf((*MockFmDAO)(nil).SetMaxFileVersions), userID, groupName, maxVersions)
+}
+
+// RemoveExcessFileVersions mocks base method
+func (m *MockFmDAO) RemoveExcessFileVersions(defaultMaxVersions uint) ([]dao.GroupFileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveExcessFileVersions", defaultMaxVersions)
+	ret0, _ := ret[0].([]dao.GroupFileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveExcessFileVersions indicates an expected call of RemoveExcessFileVersions
+func (mr *MockFmDAOMockRecorder) RemoveExcessFileVersions(defaultMaxVersions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExcessFileVersions", reflect.TypeOf((*MockFmDAO)(nil).RemoveExcessFileVersions), defaultMaxVersions)
+}
+
+// AttachFileBlob mocks base method
+func (m *MockFmDAO) AttachFileBlob(fileID uint, checksum string, size int64, quota dao.Quota, sessionID uint, storeContent func(bool) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachFileBlob", fileID, checksum, size, quota, sessionID, storeContent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachFileBlob indicates an expected call of AttachFileBlob
+func (mr *MockFmDAOMockRecorder) AttachFileBlob(fileID, checksum, size, quota, sessionID, storeContent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachFileBlob", reflect.TypeOf((*MockFmDAO)(nil).AttachFileBlob), fileID, checksum, size, quota, sessionID, storeContent)
+}
+
+// CheckQuota mocks base method
+func (m *MockFmDAO) CheckQuota(userID uint, groupName string, size int64, quota dao.Quota) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckQuota", userID, groupName, size, quota)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckQuota indicates an expected call of CheckQuota
+func (mr *MockFmDAOMockRecorder) CheckQuota(userID, groupName, size, quota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckQuota", reflect.TypeOf((*MockFmDAO)(nil).CheckQuota), userID, groupName, size, quota)
+}
+
+// GetUserUsage mocks base method
+func (m *MockFmDAO) GetUserUsage(userID uint) (dao.StorageUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserUsage", userID)
+	ret0, _ := ret[0].(dao.StorageUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserUsage indicates an expected call of GetUserUsage
+func (mr *MockFmDAOMockRecorder) GetUserUsage(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserUsage", reflect.TypeOf((*MockFmDAO)(nil).GetUserUsage), userID)
+}
+
+// GetGroupUsage mocks base method
+func (m *MockFmDAO) GetGroupUsage(userID uint, groupName string) (dao.StorageUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupUsage", userID, groupName)
+	ret0, _ := ret[0].(dao.StorageUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupUsage indicates an expected call of GetGroupUsage
+func (mr *MockFmDAOMockRecorder) GetGroupUsage(userID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupUsage", reflect.TypeOf((*MockFmDAO)(nil).GetGroupUsage), userID, groupName)
+}
+
+// RemoveGroupFiles mocks base method
+func (m *MockFmDAO) RemoveGroupFiles(groupNames []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveGroupFiles", groupNames)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveGroupFiles indicates an expected call of RemoveGroupFiles
+func (mr *MockFmDAOMockRecorder) RemoveGroupFiles(groupNames interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveGroupFiles", reflect.TypeOf((*MockFmDAO)(nil).RemoveGroupFiles), groupNames)
+}
+
+// GetUnreferencedBlobs mocks base method
+func (m *MockFmDAO) GetUnreferencedBlobs() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnreferencedBlobs")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnreferencedBlobs indicates an expected call of GetUnreferencedBlobs
+func (mr *MockFmDAOMockRecorder) GetUnreferencedBlobs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnreferencedBlobs", reflect.TypeOf((*MockFmDAO)(nil).GetUnreferencedBlobs))
+}
+
+// EraseBlob mocks base method
+func (m *MockFmDAO) EraseBlob(checksum string, eraseContent func() error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseBlob", checksum, eraseContent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EraseBlob indicates an expected call of EraseBlob
+func (mr *MockFmDAOMockRecorder) EraseBlob(checksum, eraseContent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseBlob", reflect.TypeOf((*MockFmDAO)(nil).EraseBlob), checksum, eraseContent)
+}
+
+// CreateUploadSession mocks base method
+func (m *MockFmDAO) CreateUploadSession(userID uint, fileName string, size int64, folderID uint, groupName string, quota dao.Quota) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUploadSession", userID, fileName, size, folderID, groupName, quota)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUploadSession indicates an expected call of CreateUploadSession
+func (mr *MockFmDAOMockRecorder) CreateUploadSession(userID, fileName, size, folderID, groupName, quota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUploadSession", reflect.TypeOf((*MockFmDAO)(nil).CreateUploadSession), userID, fileName, size, folderID, groupName, quota)
+}
+
+// GetUploadSession mocks base method
+func (m *MockFmDAO) GetUploadSession(userID, sessionID uint, groupName string) (models.UploadSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUploadSession", userID, sessionID, groupName)
+	ret0, _ := ret[0].(models.UploadSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUploadSession indicates an expected call of GetUploadSession
+func (mr *MockFmDAOMockRecorder) GetUploadSession(userID, sessionID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUploadSession", reflect.TypeOf((*MockFmDAO)(nil).GetUploadSession), userID, sessionID, groupName)
+}
+
+// GetUploadChunks mocks base method
+func (m *MockFmDAO) GetUploadChunks(sessionID uint) ([]models.UploadChunk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUploadChunks", sessionID)
+	ret0, _ := ret[0].([]models.UploadChunk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUploadChunks indicates an expected call of GetUploadChunks
+func (mr *MockFmDAOMockRecorder) GetUploadChunks(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUploadChunks", reflect.TypeOf((*MockFmDAO)(nil).GetUploadChunks), sessionID)
+}
+
+// AddUploadChunk mocks base method
+func (m *MockFmDAO) AddUploadChunk(sessionID uint, offset, size int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUploadChunk", sessionID, offset, size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUploadChunk indicates an expected call of AddUploadChunk
+func (mr *MockFmDAOMockRecorder) AddUploadChunk(sessionID, offset, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUploadChunk", reflect.TypeOf((*MockFmDAO)(nil).AddUploadChunk), sessionID, offset, size)
+}
+
+// RemoveUploadSession mocks base method
+func (m *MockFmDAO) RemoveUploadSession(sessionID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUploadSession", sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUploadSession indicates an expected call of RemoveUploadSession
+func (mr *MockFmDAOMockRecorder) RemoveUploadSession(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUploadSession", reflect.TypeOf((*MockFmDAO)(nil).RemoveUploadSession), sessionID)
+}
+
+// GetAbandonedUploadSessions mocks base method
+func (m *MockFmDAO) GetAbandonedUploadSessions(updatedBefore time.Time) ([]dao.GroupUploadSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAbandonedUploadSessions", updatedBefore)
+	ret0, _ := ret[0].([]dao.GroupUploadSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAbandonedUploadSessions indicates an expected call of GetAbandonedUploadSessions
+func (mr *MockFmDAOMockRecorder) GetAbandonedUploadSessions(updatedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAbandonedUploadSessions", reflect.TypeOf((*MockFmDAO)(nil).GetAbandonedUploadSessions), updatedBefore)
+}
+
+// CreateShareLink mocks base method
+func (m *MockFmDAO) CreateShareLink(userID, fileID uint, groupName, tokenHash, passwordHash string, expiresAt *time.Time, maxDownloads uint) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShareLink", userID, fileID, groupName, tokenHash, passwordHash, expiresAt, maxDownloads)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShareLink indicates an expected call of CreateShareLink
+func (mr *MockFmDAOMockRecorder) CreateShareLink(userID, fileID, groupName, tokenHash, passwordHash, expiresAt, maxDownloads interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShareLink", reflect.TypeOf((*MockFmDAO)(nil).CreateShareLink), userID, fileID, groupName, tokenHash, passwordHash, expiresAt, maxDownloads)
+}
+
+// GetShareLinks mocks base method
+func (m *MockFmDAO) GetShareLinks(userID uint, groupName string) ([]dao.ShareLinkInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareLinks", userID, groupName)
+	ret0, _ := ret[0].([]dao.ShareLinkInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareLinks indicates an expected call of GetShareLinks
+func (mr *MockFmDAOMockRecorder) GetShareLinks(userID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareLinks", reflect.TypeOf((*MockFmDAO)(nil).GetShareLinks), userID, groupName)
+}
+
+// RemoveShareLink mocks base method
+func (m *MockFmDAO) RemoveShareLink(userID, linkID uint, groupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveShareLink", userID, linkID, groupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveShareLink indicates an expected call of RemoveShareLink
+func (mr *MockFmDAOMockRecorder) RemoveShareLink(userID, linkID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveShareLink", reflect.TypeOf((*MockFmDAO)(nil).RemoveShareLink), userID, linkID, groupName)
+}
+
+// GetSharedFile mocks base method
+func (m *MockFmDAO) GetSharedFile(tokenHash string) (models.ShareLink, dao.GroupFileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharedFile", tokenHash)
+	ret0, _ := ret[0].(models.ShareLink)
+	ret1, _ := ret[1].(dao.GroupFileInfo)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSharedFile indicates an expected call of GetSharedFile
+func (mr *MockFmDAOMockRecorder) GetSharedFile(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharedFile", reflect.TypeOf((*MockFmDAO)(nil).GetSharedFile), tokenHash)
+}
+
+// UseShareLink mocks base method
+func (m *MockFmDAO) UseShareLink(linkID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseShareLink", linkID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseShareLink indicates an expected call of UseShareLink
+func (mr *MockFmDAOMockRecorder) UseShareLink(linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseShareLink", reflect.TypeOf((*MockFmDAO)(nil).UseShareLink), linkID)
+}
+
+// ReserveSharePasswordAttempt mocks base method
+func (m *MockFmDAO) ReserveSharePasswordAttempt(linkID, maxAttempts uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveSharePasswordAttempt", linkID, maxAttempts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveSharePasswordAttempt indicates an expected call of ReserveSharePasswordAttempt
+func (mr *MockFmDAOMockRecorder) ReserveSharePasswordAttempt(linkID, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveSharePasswordAttempt", reflect.TypeOf((*MockFmDAO)(nil).ReserveSharePasswordAttempt), linkID, maxAttempts)
+}
+
+// ReleaseSharePasswordAttempt mocks base method
+func (m *MockFmDAO) ReleaseSharePasswordAttempt(linkID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSharePasswordAttempt", linkID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSharePasswordAttempt indicates an expected call of ReleaseSharePasswordAttempt
+func (mr *MockFmDAOMockRecorder) ReleaseSharePasswordAttempt(linkID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSharePasswordAttempt", reflect.TypeOf((*MockFmDAO)(nil).ReleaseSharePasswordAttempt), linkID)
+}
+
+// DeleteStaleShareLinks mocks base method
+func (m *MockFmDAO) DeleteStaleShareLinks(expiredBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleShareLinks", expiredBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStaleShareLinks indicates an expected call of DeleteStaleShareLinks
+func (mr *MockFmDAOMockRecorder) DeleteStaleShareLinks(expiredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleShareLinks", reflect.TypeOf((*MockFmDAO)(nil).DeleteStaleShareLinks), expiredBefore)
+}
+
+// CreateDropBox mocks base method
+func (m *MockFmDAO) CreateDropBox(userID uint, groupName, folderPath, tokenHash string, maxFileSize int64, maxFiles uint, expiresAt *time.Time) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDropBox", userID, groupName, folderPath, tokenHash, maxFileSize, maxFiles, expiresAt)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDropBox indicates an expected call of CreateDropBox
+func (mr *MockFmDAOMockRecorder) CreateDropBox(userID, groupName, folderPath, tokenHash, maxFileSize, maxFiles, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDropBox", reflect.TypeOf((*MockFmDAO)(nil).CreateDropBox), userID, groupName, folderPath, tokenHash, maxFileSize, maxFiles, expiresAt)
+}
+
+// GetDropBoxes mocks base method
+func (m *MockFmDAO) GetDropBoxes(userID uint, groupName string) ([]dao.DropBoxInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropBoxes", userID, groupName)
+	ret0, _ := ret[0].([]dao.DropBoxInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropBoxes indicates an expected call of GetDropBoxes
+func (mr *MockFmDAOMockRecorder) GetDropBoxes(userID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropBoxes", reflect.TypeOf((*MockFmDAO)(nil).GetDropBoxes), userID, groupName)
+}
+
+// RemoveDropBox mocks base method
+func (m *MockFmDAO) RemoveDropBox(userID, dropBoxID uint, groupName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveDropBox", userID, dropBoxID, groupName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDropBox indicates an expected call of RemoveDropBox
+func (mr *MockFmDAOMockRecorder) RemoveDropBox(userID, dropBoxID, groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDropBox", reflect.TypeOf((*MockFmDAO)(nil).RemoveDropBox), userID, dropBoxID, groupName)
+}
+
+// GetDropBox mocks base method
+func (m *MockFmDAO) GetDropBox(tokenHash string) (dao.GroupDropBox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropBox", tokenHash)
+	ret0, _ := ret[0].(dao.GroupDropBox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropBox indicates an expected call of GetDropBox
+func (mr *MockFmDAOMockRecorder) GetDropBox(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropBox", reflect.TypeOf((*MockFmDAO)(nil).GetDropBox), tokenHash)
+}
+
+// AddDropBoxFile mocks base method
+func (m *MockFmDAO) AddDropBoxFile(dropBoxID uint, fileName, uploaderName, uploaderEmail string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDropBoxFile", dropBoxID, fileName, uploaderName, uploaderEmail)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddDropBoxFile indicates an expected call of AddDropBoxFile
+func (mr *MockFmDAOMockRecorder) AddDropBoxFile(dropBoxID, fileName, uploaderName, uploaderEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDropBoxFile", reflect.TypeOf((*MockFmDAO)(nil).AddDropBoxFile), dropBoxID, fileName, uploaderName, uploaderEmail)
+}
+
+// RemoveDropBoxFile mocks base method
+func (m *MockFmDAO) RemoveDropBoxFile(dropBoxID, fileID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveDropBoxFile", dropBoxID, fileID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDropBoxFile indicates an expected call of RemoveDropBoxFile
+func (mr *MockFmDAOMockRecorder) RemoveDropBoxFile(dropBoxID, fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDropBoxFile", reflect.TypeOf((*MockFmDAO)(nil).RemoveDropBoxFile), dropBoxID, fileID)
+}
+
+// DeleteExpiredDropBoxes mocks base method
+func (m *MockFmDAO) DeleteExpiredDropBoxes(expiredBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredDropBoxes", expiredBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredDropBoxes indicates an expected call of DeleteExpiredDropBoxes
+func (mr *MockFmDAOMockRecorder) DeleteExpiredDropBoxes(expiredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredDropBoxes", reflect.TypeOf((*MockFmDAO)(nil).DeleteExpiredDropBoxes), expiredBefore)
+}
+
 // Migrate mocks base method
 func (m *MockFmDAO) Migrate() error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: login_failure_dao.go
+
+// Package dao_mocks is a generated GoMock package.
+package dao_mocks
+
+import (
+	dao "github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockLoginFailureDAO is a mock of LoginFailureDAO interface
+type MockLoginFailureDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginFailureDAOMockRecorder
+}
+
+// MockLoginFailureDAOMockRecorder is the mock recorder for MockLoginFailureDAO
+type MockLoginFailureDAOMockRecorder struct {
+	mock *MockLoginFailureDAO
+}
+
+// NewMockLoginFailureDAO creates a new mock instance
+func NewMockLoginFailureDAO(ctrl *gomock.Controller) *MockLoginFailureDAO {
+	mock := &MockLoginFailureDAO{ctrl: ctrl}
+	mock.recorder = &MockLoginFailureDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoginFailureDAO) EXPECT() *MockLoginFailureDAOMockRecorder {
+	return m.recorder
+}
+
+// Migrate mocks base method
+func (m *MockLoginFailureDAO) Migrate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Migrate indicates an expected call of Migrate
+func (mr *MockLoginFailureDAOMockRecorder) Migrate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockLoginFailureDAO)(nil).Migrate))
+}
+
+// ReserveLoginAttempt mocks base method
+func (m *MockLoginFailureDAO) ReserveLoginAttempt(username, ip string, policy dao.LockoutPolicy) (dao.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveLoginAttempt", username, ip, policy)
+	ret0, _ := ret[0].(dao.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveLoginAttempt indicates an expected call of ReserveLoginAttempt
+func (mr *MockLoginFailureDAOMockRecorder) ReserveLoginAttempt(username, ip, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveLoginAttempt", reflect.TypeOf((*MockLoginFailureDAO)(nil).ReserveLoginAttempt), username, ip, policy)
+}
+
+// ReleaseLoginAttempt mocks base method
+func (m *MockLoginFailureDAO) ReleaseLoginAttempt(attempt dao.LoginAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLoginAttempt", attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLoginAttempt indicates an expected call of ReleaseLoginAttempt
+func (mr *MockLoginFailureDAOMockRecorder) ReleaseLoginAttempt(attempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLoginAttempt", reflect.TypeOf((*MockLoginFailureDAO)(nil).ReleaseLoginAttempt), attempt)
+}
+
+// ResetLoginFailures mocks base method
+func (m *MockLoginFailureDAO) ResetLoginFailures(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginFailures", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginFailures indicates an expected call of ResetLoginFailures
+func (mr *MockLoginFailureDAOMockRecorder) ResetLoginFailures(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginFailures", reflect.TypeOf((*MockLoginFailureDAO)(nil).ResetLoginFailures), username)
+}
+
+// DeleteExpiredLoginFailures mocks base method
+func (m *MockLoginFailureDAO) DeleteExpiredLoginFailures(expiredBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredLoginFailures", expiredBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredLoginFailures indicates an expected call of DeleteExpiredLoginFailures
+func (mr *MockLoginFailureDAOMockRecorder) DeleteExpiredLoginFailures(expiredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredLoginFailures", reflect.TypeOf((*MockLoginFailureDAO)(nil).DeleteExpiredLoginFailures), expiredBefore)
+}
@@ -0,0 +1,238 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: token_dao.go
+
+// Package dao_mocks is a generated GoMock package.
+package dao_mocks
+
+import (
+	dao "github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	models "github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockTokenDAO is a mock of TokenDAO interface
+type MockTokenDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenDAOMockRecorder
+}
+
+// MockTokenDAOMockRecorder is the mock recorder for MockTokenDAO
+type MockTokenDAOMockRecorder struct {
+	mock *MockTokenDAO
+}
+
+// NewMockTokenDAO creates a new mock instance
+func NewMockTokenDAO(ctrl *gomock.Controller) *MockTokenDAO {
+	mock := &MockTokenDAO{ctrl: ctrl}
+	mock.recorder = &MockTokenDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTokenDAO) EXPECT() *MockTokenDAOMockRecorder {
+	return m.recorder
+}
+
+// Migrate mocks base method
+func (m *MockTokenDAO) Migrate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Migrate indicates an expected call of Migrate
+func (mr *MockTokenDAOMockRecorder) Migrate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockTokenDAO)(nil).Migrate))
+}
+
+// CreateRefreshToken mocks base method
+func (m *MockTokenDAO) CreateRefreshToken(userID uint, tokenHash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", userID, tokenHash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken
+func (mr *MockTokenDAOMockRecorder) CreateRefreshToken(userID, tokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockTokenDAO)(nil).CreateRefreshToken), userID, tokenHash, expiresAt)
+}
+
+// RotateRefreshToken mocks base method
+func (m *MockTokenDAO) RotateRefreshToken(tokenHash, newTokenHash string, expiresAt time.Time) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", tokenHash, newTokenHash, expiresAt)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken
+func (mr *MockTokenDAOMockRecorder) RotateRefreshToken(tokenHash, newTokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockTokenDAO)(nil).RotateRefreshToken), tokenHash, newTokenHash, expiresAt)
+}
+
+// RevokeRefreshToken mocks base method
+func (m *MockTokenDAO) RevokeRefreshToken(userID uint, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", userID, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken
+func (mr *MockTokenDAOMockRecorder) RevokeRefreshToken(userID, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockTokenDAO)(nil).RevokeRefreshToken), userID, tokenHash)
+}
+
+// RevokeAccessToken mocks base method
+func (m *MockTokenDAO) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", tokenID, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken
+func (mr *MockTokenDAOMockRecorder) RevokeAccessToken(tokenID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockTokenDAO)(nil).RevokeAccessToken), tokenID, expiresAt)
+}
+
+// IsAccessTokenRevoked mocks base method
+func (m *MockTokenDAO) IsAccessTokenRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessTokenRevoked", tokenID, userID, issuedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessTokenRevoked indicates an expected call of IsAccessTokenRevoked
+func (mr *MockTokenDAOMockRecorder) IsAccessTokenRevoked(tokenID, userID, issuedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessTokenRevoked", reflect.TypeOf((*MockTokenDAO)(nil).IsAccessTokenRevoked), tokenID, userID, issuedAt)
+}
+
+// DeleteExpiredTokens mocks base method
+func (m *MockTokenDAO) DeleteExpiredTokens(expiredBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredTokens", expiredBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredTokens indicates an expected call of DeleteExpiredTokens
+func (mr *MockTokenDAOMockRecorder) DeleteExpiredTokens(expiredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTokens", reflect.TypeOf((*MockTokenDAO)(nil).DeleteExpiredTokens), expiredBefore)
+}
+
+// CreatePersonalAccessToken mocks base method
+func (m *MockTokenDAO) CreatePersonalAccessToken(userID uint, name, tokenHash string, scopes, groupNames []string, expiresAt time.Time) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePersonalAccessToken", userID, name, tokenHash, scopes, groupNames, expiresAt)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePersonalAccessToken indicates an expected call of CreatePersonalAccessToken
+func (mr *MockTokenDAOMockRecorder) CreatePersonalAccessToken(userID, name, tokenHash, scopes, groupNames, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePersonalAccessToken", reflect.TypeOf((*MockTokenDAO)(nil).CreatePersonalAccessToken), userID, name, tokenHash, scopes, groupNames, expiresAt)
+}
+
+// GetPersonalAccessTokens mocks base method
+func (m *MockTokenDAO) GetPersonalAccessTokens(userID uint) ([]dao.PersonalAccessTokenInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessTokens", userID)
+	ret0, _ := ret[0].([]dao.PersonalAccessTokenInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessTokens indicates an expected call of GetPersonalAccessTokens
+func (mr *MockTokenDAOMockRecorder) GetPersonalAccessTokens(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessTokens", reflect.TypeOf((*MockTokenDAO)(nil).GetPersonalAccessTokens), userID)
+}
+
+// UsePersonalAccessToken mocks base method
+func (m *MockTokenDAO) UsePersonalAccessToken(tokenHash string) (dao.PersonalAccessTokenInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePersonalAccessToken", tokenHash)
+	ret0, _ := ret[0].(dao.PersonalAccessTokenInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsePersonalAccessToken indicates an expected call of UsePersonalAccessToken
+func (mr *MockTokenDAOMockRecorder) UsePersonalAccessToken(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePersonalAccessToken", reflect.TypeOf((*MockTokenDAO)(nil).UsePersonalAccessToken), tokenHash)
+}
+
+// DeletePersonalAccessToken mocks base method
+func (m *MockTokenDAO) DeletePersonalAccessToken(userID, tokenID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePersonalAccessToken", userID, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePersonalAccessToken indicates an expected call of DeletePersonalAccessToken
+func (mr *MockTokenDAOMockRecorder) DeletePersonalAccessToken(userID, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePersonalAccessToken", reflect.TypeOf((*MockTokenDAO)(nil).DeletePersonalAccessToken), userID, tokenID)
+}
+
+// ChangePassword mocks base method
+func (m *MockTokenDAO) ChangePassword(userID uint, passwordHash string, confirm func(models.User) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", userID, passwordHash, confirm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword
+func (mr *MockTokenDAOMockRecorder) ChangePassword(userID, passwordHash, confirm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockTokenDAO)(nil).ChangePassword), userID, passwordHash, confirm)
+}
+
+// CreatePasswordResetToken mocks base method
+func (m *MockTokenDAO) CreatePasswordResetToken(username, tokenHash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", username, tokenHash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken
+func (mr *MockTokenDAOMockRecorder) CreatePasswordResetToken(username, tokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockTokenDAO)(nil).CreatePasswordResetToken), username, tokenHash, expiresAt)
+}
+
+// ResetPassword mocks base method
+func (m *MockTokenDAO) ResetPassword(tokenHash, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", tokenHash, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockTokenDAOMockRecorder) ResetPassword(tokenHash, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockTokenDAO)(nil).ResetPassword), tokenHash, passwordHash)
+}
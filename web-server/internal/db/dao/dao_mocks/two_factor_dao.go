@@ -0,0 +1,180 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: two_factor_dao.go
+
+// Package dao_mocks is a generated GoMock package.
+package dao_mocks
+
+import (
+	models "github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockTwoFactorDAO is a mock of TwoFactorDAO interface
+type MockTwoFactorDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorDAOMockRecorder
+}
+
+// MockTwoFactorDAOMockRecorder is the mock recorder for MockTwoFactorDAO
+type MockTwoFactorDAOMockRecorder struct {
+	mock *MockTwoFactorDAO
+}
+
+// NewMockTwoFactorDAO creates a new mock instance
+func NewMockTwoFactorDAO(ctrl *gomock.Controller) *MockTwoFactorDAO {
+	mock := &MockTwoFactorDAO{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTwoFactorDAO) EXPECT() *MockTwoFactorDAOMockRecorder {
+	return m.recorder
+}
+
+// Migrate mocks base method
+func (m *MockTwoFactorDAO) Migrate() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrate")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Migrate indicates an expected call of Migrate
+func (mr *MockTwoFactorDAOMockRecorder) Migrate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockTwoFactorDAO)(nil).Migrate))
+}
+
+// EnrollTwoFactor mocks base method
+func (m *MockTwoFactorDAO) EnrollTwoFactor(userID uint, secret string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTwoFactor", userID, secret)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTwoFactor indicates an expected call of EnrollTwoFactor
+func (mr *MockTwoFactorDAOMockRecorder) EnrollTwoFactor(userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTwoFactor", reflect.TypeOf((*MockTwoFactorDAO)(nil).EnrollTwoFactor), userID, secret)
+}
+
+// GetTwoFactor mocks base method
+func (m *MockTwoFactorDAO) GetTwoFactor(userID uint) (models.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactor", userID)
+	ret0, _ := ret[0].(models.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactor indicates an expected call of GetTwoFactor
+func (mr *MockTwoFactorDAOMockRecorder) GetTwoFactor(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactor", reflect.TypeOf((*MockTwoFactorDAO)(nil).GetTwoFactor), userID)
+}
+
+// EnableTwoFactor mocks base method
+func (m *MockTwoFactorDAO) EnableTwoFactor(userID uint, step int64, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTwoFactor", userID, step, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTwoFactor indicates an expected call of EnableTwoFactor
+func (mr *MockTwoFactorDAOMockRecorder) EnableTwoFactor(userID, step, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactor", reflect.TypeOf((*MockTwoFactorDAO)(nil).EnableTwoFactor), userID, step, recoveryCodeHashes)
+}
+
+// DisableTwoFactor mocks base method
+func (m *MockTwoFactorDAO) DisableTwoFactor(userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactor", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor
+func (mr *MockTwoFactorDAOMockRecorder) DisableTwoFactor(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockTwoFactorDAO)(nil).DisableTwoFactor), userID)
+}
+
+// UseTOTPStep mocks base method
+func (m *MockTwoFactorDAO) UseTOTPStep(userID uint, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep
+func (mr *MockTwoFactorDAOMockRecorder) UseTOTPStep(userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockTwoFactorDAO)(nil).UseTOTPStep), userID, step)
+}
+
+// UseRecoveryCode mocks base method
+func (m *MockTwoFactorDAO) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode
+func (mr *MockTwoFactorDAOMockRecorder) UseRecoveryCode(userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTwoFactorDAO)(nil).UseRecoveryCode), userID, codeHash)
+}
+
+// CreateLoginChallenge mocks base method
+func (m *MockTwoFactorDAO) CreateLoginChallenge(userID uint, tokenHash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginChallenge", userID, tokenHash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLoginChallenge indicates an expected call of CreateLoginChallenge
+func (mr *MockTwoFactorDAOMockRecorder) CreateLoginChallenge(userID, tokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginChallenge", reflect.TypeOf((*MockTwoFactorDAO)(nil).CreateLoginChallenge), userID, tokenHash, expiresAt)
+}
+
+// UseLoginChallenge mocks base method
+func (m *MockTwoFactorDAO) UseLoginChallenge(tokenHash string, maxAttempts uint) (models.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLoginChallenge", tokenHash, maxAttempts)
+	ret0, _ := ret[0].(models.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseLoginChallenge indicates an expected call of UseLoginChallenge
+func (mr *MockTwoFactorDAOMockRecorder) UseLoginChallenge(tokenHash, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLoginChallenge", reflect.TypeOf((*MockTwoFactorDAO)(nil).UseLoginChallenge), tokenHash, maxAttempts)
+}
+
+// DeleteLoginChallenge mocks base method
+func (m *MockTwoFactorDAO) DeleteLoginChallenge(challengeID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginChallenge", challengeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginChallenge indicates an expected call of DeleteLoginChallenge
+func (mr *MockTwoFactorDAOMockRecorder) DeleteLoginChallenge(challengeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginChallenge", reflect.TypeOf((*MockTwoFactorDAO)(nil).DeleteLoginChallenge), challengeID)
+}
@@ -5,9 +5,11 @@
 package dao_mocks
 
 import (
+	dao "github.com/danielpenchev98/UShare/web-server/internal/db/dao"
 	models "github.com/danielpenchev98/UShare/web-server/internal/db/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockUamDAO is a mock of UamDAO interface
@@ -76,46 +78,177 @@ func (mr *MockUamDAOMockRecorder) GetUser(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUamDAO)(nil).GetUser), arg0)
 }
 
+// GetUserByID mocks base method
+func (m *MockUamDAO) GetUserByID(arg0 uint) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID
+func (mr *MockUamDAOMockRecorder) GetUserByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUamDAO)(nil).GetUserByID), arg0)
+}
+
 // DeleteUser mocks base method
-func (m *MockUamDAO) DeleteUser(arg0 uint) error {
+func (m *MockUamDAO) DeleteUser(arg0 uint, arg1 dao.AccountDeletionPolicy, arg2 func(models.User) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", arg0)
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteUser indicates an expected call of DeleteUser
-func (mr *MockUamDAOMockRecorder) DeleteUser(arg0 interface{}) *gomock.Call {
+func (mr *MockUamDAOMockRecorder) DeleteUser(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUamDAO)(nil).DeleteUser), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUamDAO)(nil).DeleteUser), arg0, arg1, arg2)
 }
 
 // CreateGroup mocks base method
-func (m *MockUamDAO) CreateGroup(arg0 uint, arg1 string) error {
+func (m *MockUamDAO) CreateGroup(arg0 uint, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateGroup", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateGroup", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateGroup indicates an expected call of CreateGroup
-func (mr *MockUamDAOMockRecorder) CreateGroup(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockUamDAOMockRecorder) CreateGroup(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockUamDAO)(nil).CreateGroup), arg0, arg1, arg2)
+}
+
+// SetGroupVisibility mocks base method
+func (m *MockUamDAO) SetGroupVisibility(arg0 uint, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGroupVisibility", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetGroupVisibility indicates an expected call of SetGroupVisibility
+func (mr *MockUamDAOMockRecorder) SetGroupVisibility(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGroupVisibility", reflect.TypeOf((*MockUamDAO)(nil).SetGroupVisibility), arg0, arg1, arg2)
+}
+
+// RequestToJoinGroup mocks base method
+func (m *MockUamDAO) RequestToJoinGroup(arg0 uint, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestToJoinGroup", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestToJoinGroup indicates an expected call of RequestToJoinGroup
+func (mr *MockUamDAOMockRecorder) RequestToJoinGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestToJoinGroup", reflect.TypeOf((*MockUamDAO)(nil).RequestToJoinGroup), arg0, arg1)
+}
+
+// GetJoinRequests mocks base method
+func (m *MockUamDAO) GetJoinRequests(arg0 uint, arg1 string) ([]dao.JoinRequestInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJoinRequests", arg0, arg1)
+	ret0, _ := ret[0].([]dao.JoinRequestInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJoinRequests indicates an expected call of GetJoinRequests
+func (mr *MockUamDAOMockRecorder) GetJoinRequests(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockUamDAO)(nil).CreateGroup), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJoinRequests", reflect.TypeOf((*MockUamDAO)(nil).GetJoinRequests), arg0, arg1)
 }
 
-// AddUserToGroup mocks base method
-func (m *MockUamDAO) AddUserToGroup(arg0 uint, arg1, arg2 string) error {
+// ResolveJoinRequest mocks base method
+func (m *MockUamDAO) ResolveJoinRequest(arg0 uint, arg1, arg2 string, arg3 bool, arg4 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddUserToGroup", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "ResolveJoinRequest", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AddUserToGroup indicates an expected call of AddUserToGroup
-func (mr *MockUamDAOMockRecorder) AddUserToGroup(arg0, arg1, arg2 interface{}) *gomock.Call {
+// ResolveJoinRequest indicates an expected call of ResolveJoinRequest
+func (mr *MockUamDAOMockRecorder) ResolveJoinRequest(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserToGroup", reflect.TypeOf((*MockUamDAO)(nil).AddUserToGroup), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveJoinRequest", reflect.TypeOf((*MockUamDAO)(nil).ResolveJoinRequest), arg0, arg1, arg2, arg3, arg4)
+}
+
+// InviteUserToGroup mocks base method
+func (m *MockUamDAO) InviteUserToGroup(arg0 uint, arg1, arg2, arg3 string, arg4 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteUserToGroup", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InviteUserToGroup indicates an expected call of InviteUserToGroup
+func (mr *MockUamDAOMockRecorder) InviteUserToGroup(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteUserToGroup", reflect.TypeOf((*MockUamDAO)(nil).InviteUserToGroup), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetInvitations mocks base method
+func (m *MockUamDAO) GetInvitations(arg0 uint) ([]dao.InvitationInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitations", arg0)
+	ret0, _ := ret[0].([]dao.InvitationInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitations indicates an expected call of GetInvitations
+func (mr *MockUamDAOMockRecorder) GetInvitations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockUamDAO)(nil).GetInvitations), arg0)
+}
+
+// AcceptInvitation mocks base method
+func (m *MockUamDAO) AcceptInvitation(arg0 uint, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation
+func (mr *MockUamDAOMockRecorder) AcceptInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockUamDAO)(nil).AcceptInvitation), arg0, arg1)
+}
+
+// DeclineInvitation mocks base method
+func (m *MockUamDAO) DeclineInvitation(arg0 uint, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineInvitation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeclineInvitation indicates an expected call of DeclineInvitation
+func (mr *MockUamDAOMockRecorder) DeclineInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineInvitation", reflect.TypeOf((*MockUamDAO)(nil).DeclineInvitation), arg0, arg1)
+}
+
+// DeleteExpiredInvitations mocks base method
+func (m *MockUamDAO) DeleteExpiredInvitations(arg0 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredInvitations", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredInvitations indicates an expected call of DeleteExpiredInvitations
+func (mr *MockUamDAOMockRecorder) DeleteExpiredInvitations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredInvitations", reflect.TypeOf((*MockUamDAO)(nil).DeleteExpiredInvitations), arg0)
 }
 
 // RemoveUserFromGroup mocks base method
@@ -132,6 +265,34 @@ func (mr *MockUamDAOMockRecorder) RemoveUserFromGroup(arg0, arg1, arg2 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserFromGroup", reflect.TypeOf((*MockUamDAO)(nil).RemoveUserFromGroup), arg0, arg1, arg2)
 }
 
+// ChangeMemberRole mocks base method
+func (m *MockUamDAO) ChangeMemberRole(arg0 uint, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeMemberRole", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeMemberRole indicates an expected call of ChangeMemberRole
+func (mr *MockUamDAOMockRecorder) ChangeMemberRole(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMemberRole", reflect.TypeOf((*MockUamDAO)(nil).ChangeMemberRole), arg0, arg1, arg2, arg3)
+}
+
+// TransferOwnership mocks base method
+func (m *MockUamDAO) TransferOwnership(arg0 uint, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferOwnership", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferOwnership indicates an expected call of TransferOwnership
+func (mr *MockUamDAOMockRecorder) TransferOwnership(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferOwnership", reflect.TypeOf((*MockUamDAO)(nil).TransferOwnership), arg0, arg1, arg2)
+}
+
 // MemberExists mocks base method
 func (m *MockUamDAO) MemberExists(arg0, arg1 uint) (bool, error) {
 	m.ctrl.T.Helper()
@@ -206,18 +367,18 @@ func (mr *MockUamDAOMockRecorder) EraseDeactivatedGroups(arg0 interface{}) *gomo
 }
 
 // GetAllGroups mocks base method
-func (m *MockUamDAO) GetAllGroups() ([]models.Group, error) {
+func (m *MockUamDAO) GetAllGroups(arg0 uint) ([]models.Group, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllGroups")
+	ret := m.ctrl.Call(m, "GetAllGroups", arg0)
 	ret0, _ := ret[0].([]models.Group)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllGroups indicates an expected call of GetAllGroups
-func (mr *MockUamDAOMockRecorder) GetAllGroups() *gomock.Call {
+func (mr *MockUamDAOMockRecorder) GetAllGroups(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllGroups", reflect.TypeOf((*MockUamDAO)(nil).GetAllGroups))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllGroups", reflect.TypeOf((*MockUamDAO)(nil).GetAllGroups), arg0)
 }
 
 // GetAllUsers mocks base method
@@ -236,10 +397,10 @@ func (mr *MockUamDAOMockRecorder) GetAllUsers() *gomock.Call {
 }
 
 // GetAllUsersInGroup mocks base method
-func (m *MockUamDAO) GetAllUsersInGroup(arg0 uint, arg1 string) ([]models.User, error) {
+func (m *MockUamDAO) GetAllUsersInGroup(arg0 uint, arg1 string) ([]dao.GroupMember, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllUsersInGroup", arg0, arg1)
-	ret0, _ := ret[0].([]models.User)
+	ret0, _ := ret[0].([]dao.GroupMember)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -0,0 +1,247 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//DropBoxInfo - drop box together with the name of its folder, the username of its creator and the number of received files
+type DropBoxInfo struct {
+	models.DropBox
+	FolderName string
+	Creator    string
+	Files      int64
+}
+
+//GroupDropBox - drop box together with the name of its group
+type GroupDropBox struct {
+	models.DropBox
+	GroupName string
+}
+
+//CreateDropBox - creates an upload-only link, through which people without an account send files to a folder of the group. Only for the group owner
+func (i *FmDAOImpl) CreateDropBox(userID uint, groupName string, folderPath string, tokenHash string, maxFileSize int64, maxFiles uint, expiresAt *time.Time) (uint, error) {
+	var dropBoxID uint
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		if _, err = checkPermissionWithConn(tx, userID, group.ID, ManageGroup); err != nil {
+			return err
+		}
+
+		folder, err := getFolderByPathWithConn(tx, group.ID, folderPath)
+		if err != nil {
+			return err
+		}
+
+		dropBox := models.DropBox{
+			TokenHash:   tokenHash,
+			GroupID:     group.ID,
+			FolderID:    folder.ID,
+			UserID:      userID,
+			MaxFileSize: maxFileSize,
+			MaxFiles:    maxFiles,
+			ExpiresAt:   expiresAt,
+		}
+
+		if result := tx.Create(&dropBox); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, fmt.Sprintf("Cannot save drop box in the db for group [%s]", groupName))
+		}
+		dropBoxID = dropBox.ID
+		return nil
+	})
+	return dropBoxID, err
+}
+
+//GetDropBoxes - returns the drop boxes of the group together with the number of files, received through them. Only for the group owner
+func (i *FmDAOImpl) GetDropBoxes(userID uint, groupName string) ([]DropBoxInfo, error) {
+	group, err := getGroupWithConn(i.dbConn, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = checkPermissionWithConn(i.dbConn, userID, group.ID, ManageGroup); err != nil {
+		return nil, err
+	}
+
+	var dropBoxes []DropBoxInfo
+	result := i.dbConn.Table("drop_boxes").
+		Select("drop_boxes.*, folders.name AS folder_name, users.username AS creator, (?) AS files",
+			i.dbConn.Table("file_infos").Select("COUNT(*)").Where("file_infos.drop_box_id = drop_boxes.id")).
+		Joins("left join folders on drop_boxes.folder_id = folders.id").
+		Joins("left join users on drop_boxes.user_id = users.id").
+		Where("drop_boxes.group_id = ?", group.ID).
+		Order("drop_boxes.id").
+		Find(&dropBoxes)
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the drop boxes of the group")
+	}
+	return dropBoxes, nil
+}
+
+//RemoveDropBox - deletes a drop box of the group. The files, which were already received through it, are kept. Only for the group owner
+func (i *FmDAOImpl) RemoveDropBox(userID uint, dropBoxID uint, groupName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		}
+
+		if _, err = checkPermissionWithConn(tx, userID, group.ID, ManageGroup); err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ?", dropBoxID).
+			Where("group_id = ?", group.ID).
+			Delete(&models.DropBox{})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the drop box")
+		} else if result.RowsAffected == 0 {
+			return myerr.NewItemNotFoundError(fmt.Sprintf("Drop box with id [%d] doesnt exist", dropBoxID))
+		}
+		return nil
+	})
+}
+
+//GetDropBox - returns the drop box with the given token hash together with the name of its group
+//the drop box is considered missing, if it has expired or its group is being deleted
+func (i *FmDAOImpl) GetDropBox(tokenHash string) (GroupDropBox, error) {
+	var dropBox GroupDropBox
+	result := i.dbConn.Raw(`SELECT drop_boxes.*, groups.name AS group_name FROM drop_boxes
+		INNER JOIN groups ON drop_boxes.group_id = groups.id
+		WHERE drop_boxes.token_hash = ? AND groups.active`, tokenHash).
+		Scan(&dropBox)
+
+	if result.Error != nil {
+		return GroupDropBox{}, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the drop box")
+	} else if dropBox.ID == 0 || (dropBox.ExpiresAt != nil && !dropBox.ExpiresAt.After(time.Now())) {
+		return GroupDropBox{}, myerr.NewItemNotFoundError("Drop box does not exist or has expired")
+	}
+	return dropBox, nil
+}
+
+//AddDropBoxFile - saves metadata for a file, received through the drop box, together with the name and the email of its sender
+//the file doesnt belong to any user, so it counts only towards the quota of the group and is managed by the members, who can manage all files of the group
+//the file is rejected, if the drop box has already received the maximum number of files or if a file with the same name already exists in the folder
+func (i *FmDAOImpl) AddDropBoxFile(dropBoxID uint, fileName string, uploaderName string, uploaderEmail string) (uint, error) {
+	var fileID uint
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		//the lock on the drop box guarantees that concurrent uploads dont exceed the maximum number of files
+		var dropBox models.DropBox
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", dropBoxID).
+			Take(&dropBox)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewItemNotFoundError("Drop box does not exist or has expired")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the drop box")
+		}
+
+		//the lock on the group guarantees that a concurrent upload cannot add a file with the same name
+		var group models.Group
+		result = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", dropBox.GroupID).
+			Take(&group)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) || (result.Error == nil && !group.Active) {
+			return myerr.NewItemNotFoundError("Drop box does not exist or has expired")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the group")
+		}
+
+		if dropBox.MaxFiles > 0 {
+			var count int64
+			result = tx.Unscoped().Model(&models.FileInfo{}).
+				Where("drop_box_id = ?", dropBoxID).
+				Count(&count)
+			if result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with counting the files of the drop box")
+			} else if count >= int64(dropBox.MaxFiles) {
+				return myerr.NewQuotaExceededError("The drop box doesnt accept more files")
+			}
+		}
+
+		if err := checkFolderWithConn(tx, group.ID, dropBox.FolderID); err != nil {
+			return err
+		}
+
+		var count int64
+		result = tx.Model(&models.FileInfo{}).
+			Where("group_id = ?", group.ID).
+			Where("folder_id = ?", dropBox.FolderID).
+			Where("name = ?", fileName).
+			Count(&count)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the files in the folder")
+		} else if count > 0 {
+			return myerr.NewClientError(fmt.Sprintf("A file with name [%s] already exists", fileName))
+		}
+
+		fileInfo := models.FileInfo{
+			Name:          fileName,
+			GroupID:       group.ID,
+			FolderID:      dropBox.FolderID,
+			Version:       1,
+			DropBoxID:     dropBoxID,
+			UploaderName:  uploaderName,
+			UploaderEmail: uploaderEmail,
+		}
+
+		if result = tx.Create(&fileInfo); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, fmt.Sprintf("Cannot save file info in the db for group [%s]", group.Name))
+		}
+		fileID = fileInfo.ID
+		return nil
+	})
+	return fileID, err
+}
+
+//RemoveDropBoxFile - removes the metadata of a file, received through the drop box, whose content couldnt be stored
+func (i *FmDAOImpl) RemoveDropBoxFile(dropBoxID uint, fileID uint) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		var fileInfo models.FileInfo
+		result := tx.Where("id = ?", fileID).
+			Where("drop_box_id = ?", dropBoxID).
+			Take(&fileInfo)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewItemNotFoundError("File does not exist")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the file")
+		}
+
+		if result = tx.Unscoped().Delete(&fileInfo); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the file info")
+		}
+
+		if fileInfo.Deduplicated {
+			return releaseBlobWithConn(tx, fileInfo.Checksum)
+		}
+		return nil
+	})
+}
+
+//DeleteExpiredDropBoxes - deletes the drop boxes, which expired before the given moment, and the drop boxes of the deleted groups
+func (i *FmDAOImpl) DeleteExpiredDropBoxes(expiredBefore time.Time) (int64, error) {
+	result := i.dbConn.
+		Where("expires_at < ? OR NOT EXISTS (?)", expiredBefore,
+			i.dbConn.Table("groups").Select("1").Where("groups.id = drop_boxes.group_id").Where("groups.active")).
+		Delete(&models.DropBox{})
+
+	if result.Error != nil {
+		return 0, myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the expired drop boxes")
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,198 @@
+package dao
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("FmDAO drop boxes", func() {
+	var (
+		fmDao FmDAO
+		mock  sqlmock.Sqlmock
+	)
+
+	const (
+		dropBoxID = 2
+		fileID    = 9
+		tokenHash = "token-hash"
+		groupName = "group"
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		fmDao = NewFmDAOImpl(gdb)
+	})
+
+	Context("GetDropBox", func() {
+		const lookupQuery = `SELECT drop_boxes.*, groups.name AS group_name FROM drop_boxes`
+
+		When("the drop box doesnt exist", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(lookupQuery)).
+					WithArgs(tokenHash).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "group_name"}))
+			})
+
+			It("returns not found error", func() {
+				_, err := fmDao.GetDropBox(tokenHash)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the drop box has expired", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(lookupQuery)).
+					WithArgs(tokenHash).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "expires_at", "group_name"}).AddRow(dropBoxID, time.Now().Add(-time.Hour), groupName))
+			})
+
+			It("returns not found error", func() {
+				_, err := fmDao.GetDropBox(tokenHash)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the drop box is valid", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(lookupQuery)).
+					WithArgs(tokenHash).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "expires_at", "group_name"}).AddRow(dropBoxID, time.Now().Add(time.Hour), groupName))
+			})
+
+			It("returns the drop box together with its group", func() {
+				dropBox, err := fmDao.GetDropBox(tokenHash)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dropBox.ID).To(Equal(uint(dropBoxID)))
+				Expect(dropBox.GroupName).To(Equal(groupName))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("AddDropBoxFile", func() {
+		const (
+			groupID  = 4
+			folderID = 6
+			fileName = "report.pdf"
+		)
+
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "drop_boxes" WHERE id = $1 LIMIT 1 FOR UPDATE`)).
+				WithArgs(dropBoxID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "group_id", "folder_id", "max_files"}).AddRow(dropBoxID, groupID, folderID, 3))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE id = $1 LIMIT 1 FOR UPDATE`)).
+				WithArgs(groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(groupID, groupName, true))
+		})
+
+		When("the drop box has received the maximum number of files", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "file_infos" WHERE drop_box_id = $1`)).
+					WithArgs(dropBoxID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectRollback()
+			})
+
+			It("returns quota exceeded error", func() {
+				_, err := fmDao.AddDropBoxFile(dropBoxID, fileName, "Partner", "partner@example.com")
+				_, ok := err.(*myerr.QuotaExceededError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("a file with the same name exists in the folder", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "file_infos" WHERE drop_box_id = $1`)).
+					WithArgs(dropBoxID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "folders" WHERE id = $1 AND group_id = $2`)).
+					WithArgs(folderID, groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "file_infos" WHERE group_id = $1 AND folder_id = $2 AND name = $3 AND "file_infos"."deleted_at" IS NULL`)).
+					WithArgs(groupID, folderID, fileName).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error without adding a version", func() {
+				_, err := fmDao.AddDropBoxFile(dropBoxID, fileName, "Partner", "partner@example.com")
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("A file with name [report.pdf] already exists"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the file is accepted", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "file_infos" WHERE drop_box_id = $1`)).
+					WithArgs(dropBoxID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "folders" WHERE id = $1 AND group_id = $2`)).
+					WithArgs(folderID, groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "file_infos" WHERE group_id = $1 AND folder_id = $2 AND name = $3 AND "file_infos"."deleted_at" IS NULL`)).
+					WithArgs(groupID, folderID, fileName).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "file_infos"`)).
+					WithArgs(sqlmock.AnyArg(), fileName, 0, groupID, folderID, "", 0, 1, false, nil, 0, dropBoxID, "Partner", "partner@example.com").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(fileID))
+				mock.ExpectCommit()
+			})
+
+			It("adds the file without an owner together with its sender", func() {
+				id, err := fmDao.AddDropBoxFile(dropBoxID, fileName, "Partner", "partner@example.com")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal(uint(fileID)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("DeleteExpiredDropBoxes", func() {
+		var expiredBefore time.Time
+
+		BeforeEach(func() {
+			expiredBefore = time.Now()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "drop_boxes" WHERE expires_at < $1 OR NOT EXISTS (SELECT 1 FROM "groups" WHERE groups.id = drop_boxes.group_id AND groups.active)`)).
+				WithArgs(expiredBefore).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectCommit()
+		})
+
+		It("returns the number of deleted drop boxes", func() {
+			count, err := fmDao.DeleteExpiredDropBoxes(expiredBefore)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(2)))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+})
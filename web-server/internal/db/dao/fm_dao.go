@@ -3,23 +3,98 @@ package dao
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 //go:generate mockgen --source=fm_dao.go --destination dao_mocks/fm_dao.go --package dao_mocks
 
 //FmDAO - interface, used for file management
 type FmDAO interface {
-	AddFileInfo(userID uint, fileName string, groupName string) (uint, error)
-	GetFileInfo(userID uint, fileID uint, groupName string) (models.FileInfo, error)
-	GetAllFilesInfo(userID uint, groupName string) ([]models.FileInfo, error)
+	AddFileInfo(userID uint, fileName string, folderID uint, groupName string) (uint, error)
+	GetFileVersion(userID uint, fileID uint, version uint, groupName string) (models.FileInfo, error)
+	GetFileVersions(userID uint, fileID uint, groupName string) ([]models.FileInfo, error)
+	GetAllFilesInfo(userID uint, folderID uint, groupName string) ([]models.FileInfo, error)
 	RemoveFileInfo(userID uint, fileID uint, groupName string) error
+	TrashFile(userID uint, fileID uint, groupName string) error
+	GetTrashedFiles(userID uint, groupName string) ([]models.FileInfo, error)
+	RestoreTrashedFile(userID uint, fileID uint, groupName string) error
+	RemoveTrashedFile(userID uint, fileID uint, groupName string) ([]models.FileInfo, error)
+	PurgeTrashedFiles(trashedBefore time.Time) ([]GroupFileInfo, error)
+	MoveFile(userID uint, fileID uint, groupName string, folderPath string, fileName string) error
+	GetFolder(userID uint, groupName string, path string) (models.Folder, error)
+	GetSubfolders(groupID uint, folderID uint) ([]models.Folder, error)
+	CreateFolder(userID uint, groupName string, path string) (uint, error)
+	RenameFolder(userID uint, groupName string, path string, newName string) error
+	MoveFolder(userID uint, groupName string, path string, parentPath string) error
+	RemoveFolder(userID uint, groupName string, path string) error
+	SetMaxFileVersions(userID uint, groupName string, maxVersions uint) error
+	RemoveExcessFileVersions(defaultMaxVersions uint) ([]GroupFileInfo, error)
+	AttachFileBlob(fileID uint, checksum string, size int64, quota Quota, sessionID uint, storeContent func(isNewBlob bool) error) error
+	CheckQuota(userID uint, groupName string, size int64, quota Quota) error
+	GetUserUsage(userID uint) (StorageUsage, error)
+	GetGroupUsage(userID uint, groupName string) (StorageUsage, error)
+	RemoveGroupFiles(groupNames []string) error
+	GetUnreferencedBlobs() ([]string, error)
+	EraseBlob(checksum string, eraseContent func() error) error
+	CreateUploadSession(userID uint, fileName string, size int64, folderID uint, groupName string, quota Quota) (uint, error)
+	GetUploadSession(userID uint, sessionID uint, groupName string) (models.UploadSession, error)
+	GetUploadChunks(sessionID uint) ([]models.UploadChunk, error)
+	AddUploadChunk(sessionID uint, offset int64, size int64) error
+	RemoveUploadSession(sessionID uint) error
+	GetAbandonedUploadSessions(updatedBefore time.Time) ([]GroupUploadSession, error)
+	CreateShareLink(userID uint, fileID uint, groupName string, tokenHash string, passwordHash string, expiresAt *time.Time, maxDownloads uint) (uint, error)
+	GetShareLinks(userID uint, groupName string) ([]ShareLinkInfo, error)
+	RemoveShareLink(userID uint, linkID uint, groupName string) error
+	GetSharedFile(tokenHash string) (models.ShareLink, GroupFileInfo, error)
+	UseShareLink(linkID uint) error
+	ReserveSharePasswordAttempt(linkID uint, maxAttempts uint) error
+	ReleaseSharePasswordAttempt(linkID uint) error
+	DeleteStaleShareLinks(expiredBefore time.Time) (int64, error)
+	CreateDropBox(userID uint, groupName string, folderPath string, tokenHash string, maxFileSize int64, maxFiles uint, expiresAt *time.Time) (uint, error)
+	GetDropBoxes(userID uint, groupName string) ([]DropBoxInfo, error)
+	RemoveDropBox(userID uint, dropBoxID uint, groupName string) error
+	GetDropBox(tokenHash string) (GroupDropBox, error)
+	AddDropBoxFile(dropBoxID uint, fileName string, uploaderName string, uploaderEmail string) (uint, error)
+	RemoveDropBoxFile(dropBoxID uint, fileID uint) error
+	DeleteExpiredDropBoxes(expiredBefore time.Time) (int64, error)
 	Migrate() error
 }
 
+//GroupFileInfo - metadata of a file together with the name of its group
+type GroupFileInfo struct {
+	models.FileInfo
+	GroupName string
+}
+
+//GroupUploadSession - upload session together with the name of its group
+type GroupUploadSession struct {
+	models.UploadSession
+	GroupName string
+}
+
+//Quota - the maximum number of bytes, which can be used by the files of a user and by the files of a group. 0 means unlimited
+type Quota struct {
+	UserBytes  int64
+	GroupBytes int64
+}
+
+//IsLimited - whether any of the limits is set
+func (q Quota) IsLimited() bool {
+	return q.UserBytes > 0 || q.GroupBytes > 0
+}
+
+//StorageUsage - the number of files and the bytes used by them. The trashed files are also included, as their content is still stored
+type StorageUsage struct {
+	UsedBytes int64
+	Files     int64
+}
+
 //FmDAOImpl - implementation of FmDAO
 type FmDAOImpl struct {
 	dbConn *gorm.DB
@@ -34,38 +109,49 @@ func NewFmDAOImpl(dbConn *gorm.DB) *FmDAOImpl {
 
 //Migrate - updates the models in the db
 func (i *FmDAOImpl) Migrate() error {
-	return i.dbConn.AutoMigrate(models.FileInfo{})
+	return i.dbConn.AutoMigrate(models.FileInfo{}, models.Folder{}, models.Blob{}, models.UploadSession{}, models.UploadChunk{}, models.ShareLink{}, models.DropBox{})
 }
 
 //AddFileInfo - saves metadate for a newly added file (just like in linux with inodes)
-func (i *FmDAOImpl) AddFileInfo(userID uint, fileName string, groupName string) (uint, error) {
+//if the folder already has a file with the same name, the new file becomes its next version
+func (i *FmDAOImpl) AddFileInfo(userID uint, fileName string, folderID uint, groupName string) (uint, error) {
 	var (
 		fileID uint
 		err    error
 	)
 	err = i.dbConn.Transaction(func(tx *gorm.DB) error {
 
-		group, err := getGroupWithConn(tx, groupName)
+		//the lock on the group guarantees that concurrent uploads get different version numbers
+		group, err := getGroupWithConn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), groupName)
 		if err != nil {
 			return err
 		}
 
-		var count int64
-		result := tx.Table("memberships").
-			Where("user_id = ?", userID).
-			Where("group_id = ?", group.ID).
-			Count(&count)
+		if _, err = checkPermissionWithConn(tx, userID, group.ID, UploadFiles); err != nil {
+			return err
+		}
 
+		if err = checkFolderWithConn(tx, group.ID, folderID); err != nil {
+			return err
+		}
+
+		var latestVersion uint
+		result := tx.Model(&models.FileInfo{}).
+			Select("COALESCE(MAX(version), 0)").
+			Where("group_id = ?", group.ID).
+			Where("folder_id = ?", folderID).
+			Where("name = ?", fileName).
+			Scan(&latestVersion)
 		if result.Error != nil {
-			return myerr.NewServerError(fmt.Sprintf("Couldnt check if membership exists. Reason: %v\n", result.Error))
-		} else if count == 0 {
-			return myerr.NewClientError("Cannot upload a file in a group you aren't part of")
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the file versions")
 		}
 
 		fileInfo := models.FileInfo{
-			Name:    fileName,
-			OwnerID: userID,
-			GroupID: group.ID,
+			Name:     fileName,
+			OwnerID:  userID,
+			GroupID:  group.ID,
+			FolderID: folderID,
+			Version:  latestVersion + 1,
 		}
 
 		if result = tx.Create(&fileInfo); result.Error != nil {
@@ -90,80 +176,1154 @@ func (i *FmDAOImpl) RemoveFileInfo(userID uint, fileID uint, groupName string) e
 			return err
 		}
 
-		if group.OwnerID != userID && fileInfo.OwnerID != userID {
-			return myerr.NewClientError("Only the onwer of the file or the group owner can remove files from the group")
+		if err = checkEntryPermissionWithConn(tx, userID, group.ID, fileInfo.OwnerID); err != nil {
+			return err
 		}
 
-		if result := tx.Delete(&fileInfo); result.Error != nil {
+		if result := tx.Unscoped().Delete(&fileInfo); result.Error != nil {
 			return myerr.NewServerError(fmt.Sprintf("Cannot save file info in the db for group [%s]", groupName))
 		} else if result.RowsAffected == 0 {
 			return myerr.NewClientError("File info not found")
 		}
-		fileID = fileInfo.ID
+
+		if fileInfo.Deduplicated {
+			return releaseBlobWithConn(tx, fileInfo.Checksum)
+		}
 		return nil
 	})
 }
 
-//GetFileInfo - fetches metadata for a particular file
-func (i *FmDAOImpl) GetFileInfo(userID uint, fileID uint, groupName string) (models.FileInfo, error) {
-
-	var count int64
-	result := i.dbConn.Table("memberships").Joins("inner join groups on memberships.group_id = groups.id").
-		Where("groups.name = ?", groupName).
-		Where("memberships.user_id = ?", userID).
-		Count(&count)
+//TrashFile - moves all versions of a file to the trash of the group, from where it can be restored or permanently removed
+func (i *FmDAOImpl) TrashFile(userID uint, fileID uint, groupName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		}
 
-	if result.Error != nil {
-		return models.FileInfo{}, myerr.NewServerErrorWrap(result.Error, "Problem with checking if user is a member of the group.")
-	} else if count == 0 {
-		return models.FileInfo{}, myerr.NewClientError("You arent a member of the group.")
-	}
+		fileInfo, err := getFileInfoWithConn(tx, fileID)
+		if err != nil {
+			return err
+		} else if fileInfo.GroupID != group.ID {
+			return myerr.NewItemNotFoundError("File does not exist")
+		}
 
-	fileInfo, err := getFileInfoWithConn(i.dbConn, fileID)
-	if err != nil {
-		return models.FileInfo{}, err
-	}
-	return fileInfo, err
+		if err = checkEntryPermissionWithConn(tx, userID, group.ID, fileInfo.OwnerID); err != nil {
+			return err
+		}
 
+		result := tx.Model(&models.FileInfo{}).
+			Where("group_id = ?", group.ID).
+			Where("folder_id = ?", fileInfo.FolderID).
+			Where("name = ?", fileInfo.Name).
+			Where("deleted_at IS NULL").
+			Updates(map[string]interface{}{"deleted_at": time.Now(), "deleted_by": userID})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with moving the file to the trash")
+		}
+		return nil
+	})
 }
 
-//GetAllFilesInfo - returns information about all files, given a praticular group
-func (i *FmDAOImpl) GetAllFilesInfo(userID uint, groupName string) ([]models.FileInfo, error) {
-	var count int64
-	result := i.dbConn.Table("memberships").Joins("inner join groups on memberships.group_id = groups.id").
-		Where("groups.name = ?", groupName).
-		Where("memberships.user_id = ?", userID).
-		Count(&count)
-
-	if result.Error != nil {
-		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with checking if user is a member of the group.")
-	} else if count == 0 {
-		return nil, myerr.NewClientError("You arent a member of the group.")
+//GetTrashedFiles - returns information about the latest versions of all files in the trash of the group
+func (i *FmDAOImpl) GetTrashedFiles(userID uint, groupName string) ([]models.FileInfo, error) {
+	if err := checkMembershipWithConn(i.dbConn, userID, groupName); err != nil {
+		return nil, err
 	}
 
 	var fileInfos []models.FileInfo
-	result = i.dbConn.Table("file_infos").Joins("inner join groups on file_infos.group_id = groups.id").
+	result := i.dbConn.Unscoped().Table("file_infos").
+		Select("DISTINCT ON (file_infos.deleted_at, file_infos.folder_id, file_infos.name) file_infos.*").
+		Joins("inner join groups on file_infos.group_id = groups.id").
 		Where("groups.name = ?", groupName).
+		Where("file_infos.deleted_at IS NOT NULL").
+		Order("file_infos.deleted_at DESC, file_infos.folder_id, file_infos.name, file_infos.version DESC, file_infos.id DESC").
 		Find(&fileInfos)
 	if result.Error != nil {
-		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching all files from a specific group")
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the trashed files of the group")
 	}
-
 	return fileInfos, nil
 }
 
-func getFileInfoWithConn(dbConn *gorm.DB, fileID uint) (models.FileInfo, error) {
-	var fileInfo models.FileInfo
+//RestoreTrashedFile - moves all versions of a trashed file back to their folder
+//if the folder was removed in the meantime, the file is restored in the root of the group
+func (i *FmDAOImpl) RestoreTrashedFile(userID uint, fileID uint, groupName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
 
-	result := dbConn.Table("file_infos").
-		Where("id = ?", fileID).
-		Take(&fileInfo)
+		fileInfo, err := getTrashedFileWithConn(tx, userID, fileID, group)
+		if err != nil {
+			return err
+		}
 
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return fileInfo, myerr.NewItemNotFoundError("File does not exist")
-	} else if result.Error != nil {
-		return fileInfo, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if file exists")
+		folderID := fileInfo.FolderID
+		if err = checkFolderWithConn(tx, group.ID, folderID); err != nil {
+			if _, ok := err.(*myerr.ItemNotFoundError); !ok {
+				return err
+			}
+			folderID = 0
+		}
+
+		var count int64
+		result := tx.Model(&models.FileInfo{}).
+			Where("group_id = ?", group.ID).
+			Where("folder_id = ?", folderID).
+			Where("name = ?", fileInfo.Name).
+			Count(&count)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of files in the folder")
+		} else if count > 0 {
+			return myerr.NewClientError(fmt.Sprintf("A file with name [%s] already exists in the folder", fileInfo.Name))
+		}
+
+		result = trashedVersionsWithConn(tx, fileInfo).
+			Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": 0, "folder_id": folderID})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with restoring the file from the trash")
+		}
+		return nil
+	})
+}
+
+//RemoveTrashedFile - permanently removes the metadata of all versions of a trashed file and returns them
+func (i *FmDAOImpl) RemoveTrashedFile(userID uint, fileID uint, groupName string) ([]models.FileInfo, error) {
+	var versions []models.FileInfo
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		}
+
+		fileInfo, err := getTrashedFileWithConn(tx, userID, fileID, group)
+		if err != nil {
+			return err
+		}
+
+		if result := trashedVersionsWithConn(tx, fileInfo).Find(&versions); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with fetching the versions of the file")
+		}
+
+		if result := tx.Unscoped().Delete(&versions); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the versions of the file")
+		}
+
+		for _, version := range versions {
+			if !version.Deduplicated {
+				continue
+			}
+			if err = releaseBlobWithConn(tx, version.Checksum); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return versions, err
+}
+
+//PurgeTrashedFiles - permanently removes the metadata of the files, which were moved to the trash before the given time, and returns them
+func (i *FmDAOImpl) PurgeTrashedFiles(trashedBefore time.Time) ([]GroupFileInfo, error) {
+	var fileInfos []GroupFileInfo
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(`SELECT file_infos.*, groups.name AS group_name FROM file_infos
+			INNER JOIN groups ON file_infos.group_id = groups.id
+			WHERE file_infos.deleted_at < ?`, trashedBefore).
+			Scan(&fileInfos)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with fetching the expired trashed files")
+		} else if len(fileInfos) == 0 {
+			return nil
+		}
+
+		fileIDs := make([]uint, 0, len(fileInfos))
+		for _, fileInfo := range fileInfos {
+			fileIDs = append(fileIDs, fileInfo.ID)
+		}
+
+		if result = tx.Unscoped().Delete(&models.FileInfo{}, fileIDs); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the expired trashed files")
+		}
+
+		for _, fileInfo := range fileInfos {
+			if !fileInfo.Deduplicated {
+				continue
+			}
+			if err := releaseBlobWithConn(tx, fileInfo.Checksum); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return fileInfos, err
+}
+
+//MoveFile - moves all versions of a file in another folder of the group, giving them a new name if it isnt empty
+func (i *FmDAOImpl) MoveFile(userID uint, fileID uint, groupName string, folderPath string, fileName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), groupName)
+		if err != nil {
+			return err
+		}
+
+		fileInfo, err := getGroupFileInfoWithConn(tx, userID, fileID, groupName)
+		if err != nil {
+			return err
+		} else if err = checkEntryPermissionWithConn(tx, userID, group.ID, fileInfo.OwnerID); err != nil {
+			return err
+		}
+
+		if fileName == "" {
+			fileName = fileInfo.Name
+		} else if err = validateEntryName(fileName); err != nil {
+			return err
+		}
+
+		folder, err := getFolderByPathWithConn(tx, group.ID, folderPath)
+		if err != nil {
+			return err
+		} else if folder.ID == fileInfo.FolderID && fileName == fileInfo.Name {
+			return nil
+		}
+
+		var count int64
+		result := tx.Model(&models.FileInfo{}).
+			Where("group_id = ?", group.ID).
+			Where("folder_id = ?", folder.ID).
+			Where("name = ?", fileName).
+			Count(&count)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of files in the folder")
+		} else if count > 0 {
+			return myerr.NewClientError(fmt.Sprintf("A file with name [%s] already exists in the folder", fileName))
+		}
+
+		result = tx.Model(&models.FileInfo{}).
+			Where("group_id = ?", group.ID).
+			Where("folder_id = ?", fileInfo.FolderID).
+			Where("name = ?", fileInfo.Name).
+			Where("deleted_at IS NULL").
+			Updates(map[string]interface{}{"folder_id": folder.ID, "name": fileName})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with moving the file")
+		}
+		return nil
+	})
+}
+
+//GetFolder - fetches a folder of the group, given its path like "reports/2026". The empty path refers to the root of the group
+func (i *FmDAOImpl) GetFolder(userID uint, groupName string, path string) (models.Folder, error) {
+	if err := checkMembershipWithConn(i.dbConn, userID, groupName); err != nil {
+		return models.Folder{}, err
 	}
 
-	return fileInfo, nil
+	group, err := getGroupWithConn(i.dbConn, groupName)
+	if err != nil {
+		return models.Folder{}, err
+	}
+	return getFolderByPathWithConn(i.dbConn, group.ID, path)
+}
+
+//GetSubfolders - returns the folders, which are directly inside a folder of the group, ordered by name
+func (i *FmDAOImpl) GetSubfolders(groupID uint, folderID uint) ([]models.Folder, error) {
+	var folders []models.Folder
+
+	result := i.dbConn.Table("folders").
+		Where("group_id = ?", groupID).
+		Where("parent_id = ?", folderID).
+		Order("name").
+		Find(&folders)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the subfolders")
+	}
+	return folders, nil
+}
+
+//CreateFolder - creates a folder, given its path, together with all missing parent folders
+func (i *FmDAOImpl) CreateFolder(userID uint, groupName string, path string) (uint, error) {
+	var folderID uint
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		if _, err = checkPermissionWithConn(tx, userID, group.ID, UploadFiles); err != nil {
+			return err
+		}
+
+		names, err := splitFolderPath(path)
+		if err != nil {
+			return err
+		} else if len(names) == 0 {
+			return myerr.NewClientError("Folder path isnt specified")
+		}
+
+		created := false
+		for _, name := range names {
+			folder, err := getFolderWithConn(tx, group.ID, folderID, name)
+			if _, ok := err.(*myerr.ItemNotFoundError); ok {
+				folder = models.Folder{
+					Name:     name,
+					GroupID:  group.ID,
+					ParentID: folderID,
+					OwnerID:  userID,
+				}
+				if result := tx.Create(&folder); result.Error != nil {
+					return myerr.NewServerErrorWrap(result.Error, fmt.Sprintf("Cannot save folder in the db for group [%s]", groupName))
+				}
+				created = true
+			} else if err != nil {
+				return err
+			} else {
+				created = false
+			}
+			folderID = folder.ID
+		}
+
+		if !created {
+			return myerr.NewClientError(fmt.Sprintf("Folder [%s] already exists", path))
+		}
+		return nil
+	})
+	return folderID, err
+}
+
+//RenameFolder - gives a new name to a folder of the group
+func (i *FmDAOImpl) RenameFolder(userID uint, groupName string, path string, newName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, folder, err := getModifiableFolderWithConn(tx, userID, groupName, path)
+		if err != nil {
+			return err
+		} else if err = validateEntryName(newName); err != nil {
+			return err
+		} else if newName == folder.Name {
+			return nil
+		}
+
+		if err = checkFolderNameIsFreeWithConn(tx, group.ID, folder.ParentID, newName); err != nil {
+			return err
+		}
+
+		if result := tx.Model(&folder).Update("name", newName); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with renaming the folder")
+		}
+		return nil
+	})
+}
+
+//MoveFolder - moves a folder of the group, together with its contents, inside another folder
+func (i *FmDAOImpl) MoveFolder(userID uint, groupName string, path string, parentPath string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, folder, err := getModifiableFolderWithConn(tx, userID, groupName, path)
+		if err != nil {
+			return err
+		}
+
+		parent, err := getFolderByPathWithConn(tx, group.ID, parentPath)
+		if err != nil {
+			return err
+		} else if parent.ID == folder.ParentID {
+			return nil
+		}
+
+		//the new parent cannot be the folder itself or one of its subfolders
+		for ancestor := parent; ancestor.ID != 0; {
+			if ancestor.ID == folder.ID {
+				return myerr.NewClientError("A folder cannot be moved inside itself")
+			}
+
+			result := tx.Table("folders").Where("id = ?", ancestor.ParentID).Take(&ancestor)
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				break
+			} else if result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the parent folders")
+			}
+		}
+
+		if err = checkFolderNameIsFreeWithConn(tx, group.ID, parent.ID, folder.Name); err != nil {
+			return err
+		}
+
+		if result := tx.Model(&folder).Update("parent_id", parent.ID); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with moving the folder")
+		}
+		return nil
+	})
+}
+
+//RemoveFolder - removes a folder of the group, if it is empty
+func (i *FmDAOImpl) RemoveFolder(userID uint, groupName string, path string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, folder, err := getModifiableFolderWithConn(tx, userID, groupName, path)
+		if err != nil {
+			return err
+		}
+
+		var subfolders, files int64
+		result := tx.Model(&models.Folder{}).
+			Where("group_id = ?", group.ID).
+			Where("parent_id = ?", folder.ID).
+			Count(&subfolders)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of subfolders")
+		}
+
+		result = tx.Model(&models.FileInfo{}).
+			Where("group_id = ?", group.ID).
+			Where("folder_id = ?", folder.ID).
+			Count(&files)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of files in the folder")
+		} else if subfolders+files > 0 {
+			return myerr.NewClientError("The folder isnt empty")
+		}
+
+		if result = tx.Delete(&folder); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the folder")
+		}
+		return nil
+	})
+}
+
+//SetMaxFileVersions - sets how many versions of each file are kept in the group. 0 means the default of the server
+func (i *FmDAOImpl) SetMaxFileVersions(userID uint, groupName string, maxVersions uint) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		}
+
+		if _, err = checkPermissionWithConn(tx, userID, group.ID, ManageGroup); err != nil {
+			return err
+		}
+
+		if result := tx.Model(&group).Update("max_file_versions", maxVersions); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the group")
+		}
+		return nil
+	})
+}
+
+//RemoveExcessFileVersions - removes the oldest versions of the files, which exceed the limit of their group, and returns them
+//the versions, which are still downloaded through usable share links or were received through existing drop boxes, are kept until they arent referenced
+func (i *FmDAOImpl) RemoveExcessFileVersions(defaultMaxVersions uint) ([]GroupFileInfo, error) {
+	var versions []GroupFileInfo
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(`SELECT ranked.*, groups.name AS group_name FROM (
+				SELECT file_infos.*, ROW_NUMBER() OVER (PARTITION BY group_id, folder_id, name ORDER BY version DESC, id DESC) AS version_rank
+				FROM file_infos
+				WHERE deleted_at IS NULL
+			) AS ranked
+			INNER JOIN groups ON ranked.group_id = groups.id
+			WHERE ranked.version_rank > COALESCE(NULLIF(groups.max_file_versions, 0), ?)
+			AND NOT EXISTS (SELECT 1 FROM share_links WHERE share_links.file_id = ranked.id
+				AND (share_links.expires_at IS NULL OR share_links.expires_at > ?)
+				AND (share_links.max_downloads = 0 OR share_links.downloads < share_links.max_downloads))
+			AND NOT EXISTS (SELECT 1 FROM drop_boxes WHERE drop_boxes.id = ranked.drop_box_id)`, defaultMaxVersions, time.Now()).
+			Scan(&versions)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with fetching the excess file versions")
+		} else if len(versions) == 0 {
+			return nil
+		}
+
+		fileIDs := make([]uint, 0, len(versions))
+		for _, version := range versions {
+			fileIDs = append(fileIDs, version.ID)
+		}
+
+		if result = tx.Unscoped().Delete(&models.FileInfo{}, fileIDs); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the excess file versions")
+		}
+
+		for _, version := range versions {
+			if !version.Deduplicated {
+				continue
+			}
+			if err := releaseBlobWithConn(tx, version.Checksum); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return versions, err
+}
+
+//AttachFileBlob - saves the SHA-256 checksum and the size (in bytes) of the content of a file and references the blob with the same checksum
+//storeContent is called while the blob is locked, so it can safely move the content in the blob store, if the blob is new, or discard it otherwise
+//the file is rejected, if its owner or its group would exceed the quota
+//sessionID is the upload session, whose content is attached (0 if there is none), so the bytes reserved by it arent counted twice
+func (i *FmDAOImpl) AttachFileBlob(fileID uint, checksum string, size int64, quota Quota, sessionID uint, storeContent func(isNewBlob bool) error) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		fileInfo, err := getFileInfoWithConn(tx, fileID)
+		if err != nil {
+			return err
+		}
+
+		if quota.IsLimited() {
+			if err = lockQuotaWithConn(tx, fileInfo.OwnerID, fileInfo.GroupID); err != nil {
+				return err
+			}
+
+			if err = checkQuotaWithConn(tx, fileInfo.OwnerID, fileInfo.GroupID, size, quota, sessionID); err != nil {
+				return err
+			}
+		}
+
+		blob := models.Blob{
+			Checksum: checksum,
+			Size:     size,
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&blob)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of the blob")
+		}
+
+		result = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("checksum = ?", checksum).
+			Take(&blob)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the blob")
+		}
+
+		if err = storeContent(blob.RefCount <= 0); err != nil {
+			return err
+		}
+
+		result = tx.Model(&models.Blob{}).
+			Where("checksum = ?", checksum).
+			Update("ref_count", gorm.Expr("GREATEST(ref_count, 0) + 1"))
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the blob references")
+		}
+
+		result = tx.Model(&models.FileInfo{}).
+			Where("id = ?", fileID).
+			Updates(map[string]interface{}{"checksum": checksum, "size": size, "deduplicated": true})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with saving the checksum and size of the file")
+		} else if result.RowsAffected == 0 {
+			return myerr.NewItemNotFoundError("File does not exist")
+		}
+		return nil
+	})
+}
+
+//CheckQuota - checks if a file with the given size can be added to the group without exceeding the quota
+//the quota is checked only for the members, whose role allows uploading files, so it isnt revealed to anyone else
+func (i *FmDAOImpl) CheckQuota(userID uint, groupName string, size int64, quota Quota) error {
+	group, err := getGroupWithConn(i.dbConn, groupName)
+	if err != nil {
+		return err
+	}
+
+	if _, err = checkPermissionWithConn(i.dbConn, userID, group.ID, UploadFiles); err != nil {
+		return err
+	}
+	return checkQuotaWithConn(i.dbConn, userID, group.ID, size, quota, 0)
+}
+
+//GetUserUsage - returns the storage, used by all files the user has uploaded
+func (i *FmDAOImpl) GetUserUsage(userID uint) (StorageUsage, error) {
+	return getStorageUsageWithConn(i.dbConn, "owner_id", userID)
+}
+
+//GetGroupUsage - returns the storage, used by all files of the group
+func (i *FmDAOImpl) GetGroupUsage(userID uint, groupName string) (StorageUsage, error) {
+	if err := checkMembershipWithConn(i.dbConn, userID, groupName); err != nil {
+		return StorageUsage{}, err
+	}
+
+	group, err := getGroupWithConn(i.dbConn, groupName)
+	if err != nil {
+		return StorageUsage{}, err
+	}
+	return getStorageUsageWithConn(i.dbConn, "group_id", group.ID)
+}
+
+//RemoveGroupFiles - removes the metadata of all files (including the trashed ones), folders and upload sessions of the groups and releases the blobs they reference
+func (i *FmDAOImpl) RemoveGroupFiles(groupNames []string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		groupIDs := tx.Table("groups").Select("id").Where("name IN ?", groupNames)
+
+		result := tx.Exec(`UPDATE blobs SET ref_count = blobs.ref_count - refs.count
+			FROM (SELECT checksum, COUNT(*) AS count FROM file_infos WHERE group_id IN (?) AND deduplicated GROUP BY checksum) AS refs
+			WHERE blobs.checksum = refs.checksum`, groupIDs)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with releasing the blobs of the groups")
+		}
+
+		if result = tx.Unscoped().Where("group_id IN (?)", groupIDs).Delete(&models.FileInfo{}); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the files of the groups")
+		}
+
+		if result = tx.Where("group_id IN (?)", groupIDs).Delete(&models.Folder{}); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the folders of the groups")
+		}
+
+		sessionIDs := tx.Table("upload_sessions").Select("id").Where("group_id IN (?)", groupIDs)
+		if result = tx.Where("session_id IN (?)", sessionIDs).Delete(&models.UploadChunk{}); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of upload chunks in db")
+		}
+
+		if result = tx.Where("group_id IN (?)", groupIDs).Delete(&models.UploadSession{}); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of upload sessions in db")
+		}
+		return nil
+	})
+}
+
+//GetUnreferencedBlobs - returns the checksums of all blobs, which arent referenced by any file
+func (i *FmDAOImpl) GetUnreferencedBlobs() ([]string, error) {
+	var checksums []string
+
+	result := i.dbConn.Model(&models.Blob{}).
+		Where("ref_count <= 0").
+		Pluck("checksum", &checksums)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the unreferenced blobs")
+	}
+	return checksums, nil
+}
+
+//EraseBlob - removes a blob, if it is still unreferenced. eraseContent is called while the blob is locked
+func (i *FmDAOImpl) EraseBlob(checksum string, eraseContent func() error) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		var blob models.Blob
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("checksum = ?", checksum).
+			Where("ref_count <= 0").
+			Take(&blob)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the blob")
+		}
+
+		if err := eraseContent(); err != nil {
+			return err
+		}
+
+		if result = tx.Delete(&blob); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the blob")
+		}
+		return nil
+	})
+}
+
+//GetFileVersion - fetches metadata for a particular version of a file. Version 0 means the latest one
+func (i *FmDAOImpl) GetFileVersion(userID uint, fileID uint, version uint, groupName string) (models.FileInfo, error) {
+	fileInfo, err := getGroupFileInfoWithConn(i.dbConn, userID, fileID, groupName)
+	if err != nil {
+		return models.FileInfo{}, err
+	}
+
+	query := i.dbConn.Table("file_infos").
+		Where("group_id = ?", fileInfo.GroupID).
+		Where("folder_id = ?", fileInfo.FolderID).
+		Where("name = ?", fileInfo.Name)
+	if version == 0 {
+		query = query.Order("version DESC, id DESC")
+	} else {
+		query = query.Where("version = ?", version)
+	}
+
+	var versionInfo models.FileInfo
+	result := query.Take(&versionInfo)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.FileInfo{}, myerr.NewItemNotFoundError("File version does not exist")
+	} else if result.Error != nil {
+		return models.FileInfo{}, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the file version")
+	}
+	return versionInfo, nil
+}
+
+//GetFileVersions - returns all versions of a file, starting from the latest one
+func (i *FmDAOImpl) GetFileVersions(userID uint, fileID uint, groupName string) ([]models.FileInfo, error) {
+	fileInfo, err := getGroupFileInfoWithConn(i.dbConn, userID, fileID, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []models.FileInfo
+	result := i.dbConn.Table("file_infos").
+		Where("group_id = ?", fileInfo.GroupID).
+		Where("folder_id = ?", fileInfo.FolderID).
+		Where("name = ?", fileInfo.Name).
+		Order("version DESC, id DESC").
+		Find(&versions)
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the versions of the file")
+	}
+	return versions, nil
+}
+
+//GetAllFilesInfo - returns information about the latest versions of all files in a folder of a praticular group
+func (i *FmDAOImpl) GetAllFilesInfo(userID uint, folderID uint, groupName string) ([]models.FileInfo, error) {
+	if err := checkMembershipWithConn(i.dbConn, userID, groupName); err != nil {
+		return nil, err
+	}
+
+	var fileInfos []models.FileInfo
+	result := i.dbConn.Table("file_infos").
+		Select("DISTINCT ON (file_infos.name) file_infos.*").
+		Joins("inner join groups on file_infos.group_id = groups.id").
+		Where("groups.name = ?", groupName).
+		Where("file_infos.folder_id = ?", folderID).
+		Order("file_infos.name, file_infos.version DESC, file_infos.id DESC").
+		Find(&fileInfos)
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching all files from a specific group")
+	}
+
+	return fileInfos, nil
+}
+
+//CreateUploadSession - starts an upload of a file, whose content will be sent in chunks
+//the size of the file is reserved until the session is finished, so parallel uploads cannot exceed the quota together
+func (i *FmDAOImpl) CreateUploadSession(userID uint, fileName string, size int64, folderID uint, groupName string, quota Quota) (uint, error) {
+	var sessionID uint
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		if _, err = checkPermissionWithConn(tx, userID, group.ID, UploadFiles); err != nil {
+			return err
+		}
+
+		if err = checkFolderWithConn(tx, group.ID, folderID); err != nil {
+			return err
+		}
+
+		if quota.IsLimited() {
+			if err = lockQuotaWithConn(tx, userID, group.ID); err != nil {
+				return err
+			}
+
+			if err = checkQuotaWithConn(tx, userID, group.ID, size, quota, 0); err != nil {
+				return err
+			}
+		}
+
+		session := models.UploadSession{
+			FileName: fileName,
+			Size:     size,
+			OwnerID:  userID,
+			GroupID:  group.ID,
+			FolderID: folderID,
+		}
+
+		if result := tx.Create(&session); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, fmt.Sprintf("Cannot save upload session in the db for group [%s]", groupName))
+		}
+		sessionID = session.ID
+		return nil
+	})
+	return sessionID, err
+}
+
+//GetUploadSession - fetches an upload session, started by the user in a particular group
+func (i *FmDAOImpl) GetUploadSession(userID uint, sessionID uint, groupName string) (models.UploadSession, error) {
+	var session models.UploadSession
+
+	result := i.dbConn.Table("upload_sessions").
+		Select("upload_sessions.*").
+		Joins("inner join groups on upload_sessions.group_id = groups.id").
+		Where("upload_sessions.id = ?", sessionID).
+		Where("upload_sessions.owner_id = ?", userID).
+		Where("groups.name = ?", groupName).
+		Take(&session)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return session, myerr.NewItemNotFoundError("Upload session does not exist")
+	} else if result.Error != nil {
+		return session, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of upload session")
+	}
+	return session, nil
+}
+
+//GetUploadChunks - returns all received chunks of an upload session, ordered by their offset
+func (i *FmDAOImpl) GetUploadChunks(sessionID uint) ([]models.UploadChunk, error) {
+	var chunks []models.UploadChunk
+
+	result := i.dbConn.Table("upload_chunks").
+		Where("session_id = ?", sessionID).
+		Order("\"offset\"").
+		Find(&chunks)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the chunks of upload session")
+	}
+	return chunks, nil
+}
+
+//AddUploadChunk - marks a range of an upload session as received
+//receiving the same chunk twice is allowed, but a chunk cannot partially overlap other chunks
+func (i *FmDAOImpl) AddUploadChunk(sessionID uint, offset int64, size int64) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		var chunks []models.UploadChunk
+		result := tx.Table("upload_chunks").
+			Where("session_id = ?", sessionID).
+			Where("\"offset\" < ?", offset+size).
+			Where("\"offset\" + size > ?", offset).
+			Find(&chunks)
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of received chunks")
+		}
+
+		for _, chunk := range chunks {
+			if chunk.Offset == offset && chunk.Size == size {
+				return nil
+			}
+		}
+
+		if len(chunks) != 0 {
+			return myerr.NewClientError("The chunk overlaps with already received data")
+		}
+
+		chunk := models.UploadChunk{
+			SessionID: sessionID,
+			Offset:    offset,
+			Size:      size,
+		}
+
+		if result = tx.Create(&chunk); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Cannot save the chunk of upload session in the db")
+		}
+
+		result = tx.Model(&models.UploadSession{}).
+			Where("id = ?", sessionID).
+			Update("updated_at", time.Now())
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Cannot update the upload session in the db")
+		}
+		return nil
+	})
+}
+
+//RemoveUploadSession - removes an upload session together with the information about its chunks
+func (i *FmDAOImpl) RemoveUploadSession(sessionID uint) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("session_id = ?", sessionID).Delete(&models.UploadChunk{})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of upload chunks in db")
+		}
+
+		if result = tx.Delete(&models.UploadSession{}, sessionID); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of upload session in db")
+		}
+		return nil
+	})
+}
+
+//GetAbandonedUploadSessions - returns the upload sessions in the active groups, whose owners were deleted
+//or which didnt receive any chunk since updatedBefore
+func (i *FmDAOImpl) GetAbandonedUploadSessions(updatedBefore time.Time) ([]GroupUploadSession, error) {
+	var sessions []GroupUploadSession
+
+	result := i.dbConn.Table("upload_sessions").
+		Select("upload_sessions.*, groups.name as group_name").
+		Joins("inner join groups on upload_sessions.group_id = groups.id").
+		Where("groups.active = ?", true).
+		Where(i.dbConn.Where("NOT EXISTS (?)", i.dbConn.Table("users").Select("1").Where("users.id = upload_sessions.owner_id")).
+			Or("upload_sessions.updated_at < ?", updatedBefore)).
+		Find(&sessions)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the abandoned upload sessions")
+	}
+	return sessions, nil
+}
+
+func getFileInfoWithConn(dbConn *gorm.DB, fileID uint) (models.FileInfo, error) {
+	var fileInfo models.FileInfo
+
+	result := dbConn.Table("file_infos").
+		Where("id = ?", fileID).
+		Take(&fileInfo)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return fileInfo, myerr.NewItemNotFoundError("File does not exist")
+	} else if result.Error != nil {
+		return fileInfo, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if file exists")
+	}
+
+	return fileInfo, nil
+}
+
+//getGroupFileInfoWithConn - fetches metadata for a file of the group, if the user is a member of the group
+func getGroupFileInfoWithConn(dbConn *gorm.DB, userID uint, fileID uint, groupName string) (models.FileInfo, error) {
+	if err := checkMembershipWithConn(dbConn, userID, groupName); err != nil {
+		return models.FileInfo{}, err
+	}
+
+	var fileInfo models.FileInfo
+	result := dbConn.Table("file_infos").
+		Select("file_infos.*").
+		Joins("inner join groups on file_infos.group_id = groups.id").
+		Where("file_infos.id = ?", fileID).
+		Where("groups.name = ?", groupName).
+		Take(&fileInfo)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return fileInfo, myerr.NewItemNotFoundError("File does not exist")
+	} else if result.Error != nil {
+		return fileInfo, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if file exists")
+	}
+	return fileInfo, nil
+}
+
+func checkMembershipWithConn(dbConn *gorm.DB, userID uint, groupName string) error {
+	var count int64
+	result := dbConn.Table("memberships").Joins("inner join groups on memberships.group_id = groups.id").
+		Where("groups.name = ?", groupName).
+		Where("memberships.user_id = ?", userID).
+		Count(&count)
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with checking if user is a member of the group.")
+	} else if count == 0 {
+		return myerr.NewClientError("You arent a member of the group.")
+	}
+	return nil
+}
+
+func releaseBlobWithConn(dbConn *gorm.DB, checksum string) error {
+	result := dbConn.Model(&models.Blob{}).
+		Where("checksum = ?", checksum).
+		Update("ref_count", gorm.Expr("ref_count - 1"))
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with releasing the blob of the file")
+	}
+	return nil
+}
+
+//getModifiableFolderWithConn - fetches a folder (other than the root), which can be changed by the user, depending on its role and the owner of the folder
+//the group is locked until the end of the transaction
+func getModifiableFolderWithConn(tx *gorm.DB, userID uint, groupName string, path string) (models.Group, models.Folder, error) {
+	group, err := getGroupWithConn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), groupName)
+	if err != nil {
+		return models.Group{}, models.Folder{}, err
+	}
+
+	if err = checkMembershipWithConn(tx, userID, groupName); err != nil {
+		return models.Group{}, models.Folder{}, err
+	}
+
+	folder, err := getFolderByPathWithConn(tx, group.ID, path)
+	if err != nil {
+		return models.Group{}, models.Folder{}, err
+	} else if folder.ID == 0 {
+		return models.Group{}, models.Folder{}, myerr.NewClientError("The root folder of the group cannot be changed")
+	} else if err = checkEntryPermissionWithConn(tx, userID, group.ID, folder.OwnerID); err != nil {
+		return models.Group{}, models.Folder{}, err
+	}
+	return group, folder, nil
+}
+
+//getFolderByPathWithConn - walks the folders of the group, given a path like "reports/2026"
+func getFolderByPathWithConn(dbConn *gorm.DB, groupID uint, path string) (models.Folder, error) {
+	names, err := splitFolderPath(path)
+	if err != nil {
+		return models.Folder{}, err
+	}
+
+	folder := models.Folder{GroupID: groupID}
+	for _, name := range names {
+		if folder, err = getFolderWithConn(dbConn, groupID, folder.ID, name); err != nil {
+			return models.Folder{}, myerr.NewItemNotFoundError(fmt.Sprintf("Folder [%s] does not exist", path))
+		}
+	}
+	return folder, nil
+}
+
+func getFolderWithConn(dbConn *gorm.DB, groupID uint, parentID uint, name string) (models.Folder, error) {
+	var folder models.Folder
+
+	result := dbConn.Table("folders").
+		Where("group_id = ?", groupID).
+		Where("parent_id = ?", parentID).
+		Where("name = ?", name).
+		Take(&folder)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return folder, myerr.NewItemNotFoundError(fmt.Sprintf("Folder [%s] does not exist", name))
+	} else if result.Error != nil {
+		return folder, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the folder")
+	}
+	return folder, nil
+}
+
+//checkFolderWithConn - checks if the folder belongs to the group. Folder 0 is the root of every group
+func checkFolderWithConn(dbConn *gorm.DB, groupID uint, folderID uint) error {
+	if folderID == 0 {
+		return nil
+	}
+
+	var count int64
+	result := dbConn.Model(&models.Folder{}).
+		Where("id = ?", folderID).
+		Where("group_id = ?", groupID).
+		Count(&count)
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the folder")
+	} else if count == 0 {
+		return myerr.NewItemNotFoundError("Folder does not exist")
+	}
+	return nil
+}
+
+func checkFolderNameIsFreeWithConn(dbConn *gorm.DB, groupID uint, parentID uint, name string) error {
+	var count int64
+	result := dbConn.Model(&models.Folder{}).
+		Where("group_id = ?", groupID).
+		Where("parent_id = ?", parentID).
+		Where("name = ?", name).
+		Count(&count)
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the folder")
+	} else if count > 0 {
+		return myerr.NewClientError(fmt.Sprintf("A folder with name [%s] already exists", name))
+	}
+	return nil
+}
+
+//splitFolderPath - splits a path like "reports/2026" into the names of the folders. The empty path refers to the root
+func splitFolderPath(path string) ([]string, error) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil, nil
+	}
+
+	names := strings.Split(path, "/")
+	for _, name := range names {
+		if err := validateEntryName(name); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+func validateEntryName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") || len(name) > 256 {
+		return myerr.NewClientError(fmt.Sprintf("Invalid name [%s]", name))
+	}
+	return nil
+}
+
+//getTrashedFileWithConn - fetches metadata for a trashed file of the group, if the role of the user allows changing it
+func getTrashedFileWithConn(dbConn *gorm.DB, userID uint, fileID uint, group models.Group) (models.FileInfo, error) {
+	var fileInfo models.FileInfo
+	result := dbConn.Unscoped().Table("file_infos").
+		Where("id = ?", fileID).
+		Where("group_id = ?", group.ID).
+		Where("deleted_at IS NOT NULL").
+		Take(&fileInfo)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return fileInfo, myerr.NewItemNotFoundError("File does not exist in the trash")
+	} else if result.Error != nil {
+		return fileInfo, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the trashed file")
+	} else if err := checkEntryPermissionWithConn(dbConn, userID, group.ID, fileInfo.OwnerID); err != nil {
+		return fileInfo, err
+	}
+	return fileInfo, nil
+}
+
+//trashedVersionsWithConn - query for all versions of a file, which were moved to the trash together with the given one
+func trashedVersionsWithConn(dbConn *gorm.DB, fileInfo models.FileInfo) *gorm.DB {
+	return dbConn.Unscoped().Model(&models.FileInfo{}).
+		Where("group_id = ?", fileInfo.GroupID).
+		Where("folder_id = ?", fileInfo.FolderID).
+		Where("name = ?", fileInfo.Name).
+		Where("deleted_at = ?", fileInfo.DeletedAt)
+}
+
+//lockQuotaWithConn - locks the group and the owner, so concurrent uploads cannot exceed the quota together
+func lockQuotaWithConn(tx *gorm.DB, userID uint, groupID uint) error {
+	if result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&models.Group{}, groupID); result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the group")
+	}
+	//the files, received through drop boxes, dont have an owner
+	if userID != 0 {
+		if result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&models.User{}, userID); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the user")
+		}
+	}
+	return nil
+}
+
+//checkQuotaWithConn - checks if the user and the group can store additional bytes without exceeding the quota
+//the bytes, reserved by the unfinished upload sessions (except excludedSessionID), are counted as used
+//the quota of the user isnt checked for the files without an owner
+func checkQuotaWithConn(dbConn *gorm.DB, userID uint, groupID uint, size int64, quota Quota, excludedSessionID uint) error {
+	if quota.UserBytes > 0 && userID != 0 {
+		usedBytes, err := getReservedUsageWithConn(dbConn, "owner_id", userID, excludedSessionID)
+		if err != nil {
+			return err
+		} else if usedBytes+size > quota.UserBytes {
+			return myerr.NewQuotaExceededError(fmt.Sprintf("The file exceeds your storage quota. Used %d of %d bytes", usedBytes, quota.UserBytes))
+		}
+	}
+
+	if quota.GroupBytes > 0 {
+		usedBytes, err := getReservedUsageWithConn(dbConn, "group_id", groupID, excludedSessionID)
+		if err != nil {
+			return err
+		} else if usedBytes+size > quota.GroupBytes {
+			return myerr.NewQuotaExceededError(fmt.Sprintf("The file exceeds the storage quota of the group. Used %d of %d bytes", usedBytes, quota.GroupBytes))
+		}
+	}
+	return nil
+}
+
+//getReservedUsageWithConn - returns the bytes, used by the files, together with the ones reserved by the unfinished upload sessions
+func getReservedUsageWithConn(dbConn *gorm.DB, column string, id uint, excludedSessionID uint) (int64, error) {
+	usage, err := getStorageUsageWithConn(dbConn, column, id)
+	if err != nil {
+		return 0, err
+	}
+
+	var reservedBytes int64
+	result := dbConn.Model(&models.UploadSession{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where(column+" = ?", id).
+		Where("id <> ?", excludedSessionID).
+		Scan(&reservedBytes)
+
+	if result.Error != nil {
+		return 0, myerr.NewServerErrorWrap(result.Error, "Problem with calculating the storage, reserved by the upload sessions")
+	}
+	return usage.UsedBytes + reservedBytes, nil
+}
+
+func getStorageUsageWithConn(dbConn *gorm.DB, column string, id uint) (StorageUsage, error) {
+	var usage StorageUsage
+	result := dbConn.Unscoped().Model(&models.FileInfo{}).
+		Select("COALESCE(SUM(size), 0) AS used_bytes, COUNT(*) AS files").
+		Where(column+" = ?", id).
+		Scan(&usage)
+
+	if result.Error != nil {
+		return StorageUsage{}, myerr.NewServerErrorWrap(result.Error, "Problem with calculating the used storage")
+	}
+	return usage, nil
 }
@@ -0,0 +1,731 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("FmDAO files", func() {
+	var (
+		gdb   *gorm.DB
+		fmDao FmDAO
+		mock  sqlmock.Sqlmock
+	)
+
+	const (
+		userID    = 1
+		groupID   = 4
+		folderID  = 6
+		fileID    = 9
+		fileName  = "report.pdf"
+		otherID   = 2
+		checksum  = "checksum"
+		size      = 100
+		groupName = "group"
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err = gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		fmDao = NewFmDAOImpl(gdb)
+	})
+
+	expectGroupUsage := func(usedBytes, reservedBytes int64, excludedSessionID uint) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(size), 0) AS used_bytes, COUNT(*) AS files FROM "file_infos" WHERE group_id = $1`)).
+			WithArgs(groupID).
+			WillReturnRows(sqlmock.NewRows([]string{"used_bytes", "files"}).AddRow(usedBytes, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(size), 0) FROM "upload_sessions" WHERE group_id = $1 AND id <> $2`)).
+			WithArgs(groupID, excludedSessionID).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(reservedBytes))
+	}
+
+	Context("AddFileInfo", func() {
+		const versionQuery = `SELECT COALESCE(MAX(version), 0) FROM "file_infos" WHERE group_id = $1 AND folder_id = $2 AND name = $3 AND "file_infos"."deleted_at" IS NULL`
+
+		expectMembership := func(role string) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+				WithArgs(userID, groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "group_id", "role"}).AddRow(1, userID, groupID, role))
+		}
+
+		expectVersionInsert := func(latestVersion, version uint) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "folders" WHERE id = $1 AND group_id = $2`)).
+				WithArgs(folderID, groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			mock.ExpectQuery(regexp.QuoteMeta(versionQuery)).
+				WithArgs(groupID, folderID, fileName).
+				WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(latestVersion))
+			mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "file_infos"`)).
+				WithArgs(sqlmock.AnyArg(), fileName, userID, groupID, folderID, "", 0, version, false, nil, 0, 0, "", "").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(fileID))
+		}
+
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1 FOR UPDATE`)).
+				WithArgs(groupName).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(groupID, groupName, true))
+		})
+
+		When("the role of the user doesnt allow uploading files", func() {
+			BeforeEach(func() {
+				expectMembership(models.RoleViewer)
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				_, err := fmDao.AddFileInfo(userID, fileName, folderID, groupName)
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the folder has no file with the same name", func() {
+			BeforeEach(func() {
+				expectMembership(models.RoleEditor)
+				expectVersionInsert(0, 1)
+				mock.ExpectCommit()
+			})
+
+			It("adds the first version of the file", func() {
+				id, err := fmDao.AddFileInfo(userID, fileName, folderID, groupName)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal(uint(fileID)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the folder already has versions of the file", func() {
+			BeforeEach(func() {
+				expectMembership(models.RoleEditor)
+				expectVersionInsert(3, 4)
+				mock.ExpectCommit()
+			})
+
+			It("adds the next version of the file", func() {
+				id, err := fmDao.AddFileInfo(userID, fileName, folderID, groupName)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal(uint(fileID)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("TrashFile", func() {
+		const trashQuery = `UPDATE "file_infos" SET "deleted_at"=$1,"deleted_by"=$2 WHERE group_id = $3 AND folder_id = $4 AND name = $5 AND deleted_at IS NULL`
+
+		expectFileLookup := func(ownerID, fileGroupID uint) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "file_infos" WHERE id = $1 AND "file_infos"."deleted_at" IS NULL LIMIT 1`)).
+				WithArgs(fileID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "owner_id", "group_id", "folder_id"}).AddRow(fileID, fileName, ownerID, fileGroupID, folderID))
+		}
+
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1`)).
+				WithArgs(groupName).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(groupID, groupName, true))
+		})
+
+		When("the file belongs to another group", func() {
+			BeforeEach(func() {
+				expectFileLookup(userID, groupID+1)
+				mock.ExpectRollback()
+			})
+
+			It("returns not found error", func() {
+				err := fmDao.TrashFile(userID, fileID, groupName)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the role of the user doesnt allow changing files of other members", func() {
+			BeforeEach(func() {
+				expectFileLookup(otherID, groupID)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "group_id", "role"}).AddRow(1, userID, groupID, models.RoleEditor))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := fmDao.TrashFile(userID, fileID, groupName)
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the user owns the file", func() {
+			BeforeEach(func() {
+				expectFileLookup(userID, groupID)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "group_id", "role"}).AddRow(1, userID, groupID, models.RoleEditor))
+				mock.ExpectExec(regexp.QuoteMeta(trashQuery)).
+					WithArgs(sqlmock.AnyArg(), userID, groupID, folderID, fileName).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			})
+
+			It("moves all versions of the file to the trash", func() {
+				Expect(fmDao.TrashFile(userID, fileID, groupName)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("RestoreTrashedFile", func() {
+		const (
+			nameQuery    = `SELECT count(1) FROM "file_infos" WHERE group_id = $1 AND folder_id = $2 AND name = $3 AND "file_infos"."deleted_at" IS NULL`
+			restoreQuery = `UPDATE "file_infos" SET "deleted_at"=$1,"deleted_by"=$2,"folder_id"=$3 WHERE group_id = $4 AND folder_id = $5 AND name = $6 AND deleted_at = $7`
+		)
+
+		var deletedAt time.Time
+
+		expectFolderLookup := func(count int) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "folders" WHERE id = $1 AND group_id = $2`)).
+				WithArgs(folderID, groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+		}
+
+		BeforeEach(func() {
+			deletedAt = time.Now().Add(-time.Hour)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1 FOR UPDATE`)).
+				WithArgs(groupName).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(groupID, groupName, true))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "file_infos" WHERE id = $1 AND group_id = $2 AND deleted_at IS NOT NULL LIMIT 1`)).
+				WithArgs(fileID, groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "owner_id", "group_id", "folder_id", "deleted_at"}).
+					AddRow(fileID, fileName, userID, groupID, folderID, deletedAt))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+				WithArgs(userID, groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "group_id", "role"}).AddRow(1, userID, groupID, models.RoleEditor))
+		})
+
+		When("the folder has a file with the same name", func() {
+			BeforeEach(func() {
+				expectFolderLookup(1)
+				mock.ExpectQuery(regexp.QuoteMeta(nameQuery)).
+					WithArgs(groupID, folderID, fileName).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := fmDao.RestoreTrashedFile(userID, fileID, groupName)
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("A file with name [report.pdf] already exists in the folder"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the folder still exists", func() {
+			BeforeEach(func() {
+				expectFolderLookup(1)
+				mock.ExpectQuery(regexp.QuoteMeta(nameQuery)).
+					WithArgs(groupID, folderID, fileName).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec(regexp.QuoteMeta(restoreQuery)).
+					WithArgs(nil, 0, folderID, groupID, folderID, fileName, deletedAt).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			})
+
+			It("restores all versions of the file in the folder", func() {
+				Expect(fmDao.RestoreTrashedFile(userID, fileID, groupName)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the folder was removed in the meantime", func() {
+			BeforeEach(func() {
+				expectFolderLookup(0)
+				mock.ExpectQuery(regexp.QuoteMeta(nameQuery)).
+					WithArgs(groupID, 0, fileName).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec(regexp.QuoteMeta(restoreQuery)).
+					WithArgs(nil, 0, 0, groupID, folderID, fileName, deletedAt).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			})
+
+			It("restores all versions of the file in the root of the group", func() {
+				Expect(fmDao.RestoreTrashedFile(userID, fileID, groupName)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("AddUploadChunk", func() {
+		const (
+			sessionID    = 5
+			overlapQuery = `SELECT * FROM "upload_chunks" WHERE session_id = $1 AND "offset" < $2 AND "offset" + size > $3`
+		)
+
+		BeforeEach(func() {
+			mock.ExpectBegin()
+		})
+
+		When("the same chunk was already received", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(overlapQuery)).
+					WithArgs(sessionID, 200, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "offset", "size"}).AddRow(1, sessionID, 100, 100))
+				mock.ExpectCommit()
+			})
+
+			It("accepts the chunk again without saving it", func() {
+				Expect(fmDao.AddUploadChunk(sessionID, 100, 100)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the chunk overlaps with received data", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(overlapQuery)).
+					WithArgs(sessionID, 200, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "offset", "size"}).AddRow(1, sessionID, 50, 100))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := fmDao.AddUploadChunk(sessionID, 100, 100)
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the chunk is new", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(overlapQuery)).
+					WithArgs(sessionID, 200, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "offset", "size"}))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "upload_chunks" ("created_at","session_id","offset","size") VALUES ($1,$2,$3,$4) RETURNING "id"`)).
+					WithArgs(sqlmock.AnyArg(), sessionID, 100, 100).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "upload_sessions" SET "updated_at"=$1 WHERE id = $2`)).
+					WithArgs(sqlmock.AnyArg(), sessionID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("saves the chunk", func() {
+				Expect(fmDao.AddUploadChunk(sessionID, 100, 100)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("CreateUploadSession", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1`)).
+				WithArgs(groupName).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(groupID, groupName, true))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+				WithArgs(userID, groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "group_id", "role"}).AddRow(1, userID, groupID, models.RoleEditor))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE "groups"."id" = $1 LIMIT 1 FOR UPDATE`)).
+				WithArgs(groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(groupID))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1 LIMIT 1 FOR UPDATE`)).
+				WithArgs(userID).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+		})
+
+		When("the unfinished upload sessions reserve the rest of the quota", func() {
+			BeforeEach(func() {
+				expectGroupUsage(0, 100, 0)
+				mock.ExpectRollback()
+			})
+
+			It("returns quota exceeded error", func() {
+				_, err := fmDao.CreateUploadSession(userID, fileName, size, 0, groupName, Quota{GroupBytes: 150})
+				_, ok := err.(*myerr.QuotaExceededError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("GetAbandonedUploadSessions", func() {
+		const sessionID = 5
+
+		It("returns the orphaned sessions and the ones without recent chunks", func() {
+			updatedBefore := time.Now().Add(-24 * time.Hour)
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT upload_sessions.*, groups.name as group_name FROM "upload_sessions" inner join groups on upload_sessions.group_id = groups.id WHERE groups.active = $1 AND (NOT EXISTS (SELECT 1 FROM "users" WHERE users.id = upload_sessions.owner_id) OR upload_sessions.updated_at < $2)`)).
+				WithArgs(true, updatedBefore).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "group_id", "updated_at", "group_name"}).AddRow(sessionID, userID, groupID, updatedBefore.Add(-time.Hour), groupName))
+
+			sessions, err := fmDao.GetAbandonedUploadSessions(updatedBefore)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sessions).To(HaveLen(1))
+			Expect(sessions[0].ID).To(Equal(uint(sessionID)))
+			Expect(sessions[0].GroupName).To(Equal(groupName))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Context("CreateFolder", func() {
+		const folderQuery = `SELECT * FROM "folders" WHERE group_id = $1 AND parent_id = $2 AND name = $3 LIMIT 1`
+
+		expectFolderLookup := func(parentID uint, name string, rows *sqlmock.Rows) {
+			mock.ExpectQuery(regexp.QuoteMeta(folderQuery)).
+				WithArgs(groupID, parentID, name).
+				WillReturnRows(rows)
+		}
+
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1 FOR UPDATE`)).
+				WithArgs(groupName).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(groupID, groupName, true))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+				WithArgs(userID, groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "group_id", "role"}).AddRow(1, userID, groupID, models.RoleEditor))
+		})
+
+		When("the path is invalid", func() {
+			BeforeEach(func() {
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				_, err := fmDao.CreateFolder(userID, groupName, "reports/..")
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the folder already exists", func() {
+			BeforeEach(func() {
+				expectFolderLookup(0, "reports", sqlmock.NewRows([]string{"id", "name"}).AddRow(folderID, "reports"))
+				expectFolderLookup(folderID, "2026", sqlmock.NewRows([]string{"id", "name"}).AddRow(folderID+1, "2026"))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				_, err := fmDao.CreateFolder(userID, groupName, "reports/2026")
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("Folder [reports/2026] already exists"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("only the parent folder exists", func() {
+			BeforeEach(func() {
+				expectFolderLookup(0, "reports", sqlmock.NewRows([]string{"id", "name"}).AddRow(folderID, "reports"))
+				expectFolderLookup(folderID, "2026", sqlmock.NewRows([]string{"id", "name"}))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "folders" ("created_at","updated_at","name","group_id","parent_id","owner_id") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "2026", groupID, folderID, userID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(folderID + 1))
+				mock.ExpectCommit()
+			})
+
+			It("creates the missing folder inside the parent", func() {
+				id, err := fmDao.CreateFolder(userID, groupName, "reports/2026")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal(uint(folderID + 1)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("AttachFileBlob", func() {
+		const (
+			fileLookupQuery = `SELECT * FROM "file_infos" WHERE id = $1 AND "file_infos"."deleted_at" IS NULL LIMIT 1`
+			blobLookupQuery = `SELECT * FROM "blobs" WHERE checksum = $1 AND "blobs"."checksum" = $2 LIMIT 1 FOR UPDATE`
+		)
+
+		var (
+			quota         Quota
+			storedNewBlob []bool
+			storeErr      error
+		)
+
+		storeContent := func(isNewBlob bool) error {
+			storedNewBlob = append(storedNewBlob, isNewBlob)
+			return storeErr
+		}
+
+		expectBlobLookup := func(refCount int64) {
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "blobs" ("checksum","created_at","updated_at","size","ref_count") VALUES ($1,$2,$3,$4,$5) ON CONFLICT DO NOTHING`)).
+				WithArgs(checksum, sqlmock.AnyArg(), sqlmock.AnyArg(), size, 0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(regexp.QuoteMeta(blobLookupQuery)).
+				WithArgs(checksum, checksum).
+				WillReturnRows(sqlmock.NewRows([]string{"checksum", "size", "ref_count"}).AddRow(checksum, size, refCount))
+		}
+
+		expectReferenceUpdates := func() {
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "blobs" SET "ref_count"=GREATEST(ref_count, 0) + 1,"updated_at"=$1 WHERE checksum = $2`)).
+				WithArgs(sqlmock.AnyArg(), checksum).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "file_infos" SET "checksum"=$1,"deduplicated"=$2,"size"=$3 WHERE id = $4`)).
+				WithArgs(checksum, true, size, fileID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+
+		BeforeEach(func() {
+			quota = Quota{}
+			storedNewBlob = nil
+			storeErr = nil
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(fileLookupQuery)).
+				WithArgs(fileID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "group_id"}).AddRow(fileID, userID, groupID))
+		})
+
+		When("the blob is new", func() {
+			BeforeEach(func() {
+				expectBlobLookup(0)
+				expectReferenceUpdates()
+				mock.ExpectCommit()
+			})
+
+			It("stores the content and references the blob", func() {
+				Expect(fmDao.AttachFileBlob(fileID, checksum, size, quota, 0, storeContent)).To(Succeed())
+				Expect(storedNewBlob).To(Equal([]bool{true}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("a blob with the same checksum already exists", func() {
+			BeforeEach(func() {
+				expectBlobLookup(2)
+				expectReferenceUpdates()
+				mock.ExpectCommit()
+			})
+
+			It("discards the content and references the existing blob", func() {
+				Expect(fmDao.AttachFileBlob(fileID, checksum, size, quota, 0, storeContent)).To(Succeed())
+				Expect(storedNewBlob).To(Equal([]bool{false}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the content cannot be stored", func() {
+			BeforeEach(func() {
+				storeErr = myerr.NewServerError("Couldnt save the file")
+				expectBlobLookup(0)
+				mock.ExpectRollback()
+			})
+
+			It("returns the error without referencing the blob", func() {
+				err := fmDao.AttachFileBlob(fileID, checksum, size, quota, 0, storeContent)
+				Expect(err).To(Equal(storeErr))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the file exceeds the quota of the group", func() {
+			BeforeEach(func() {
+				quota = Quota{GroupBytes: 150}
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE "groups"."id" = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(groupID))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(userID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+				expectGroupUsage(100, 0, 0)
+				mock.ExpectRollback()
+			})
+
+			It("returns quota exceeded error without storing the content", func() {
+				err := fmDao.AttachFileBlob(fileID, checksum, size, quota, 0, storeContent)
+				_, ok := err.(*myerr.QuotaExceededError)
+				Expect(ok).To(BeTrue())
+				Expect(storedNewBlob).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the content comes from an upload session", func() {
+			const sessionID = 5
+
+			BeforeEach(func() {
+				quota = Quota{GroupBytes: 150}
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE "groups"."id" = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(groupID))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(userID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+				expectGroupUsage(0, 0, sessionID)
+				expectBlobLookup(0)
+				expectReferenceUpdates()
+				mock.ExpectCommit()
+			})
+
+			It("doesnt count the bytes, reserved by the session, twice", func() {
+				Expect(fmDao.AttachFileBlob(fileID, checksum, size, quota, sessionID, storeContent)).To(Succeed())
+				Expect(storedNewBlob).To(Equal([]bool{true}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("CheckQuota", func() {
+		var quota Quota
+
+		expectMembership := func(role string) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships" WHERE user_id = $1 AND group_id = $2 LIMIT 1`)).
+				WithArgs(userID, groupID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "group_id", "role"}).AddRow(1, userID, groupID, role))
+		}
+
+		BeforeEach(func() {
+			quota = Quota{GroupBytes: 150}
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE name = $1`)).
+				WithArgs(groupName).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "active"}).AddRow(groupID, groupName, true))
+		})
+
+		When("the role of the user doesnt allow uploading files", func() {
+			BeforeEach(func() {
+				expectMembership(models.RoleViewer)
+			})
+
+			It("returns client error without checking the usage", func() {
+				err := fmDao.CheckQuota(userID, groupName, size, quota)
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the file exceeds the quota of the group", func() {
+			BeforeEach(func() {
+				expectMembership(models.RoleEditor)
+				expectGroupUsage(100, 0, 0)
+			})
+
+			It("returns quota exceeded error", func() {
+				err := fmDao.CheckQuota(userID, groupName, size, quota)
+				_, ok := err.(*myerr.QuotaExceededError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("RemoveExcessFileVersions", func() {
+		const (
+			maxVersions = 3
+			sharedQuery = `AND NOT EXISTS (SELECT 1 FROM share_links WHERE share_links.file_id = ranked.id`
+		)
+
+		BeforeEach(func() {
+			mock.ExpectBegin()
+		})
+
+		When("the excess versions are referenced by share links or drop boxes", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(sharedQuery)).
+					WithArgs(maxVersions, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "group_id", "version", "group_name"}))
+				mock.ExpectCommit()
+			})
+
+			It("keeps the versions", func() {
+				versions, err := fmDao.RemoveExcessFileVersions(maxVersions)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(versions).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("there are unreferenced excess versions", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(sharedQuery)).
+					WithArgs(maxVersions, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "group_id", "version", "checksum", "deduplicated", "group_name"}).
+						AddRow(fileID, groupID, 1, checksum, true, groupName))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "file_infos" WHERE "file_infos"."id" = $1`)).
+					WithArgs(fileID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "blobs" SET "ref_count"=ref_count - 1,"updated_at"=$1 WHERE checksum = $2`)).
+					WithArgs(sqlmock.AnyArg(), checksum).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("removes the versions and releases their blobs", func() {
+				versions, err := fmDao.RemoveExcessFileVersions(maxVersions)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(versions).To(HaveLen(1))
+				Expect(versions[0].ID).To(Equal(uint(fileID)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("releaseBlobWithConn", func() {
+		const updateQuery = `UPDATE "blobs" SET "ref_count"=ref_count - 1,"updated_at"=$1 WHERE checksum = $2`
+
+		When("the update fails", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(sqlmock.AnyArg(), checksum).
+					WillReturnError(errors.New("connection lost"))
+				mock.ExpectRollback()
+			})
+
+			It("returns server error", func() {
+				err := releaseBlobWithConn(gdb, checksum)
+				_, ok := err.(*myerr.ServerError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the blob is released", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(sqlmock.AnyArg(), checksum).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("decrements the references of the blob", func() {
+				Expect(releaseBlobWithConn(gdb, checksum)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+})
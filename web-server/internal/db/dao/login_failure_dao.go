@@ -0,0 +1,214 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen --source=login_failure_dao.go --destination dao_mocks/login_failure_dao.go --package dao_mocks
+
+//LockoutPolicy - limits of the failed logins with a username and from a client IP. 0 means that the failed logins arent limited
+type LockoutPolicy struct {
+	MaxUserFailures uint
+	MaxIPFailures   uint
+	//BaseDelay - the wait after the first failed login, which is doubled after every next one, but not above MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	//LockoutDuration - the wait after too many failed logins. The failures are forgotten if there are no new ones for that long
+	LockoutDuration time.Duration
+}
+
+//IsLimited - whether any of the limits is set
+func (p LockoutPolicy) IsLimited() bool {
+	return p.MaxUserFailures > 0 || p.MaxIPFailures > 0
+}
+
+//delay - the wait after the given number of failed logins and whether it is a lockout
+func (p LockoutPolicy) delay(failures, maxFailures uint) (time.Duration, bool) {
+	if failures >= maxFailures {
+		return p.LockoutDuration, true
+	}
+
+	delay := p.BaseDelay
+	for n := uint(1); n < failures && delay < p.MaxDelay; n++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay, false
+}
+
+//LoginFailureDAO - interface for working with the Database in regards to the failed logins
+type LoginFailureDAO interface {
+	Migrate() error
+	ReserveLoginAttempt(username, ip string, policy LockoutPolicy) (LoginAttempt, error)
+	ReleaseLoginAttempt(attempt LoginAttempt) error
+	ResetLoginFailures(username string) error
+	DeleteExpiredLoginFailures(expiredBefore time.Time) (int64, error)
+}
+
+//LoginAttempt - login attempt, which is counted as failed until its credentials are confirmed
+type LoginAttempt struct {
+	//BlockedUntil - if set, the logins were already blocked and the attempt wasnt counted
+	BlockedUntil time.Time
+	//Reserved - the failed logins with the username and from the client IP, including the attempt
+	Reserved []models.LoginFailure
+	//Lockouts - the failed logins, which have reached the lockout with the attempt
+	Lockouts []models.LoginFailure
+}
+
+//LoginFailureDAOImpl - implementation of LoginFailureDAO
+type LoginFailureDAOImpl struct {
+	dbConn *gorm.DB
+}
+
+//NewLoginFailureDAOImpl - function for creation an instance of LoginFailureDAOImpl
+func NewLoginFailureDAOImpl(dbConn *gorm.DB) *LoginFailureDAOImpl {
+	return &LoginFailureDAOImpl{dbConn: dbConn}
+}
+
+//Migrate - function which updates the models(table structure) in db
+func (i *LoginFailureDAOImpl) Migrate() error {
+	return i.dbConn.AutoMigrate(models.LoginFailure{})
+}
+
+//ReserveLoginAttempt - counts the login attempt with the username and from the client IP as failed, before the credentials are checked, and blocks the next logins according to the policy
+//the failed logins are locked during the check of the block and the counting, so parallel attempts cannot pass the check before the failures of each other are counted
+//if the logins are already blocked, the attempt isnt counted and only the end of the block is returned
+//the failed logins with usernames, which dont belong to any user, arent stored, so arbitrary usernames cannot bloat the table. They are still counted for the client IP
+func (i *LoginFailureDAOImpl) ReserveLoginAttempt(username, ip string, policy LockoutPolicy) (LoginAttempt, error) {
+	subjects := []struct {
+		kind        string
+		identifier  string
+		maxFailures uint
+	}{
+		{models.LoginFailureUsername, username, policy.MaxUserFailures},
+		{models.LoginFailureIP, ip, policy.MaxIPFailures},
+	}
+
+	var attempt LoginAttempt
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		failures := make([]models.LoginFailure, 0, len(subjects))
+		for _, subject := range subjects {
+			if subject.maxFailures == 0 {
+				continue
+			}
+
+			if subject.kind == models.LoginFailureUsername {
+				var users int64
+				result := tx.Model(&models.User{}).Where("username = ?", subject.identifier).Count(&users)
+				if result.Error != nil {
+					return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the user")
+				} else if users == 0 {
+					continue
+				}
+			}
+
+			//the row is created in advance, so there is always a row to lock, even for the first failed login
+			failure := models.LoginFailure{Kind: subject.kind, Identifier: subject.identifier, ExpiresAt: now}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&failure)
+			if result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with storing the failed login")
+			}
+
+			failure = models.LoginFailure{}
+			result = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("kind = ? AND identifier = ?", subject.kind, subject.identifier).
+				Take(&failure)
+
+			if result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the failed logins")
+			}
+
+			if failure.BlockedUntil.After(now) && failure.BlockedUntil.After(attempt.BlockedUntil) {
+				attempt.BlockedUntil = failure.BlockedUntil
+			}
+			failures = append(failures, failure)
+		}
+
+		if !attempt.BlockedUntil.IsZero() {
+			return nil
+		}
+
+		for idx, failure := range failures {
+			maxFailures := policy.MaxUserFailures
+			if failure.Kind == models.LoginFailureIP {
+				maxFailures = policy.MaxIPFailures
+			}
+
+			if failure.ExpiresAt.Before(now) {
+				failure.Failures = 0
+			}
+
+			failure.Failures++
+			delay, locked := policy.delay(failure.Failures, maxFailures)
+			failure.BlockedUntil = now.Add(delay)
+			failure.ExpiresAt = now.Add(policy.LockoutDuration)
+
+			result := tx.Model(&failures[idx]).
+				Updates(map[string]interface{}{"failures": failure.Failures, "blocked_until": failure.BlockedUntil, "expires_at": failure.ExpiresAt})
+
+			if result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with storing the failed login")
+			}
+
+			attempt.Reserved = append(attempt.Reserved, failure)
+			if locked {
+				attempt.Lockouts = append(attempt.Lockouts, failure)
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return LoginAttempt{}, err
+	}
+	return attempt, nil
+}
+
+//ReleaseLoginAttempt - stops counting the attempt as failed, after its credentials were confirmed, and lifts the block, caused by it
+//the failed logins, which were counted after the attempt, are kept together with their block
+func (i *LoginFailureDAOImpl) ReleaseLoginAttempt(attempt LoginAttempt) error {
+	now := time.Now()
+	for _, failure := range attempt.Reserved {
+		result := i.dbConn.Model(&models.LoginFailure{}).
+			Where("id = ? AND failures = ?", failure.ID, failure.Failures).
+			Updates(map[string]interface{}{"failures": failure.Failures - 1, "blocked_until": now})
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with releasing the login attempt")
+		}
+	}
+	return nil
+}
+
+//ResetLoginFailures - forgets the failed logins with the username, after the user has logged in successfully
+//the failed logins from the client IP are kept, so a valid account cannot be used for guessing the passwords of others
+func (i *LoginFailureDAOImpl) ResetLoginFailures(username string) error {
+	result := i.dbConn.
+		Where("kind = ? AND identifier = ?", models.LoginFailureUsername, username).
+		Delete(&models.LoginFailure{})
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the failed logins")
+	}
+	return nil
+}
+
+//DeleteExpiredLoginFailures - deletes the failed logins, which were forgotten before the given moment
+func (i *LoginFailureDAOImpl) DeleteExpiredLoginFailures(expiredBefore time.Time) (int64, error) {
+	result := i.dbConn.
+		Where("expires_at < ?", expiredBefore).
+		Delete(&models.LoginFailure{})
+
+	if result.Error != nil {
+		return 0, myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the expired failed logins")
+	}
+	return result.RowsAffected, nil
+}
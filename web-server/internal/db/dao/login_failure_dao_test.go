@@ -0,0 +1,195 @@
+package dao
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("LoginFailureDAO", func() {
+	var (
+		loginFailureDao LoginFailureDAO
+		mock            sqlmock.Sqlmock
+		policy          LockoutPolicy
+	)
+
+	const (
+		username = "username"
+		ip       = "127.0.0.1"
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		loginFailureDao = NewLoginFailureDAOImpl(gdb)
+		policy = LockoutPolicy{
+			MaxUserFailures: 3,
+			MaxIPFailures:   10,
+			BaseDelay:       time.Second,
+			MaxDelay:        time.Minute,
+			LockoutDuration: 15 * time.Minute,
+		}
+	})
+
+	Context("LockoutPolicy", func() {
+		It("doubles the delay after every failed login", func() {
+			policy.MaxUserFailures = 20
+			for failures, expected := range map[uint]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 7: time.Minute, 19: time.Minute} {
+				delay, locked := policy.delay(failures, policy.MaxUserFailures)
+				Expect(delay).To(Equal(expected))
+				Expect(locked).To(BeFalse())
+			}
+		})
+
+		It("locks out after too many failed logins", func() {
+			delay, locked := policy.delay(3, policy.MaxUserFailures)
+			Expect(delay).To(Equal(policy.LockoutDuration))
+			Expect(locked).To(BeTrue())
+		})
+	})
+
+	Context("ReserveLoginAttempt", func() {
+		expectUser := func(count int) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users" WHERE username = $1`)).
+				WithArgs(username).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+		}
+
+		expectLockedFailure := func(kind, identifier string, rows *sqlmock.Rows) {
+			mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "login_failures"`)).
+				WithArgs(kind, identifier, 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "login_failures" WHERE kind = $1 AND identifier = $2 LIMIT 1 FOR UPDATE`)).
+				WithArgs(kind, identifier).
+				WillReturnRows(rows)
+		}
+
+		When("the logins are blocked", func() {
+			var blockedUntil time.Time
+
+			BeforeEach(func() {
+				blockedUntil = time.Now().Add(time.Minute)
+				mock.ExpectBegin()
+				expectUser(1)
+				expectLockedFailure(models.LoginFailureUsername, username, sqlmock.NewRows([]string{"id", "kind", "identifier", "failures", "blocked_until", "expires_at"}).
+					AddRow(1, models.LoginFailureUsername, username, 2, blockedUntil, time.Now().Add(time.Hour)))
+				expectLockedFailure(models.LoginFailureIP, ip, sqlmock.NewRows([]string{"id", "kind", "identifier", "failures", "blocked_until", "expires_at"}).
+					AddRow(2, models.LoginFailureIP, ip, 1, blockedUntil.Add(-time.Hour), time.Now().Add(time.Hour)))
+				mock.ExpectCommit()
+			})
+
+			It("returns the latest block without counting the attempt", func() {
+				attempt, err := loginFailureDao.ReserveLoginAttempt(username, ip, policy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(attempt.BlockedUntil).To(BeTemporally("==", blockedUntil))
+				Expect(attempt.Reserved).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the username reaches the maximum number of failed logins", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				expectUser(1)
+				expectLockedFailure(models.LoginFailureUsername, username, sqlmock.NewRows([]string{"id", "kind", "identifier", "failures", "blocked_until", "expires_at"}).
+					AddRow(1, models.LoginFailureUsername, username, 2, time.Now().Add(-time.Second), time.Now().Add(time.Minute)))
+				expectLockedFailure(models.LoginFailureIP, ip, sqlmock.NewRows([]string{"id", "kind", "identifier", "failures", "blocked_until", "expires_at"}).
+					AddRow(2, models.LoginFailureIP, ip, 0, time.Time{}, time.Now()))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "login_failures" SET "blocked_until"=$1,"expires_at"=$2,"failures"=$3 WHERE "id" = $4`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 3, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "login_failures" SET "blocked_until"=$1,"expires_at"=$2,"failures"=$3 WHERE "id" = $4`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("counts the attempt and returns the lockout of the username", func() {
+				attempt, err := loginFailureDao.ReserveLoginAttempt(username, ip, policy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(attempt.BlockedUntil.IsZero()).To(BeTrue())
+				Expect(attempt.Reserved).To(HaveLen(2))
+				Expect(attempt.Lockouts).To(HaveLen(1))
+				Expect(attempt.Lockouts[0].Kind).To(Equal(models.LoginFailureUsername))
+				Expect(attempt.Lockouts[0].BlockedUntil).To(BeTemporally("~", time.Now().Add(policy.LockoutDuration), time.Minute))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the username doesnt belong to any user", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				expectUser(0)
+				expectLockedFailure(models.LoginFailureIP, ip, sqlmock.NewRows([]string{"id", "kind", "identifier", "failures", "blocked_until", "expires_at"}).
+					AddRow(2, models.LoginFailureIP, ip, 0, time.Time{}, time.Now()))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "login_failures" SET "blocked_until"=$1,"expires_at"=$2,"failures"=$3 WHERE "id" = $4`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("counts the attempt only for the client IP", func() {
+				attempt, err := loginFailureDao.ReserveLoginAttempt(username, ip, policy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(attempt.Reserved).To(HaveLen(1))
+				Expect(attempt.Reserved[0].Kind).To(Equal(models.LoginFailureIP))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the previous failed logins are forgotten", func() {
+			BeforeEach(func() {
+				policy.MaxIPFailures = 0
+				mock.ExpectBegin()
+				expectUser(1)
+				expectLockedFailure(models.LoginFailureUsername, username, sqlmock.NewRows([]string{"id", "kind", "identifier", "failures", "blocked_until", "expires_at"}).
+					AddRow(1, models.LoginFailureUsername, username, 2, time.Now().Add(-time.Hour), time.Now().Add(-time.Minute)))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "login_failures" SET "blocked_until"=$1,"expires_at"=$2,"failures"=$3 WHERE "id" = $4`)).
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("starts counting from the beginning", func() {
+				attempt, err := loginFailureDao.ReserveLoginAttempt(username, ip, policy)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(attempt.Reserved).To(HaveLen(1))
+				Expect(attempt.Lockouts).To(BeEmpty())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("ReleaseLoginAttempt", func() {
+		It("uncounts the attempt, unless newer failed logins were counted", func() {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "login_failures" SET "blocked_until"=$1,"failures"=$2 WHERE id = $3 AND failures = $4`)).
+				WithArgs(sqlmock.AnyArg(), 2, 1, 3).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			err := loginFailureDao.ReleaseLoginAttempt(LoginAttempt{Reserved: []models.LoginFailure{{ID: 1, Failures: 3}}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+})
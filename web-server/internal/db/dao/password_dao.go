@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//ChangePassword - replaces the password of the user, after the current one is confirmed, and invalidates all tokens of the user
+func (i *TokenDAOImpl) ChangePassword(userID uint, passwordHash string, confirm func(models.User) error) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).
+			Take(&user)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewItemNotFoundError("User with that id does not exist")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if user exists")
+		}
+
+		if err := confirm(user); err != nil {
+			return err
+		}
+		return replacePasswordWithConn(tx, userID, passwordHash)
+	})
+}
+
+//CreatePasswordResetToken - stores the hash of a one-time token, with which the user sets a new password. The previous reset tokens of the user are replaced
+func (i *TokenDAOImpl) CreatePasswordResetToken(username string, tokenHash string, expiresAt time.Time) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		result := tx.Where("username = ?", username).Take(&user)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewItemNotFoundError("User with that username does not exist")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if user exists")
+		}
+
+		if result = tx.Where("user_id = ?", user.ID).Delete(&models.PasswordResetToken{}); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the previous password reset tokens")
+		}
+
+		token := models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: tokenHash,
+			ExpiresAt: expiresAt,
+		}
+
+		if result = tx.Create(&token); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of the password reset token")
+		}
+		return nil
+	})
+}
+
+//ResetPassword - sets a new password of the user, who owns the reset token, and invalidates the reset token together with all other tokens of the user
+func (i *TokenDAOImpl) ResetPassword(tokenHash string, passwordHash string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		var token models.PasswordResetToken
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", tokenHash).
+			Take(&token)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewClientError("Invalid password reset token")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the password reset token")
+		}
+
+		if !token.ExpiresAt.After(time.Now()) {
+			return myerr.NewClientError("The password reset token has expired. Please request a new one")
+		}
+		return replacePasswordWithConn(tx, token.UserID, passwordHash)
+	})
+}
+
+//replacePasswordWithConn - stores the new password of the user and invalidates all of its tokens
+//the access tokens cannot be listed, so the ones, issued before the change, are rejected by IsAccessTokenRevoked
+func replacePasswordWithConn(tx *gorm.DB, userID uint, passwordHash string) error {
+	result := tx.Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"password": passwordHash, "password_changed_at": time.Now().Truncate(time.Second)})
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the change of the password")
+	}
+
+	if err := revokeRefreshTokensWithConn(tx.Where("user_id = ?", userID)); err != nil {
+		return err
+	}
+
+	if _, err := deletePersonalAccessTokensWithConn(tx, "user_id = ?", userID); err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{&models.LoginChallenge{}, &models.PasswordResetToken{}} {
+		if result = tx.Where("user_id = ?", userID).Delete(model); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the invalidation of the tokens")
+		}
+	}
+	return nil
+}
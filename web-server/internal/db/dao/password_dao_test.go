@@ -0,0 +1,206 @@
+package dao
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("TokenDAO passwords", func() {
+	var (
+		tokenDao TokenDAO
+		mock     sqlmock.Sqlmock
+	)
+
+	const (
+		userID       = 1
+		username     = "username"
+		passwordHash = "password-hash"
+		tokenHash    = "token-hash"
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		tokenDao = NewTokenDAOImpl(gdb)
+	})
+
+	expectPasswordReplacement := func() {
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "password"=$1,"password_changed_at"=$2,"updated_at"=$3 WHERE id = $4`)).
+			WithArgs(passwordHash, sqlmock.AnyArg(), sqlmock.AnyArg(), userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "revoked_at"=$1 WHERE user_id = $2 AND revoked_at IS NULL`)).
+			WithArgs(sqlmock.AnyArg(), userID).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "personal_access_token_groups" WHERE token_id IN (SELECT id FROM "personal_access_tokens" WHERE user_id = $1)`)).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "personal_access_tokens" WHERE user_id = $1`)).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_challenges" WHERE user_id = $1`)).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "password_reset_tokens" WHERE user_id = $1`)).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	Context("ChangePassword", func() {
+		BeforeEach(func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1 LIMIT 1 FOR UPDATE`)).
+				WithArgs(userID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password"}).AddRow(userID, username, "old-hash"))
+		})
+
+		When("the current password isnt confirmed", func() {
+			BeforeEach(func() {
+				mock.ExpectRollback()
+			})
+
+			It("returns the error of the confirmation", func() {
+				err := tokenDao.ChangePassword(userID, passwordHash, func(user models.User) error {
+					Expect(user.Password).To(Equal("old-hash"))
+					return myerr.NewClientError("Invalid password")
+				})
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the current password is confirmed", func() {
+			BeforeEach(func() {
+				expectPasswordReplacement()
+				mock.ExpectCommit()
+			})
+
+			It("replaces the password and invalidates the tokens", func() {
+				err := tokenDao.ChangePassword(userID, passwordHash, func(models.User) error { return nil })
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("CreatePasswordResetToken", func() {
+		When("the user doesnt exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE username = $1 LIMIT 1`)).
+					WithArgs(username).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			})
+
+			It("returns item not found error", func() {
+				err := tokenDao.CreatePasswordResetToken(username, tokenHash, time.Now().Add(time.Hour))
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the user exists", func() {
+			expiresAt := time.Now().Add(time.Hour)
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE username = $1 LIMIT 1`)).
+					WithArgs(username).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(userID, username))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "password_reset_tokens" WHERE user_id = $1`)).
+					WithArgs(userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "password_reset_tokens"`)).
+					WithArgs(sqlmock.AnyArg(), userID, tokenHash, expiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			})
+
+			It("replaces the previous reset tokens", func() {
+				err := tokenDao.CreatePasswordResetToken(username, tokenHash, expiresAt)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("ResetPassword", func() {
+		When("the reset token doesnt exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "password_reset_tokens" WHERE token_hash = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(tokenHash).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := tokenDao.ResetPassword(tokenHash, passwordHash)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the reset token has expired", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "password_reset_tokens" WHERE token_hash = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(tokenHash).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at"}).AddRow(1, userID, tokenHash, time.Now().Add(-time.Minute)))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := tokenDao.ResetPassword(tokenHash, passwordHash)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the reset token is valid", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "password_reset_tokens" WHERE token_hash = $1 LIMIT 1 FOR UPDATE`)).
+					WithArgs(tokenHash).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at"}).AddRow(1, userID, tokenHash, time.Now().Add(time.Hour)))
+				expectPasswordReplacement()
+				mock.ExpectCommit()
+			})
+
+			It("replaces the password and invalidates the tokens", func() {
+				err := tokenDao.ResetPassword(tokenHash, passwordHash)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+})
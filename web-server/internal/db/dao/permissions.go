@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+)
+
+//Permission - an action inside a group, which is allowed or not, depending on the role of the member
+type Permission int
+
+const (
+	//ViewFiles - listing and downloading the files of the group
+	ViewFiles Permission = iota
+	//UploadFiles - uploading files and creating folders
+	UploadFiles
+	//ManageOwnFiles - moving, deleting and restoring the files and folders, owned by the member
+	ManageOwnFiles
+	//ManageAllFiles - moving, deleting and restoring the files and folders of every member
+	ManageAllFiles
+	//ManageMembers - adding and removing members and changing their roles. Only members with lower rank can be managed
+	ManageMembers
+	//ManageGroup - deleting the group and changing its settings
+	ManageGroup
+)
+
+var permissionDescriptions = map[Permission]string{
+	ViewFiles:      "viewing files",
+	UploadFiles:    "uploading files",
+	ManageOwnFiles: "changing files",
+	ManageAllFiles: "changing files of other members",
+	ManageMembers:  "managing members",
+	ManageGroup:    "managing the group",
+}
+
+var rolePermissions = map[string][]Permission{
+	models.RoleOwner:  {ViewFiles, UploadFiles, ManageOwnFiles, ManageAllFiles, ManageMembers, ManageGroup},
+	models.RoleAdmin:  {ViewFiles, UploadFiles, ManageOwnFiles, ManageAllFiles, ManageMembers},
+	models.RoleEditor: {ViewFiles, UploadFiles, ManageOwnFiles},
+	models.RoleViewer: {ViewFiles},
+}
+
+//roleRanks - a member can manage only members with lower rank, so admins cannot manage other admins or the owner
+var roleRanks = map[string]int{
+	models.RoleViewer: 1,
+	models.RoleEditor: 2,
+	models.RoleAdmin:  3,
+	models.RoleOwner:  4,
+}
+
+//HasPermission - checks if the role allows the action
+func HasPermission(role string, permission Permission) bool {
+	for _, allowed := range rolePermissions[role] {
+		if allowed == permission {
+			return true
+		}
+	}
+	return false
+}
+
+//ValidateAssignableRole - checks if the role can be given to a member. The owner role cannot be assigned
+func ValidateAssignableRole(role string) error {
+	if role != models.RoleAdmin && role != models.RoleEditor && role != models.RoleViewer {
+		return myerr.NewClientError(fmt.Sprintf("Invalid role [%s]. Valid roles are admin, editor and viewer", role))
+	}
+	return nil
+}
+
+//ValidateScopes - checks if there is at least one scope and every scope is one of read, upload and write
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return myerr.NewClientError("At least one scope should be specified")
+	}
+
+	for _, scope := range scopes {
+		if scope != models.ScopeRead && scope != models.ScopeUpload && scope != models.ScopeWrite {
+			return myerr.NewClientError(fmt.Sprintf("Invalid scope [%s]. Valid scopes are read, upload and write", scope))
+		}
+	}
+	return nil
+}
+
+//ValidateVisibility - checks if the visibility is one of private, discoverable and open
+func ValidateVisibility(visibility string) error {
+	if visibility != models.VisibilityPrivate && visibility != models.VisibilityDiscoverable && visibility != models.VisibilityOpen {
+		return myerr.NewClientError(fmt.Sprintf("Invalid visibility [%s]. Valid visibilities are private, discoverable and open", visibility))
+	}
+	return nil
+}
+
+//canManageMember - checks if the member can manage members with the given role or give this role to other members
+func canManageMember(membership models.Membership, role string) bool {
+	return HasPermission(membership.Role, ManageMembers) && roleRanks[membership.Role] > roleRanks[role]
+}
+
+//getMembershipWithConn - fetches the membership of the user in the group
+func getMembershipWithConn(dbConn *gorm.DB, userID uint, groupID uint) (models.Membership, error) {
+	var membership models.Membership
+
+	result := dbConn.Table("memberships").
+		Where("user_id = ?", userID).
+		Where("group_id = ?", groupID).
+		Take(&membership)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return membership, myerr.NewItemNotFoundError("Membership not found")
+	} else if result.Error != nil {
+		return membership, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of membership in db")
+	}
+	return membership, nil
+}
+
+//checkPermissionWithConn - checks if the role of the user in the group allows the action and returns its membership
+func checkPermissionWithConn(dbConn *gorm.DB, userID uint, groupID uint, permission Permission) (models.Membership, error) {
+	membership, err := getMembershipWithConn(dbConn, userID, groupID)
+	if _, ok := err.(*myerr.ItemNotFoundError); ok {
+		return membership, myerr.NewClientError("You arent a member of the group.")
+	} else if err != nil {
+		return membership, err
+	}
+
+	if !HasPermission(membership.Role, permission) {
+		return membership, myerr.NewClientError(fmt.Sprintf("Your role [%s] in the group doesnt allow %s", membership.Role, permissionDescriptions[permission]))
+	}
+	return membership, nil
+}
+
+//checkEntryPermissionWithConn - checks if the user can change a file or a folder of the group, owned by the given user
+func checkEntryPermissionWithConn(dbConn *gorm.DB, userID uint, groupID uint, ownerID uint) error {
+	permission := ManageOwnFiles
+	if ownerID != userID {
+		permission = ManageAllFiles
+	}
+
+	_, err := checkPermissionWithConn(dbConn, userID, groupID, permission)
+	return err
+}
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Permissions", func() {
+	DescribeTable("HasPermission",
+		func(role string, permission Permission, expected bool) {
+			Expect(HasPermission(role, permission)).To(Equal(expected))
+		},
+		Entry("viewers can view files", models.RoleViewer, ViewFiles, true),
+		Entry("viewers cannot upload files", models.RoleViewer, UploadFiles, false),
+		Entry("editors can upload files", models.RoleEditor, UploadFiles, true),
+		Entry("editors can change their own files", models.RoleEditor, ManageOwnFiles, true),
+		Entry("editors cannot change files of others", models.RoleEditor, ManageAllFiles, false),
+		Entry("admins can change files of others", models.RoleAdmin, ManageAllFiles, true),
+		Entry("admins can manage members", models.RoleAdmin, ManageMembers, true),
+		Entry("admins cannot manage the group", models.RoleAdmin, ManageGroup, false),
+		Entry("owners can manage the group", models.RoleOwner, ManageGroup, true),
+		Entry("unknown roles cannot do anything", "guest", ViewFiles, false),
+	)
+
+	DescribeTable("canManageMember",
+		func(role string, targetRole string, expected bool) {
+			Expect(canManageMember(models.Membership{Role: role}, targetRole)).To(Equal(expected))
+		},
+		Entry("owners can manage admins", models.RoleOwner, models.RoleAdmin, true),
+		Entry("admins can manage editors", models.RoleAdmin, models.RoleEditor, true),
+		Entry("admins cannot manage other admins", models.RoleAdmin, models.RoleAdmin, false),
+		Entry("admins cannot manage the owner", models.RoleAdmin, models.RoleOwner, false),
+		Entry("editors cannot manage viewers", models.RoleEditor, models.RoleViewer, false),
+	)
+
+	Context("ValidateAssignableRole", func() {
+		It("accepts admin, editor and viewer", func() {
+			Expect(ValidateAssignableRole(models.RoleAdmin)).To(Succeed())
+			Expect(ValidateAssignableRole(models.RoleEditor)).To(Succeed())
+			Expect(ValidateAssignableRole(models.RoleViewer)).To(Succeed())
+		})
+
+		It("rejects the owner role", func() {
+			err := ValidateAssignableRole(models.RoleOwner)
+			_, ok := err.(*myerr.ClientError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,203 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+)
+
+//ShareLinkInfo - share link together with the name and the version of the shared file and the username of the creator of the link
+type ShareLinkInfo struct {
+	models.ShareLink
+	FileName string
+	Version  uint
+	Creator  string
+}
+
+//CreateShareLink - creates a link, through which a file of the group can be downloaded without an account
+//only the hashes of the token and of the optional password are stored
+func (i *FmDAOImpl) CreateShareLink(userID uint, fileID uint, groupName string, tokenHash string, passwordHash string, expiresAt *time.Time, maxDownloads uint) (uint, error) {
+	var linkID uint
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		fileInfo, err := getFileInfoWithConn(tx, fileID)
+		if err != nil {
+			return err
+		} else if fileInfo.GroupID != group.ID {
+			return myerr.NewItemNotFoundError("File does not exist")
+		}
+
+		if err = checkEntryPermissionWithConn(tx, userID, group.ID, fileInfo.OwnerID); err != nil {
+			return err
+		}
+
+		link := models.ShareLink{
+			TokenHash:    tokenHash,
+			FileID:       fileInfo.ID,
+			GroupID:      group.ID,
+			UserID:       userID,
+			PasswordHash: passwordHash,
+			ExpiresAt:    expiresAt,
+			MaxDownloads: maxDownloads,
+		}
+
+		if result := tx.Create(&link); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, fmt.Sprintf("Cannot save share link in the db for group [%s]", groupName))
+		}
+		linkID = link.ID
+		return nil
+	})
+	return linkID, err
+}
+
+//GetShareLinks - returns the share links of the files in the group, which arent in the trash
+func (i *FmDAOImpl) GetShareLinks(userID uint, groupName string) ([]ShareLinkInfo, error) {
+	if err := checkMembershipWithConn(i.dbConn, userID, groupName); err != nil {
+		return nil, err
+	}
+
+	var links []ShareLinkInfo
+	result := i.dbConn.Table("share_links").
+		Select("share_links.*, file_infos.name AS file_name, file_infos.version, users.username AS creator").
+		Joins("inner join file_infos on share_links.file_id = file_infos.id").
+		Joins("inner join groups on share_links.group_id = groups.id").
+		Joins("left join users on share_links.user_id = users.id").
+		Where("groups.name = ?", groupName).
+		Where("file_infos.deleted_at IS NULL").
+		Order("share_links.id").
+		Find(&links)
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the share links of the group")
+	}
+	return links, nil
+}
+
+//RemoveShareLink - revokes a share link of the group. Members can revoke the links they created, unless their role allows changing all files
+func (i *FmDAOImpl) RemoveShareLink(userID uint, linkID uint, groupName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		}
+
+		var link models.ShareLink
+		result := tx.Where("id = ?", linkID).
+			Where("group_id = ?", group.ID).
+			Take(&link)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewItemNotFoundError(fmt.Sprintf("Share link with id [%d] doesnt exist", linkID))
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the share link")
+		}
+
+		if err = checkEntryPermissionWithConn(tx, userID, group.ID, link.UserID); err != nil {
+			return err
+		}
+
+		if result = tx.Delete(&link); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the share link")
+		}
+		return nil
+	})
+}
+
+//GetSharedFile - returns the share link with the given token hash together with the shared file and the name of its group
+//the link is considered missing, if it has expired, if its downloads are exhausted or if the file was moved to the trash
+func (i *FmDAOImpl) GetSharedFile(tokenHash string) (models.ShareLink, GroupFileInfo, error) {
+	var link models.ShareLink
+	result := i.dbConn.Where("token_hash = ?", tokenHash).Take(&link)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.ShareLink{}, GroupFileInfo{}, myerr.NewItemNotFoundError("Share link does not exist or has expired")
+	} else if result.Error != nil {
+		return models.ShareLink{}, GroupFileInfo{}, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the share link")
+	} else if !isShareLinkUsable(link, time.Now()) {
+		return models.ShareLink{}, GroupFileInfo{}, myerr.NewItemNotFoundError("Share link does not exist or has expired")
+	}
+
+	var fileInfo GroupFileInfo
+	result = i.dbConn.Raw(`SELECT file_infos.*, groups.name AS group_name FROM file_infos
+		INNER JOIN groups ON file_infos.group_id = groups.id
+		WHERE file_infos.id = ? AND file_infos.deleted_at IS NULL AND groups.active`, link.FileID).
+		Scan(&fileInfo)
+	if result.Error != nil {
+		return models.ShareLink{}, GroupFileInfo{}, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the shared file")
+	} else if fileInfo.ID == 0 {
+		return models.ShareLink{}, GroupFileInfo{}, myerr.NewItemNotFoundError("Share link does not exist or has expired")
+	}
+	return link, fileInfo, nil
+}
+
+//UseShareLink - counts a download through the share link. The download is rejected, if meanwhile the link expired or its downloads were exhausted
+func (i *FmDAOImpl) UseShareLink(linkID uint) error {
+	result := i.dbConn.Model(&models.ShareLink{}).
+		Where("id = ?", linkID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Where("max_downloads = 0 OR downloads < max_downloads").
+		Update("downloads", gorm.Expr("downloads + 1"))
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with counting the download through the share link")
+	} else if result.RowsAffected == 0 {
+		return myerr.NewItemNotFoundError("Share link does not exist or has expired")
+	}
+	return nil
+}
+
+//ReserveSharePasswordAttempt - counts an attempt to send the password of the share link, before the password is checked
+//the attempt is rejected, if the link was already sent maxAttempts wrong passwords
+func (i *FmDAOImpl) ReserveSharePasswordAttempt(linkID uint, maxAttempts uint) error {
+	result := i.dbConn.Model(&models.ShareLink{}).
+		Where("id = ? AND password_failures < ?", linkID, maxAttempts).
+		Update("password_failures", gorm.Expr("password_failures + 1"))
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with counting the password attempt for the share link")
+	} else if result.RowsAffected == 0 {
+		return myerr.NewTooManyRequestsError("The share link is locked after too many wrong passwords", 0)
+	}
+	return nil
+}
+
+//ReleaseSharePasswordAttempt - undoes the attempt, counted by ReserveSharePasswordAttempt, after the password turned out to be correct
+func (i *FmDAOImpl) ReleaseSharePasswordAttempt(linkID uint) error {
+	result := i.dbConn.Model(&models.ShareLink{}).
+		Where("id = ? AND password_failures > 0", linkID).
+		Update("password_failures", gorm.Expr("password_failures - 1"))
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with counting the password attempt for the share link")
+	}
+	return nil
+}
+
+//DeleteStaleShareLinks - deletes the share links, which expired before the given moment, whose downloads are exhausted or whose files were permanently deleted
+func (i *FmDAOImpl) DeleteStaleShareLinks(expiredBefore time.Time) (int64, error) {
+	result := i.dbConn.
+		Where("expires_at < ? OR (max_downloads > 0 AND downloads >= max_downloads) OR NOT EXISTS (?)", expiredBefore,
+			i.dbConn.Table("file_infos").Select("1").Where("file_infos.id = share_links.file_id")).
+		Delete(&models.ShareLink{})
+
+	if result.Error != nil {
+		return 0, myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the stale share links")
+	}
+	return result.RowsAffected, nil
+}
+
+func isShareLinkUsable(link models.ShareLink, now time.Time) bool {
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(now) {
+		return false
+	}
+	return link.MaxDownloads == 0 || link.Downloads < link.MaxDownloads
+}
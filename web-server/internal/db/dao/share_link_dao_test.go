@@ -0,0 +1,223 @@
+package dao
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("FmDAO share links", func() {
+	var (
+		fmDao FmDAO
+		mock  sqlmock.Sqlmock
+	)
+
+	const (
+		linkID    = 3
+		fileID    = 7
+		tokenHash = "token-hash"
+		groupName = "group"
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		fmDao = NewFmDAOImpl(gdb)
+	})
+
+	Context("GetSharedFile", func() {
+		expectLinkLookup := func(expiresAt *time.Time, maxDownloads, downloads uint) {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "share_links" WHERE token_hash = $1 LIMIT 1`)).
+				WithArgs(tokenHash).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "file_id", "expires_at", "max_downloads", "downloads"}).
+					AddRow(linkID, fileID, expiresAt, maxDownloads, downloads))
+		}
+
+		When("the link doesnt exist", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "share_links" WHERE token_hash = $1 LIMIT 1`)).
+					WithArgs(tokenHash).
+					WillReturnError(gorm.ErrRecordNotFound)
+			})
+
+			It("returns not found error", func() {
+				_, _, err := fmDao.GetSharedFile(tokenHash)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the link has expired", func() {
+			BeforeEach(func() {
+				expiredAt := time.Now().Add(-time.Hour)
+				expectLinkLookup(&expiredAt, 0, 0)
+			})
+
+			It("returns not found error", func() {
+				_, _, err := fmDao.GetSharedFile(tokenHash)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the downloads of the link are exhausted", func() {
+			BeforeEach(func() {
+				expectLinkLookup(nil, 2, 2)
+			})
+
+			It("returns not found error", func() {
+				_, _, err := fmDao.GetSharedFile(tokenHash)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the file is in the trash", func() {
+			BeforeEach(func() {
+				expectLinkLookup(nil, 2, 1)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT file_infos.*, groups.name AS group_name FROM file_infos`)).
+					WithArgs(fileID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "group_name"}))
+			})
+
+			It("returns not found error", func() {
+				_, _, err := fmDao.GetSharedFile(tokenHash)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the link is valid", func() {
+			BeforeEach(func() {
+				expectLinkLookup(nil, 0, 5)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT file_infos.*, groups.name AS group_name FROM file_infos`)).
+					WithArgs(fileID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "group_name"}).AddRow(fileID, "report.pdf", groupName))
+			})
+
+			It("returns the link and the file", func() {
+				link, fileInfo, err := fmDao.GetSharedFile(tokenHash)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(link.ID).To(Equal(uint(linkID)))
+				Expect(fileInfo.ID).To(Equal(uint(fileID)))
+				Expect(fileInfo.GroupName).To(Equal(groupName))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("UseShareLink", func() {
+		const updateQuery = `UPDATE "share_links" SET "downloads"=downloads + 1 WHERE id = $1 AND (expires_at IS NULL OR expires_at > $2) AND (max_downloads = 0 OR downloads < max_downloads)`
+
+		When("the link is no longer valid", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(linkID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			})
+
+			It("returns not found error", func() {
+				err := fmDao.UseShareLink(linkID)
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the download is counted", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(linkID, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("returns no error", func() {
+				Expect(fmDao.UseShareLink(linkID)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("ReserveSharePasswordAttempt", func() {
+		const updateQuery = `UPDATE "share_links" SET "password_failures"=password_failures + 1 WHERE id = $1 AND password_failures < $2`
+
+		When("the link was sent too many wrong passwords", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(linkID, 10).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			})
+
+			It("returns too many requests error", func() {
+				err := fmDao.ReserveSharePasswordAttempt(linkID, 10)
+				_, ok := err.(*myerr.TooManyRequestsError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the attempt is counted", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).
+					WithArgs(linkID, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("returns no error", func() {
+				Expect(fmDao.ReserveSharePasswordAttempt(linkID, 10)).To(Succeed())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("DeleteStaleShareLinks", func() {
+		var expiredBefore time.Time
+
+		BeforeEach(func() {
+			expiredBefore = time.Now()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "share_links" WHERE expires_at < $1 OR (max_downloads > 0 AND downloads >= max_downloads) OR NOT EXISTS (SELECT 1 FROM "file_infos" WHERE file_infos.id = share_links.file_id)`)).
+				WithArgs(expiredBefore).
+				WillReturnResult(sqlmock.NewResult(0, 4))
+			mock.ExpectCommit()
+		})
+
+		It("returns the number of deleted links", func() {
+			count, err := fmDao.DeleteStaleShareLinks(expiredBefore)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(4)))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+})
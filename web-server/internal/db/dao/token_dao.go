@@ -0,0 +1,403 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen --source=token_dao.go --destination dao_mocks/token_dao.go --package dao_mocks
+
+//TokenDAO - interface for working with the Database in regards to the refresh tokens, the revoked access tokens and the personal access tokens
+type TokenDAO interface {
+	Migrate() error
+	CreateRefreshToken(userID uint, tokenHash string, expiresAt time.Time) error
+	RotateRefreshToken(tokenHash string, newTokenHash string, expiresAt time.Time) (uint, error)
+	RevokeRefreshToken(userID uint, tokenHash string) error
+	RevokeAccessToken(tokenID string, expiresAt time.Time) error
+	IsAccessTokenRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error)
+	DeleteExpiredTokens(expiredBefore time.Time) (int64, error)
+	CreatePersonalAccessToken(userID uint, name string, tokenHash string, scopes []string, groupNames []string, expiresAt time.Time) (uint, error)
+	GetPersonalAccessTokens(userID uint) ([]PersonalAccessTokenInfo, error)
+	UsePersonalAccessToken(tokenHash string) (PersonalAccessTokenInfo, error)
+	DeletePersonalAccessToken(userID uint, tokenID uint) error
+	ChangePassword(userID uint, passwordHash string, confirm func(models.User) error) error
+	CreatePasswordResetToken(username string, tokenHash string, expiresAt time.Time) error
+	ResetPassword(tokenHash string, passwordHash string) error
+}
+
+//PersonalAccessTokenInfo - personal access token together with its scopes and the names of the groups, to which it is limited
+type PersonalAccessTokenInfo struct {
+	models.PersonalAccessToken
+	ScopeList []string
+	Groups    []string
+}
+
+//TokenDAOImpl - implementation of TokenDAO
+type TokenDAOImpl struct {
+	dbConn *gorm.DB
+}
+
+//NewTokenDAOImpl - function for creation an instance of TokenDAOImpl
+func NewTokenDAOImpl(dbConn *gorm.DB) *TokenDAOImpl {
+	return &TokenDAOImpl{dbConn: dbConn}
+}
+
+//Migrate - function which updates the models(table structure) in db
+func (i *TokenDAOImpl) Migrate() error {
+	return i.dbConn.AutoMigrate(models.RefreshToken{}, models.RevokedToken{}, models.PersonalAccessToken{}, models.PersonalAccessTokenGroup{}, models.PasswordResetToken{})
+}
+
+//CreateRefreshToken - stores the hash of a refresh token, issued to the user
+func (i *TokenDAOImpl) CreateRefreshToken(userID uint, tokenHash string, expiresAt time.Time) error {
+	token := models.RefreshToken{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}
+
+	if result := i.dbConn.Create(&token); result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of refresh token")
+	}
+	return nil
+}
+
+//RotateRefreshToken - revokes the refresh token and stores the new one, issued instead of it
+//using an already revoked token means that it was stolen, so all refresh tokens of the user are revoked
+//returns the id of the user, to whom the token was issued
+func (i *TokenDAOImpl) RotateRefreshToken(tokenHash string, newTokenHash string, expiresAt time.Time) (uint, error) {
+	var (
+		token  models.RefreshToken
+		reused bool
+	)
+
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", tokenHash).
+			Take(&token)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewClientError("Invalid refresh token. Please login again")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of refresh token")
+		}
+
+		if token.RevokedAt != nil {
+			log.Printf("Revoked refresh token of user with id [%d] was used. Revoking all refresh tokens of the user", token.UserID)
+			reused = true
+			return revokeRefreshTokensWithConn(tx.Where("user_id = ?", token.UserID))
+		}
+
+		if !token.ExpiresAt.After(time.Now()) {
+			return myerr.NewClientError("The refresh token has expired. Please login again")
+		}
+
+		if err := revokeRefreshTokensWithConn(tx.Where("id = ?", token.ID)); err != nil {
+			return err
+		}
+
+		newToken := models.RefreshToken{
+			UserID:    token.UserID,
+			TokenHash: newTokenHash,
+			ExpiresAt: expiresAt,
+		}
+
+		if result = tx.Create(&newToken); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of refresh token")
+		}
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	} else if reused {
+		return 0, myerr.NewClientError("The refresh token was already used. Please login again")
+	}
+	return token.UserID, nil
+}
+
+//RevokeRefreshToken - revokes a refresh token of the user. Revoking an unknown or already revoked token isnt an error
+func (i *TokenDAOImpl) RevokeRefreshToken(userID uint, tokenHash string) error {
+	return revokeRefreshTokensWithConn(i.dbConn.Where("user_id = ?", userID).Where("token_hash = ?", tokenHash))
+}
+
+//RevokeAccessToken - adds the id of an access token to the denylist, where it is kept until the token expires
+func (i *TokenDAOImpl) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	token := models.RevokedToken{
+		TokenID:   tokenID,
+		ExpiresAt: expiresAt,
+	}
+
+	result := i.dbConn.Clauses(clause.OnConflict{DoNothing: true}).Create(&token)
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the revocation of access token")
+	}
+	return nil
+}
+
+//IsAccessTokenRevoked - checks if the access token is in the denylist, was issued before the last password change or its user no longer exists
+func (i *TokenDAOImpl) IsAccessTokenRevoked(tokenID string, userID uint, issuedAt time.Time) (bool, error) {
+	var count int64
+	result := i.dbConn.Table("users").
+		Where("id = ?", userID).
+		Where("password_changed_at IS NULL OR password_changed_at <= ?", issuedAt).
+		Where("NOT EXISTS (?)", i.dbConn.Table("revoked_tokens").Select("1").Where("token_id = ?", tokenID)).
+		Count(&count)
+
+	if result.Error != nil {
+		return false, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of revoked tokens")
+	}
+	return count == 0, nil
+}
+
+//DeleteExpiredTokens - deletes the refresh tokens, the revoked access tokens, the login challenges, the password reset tokens and the personal access tokens, which expired before the given moment
+//the revoked access tokens are no longer needed in the denylist, because they dont pass the validation anyway
+func (i *TokenDAOImpl) DeleteExpiredTokens(expiredBefore time.Time) (int64, error) {
+	var count int64
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at <= ?", expiredBefore).Delete(&models.RefreshToken{})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of expired refresh tokens")
+		}
+		count += result.RowsAffected
+
+		result = tx.Where("expires_at <= ?", expiredBefore).Delete(&models.RevokedToken{})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of expired revoked tokens")
+		}
+		count += result.RowsAffected
+
+		result = tx.Where("expires_at <= ?", expiredBefore).Delete(&models.LoginChallenge{})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of expired login challenges")
+		}
+		count += result.RowsAffected
+
+		result = tx.Where("expires_at <= ?", expiredBefore).Delete(&models.PasswordResetToken{})
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of expired password reset tokens")
+		}
+		count += result.RowsAffected
+
+		deleted, err := deletePersonalAccessTokensWithConn(tx, "expires_at <= ?", expiredBefore)
+		count += deleted
+		return err
+	})
+	return count, err
+}
+
+//CreatePersonalAccessToken - stores the hash of a named personal access token of the user
+//the token is limited to the given groups, in which the user must be a member. No groups means that the token isnt limited
+//returns the id of the token
+func (i *TokenDAOImpl) CreatePersonalAccessToken(userID uint, name string, tokenHash string, scopes []string, groupNames []string, expiresAt time.Time) (uint, error) {
+	if err := ValidateScopes(scopes); err != nil {
+		return 0, err
+	}
+
+	token := models.PersonalAccessToken{
+		UserID:          userID,
+		Name:            name,
+		TokenHash:       tokenHash,
+		Scopes:          strings.Join(scopes, ","),
+		GroupRestricted: len(groupNames) > 0,
+		ExpiresAt:       expiresAt,
+	}
+
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		result := tx.Model(&models.PersonalAccessToken{}).
+			Where("user_id = ? AND name = ?", userID, name).
+			Count(&count)
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of personal access tokens")
+		} else if count > 0 {
+			return myerr.NewClientError(fmt.Sprintf("Token with name [%s] already exists", name))
+		}
+
+		var groups []models.Group
+		if token.GroupRestricted {
+			result = tx.Where("name IN ? AND active = ?", groupNames, true).
+				Where("id IN (?)", tx.Table("memberships").Select("group_id").Where("user_id = ?", userID)).
+				Find(&groups)
+
+			if result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the groups of the token")
+			} else if len(groups) != countDistinct(groupNames) {
+				return myerr.NewClientError("Some of the groups dont exist or you arent a member of them")
+			}
+		}
+
+		if result = tx.Create(&token); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of personal access token")
+		}
+
+		for _, group := range groups {
+			tokenGroup := models.PersonalAccessTokenGroup{
+				TokenID: token.ID,
+				GroupID: group.ID,
+			}
+
+			if result = tx.Create(&tokenGroup); result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of personal access token")
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	return token.ID, nil
+}
+
+//GetPersonalAccessTokens - fetches the personal access tokens of the user
+func (i *TokenDAOImpl) GetPersonalAccessTokens(userID uint) ([]PersonalAccessTokenInfo, error) {
+	var tokens []models.PersonalAccessToken
+	result := i.dbConn.Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&tokens)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of personal access tokens")
+	}
+
+	tokenIDs := make([]uint, 0, len(tokens))
+	for _, token := range tokens {
+		tokenIDs = append(tokenIDs, token.ID)
+	}
+
+	groups, err := getPersonalAccessTokenGroupsWithConn(i.dbConn, tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]PersonalAccessTokenInfo, 0, len(tokens))
+	for _, token := range tokens {
+		infos = append(infos, newPersonalAccessTokenInfo(token, groups[token.ID]))
+	}
+	return infos, nil
+}
+
+//UsePersonalAccessToken - fetches the personal access token with the given hash, which isnt expired, and records its usage
+func (i *TokenDAOImpl) UsePersonalAccessToken(tokenHash string) (PersonalAccessTokenInfo, error) {
+	var token models.PersonalAccessToken
+	result := i.dbConn.Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).
+		Take(&token)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return PersonalAccessTokenInfo{}, myerr.NewItemNotFoundError("Invalid or expired personal access token")
+	} else if result.Error != nil {
+		return PersonalAccessTokenInfo{}, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of personal access token")
+	}
+
+	var groups map[uint][]string
+	if token.GroupRestricted {
+		var err error
+		if groups, err = getPersonalAccessTokenGroupsWithConn(i.dbConn, []uint{token.ID}); err != nil {
+			return PersonalAccessTokenInfo{}, err
+		}
+	}
+
+	if result = i.dbConn.Model(&token).Update("last_used_at", time.Now()); result.Error != nil {
+		return PersonalAccessTokenInfo{}, myerr.NewServerErrorWrap(result.Error, "Problem with the update of personal access token")
+	}
+	return newPersonalAccessTokenInfo(token, groups[token.ID]), nil
+}
+
+//DeletePersonalAccessToken - deletes a personal access token of the user, after which it can no longer be used
+func (i *TokenDAOImpl) DeletePersonalAccessToken(userID uint, tokenID uint) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		deleted, err := deletePersonalAccessTokensWithConn(tx, "id = ? AND user_id = ?", tokenID, userID)
+		if err != nil {
+			return err
+		} else if deleted == 0 {
+			return myerr.NewItemNotFoundError(fmt.Sprintf("Token with id [%d] doesnt exist", tokenID))
+		}
+		return nil
+	})
+}
+
+func newPersonalAccessTokenInfo(token models.PersonalAccessToken, groups []string) PersonalAccessTokenInfo {
+	if groups == nil {
+		groups = []string{}
+	}
+
+	return PersonalAccessTokenInfo{
+		PersonalAccessToken: token,
+		ScopeList:           strings.Split(token.Scopes, ","),
+		Groups:              groups,
+	}
+}
+
+//getPersonalAccessTokenGroupsWithConn - fetches the names of the active groups, to which the tokens are limited, grouped by the id of the token
+func getPersonalAccessTokenGroupsWithConn(dbConn *gorm.DB, tokenIDs []uint) (map[uint][]string, error) {
+	groups := make(map[uint][]string)
+	if len(tokenIDs) == 0 {
+		return groups, nil
+	}
+
+	var rows []struct {
+		TokenID uint
+		Name    string
+	}
+
+	result := dbConn.Table("personal_access_token_groups").
+		Select("personal_access_token_groups.token_id, groups.name").
+		Joins("inner join groups on personal_access_token_groups.group_id = groups.id").
+		Where("personal_access_token_groups.token_id IN ? AND groups.active = ?", tokenIDs, true).
+		Order("groups.name").
+		Scan(&rows)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the groups of personal access tokens")
+	}
+
+	for _, row := range rows {
+		groups[row.TokenID] = append(groups[row.TokenID], row.Name)
+	}
+	return groups, nil
+}
+
+//deletePersonalAccessTokensWithConn - deletes the personal access tokens, matching the condition, together with their groups
+//returns the number of deleted tokens
+func deletePersonalAccessTokensWithConn(tx *gorm.DB, condition string, args ...interface{}) (int64, error) {
+	result := tx.Where("token_id IN (?)", tx.Table("personal_access_tokens").Select("id").Where(condition, args...)).
+		Delete(&models.PersonalAccessTokenGroup{})
+
+	if result.Error != nil {
+		return 0, myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the groups of personal access tokens")
+	}
+
+	result = tx.Where(condition, args...).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		return 0, myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of personal access tokens")
+	}
+	return result.RowsAffected, nil
+}
+
+func countDistinct(values []string) int {
+	distinct := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		distinct[value] = struct{}{}
+	}
+	return len(distinct)
+}
+
+func revokeRefreshTokensWithConn(dbConn *gorm.DB) error {
+	result := dbConn.Model(&models.RefreshToken{}).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the revocation of refresh tokens")
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("TokenDAO", func() {
+	var (
+		tokenDao TokenDAO
+		mock     sqlmock.Sqlmock
+	)
+
+	const (
+		userID       = 1
+		tokenHash    = "token-hash"
+		newTokenHash = "new-token-hash"
+		tokenID      = "token-id"
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		tokenDao = NewTokenDAOImpl(gdb)
+	})
+
+	refreshTokenRows := func(expiresAt time.Time, revokedAt *time.Time) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "created_at", "user_id", "token_hash", "expires_at", "revoked_at"}).
+			AddRow(3, time.Now(), userID, tokenHash, expiresAt, revokedAt)
+	}
+
+	Context("RotateRefreshToken", func() {
+		expiresAt := time.Now().Add(time.Hour)
+
+		When("the token doesnt exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "refresh_tokens"`)).
+					WithArgs(tokenHash).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			})
+
+			It("propagates error", func() {
+				_, err := tokenDao.RotateRefreshToken(tokenHash, newTokenHash, expiresAt)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the token has expired", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "refresh_tokens"`)).
+					WithArgs(tokenHash).
+					WillReturnRows(refreshTokenRows(time.Now().Add(-time.Minute), nil))
+				mock.ExpectRollback()
+			})
+
+			It("propagates error", func() {
+				_, err := tokenDao.RotateRefreshToken(tokenHash, newTokenHash, expiresAt)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the token was already used", func() {
+			BeforeEach(func() {
+				revokedAt := time.Now().Add(-time.Minute)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "refresh_tokens"`)).
+					WithArgs(tokenHash).
+					WillReturnRows(refreshTokenRows(expiresAt, &revokedAt))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "revoked_at"`)).
+					WithArgs(Any{}, userID).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			})
+
+			It("revokes all tokens of the user and returns error", func() {
+				_, err := tokenDao.RotateRefreshToken(tokenHash, newTokenHash, expiresAt)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the token is valid", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "refresh_tokens"`)).
+					WithArgs(tokenHash).
+					WillReturnRows(refreshTokenRows(expiresAt, nil))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "revoked_at"`)).
+					WithArgs(Any{}, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "refresh_tokens"`)).
+					WithArgs(Any{}, userID, newTokenHash, expiresAt, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+				mock.ExpectCommit()
+			})
+
+			It("replaces the token with the new one", func() {
+				id, err := tokenDao.RotateRefreshToken(tokenHash, newTokenHash, expiresAt)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal(uint(userID)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("IsAccessTokenRevoked", func() {
+		issuedAt := time.Unix(100, 0)
+
+		When("the lookup fails", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users"`)).
+					WithArgs(userID, issuedAt, tokenID).
+					WillReturnError(fmt.Errorf("some error"))
+			})
+
+			It("propagates error", func() {
+				_, err := tokenDao.IsAccessTokenRevoked(tokenID, userID, issuedAt)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ServerError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the token is revoked, issued before the last password change or the user was deleted", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users"`)).
+					WithArgs(userID, issuedAt, tokenID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			})
+
+			It("classifies the token as revoked", func() {
+				revoked, err := tokenDao.IsAccessTokenRevoked(tokenID, userID, issuedAt)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revoked).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the token isnt revoked", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users"`)).
+					WithArgs(userID, issuedAt, tokenID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			})
+
+			It("classifies the token as valid", func() {
+				revoked, err := tokenDao.IsAccessTokenRevoked(tokenID, userID, issuedAt)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revoked).To(BeFalse())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("RevokeAccessToken", func() {
+		It("adds the token to the denylist", func() {
+			expiresAt := time.Now().Add(time.Minute)
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "revoked_tokens"`)).
+				WithArgs(tokenID, expiresAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			err := tokenDao.RevokeAccessToken(tokenID, expiresAt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Context("DeleteExpiredTokens", func() {
+		It("deletes the expired refresh, revoked, password reset and personal access tokens and login challenges", func() {
+			now := time.Now()
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "refresh_tokens"`)).
+				WithArgs(now).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "revoked_tokens"`)).
+				WithArgs(now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_challenges"`)).
+				WithArgs(now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "password_reset_tokens"`)).
+				WithArgs(now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "personal_access_token_groups" WHERE token_id IN (SELECT id FROM "personal_access_tokens" WHERE expires_at <= $1)`)).
+				WithArgs(now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "personal_access_tokens"`)).
+				WithArgs(now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			count, err := tokenDao.DeleteExpiredTokens(now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(6)))
+			Expect(mock.ExpectationsWereMet()).To(BeNil())
+		})
+	})
+
+	Context("CreatePersonalAccessToken", func() {
+		const tokenName = "ci"
+		expiresAt := time.Now().Add(time.Hour)
+
+		When("the scope is invalid", func() {
+			It("returns client error", func() {
+				_, err := tokenDao.CreatePersonalAccessToken(userID, tokenName, tokenHash, []string{"admin"}, nil, expiresAt)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("token with the same name exists", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "personal_access_tokens"`)).
+					WithArgs(userID, tokenName).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				_, err := tokenDao.CreatePersonalAccessToken(userID, tokenName, tokenHash, []string{"read"}, nil, expiresAt)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the user isnt a member of some of the groups", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "personal_access_tokens"`)).
+					WithArgs(userID, tokenName).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs("first", "second", true, userID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "first"))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				_, err := tokenDao.CreatePersonalAccessToken(userID, tokenName, tokenHash, []string{"read"}, []string{"first", "second"}, expiresAt)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the token is limited to groups of the user", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "personal_access_tokens"`)).
+					WithArgs(userID, tokenName).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs("first", true, userID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "first"))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "personal_access_tokens"`)).
+					WithArgs(sqlmock.AnyArg(), userID, tokenName, tokenHash, "read,upload", true, expiresAt, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "personal_access_token_groups"`)).
+					WithArgs(4, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("returns the id of the token", func() {
+				id, err := tokenDao.CreatePersonalAccessToken(userID, tokenName, tokenHash, []string{"read", "upload"}, []string{"first"}, expiresAt)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal(uint(4)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("UsePersonalAccessToken", func() {
+		When("the token doesnt exist or is expired", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "personal_access_tokens"`)).
+					WithArgs(tokenHash, sqlmock.AnyArg()).
+					WillReturnError(gorm.ErrRecordNotFound)
+			})
+
+			It("returns item not found error", func() {
+				_, err := tokenDao.UsePersonalAccessToken(tokenHash)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the token is limited to groups", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "personal_access_tokens"`)).
+					WithArgs(tokenHash, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash", "scopes", "group_restricted"}).
+						AddRow(4, userID, tokenHash, "read,upload", true))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT personal_access_token_groups.token_id, groups.name FROM "personal_access_token_groups"`)).
+					WithArgs(4, true).
+					WillReturnRows(sqlmock.NewRows([]string{"token_id", "name"}).AddRow(4, "first"))
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "personal_access_tokens" SET "last_used_at"`)).
+					WithArgs(sqlmock.AnyArg(), 4).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("returns the token with its scopes and groups", func() {
+				token, err := tokenDao.UsePersonalAccessToken(tokenHash)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(token.UserID).To(Equal(uint(userID)))
+				Expect(token.ScopeList).To(Equal([]string{"read", "upload"}))
+				Expect(token.Groups).To(Equal([]string{"first"}))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("DeletePersonalAccessToken", func() {
+		When("the token doesnt belong to the user", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "personal_access_token_groups"`)).
+					WithArgs(4, userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "personal_access_tokens"`)).
+					WithArgs(4, userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			})
+
+			It("returns item not found error", func() {
+				err := tokenDao.DeletePersonalAccessToken(userID, 4)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,230 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen --source=two_factor_dao.go --destination dao_mocks/two_factor_dao.go --package dao_mocks
+
+//TwoFactorDAO - interface for working with the Database in regards to the two-factor authentication
+type TwoFactorDAO interface {
+	Migrate() error
+	EnrollTwoFactor(userID uint, secret string) (models.User, error)
+	GetTwoFactor(userID uint) (models.TwoFactor, error)
+	EnableTwoFactor(userID uint, step int64, recoveryCodeHashes []string) error
+	DisableTwoFactor(userID uint) error
+	UseTOTPStep(userID uint, step int64) (bool, error)
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+	CreateLoginChallenge(userID uint, tokenHash string, expiresAt time.Time) error
+	UseLoginChallenge(tokenHash string, maxAttempts uint) (models.LoginChallenge, error)
+	DeleteLoginChallenge(challengeID uint) error
+}
+
+//TwoFactorDAOImpl - implementation of TwoFactorDAO
+type TwoFactorDAOImpl struct {
+	dbConn *gorm.DB
+}
+
+//NewTwoFactorDAOImpl - function for creation an instance of TwoFactorDAOImpl
+func NewTwoFactorDAOImpl(dbConn *gorm.DB) *TwoFactorDAOImpl {
+	return &TwoFactorDAOImpl{dbConn: dbConn}
+}
+
+//Migrate - function which updates the models(table structure) in db
+func (i *TwoFactorDAOImpl) Migrate() error {
+	return i.dbConn.AutoMigrate(models.TwoFactor{}, models.RecoveryCode{}, models.LoginChallenge{})
+}
+
+//EnrollTwoFactor - stores a new secret of the user, which isnt used until the enrollment is confirmed
+//a previous unconfirmed enrollment is replaced
+//returns the user, for whom the secret is stored
+func (i *TwoFactorDAOImpl) EnrollTwoFactor(userID uint, secret string) (models.User, error) {
+	var user models.User
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).
+			Take(&user)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewItemNotFoundError("User doesnt exist")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the user")
+		}
+
+		var count int64
+		result = tx.Model(&models.TwoFactor{}).
+			Where("user_id = ? AND enabled = ?", userID, true).
+			Count(&count)
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the two-factor authentication")
+		} else if count > 0 {
+			return myerr.NewClientError("Two-factor authentication is already enabled")
+		}
+
+		if result = tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the previous enrollment")
+		}
+
+		twoFactor := models.TwoFactor{
+			UserID: userID,
+			Secret: secret,
+		}
+
+		if result = tx.Create(&twoFactor); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of the two-factor authentication")
+		}
+		return nil
+	})
+	return user, err
+}
+
+//GetTwoFactor - fetches the two-factor authentication of the user
+func (i *TwoFactorDAOImpl) GetTwoFactor(userID uint) (models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	result := i.dbConn.Where("user_id = ?", userID).Take(&twoFactor)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.TwoFactor{}, myerr.NewItemNotFoundError("Two-factor authentication isnt enrolled")
+	} else if result.Error != nil {
+		return models.TwoFactor{}, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the two-factor authentication")
+	}
+	return twoFactor, nil
+}
+
+//EnableTwoFactor - confirms the enrollment with the time step of the confirmation code and replaces the recovery codes of the user
+func (i *TwoFactorDAOImpl) EnableTwoFactor(userID uint, step int64, recoveryCodeHashes []string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TwoFactor{}).
+			Where("user_id = ? AND enabled = ?", userID, false).
+			Updates(map[string]interface{}{"enabled": true, "last_used_step": step})
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with enabling the two-factor authentication")
+		} else if result.RowsAffected == 0 {
+			return myerr.NewClientError("There isnt an unconfirmed enrollment of two-factor authentication")
+		}
+
+		if result = tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the previous recovery codes")
+		}
+
+		codes := make([]models.RecoveryCode, 0, len(recoveryCodeHashes))
+		for _, codeHash := range recoveryCodeHashes {
+			codes = append(codes, models.RecoveryCode{UserID: userID, CodeHash: codeHash})
+		}
+
+		if result = tx.Create(&codes); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of the recovery codes")
+		}
+		return nil
+	})
+}
+
+//DisableTwoFactor - removes the two-factor authentication and the recovery codes of the user
+func (i *TwoFactorDAOImpl) DisableTwoFactor(userID uint) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.TwoFactor{}, &models.RecoveryCode{}} {
+			if result := tx.Where("user_id = ?", userID).Delete(model); result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with disabling the two-factor authentication")
+			}
+		}
+		return nil
+	})
+}
+
+//UseTOTPStep - records the time step of an accepted code
+//returns false, if a code of this or a later time step was already used
+func (i *TwoFactorDAOImpl) UseTOTPStep(userID uint, step int64) (bool, error) {
+	result := i.dbConn.Model(&models.TwoFactor{}).
+		Where("user_id = ? AND enabled = ? AND last_used_step < ?", userID, true, step).
+		Update("last_used_step", step)
+
+	if result.Error != nil {
+		return false, myerr.NewServerErrorWrap(result.Error, "Problem with the usage of the TOTP code")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+//UseRecoveryCode - marks the recovery code of the user as used
+//returns false, if there isnt such unused code
+func (i *TwoFactorDAOImpl) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := i.dbConn.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return false, myerr.NewServerErrorWrap(result.Error, "Problem with the usage of the recovery code")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+//CreateLoginChallenge - stores the hash of a challenge, issued to the user after its password was confirmed
+func (i *TwoFactorDAOImpl) CreateLoginChallenge(userID uint, tokenHash string, expiresAt time.Time) error {
+	challenge := models.LoginChallenge{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}
+
+	if result := i.dbConn.Create(&challenge); result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of login challenge")
+	}
+	return nil
+}
+
+//UseLoginChallenge - fetches the challenge, which isnt expired, and counts the attempt to complete it
+//the challenge is deleted, when it is used more than maxAttempts times
+func (i *TwoFactorDAOImpl) UseLoginChallenge(tokenHash string, maxAttempts uint) (models.LoginChallenge, error) {
+	var (
+		challenge models.LoginChallenge
+		exhausted bool
+	)
+
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).
+			Take(&challenge)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewClientError("Invalid or expired login challenge. Please login again")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of login challenge")
+		}
+
+		if challenge.Attempts >= maxAttempts {
+			exhausted = true
+			if result = tx.Delete(&challenge); result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of login challenge")
+			}
+			return nil
+		}
+
+		challenge.Attempts++
+		if result = tx.Model(&challenge).Update("attempts", challenge.Attempts); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the update of login challenge")
+		}
+		return nil
+	})
+
+	if err != nil {
+		return models.LoginChallenge{}, err
+	} else if exhausted {
+		return models.LoginChallenge{}, myerr.NewClientError("Too many wrong codes. Please login again")
+	}
+	return challenge, nil
+}
+
+//DeleteLoginChallenge - deletes the challenge, after it was completed
+func (i *TwoFactorDAOImpl) DeleteLoginChallenge(challengeID uint) error {
+	if result := i.dbConn.Where("id = ?", challengeID).Delete(&models.LoginChallenge{}); result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of login challenge")
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package dao
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("TwoFactorDAO", func() {
+	var (
+		twoFactorDao TwoFactorDAO
+		mock         sqlmock.Sqlmock
+	)
+
+	const (
+		userID    = 1
+		tokenHash = "challenge-hash"
+		step      = 100
+	)
+
+	BeforeEach(func() {
+		var (
+			db  *sql.DB
+			err error
+		)
+
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		gdb, err := gorm.Open(postgres.New(postgres.Config{
+			Conn: db,
+		}), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		twoFactorDao = NewTwoFactorDAOImpl(gdb)
+	})
+
+	Context("EnrollTwoFactor", func() {
+		When("the two-factor authentication is already enabled", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(userID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(userID, "username"))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "two_factors"`)).
+					WithArgs(userID, true).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				_, err := twoFactorDao.EnrollTwoFactor(userID, "secret")
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("EnableTwoFactor", func() {
+		When("there isnt an unconfirmed enrollment", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "two_factors" SET "enabled"=$1,"last_used_step"=$2,"updated_at"=$3`)).
+					WithArgs(true, step, sqlmock.AnyArg(), userID, false).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := twoFactorDao.EnableTwoFactor(userID, step, []string{"code-hash"})
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the enrollment is confirmed", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "two_factors" SET "enabled"=$1,"last_used_step"=$2,"updated_at"=$3`)).
+					WithArgs(true, step, sqlmock.AnyArg(), userID, false).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "recovery_codes"`)).
+					WithArgs(userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "recovery_codes"`)).
+					WithArgs(userID, "first-hash", nil, userID, "second-hash", nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectCommit()
+			})
+
+			It("stores the recovery codes", func() {
+				err := twoFactorDao.EnableTwoFactor(userID, step, []string{"first-hash", "second-hash"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("UseTOTPStep", func() {
+		When("a code of the same time step was already used", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "two_factors" SET "last_used_step"=$1`)).
+					WithArgs(step, sqlmock.AnyArg(), userID, true, step).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			})
+
+			It("returns false", func() {
+				used, err := twoFactorDao.UseTOTPStep(userID, step)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(used).To(BeFalse())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("UseLoginChallenge", func() {
+		challengeRows := func(attempts uint) *sqlmock.Rows {
+			return sqlmock.NewRows([]string{"id", "created_at", "user_id", "token_hash", "expires_at", "attempts"}).
+				AddRow(3, time.Now(), userID, tokenHash, time.Now().Add(time.Minute), attempts)
+		}
+
+		When("the challenge doesnt exist or is expired", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "login_challenges"`)).
+					WithArgs(tokenHash, sqlmock.AnyArg()).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				_, err := twoFactorDao.UseLoginChallenge(tokenHash, 5)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(BeTrue())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the challenge was used too many times", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "login_challenges"`)).
+					WithArgs(tokenHash, sqlmock.AnyArg()).
+					WillReturnRows(challengeRows(5))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_challenges"`)).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("deletes the challenge and returns client error", func() {
+				_, err := twoFactorDao.UseLoginChallenge(tokenHash, 5)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Too many wrong codes"))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the challenge can be used", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "login_challenges"`)).
+					WithArgs(tokenHash, sqlmock.AnyArg()).
+					WillReturnRows(challengeRows(1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "login_challenges" SET "attempts"=$1`)).
+					WithArgs(2, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("counts the attempt", func() {
+				challenge, err := twoFactorDao.UseLoginChallenge(tokenHash, 5)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(challenge.UserID).To(Equal(uint(userID)))
+				Expect(challenge.Attempts).To(Equal(uint(2)))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+})
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
 	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 //go:generate mockgen --source=uam_dao.go --destination dao_mocks/uam_dao.go --package dao_mocks
@@ -17,18 +19,53 @@ type UamDAO interface {
 	Migrate() error
 	CreateUser(string, string) error
 	GetUser(string) (models.User, error)
-	DeleteUser(uint) error
-	CreateGroup(uint, string) error
-	AddUserToGroup(uint, string, string) error
+	GetUserByID(uint) (models.User, error)
+	DeleteUser(uint, AccountDeletionPolicy, func(models.User) error) error
+	CreateGroup(uint, string, string) error
+	SetGroupVisibility(uint, string, string) error
+	RequestToJoinGroup(uint, string) (bool, error)
+	GetJoinRequests(uint, string) ([]JoinRequestInfo, error)
+	ResolveJoinRequest(uint, string, string, bool, string) error
+	InviteUserToGroup(uint, string, string, string, *time.Time) error
+	GetInvitations(uint) ([]InvitationInfo, error)
+	AcceptInvitation(uint, string) error
+	DeclineInvitation(uint, string) error
+	DeleteExpiredInvitations(time.Time) (int64, error)
 	RemoveUserFromGroup(uint, string, string) error
+	ChangeMemberRole(uint, string, string, string) error
+	TransferOwnership(uint, string, string) error
 	MemberExists(uint, uint) (bool, error)
 	DeactivateGroup(uint, string) error
 	GetGroup(string) (models.Group, error)
 	GetDeactivatedGroupNames() ([]string, error)
 	EraseDeactivatedGroups([]string) error
-	GetAllGroups() ([]models.Group, error)
+	GetAllGroups(uint) ([]models.Group, error)
 	GetAllUsers() ([]models.User, error)
-	GetAllUsersInGroup(uint, string) ([]models.User, error)
+	GetAllUsersInGroup(uint, string) ([]GroupMember, error)
+}
+
+//GroupMember - a member of a group together with its role in the group
+type GroupMember struct {
+	ID       uint
+	Username string
+	Role     string
+}
+
+//InvitationInfo - a pending invitation together with the name of the group and the username of the inviter
+type InvitationInfo struct {
+	ID        uint
+	GroupName string
+	Inviter   string
+	Role      string
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+}
+
+//JoinRequestInfo - a pending join request together with the username of the user, who wants to join the group
+type JoinRequestInfo struct {
+	ID        uint
+	Username  string
+	CreatedAt time.Time
 }
 
 //UamDAOImpl - implementation of UamDAO
@@ -43,7 +80,14 @@ func NewUamDAOImpl(dbConn *gorm.DB) *UamDAOImpl {
 
 //Migrate - function which updates the models(table structure) in db
 func (i *UamDAOImpl) Migrate() error {
-	return i.dbConn.AutoMigrate(models.User{}, models.Group{}, models.Membership{})
+	if err := i.dbConn.AutoMigrate(models.User{}, models.Group{}, models.Membership{}, models.Invitation{}, models.JoinRequest{}); err != nil {
+		return err
+	}
+
+	//memberships, created before the introduction of roles, get the default role, so the group owners are updated here
+	return i.dbConn.Exec(`UPDATE memberships SET role = ? FROM groups
+		WHERE memberships.group_id = groups.id AND memberships.user_id = groups.owner_id AND memberships.role <> ?`,
+		models.RoleOwner, models.RoleOwner).Error
 }
 
 //CreateUser - creates a new user in the database, given username and password (encrypted)
@@ -73,25 +117,68 @@ func (i *UamDAOImpl) CreateUser(username string, password string) error {
 	})
 }
 
-//DeleteUser - deletes user given an id of the user
-func (i *UamDAOImpl) DeleteUser(userID uint) error {
-	var count int64
-	result := i.dbConn.Table("users").Where("id = ?", userID).Count(&count)
-
-	if result.Error != nil {
-		return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if user exists")
-	} else if count == 0 {
-		return myerr.NewItemNotFoundError("User with that id does not exist")
+//DeleteUser - deletes the account of the user together with its memberships, invitations and join requests
+//confirm is called with the locked user, so the deletion can be rejected, for instance if the password isnt confirmed
+//the owned groups are transferred or deactivated and the files of the user are reassigned or moved to the trash, depending on the policy
+//the contents of the deactivated groups, the trashed files and the upload sessions of the user are later erased by the cron jobs
+func (i *UamDAOImpl) DeleteUser(userID uint, policy AccountDeletionPolicy, confirm func(models.User) error) error {
+	policy, err := policy.withDefaults()
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Deleting user with id [%d]\n", userID)
-	if result = i.dbConn.Delete(&models.User{}, userID); result.Error != nil {
-		return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the user from db")
-	}
-	log.Printf("User with id [%d] is deleted\n", userID)
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).
+			Take(&user)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewItemNotFoundError("User with that id does not exist")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup if user exists")
+		}
 
-	return nil
+		if err := confirm(user); err != nil {
+			return err
+		}
+
+		var ownedGroups []models.Group
+		result = tx.Where("owner_id = ?", userID).
+			Where("active = ?", true).
+			Find(&ownedGroups)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the groups of the user")
+		}
+
+		for _, group := range ownedGroups {
+			if err := handOverGroupWithConn(tx, group, policy.GroupPolicy); err != nil {
+				return err
+			}
+		}
+
+		if err := handOverFilesWithConn(tx, userID, policy.FilePolicy); err != nil {
+			return err
+		}
 
+		log.Printf("Deleting user with id [%d]\n", userID)
+		if _, err := deletePersonalAccessTokensWithConn(tx, "user_id = ?", userID); err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.Membership{}, &models.Invitation{}, &models.JoinRequest{}, &models.RefreshToken{},
+			&models.TwoFactor{}, &models.RecoveryCode{}, &models.LoginChallenge{}, &models.PasswordResetToken{}, &models.ShareLink{}, &models.DropBox{}} {
+			if result := tx.Where("user_id = ?", userID).Delete(model); result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the memberships of the user")
+			}
+		}
+
+		if result = tx.Delete(&user); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the user from db")
+		}
+		log.Printf("User with id [%d] is deleted\n", userID)
+
+		return nil
+	})
 }
 
 //GetUser - fetches information about an existing user
@@ -99,8 +186,27 @@ func (i *UamDAOImpl) GetUser(username string) (models.User, error) {
 	return getUserWithConn(i.dbConn, username)
 }
 
-//CreateGroup - creates a new group for sharing files
-func (i *UamDAOImpl) CreateGroup(userID uint, groupName string) error {
+//GetUserByID - fetches information about an existing user by its id
+func (i *UamDAOImpl) GetUserByID(userID uint) (models.User, error) {
+	var user models.User
+	result := i.dbConn.Where("id = ?", userID).Take(&user)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return user, myerr.NewItemNotFoundError("User does not exist")
+	} else if result.Error != nil {
+		return user, myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of the user")
+	}
+	return user, nil
+}
+
+//CreateGroup - creates a new group for sharing files. The empty visibility means private
+func (i *UamDAOImpl) CreateGroup(userID uint, groupName string, visibility string) error {
+	if visibility == "" {
+		visibility = models.VisibilityPrivate
+	} else if err := ValidateVisibility(visibility); err != nil {
+		return err
+	}
+
 	return i.dbConn.Transaction(func(tx *gorm.DB) error {
 		var count int64
 
@@ -112,8 +218,9 @@ func (i *UamDAOImpl) CreateGroup(userID uint, groupName string) error {
 		}
 
 		group := models.Group{
-			Name:    groupName,
-			OwnerID: userID,
+			Name:       groupName,
+			OwnerID:    userID,
+			Visibility: visibility,
 		}
 
 		log.Printf("Creating group [%s] with owner [%d]\n", groupName, userID)
@@ -125,6 +232,7 @@ func (i *UamDAOImpl) CreateGroup(userID uint, groupName string) error {
 		membership := models.Membership{
 			UserID:  userID,
 			GroupID: group.ID,
+			Role:    models.RoleOwner,
 		}
 
 		//its usedless to check if the membership already exists, because basically the group is created in this transaction
@@ -143,8 +251,16 @@ func (i *UamDAOImpl) GetGroup(groupName string) (models.Group, error) {
 	return getGroupWithConn(i.dbConn, groupName)
 }
 
-//AddUserToGroup - adds a new member to a specified group
-func (i *UamDAOImpl) AddUserToGroup(ownerID uint, username string, groupName string) error {
+//InviteUserToGroup - invites a user to join a specified group with the given role. The empty role means editor
+//the owner can invite admins, editors and viewers, while the admins can invite only editors and viewers
+//the user becomes a member only after accepting the invitation. Nil expiresAt means that the invitation doesnt expire
+func (i *UamDAOImpl) InviteUserToGroup(currUserID uint, username string, groupName string, role string, expiresAt *time.Time) error {
+	if role == "" {
+		role = models.RoleEditor
+	} else if err := ValidateAssignableRole(role); err != nil {
+		return err
+	}
+
 	return i.dbConn.Transaction(func(tx *gorm.DB) error {
 		var (
 			count int64
@@ -156,12 +272,17 @@ func (i *UamDAOImpl) AddUserToGroup(ownerID uint, username string, groupName str
 		group, err = getGroupWithConn(tx, groupName)
 		if err != nil {
 			return err
-		} else if group.OwnerID != ownerID {
-			return myerr.NewClientError("Only the group owner can add members to the group")
 		} else if !group.Active {
 			return myerr.NewClientError("The group is currently being deleted")
 		}
 
+		actor, err := checkPermissionWithConn(tx, currUserID, group.ID, ManageMembers)
+		if err != nil {
+			return err
+		} else if !canManageMember(actor, role) {
+			return myerr.NewClientError(fmt.Sprintf("Your role [%s] in the group doesnt allow inviting members with role [%s]", actor.Role, role))
+		}
+
 		user, err = getUserWithConn(tx, username)
 		if err != nil {
 			return err
@@ -178,51 +299,141 @@ func (i *UamDAOImpl) AddUserToGroup(ownerID uint, username string, groupName str
 			return myerr.NewClientError("The user is already a member of the group")
 		}
 
+		result = pendingInvitationsWithConn(tx, user.ID, group.ID).Count(&count)
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of invitation in db")
+		} else if count != 0 {
+			return myerr.NewClientError("The user is already invited to the group")
+		}
+
+		invitation := models.Invitation{
+			GroupID:   group.ID,
+			UserID:    user.ID,
+			InviterID: currUserID,
+			Role:      role,
+			ExpiresAt: expiresAt,
+		}
+
+		log.Printf("Creating invitation for user with id [%d] in group with id [%d]", invitation.UserID, invitation.GroupID)
+		if result := tx.Create(&invitation); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of new invitation in db")
+		}
+		log.Printf("Invitation for user with id [%d] in group with id [%d] created", invitation.UserID, invitation.GroupID)
+
+		return nil
+	})
+}
+
+//GetInvitations - retrieves the pending invitations of the user, which arent expired
+func (i *UamDAOImpl) GetInvitations(userID uint) ([]InvitationInfo, error) {
+	var invitations []InvitationInfo
+	result := i.dbConn.Table("invitations").
+		Select("invitations.id, groups.name AS group_name, users.username AS inviter, invitations.role, invitations.created_at, invitations.expires_at").
+		Joins("inner join groups on groups.id = invitations.group_id").
+		Joins("left join users on users.id = invitations.inviter_id").
+		Where("invitations.user_id = ?", userID).
+		Where("invitations.expires_at IS NULL OR invitations.expires_at > ?", time.Now()).
+		Scan(&invitations)
+
+	if result.Error != nil {
+		return nil, myerr.NewServerErrorWrap(result.Error, "Problem with fetching the invitations")
+	}
+	return invitations, nil
+}
+
+//AcceptInvitation - makes the user a member of the group with the role from the invitation and removes the invitation
+func (i *UamDAOImpl) AcceptInvitation(userID uint, groupName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		//the lock on the invitation guarantees that it cannot be accepted twice at the same time
+		var invitation models.Invitation
+		result := pendingInvitationsWithConn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID, group.ID).
+			Take(&invitation)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return myerr.NewItemNotFoundError("Invitation not found")
+		} else if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of invitation in db")
+		}
+
 		membership := models.Membership{
 			GroupID: group.ID,
-			UserID:  user.ID,
+			UserID:  userID,
+			Role:    invitation.Role,
 		}
 
 		log.Printf("Creating membership for user with id [%d] in group with id [%d]", membership.UserID, membership.GroupID)
 		if result := tx.Create(&membership); result.Error != nil {
 			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of new membership in db")
 		}
+
+		if result := tx.Delete(&invitation); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the invitation in db")
+		}
 		log.Printf("Membership for user with id [%d] in group id [%d] created", membership.UserID, membership.GroupID)
 
 		return nil
 	})
 }
 
+//DeclineInvitation - removes the invitation of the user to the group
+func (i *UamDAOImpl) DeclineInvitation(userID uint, groupName string) error {
+	group, err := getGroupWithConn(i.dbConn, groupName)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Declining invitation for user with id [%d] in group with id [%d]", userID, group.ID)
+	result := i.dbConn.Where("user_id = ?", userID).
+		Where("group_id = ?", group.ID).
+		Delete(&models.Invitation{})
+
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the invitation in db")
+	} else if result.RowsAffected == 0 {
+		return myerr.NewItemNotFoundError("Invitation not found")
+	}
+	log.Printf("Invitation for user with id [%d] in group with id [%d] is declined", userID, group.ID)
+
+	return nil
+}
+
+//DeleteExpiredInvitations - deletes the invitations, which expired before the given moment, and returns their count
+func (i *UamDAOImpl) DeleteExpiredInvitations(expiredBefore time.Time) (int64, error) {
+	result := i.dbConn.Where("expires_at <= ?", expiredBefore).Delete(&models.Invitation{})
+	if result.Error != nil {
+		return 0, myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of expired invitations")
+	}
+	return result.RowsAffected, nil
+}
+
 //DeactivateGroup - deletes all memberships and changes the status of the group to non active
 func (i *UamDAOImpl) DeactivateGroup(currUserID uint, groupName string) error {
 	return i.dbConn.Transaction(func(tx *gorm.DB) error {
 		group, err := getGroupWithConn(tx, groupName)
 		if err != nil {
 			return err
-		} else if group.OwnerID != currUserID {
-			return myerr.NewClientError("Only the group owner can delete the group")
 		} else if !group.Active {
 			return myerr.NewClientError("The group is currently being deleted")
 		}
 
-		log.Printf("Revolking membership for users in group [%s]", groupName)
-		result := tx.Table("memberships").
-			Where("group_id = ?", group.ID).Delete(&models.Membership{})
-		if result.Error != nil {
-			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of memberships in db")
+		if _, err = checkPermissionWithConn(tx, currUserID, group.ID, ManageGroup); err != nil {
+			return err
 		}
-		log.Printf("Revolked membership for users in group [%s]", groupName)
 
-		log.Printf("Change status of group [%s] to non active\n", groupName)
-		if result = tx.Model(&group).Update("active", false); result.Error != nil {
-			return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the group in db")
-		}
-		log.Printf("Status of group [%s] is set to non active\n", groupName)
-		return nil
+		return deactivateGroupWithConn(tx, group)
 	})
 }
 
 //RemoveUserFromGroup - removes a membership of a user to a specific group
+//every member can leave the group, except the owner, who has to transfer the ownership first
+//the other members can be removed only by members with higher role
 func (i *UamDAOImpl) RemoveUserFromGroup(currUserID uint, username string, groupName string) error {
 	return i.dbConn.Transaction(func(tx *gorm.DB) error {
 		var (
@@ -243,10 +454,20 @@ func (i *UamDAOImpl) RemoveUserFromGroup(currUserID uint, username string, group
 			return err
 		}
 
-		if group.OwnerID != currUserID && user.ID != currUserID {
-			return myerr.NewClientError("Only the owner of the group can revoke membership of other members")
-		} else if group.OwnerID == currUserID && user.ID == currUserID {
-			return myerr.NewClientError("The owner cannot remove its own membership. Yet to be added this functionality")
+		if group.OwnerID == currUserID && user.ID == currUserID {
+			return myerr.NewClientError("The owner cannot leave the group. Transfer the ownership to another member first")
+		} else if user.ID != currUserID {
+			actor, err := checkPermissionWithConn(tx, currUserID, group.ID, ManageMembers)
+			if err != nil {
+				return err
+			}
+
+			target, err := getMembershipWithConn(tx, user.ID, group.ID)
+			if err != nil {
+				return err
+			} else if !canManageMember(actor, target.Role) {
+				return myerr.NewClientError(fmt.Sprintf("Your role [%s] in the group doesnt allow removing members with role [%s]", actor.Role, target.Role))
+			}
 		}
 
 		log.Printf("Revolking membership for user with id [%d] in group with id [%d]", user.ID, group.ID)
@@ -265,6 +486,281 @@ func (i *UamDAOImpl) RemoveUserFromGroup(currUserID uint, username string, group
 	})
 }
 
+//ChangeMemberRole - gives a new role to a member of the group
+//the owner can change the roles of all other members, while the admins can change only the roles of editors and viewers
+func (i *UamDAOImpl) ChangeMemberRole(currUserID uint, username string, groupName string, role string) error {
+	if err := ValidateAssignableRole(role); err != nil {
+		return err
+	}
+
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		user, err := getUserWithConn(tx, username)
+		if err != nil {
+			return err
+		} else if user.ID == currUserID {
+			return myerr.NewClientError("You cannot change your own role")
+		}
+
+		actor, err := checkPermissionWithConn(tx, currUserID, group.ID, ManageMembers)
+		if err != nil {
+			return err
+		}
+
+		target, err := getMembershipWithConn(tx, user.ID, group.ID)
+		if err != nil {
+			return err
+		} else if !canManageMember(actor, target.Role) || !canManageMember(actor, role) {
+			return myerr.NewClientError(fmt.Sprintf("Your role [%s] in the group doesnt allow changing the role from [%s] to [%s]", actor.Role, target.Role, role))
+		}
+
+		log.Printf("Changing the role of user with id [%d] in group with id [%d] to [%s]", user.ID, group.ID, role)
+		if result := tx.Model(&target).Update("role", role); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the membership in db")
+		}
+		log.Printf("Role of user with id [%d] in group with id [%d] is changed to [%s]", user.ID, group.ID, role)
+
+		return nil
+	})
+}
+
+//TransferOwnership - makes another member the owner of the group. The former owner stays in the group as an admin
+func (i *UamDAOImpl) TransferOwnership(currUserID uint, username string, groupName string) error {
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		//the lock on the group guarantees that the ownership cannot be transferred twice at the same time
+		group, err := getGroupWithConn(tx.Clauses(clause.Locking{Strength: "UPDATE"}), groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		actor, err := checkPermissionWithConn(tx, currUserID, group.ID, ManageGroup)
+		if err != nil {
+			return err
+		}
+
+		user, err := getUserWithConn(tx, username)
+		if err != nil {
+			return err
+		} else if user.ID == currUserID {
+			return myerr.NewClientError("You are already the owner of the group")
+		}
+
+		target, err := getMembershipWithConn(tx, user.ID, group.ID)
+		if _, ok := err.(*myerr.ItemNotFoundError); ok {
+			return myerr.NewClientError("The ownership can be transferred only to a member of the group")
+		} else if err != nil {
+			return err
+		}
+
+		if err = transferOwnershipWithConn(tx, group, target); err != nil {
+			return err
+		}
+
+		if result := tx.Model(&actor).Update("role", models.RoleAdmin); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the membership in db")
+		}
+		return nil
+	})
+}
+
+//SetGroupVisibility - changes who can see and join the group. Only for the owner of the group
+func (i *UamDAOImpl) SetGroupVisibility(currUserID uint, groupName string, visibility string) error {
+	if err := ValidateVisibility(visibility); err != nil {
+		return err
+	}
+
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		if _, err = checkPermissionWithConn(tx, currUserID, group.ID, ManageGroup); err != nil {
+			return err
+		}
+
+		log.Printf("Changing the visibility of group [%s] to [%s]", groupName, visibility)
+		if result := tx.Model(&group).Update("visibility", visibility); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the group in db")
+		}
+
+		//the pending requests of a private group cannot be approved anymore, while the ones of an open group are no longer needed
+		if visibility != models.VisibilityDiscoverable {
+			if result := tx.Where("group_id = ?", group.ID).Delete(&models.JoinRequest{}); result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of join requests in db")
+			}
+		}
+		log.Printf("Visibility of group [%s] is changed to [%s]", groupName, visibility)
+
+		return nil
+	})
+}
+
+//RequestToJoinGroup - makes the user a viewer of an open group or creates a join request for a discoverable group
+//returns true if the user became a member of the group
+func (i *UamDAOImpl) RequestToJoinGroup(userID uint, groupName string) (bool, error) {
+	joined := false
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		} else if group.Visibility == models.VisibilityPrivate {
+			//the private groups are hidden, so their existence is not revealed
+			return myerr.NewItemNotFoundError(fmt.Sprintf("Group [%s] does not exist", groupName))
+		}
+
+		var count int64
+		result := tx.Table("memberships").
+			Where("group_id = ?", group.ID).
+			Where("user_id = ?", userID).
+			Count(&count)
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of membership in db")
+		} else if count != 0 {
+			return myerr.NewClientError("You are already a member of the group")
+		}
+
+		if group.Visibility == models.VisibilityOpen {
+			membership := models.Membership{
+				GroupID: group.ID,
+				UserID:  userID,
+				Role:    models.RoleViewer,
+			}
+
+			log.Printf("Creating membership for user with id [%d] in open group with id [%d]", userID, group.ID)
+			if result := tx.Create(&membership); result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of new membership in db")
+			}
+			log.Printf("Membership for user with id [%d] in group id [%d] created", userID, group.ID)
+
+			joined = true
+			return nil
+		}
+
+		result = tx.Table("join_requests").
+			Where("group_id = ?", group.ID).
+			Where("user_id = ?", userID).
+			Count(&count)
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of join request in db")
+		} else if count != 0 {
+			return myerr.NewClientError("You already requested to join the group")
+		}
+
+		joinRequest := models.JoinRequest{
+			GroupID: group.ID,
+			UserID:  userID,
+		}
+
+		log.Printf("Creating join request for user with id [%d] in group with id [%d]", userID, group.ID)
+		if result := tx.Create(&joinRequest); result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of new join request in db")
+		}
+		log.Printf("Join request for user with id [%d] in group with id [%d] created", userID, group.ID)
+
+		return nil
+	})
+	return joined, err
+}
+
+//GetJoinRequests - retrieves the pending join requests of the group. Only for the members, who can manage members
+func (i *UamDAOImpl) GetJoinRequests(currUserID uint, groupName string) ([]JoinRequestInfo, error) {
+	var joinRequests []JoinRequestInfo
+	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		}
+
+		if _, err = checkPermissionWithConn(tx, currUserID, group.ID, ManageMembers); err != nil {
+			return err
+		}
+
+		result := tx.Table("join_requests").
+			Select("join_requests.id, users.username, join_requests.created_at").
+			Joins("inner join users on users.id = join_requests.user_id").
+			Where("join_requests.group_id = ?", group.ID).
+			Scan(&joinRequests)
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with fetching the join requests")
+		}
+		return nil
+	})
+	return joinRequests, err
+}
+
+//ResolveJoinRequest - approves or rejects the join request of a user. The approved user becomes a member with the given role. The empty role means editor
+//the owner can approve admins, editors and viewers, while the admins can approve only editors and viewers
+func (i *UamDAOImpl) ResolveJoinRequest(currUserID uint, groupName string, username string, approve bool, role string) error {
+	if role == "" {
+		role = models.RoleEditor
+	} else if err := ValidateAssignableRole(role); err != nil {
+		return err
+	}
+
+	return i.dbConn.Transaction(func(tx *gorm.DB) error {
+		group, err := getGroupWithConn(tx, groupName)
+		if err != nil {
+			return err
+		} else if !group.Active {
+			return myerr.NewClientError("The group is currently being deleted")
+		}
+
+		actor, err := checkPermissionWithConn(tx, currUserID, group.ID, ManageMembers)
+		if err != nil {
+			return err
+		} else if approve && !canManageMember(actor, role) {
+			return myerr.NewClientError(fmt.Sprintf("Your role [%s] in the group doesnt allow adding members with role [%s]", actor.Role, role))
+		}
+
+		user, err := getUserWithConn(tx, username)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Resolving join request of user with id [%d] in group with id [%d]", user.ID, group.ID)
+		result := tx.Where("user_id = ?", user.ID).
+			Where("group_id = ?", group.ID).
+			Delete(&models.JoinRequest{})
+
+		if result.Error != nil {
+			return myerr.NewServerErrorWrap(result.Error, "Problem with the deletion of the join request in db")
+		} else if result.RowsAffected == 0 {
+			return myerr.NewItemNotFoundError("Join request not found")
+		}
+
+		if approve {
+			membership := models.Membership{
+				GroupID: group.ID,
+				UserID:  user.ID,
+				Role:    role,
+			}
+
+			if result := tx.Create(&membership); result.Error != nil {
+				return myerr.NewServerErrorWrap(result.Error, "Problem with the creation of new membership in db")
+			}
+		}
+		log.Printf("Join request of user with id [%d] in group with id [%d] is resolved. Approved: %t", user.ID, group.ID, approve)
+
+		return nil
+	})
+}
+
 //MemberExists - check if membership exists for a particular group
 func (i *UamDAOImpl) MemberExists(userID uint, groupID uint) (bool, error) {
 	var count int64
@@ -308,10 +804,13 @@ func (i *UamDAOImpl) EraseDeactivatedGroups(groupNames []string) error {
 	})
 }
 
-//GetAllGroups - retrieves all groups
-func (i *UamDAOImpl) GetAllGroups() ([]models.Group, error) {
+//GetAllGroups - retrieves all active groups, which the user can see - the discoverable and open groups and the groups, in which the user is a member
+func (i *UamDAOImpl) GetAllGroups(userID uint) ([]models.Group, error) {
 	var groups []models.Group
-	result := i.dbConn.Find(&groups)
+	memberships := i.dbConn.Table("memberships").Select("group_id").Where("user_id = ?", userID)
+	result := i.dbConn.Where("active = ?", true).
+		Where("visibility <> ? OR id IN (?)", models.VisibilityPrivate, memberships).
+		Find(&groups)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return make([]models.Group, 0), nil
 	} else if result.Error != nil {
@@ -330,9 +829,9 @@ func (i *UamDAOImpl) GetAllUsers() ([]models.User, error) {
 	return users, nil
 }
 
-//GetAllUsersInGroup - retrieves all users in a group
-func (i *UamDAOImpl) GetAllUsersInGroup(userID uint, groupName string) ([]models.User, error) {
-	var users []models.User
+//GetAllUsersInGroup - retrieves all users in a group together with their roles
+func (i *UamDAOImpl) GetAllUsersInGroup(userID uint, groupName string) ([]GroupMember, error) {
+	var users []GroupMember
 	err := i.dbConn.Transaction(func(tx *gorm.DB) error {
 		group, errGet := getGroupWithConn(tx, groupName)
 		if _, ok := errGet.(*myerr.ItemNotFoundError); ok || !group.Active {
@@ -355,8 +854,9 @@ func (i *UamDAOImpl) GetAllUsersInGroup(userID uint, groupName string) ([]models
 
 		log.Printf("Group id %d\n", group.ID)
 
-		result = tx.Table("users").Joins("inner join memberships on users.id = memberships.user_id").
-			Where("memberships.group_id = ?", group.ID).Find(&users)
+		result = tx.Table("users").Select("users.id, users.username, memberships.role").
+			Joins("inner join memberships on users.id = memberships.user_id").
+			Where("memberships.group_id = ?", group.ID).Scan(&users)
 
 		if result.Error != nil {
 			return myerr.NewServerErrorWrap(result.Error, "Problem with the lookup of users in db")
@@ -367,6 +867,56 @@ func (i *UamDAOImpl) GetAllUsersInGroup(userID uint, groupName string) ([]models
 	return users, err
 }
 
+//deactivateGroupWithConn - deletes all memberships, invitations and join requests of the group and changes its status to non active
+func deactivateGroupWithConn(tx *gorm.DB, group models.Group) error {
+	log.Printf("Revolking membership for users in group [%s]", group.Name)
+	result := tx.Table("memberships").
+		Where("group_id = ?", group.ID).Delete(&models.Membership{})
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of memberships in db")
+	}
+	log.Printf("Revolked membership for users in group [%s]", group.Name)
+
+	result = tx.Where("group_id = ?", group.ID).Delete(&models.Invitation{})
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of invitations in db")
+	}
+
+	result = tx.Where("group_id = ?", group.ID).Delete(&models.JoinRequest{})
+	if result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of join requests in db")
+	}
+
+	log.Printf("Change status of group [%s] to non active\n", group.Name)
+	if result = tx.Model(&group).Update("active", false); result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with deletion of the group in db")
+	}
+	log.Printf("Status of group [%s] is set to non active\n", group.Name)
+	return nil
+}
+
+//transferOwnershipWithConn - makes the member the owner of the group. The role of the former owner isnt changed
+func transferOwnershipWithConn(tx *gorm.DB, group models.Group, target models.Membership) error {
+	log.Printf("Transferring the ownership of group [%s] from user with id [%d] to user with id [%d]", group.Name, group.OwnerID, target.UserID)
+	if result := tx.Model(&group).Update("owner_id", target.UserID); result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the group owner in db")
+	}
+
+	if result := tx.Model(&target).Update("role", models.RoleOwner); result.Error != nil {
+		return myerr.NewServerErrorWrap(result.Error, "Problem with the update of the membership in db")
+	}
+	log.Printf("Ownership of group [%s] is transferred to user with id [%d]", group.Name, target.UserID)
+	return nil
+}
+
+//pendingInvitationsWithConn - prepares a query for the invitations of the user to the group, which arent expired
+func pendingInvitationsWithConn(dbConn *gorm.DB, userID uint, groupID uint) *gorm.DB {
+	return dbConn.Model(&models.Invitation{}).
+		Where("user_id = ?", userID).
+		Where("group_id = ?", groupID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+}
+
 func getUserWithConn(dbConn *gorm.DB, username string) (models.User, error) {
 	var user models.User
 
@@ -24,6 +24,11 @@ func (a Any) Match(v driver.Value) bool {
 	return true
 }
 
+func membershipRows(userID, groupID uint, role string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "created_at", "updated_at", "group_id", "user_id", "role"}).
+		AddRow(1, time.Now(), time.Now(), groupID, userID, role)
+}
+
 var _ = Describe("UamDAO", func() {
 	var (
 		uamDao UamDAO
@@ -114,7 +119,7 @@ var _ = Describe("UamDAO", func() {
 							WithArgs(username).
 							WillReturnRows(rows)
 						mock.ExpectQuery("INSERT INTO \"users\"").
-							WithArgs(Any{}, Any{}, username, password). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
+							WithArgs(Any{}, Any{}, username, password, nil, false, nil, "", nil). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
 							WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 						mock.ExpectCommit()
 					})
@@ -133,7 +138,7 @@ var _ = Describe("UamDAO", func() {
 							WithArgs(username).
 							WillReturnRows(rows)
 						mock.ExpectQuery("INSERT INTO \"users\"").
-							WithArgs(Any{}, Any{}, username, password). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
+							WithArgs(Any{}, Any{}, username, password, nil, false, nil, "", nil). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
 							WillReturnError(fmt.Errorf("some error"))
 						mock.ExpectRollback()
 					})
@@ -152,15 +157,83 @@ var _ = Describe("UamDAO", func() {
 	})
 
 	Context("Delete user", func() {
-		When("request if user exists fails", func() {
+		var (
+			userRows  *sqlmock.Rows
+			groupRows *sqlmock.Rows
+			confirmed func(models.User) error
+		)
+
+		BeforeEach(func() {
+			userRows = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "username", "password"}).
+				AddRow(userID, time.Now(), time.Now(), username, password)
+			groupRows = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active"}).
+				AddRow(groupID, time.Now(), time.Now(), groupName, userID, true)
+			confirmed = func(models.User) error { return nil }
+		})
+
+		expectMembershipsDeletion := func() {
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "personal_access_token_groups"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "personal_access_tokens"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "memberships"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "invitations"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "join_requests"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "refresh_tokens"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "two_factors"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "recovery_codes"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "login_challenges"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "password_reset_tokens"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "share_links"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "drop_boxes"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "users"`)).
+				WithArgs(userID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+
+		When("the policy is invalid", func() {
+			It("propagates error", func() {
+				err := uamDao.DeleteUser(uint(userID), AccountDeletionPolicy{GroupPolicy: "unknown"}, confirmed)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("request to fetch the user fails", func() {
 			BeforeEach(func() {
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users"`)).
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 					WithArgs(userID).
 					WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
 			})
 
 			It("propagates error", func() {
-				err := uamDao.DeleteUser(uint(userID))
+				err := uamDao.DeleteUser(uint(userID), AccountDeletionPolicy{}, confirmed)
 				Expect(err).To(HaveOccurred())
 				_, ok := err.(*myerr.ServerError)
 				Expect(ok).To(Equal(true))
@@ -168,65 +241,172 @@ var _ = Describe("UamDAO", func() {
 			})
 		})
 
-		When("request if user exists is succesful", func() {
-			Context("and user does not exist", func() {
+		When("the user does not exist", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(userID).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			})
+
+			It("propagates error", func() {
+				err := uamDao.DeleteUser(uint(userID), AccountDeletionPolicy{}, confirmed)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the deletion isnt confirmed", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(userID).
+					WillReturnRows(userRows)
+				mock.ExpectRollback()
+			})
+
+			It("propagates error", func() {
+				err := uamDao.DeleteUser(uint(userID), AccountDeletionPolicy{}, func(user models.User) error {
+					Expect(user.Username).To(Equal(username))
+					return myerr.NewClientError("Invalid password")
+				})
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the groups are transferred and the files reassigned", func() {
+			Context("and the group has other members", func() {
 				BeforeEach(func() {
-					rows := sqlmock.NewRows([]string{"count"}).AddRow(0)
-					mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users"`)).
+					mock.ExpectBegin()
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 						WithArgs(userID).
-						WillReturnRows(rows)
-
+						WillReturnRows(userRows)
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+						WithArgs(userID, true).
+						WillReturnRows(groupRows)
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+						WithArgs(groupID, userID).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "group_id", "user_id", "role"}).
+							AddRow(2, groupID, userID+1, models.RoleAdmin))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE "groups" SET "owner_id"`)).
+						WithArgs(userID+1, Any{}, groupID).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE "memberships" SET "role"`)).
+						WithArgs(models.RoleOwner, Any{}, 2).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE file_infos SET owner_id = groups.owner_id`)).
+						WithArgs(true, userID).
+						WillReturnResult(sqlmock.NewResult(0, 3))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE folders SET owner_id = groups.owner_id`)).
+						WithArgs(true, userID).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					expectMembershipsDeletion()
+					mock.ExpectCommit()
 				})
 
-				It("propagates error", func() {
-					err := uamDao.DeleteUser(uint(userID))
-					Expect(err).To(HaveOccurred())
-					_, ok := err.(*myerr.ItemNotFoundError)
-					Expect(ok).To(Equal(true))
+				It("makes the member with the highest role the owner", func() {
+					err := uamDao.DeleteUser(uint(userID), AccountDeletionPolicy{}, confirmed)
+					Expect(err).NotTo(HaveOccurred())
 					Expect(mock.ExpectationsWereMet()).To(BeNil())
 				})
 			})
 
-			Context("and user exists", func() {
-				var existCountRows *sqlmock.Rows
+			Context("and the group has no other members", func() {
 				BeforeEach(func() {
-					existCountRows = sqlmock.NewRows([]string{"count"}).AddRow(1)
+					mock.ExpectBegin()
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+						WithArgs(userID).
+						WillReturnRows(userRows)
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+						WithArgs(userID, true).
+						WillReturnRows(groupRows)
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+						WithArgs(groupID, userID).
+						WillReturnError(gorm.ErrRecordNotFound)
+					mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "memberships"`)).
+						WithArgs(groupID).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "invitations"`)).
+						WithArgs(groupID).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "join_requests"`)).
+						WithArgs(groupID).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE "groups" SET "active"`)).
+						WithArgs(false, Any{}, groupID).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE file_infos SET owner_id = groups.owner_id`)).
+						WithArgs(true, userID).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE folders SET owner_id = groups.owner_id`)).
+						WithArgs(true, userID).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					expectMembershipsDeletion()
+					mock.ExpectCommit()
 				})
 
-				Context("and deletion query fails", func() {
-					BeforeEach(func() {
-						mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users"`)).
-							WithArgs(userID).
-							WillReturnRows(existCountRows)
-						mock.ExpectExec("DELETE FROM \"users\"").
-							WithArgs(userID). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
-							WillReturnError(fmt.Errorf("some error"))
-					})
+				It("deactivates the group", func() {
+					err := uamDao.DeleteUser(uint(userID), AccountDeletionPolicy{}, confirmed)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
+		})
 
-					It("propagates error", func() {
-						err := uamDao.DeleteUser(userID)
-						Expect(err).To(HaveOccurred())
-						_, ok := err.(*myerr.ServerError)
-						Expect(ok).To(Equal(true))
-						Expect(mock.ExpectationsWereMet()).To(BeNil())
-					})
+		When("the files are deleted", func() {
+			Context("and moving them to the trash fails", func() {
+				BeforeEach(func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+						WithArgs(userID).
+						WillReturnRows(userRows)
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+						WithArgs(userID, true).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE "file_infos" SET "deleted_at"=$1,"deleted_by"=$2`)).
+						WithArgs(Any{}, userID, userID, true).
+						WillReturnError(fmt.Errorf("some error"))
+					mock.ExpectRollback()
 				})
 
-				Context("and deletion query is successful", func() {
-					BeforeEach(func() {
-						mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "users"`)).
-							WithArgs(userID).
-							WillReturnRows(existCountRows)
-						mock.ExpectExec("DELETE FROM \"users\"").
-							WithArgs(userID).
-							WillReturnResult(sqlmock.NewResult(0, 1))
-					})
+				It("propagates error", func() {
+					err := uamDao.DeleteUser(uint(userID), AccountDeletionPolicy{FilePolicy: FilePolicyDelete}, confirmed)
+					Expect(err).To(HaveOccurred())
+					_, ok := err.(*myerr.ServerError)
+					Expect(ok).To(Equal(true))
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
+				})
+			})
 
-					It("succeeds", func() {
-						err := uamDao.DeleteUser(uint(userID))
-						Expect(err).NotTo(HaveOccurred())
-						Expect(mock.ExpectationsWereMet()).To(BeNil())
-					})
+			Context("and moving them to the trash succeeds", func() {
+				BeforeEach(func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+						WithArgs(userID).
+						WillReturnRows(userRows)
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+						WithArgs(userID, true).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE "file_infos" SET "deleted_at"=$1,"deleted_by"=$2`)).
+						WithArgs(Any{}, userID, userID, true).
+						WillReturnResult(sqlmock.NewResult(0, 2))
+					mock.ExpectExec(regexp.QuoteMeta(`UPDATE folders SET owner_id = groups.owner_id`)).
+						WithArgs(true, userID).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					expectMembershipsDeletion()
+					mock.ExpectCommit()
+				})
+
+				It("succeeds", func() {
+					err := uamDao.DeleteUser(uint(userID), AccountDeletionPolicy{FilePolicy: FilePolicyDelete}, confirmed)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(mock.ExpectationsWereMet()).To(BeNil())
 				})
 			})
 		})
@@ -303,7 +483,7 @@ var _ = Describe("UamDAO", func() {
 			})
 
 			It("propagates error", func() {
-				err := uamDao.CreateGroup(uint(userID), groupName)
+				err := uamDao.CreateGroup(uint(userID), groupName, "")
 				Expect(err).To(HaveOccurred())
 				_, ok := err.(*myerr.ServerError)
 				Expect(ok).To(Equal(true))
@@ -324,7 +504,7 @@ var _ = Describe("UamDAO", func() {
 				})
 
 				It("propagates error", func() {
-					err := uamDao.CreateGroup(uint(userID), groupName)
+					err := uamDao.CreateGroup(uint(userID), groupName, "")
 					Expect(err).To(HaveOccurred())
 					_, ok := err.(*myerr.ClientError)
 					Expect(ok).To(Equal(true))
@@ -346,13 +526,13 @@ var _ = Describe("UamDAO", func() {
 							WithArgs(groupName).
 							WillReturnRows(zeroCountRows)
 						mock.ExpectQuery("INSERT INTO \"groups\"").
-							WithArgs(Any{}, Any{}, groupName, userID, true). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
+							WithArgs(Any{}, Any{}, groupName, userID, true, 0, models.VisibilityPrivate). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
 							WillReturnError(fmt.Errorf("some error"))
 						mock.ExpectRollback()
 					})
 
 					It("propagates error", func() {
-						err := uamDao.CreateGroup(uint(userID), groupName)
+						err := uamDao.CreateGroup(uint(userID), groupName, "")
 						Expect(err).To(HaveOccurred())
 						_, ok := err.(*myerr.ServerError)
 						Expect(ok).To(Equal(true))
@@ -380,16 +560,16 @@ var _ = Describe("UamDAO", func() {
 								WithArgs(groupName).
 								WillReturnRows(zeroCountRows)
 							mock.ExpectQuery("INSERT INTO \"groups\"").
-								WithArgs(Any{}, Any{}, groupName, userID, true). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
+								WithArgs(Any{}, Any{}, groupName, userID, true, 0, models.VisibilityPrivate). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
 								WillReturnRows(creationRows)
 							mock.ExpectQuery("INSERT INTO \"memberships\"").
-								WithArgs(Any{}, Any{}, group.ID, group.OwnerID). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
+								WithArgs(Any{}, Any{}, group.ID, group.OwnerID, models.RoleOwner). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
 								WillReturnError(fmt.Errorf("some error"))
 							mock.ExpectRollback()
 						})
 
 						It("propagates error", func() {
-							err := uamDao.CreateGroup(uint(userID), groupName)
+							err := uamDao.CreateGroup(uint(userID), groupName, "")
 							Expect(err).To(HaveOccurred())
 							_, ok := err.(*myerr.ServerError)
 							Expect(ok).To(Equal(true))
@@ -404,16 +584,16 @@ var _ = Describe("UamDAO", func() {
 								WithArgs(groupName).
 								WillReturnRows(zeroCountRows)
 							mock.ExpectQuery("INSERT INTO \"groups\"").
-								WithArgs(Any{}, Any{}, groupName, userID, true). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
+								WithArgs(Any{}, Any{}, groupName, userID, true, 0, models.VisibilityPrivate). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
 								WillReturnRows(creationRows)
 							mock.ExpectQuery("INSERT INTO \"memberships\"").
-								WithArgs(Any{}, Any{}, group.ID, group.OwnerID). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
+								WithArgs(Any{}, Any{}, group.ID, group.OwnerID, models.RoleOwner). // driver.NamedValue - {Name: Ordinal:1 Value:2020-12-28 01:22:59.344298 +0200 EET}"
 								WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 							mock.ExpectCommit()
 						})
 
 						It("succeeds", func() {
-							err := uamDao.CreateGroup(uint(userID), groupName)
+							err := uamDao.CreateGroup(uint(userID), groupName, "")
 							Expect(err).NotTo(HaveOccurred())
 							Expect(mock.ExpectationsWereMet()).To(BeNil())
 						})
@@ -423,7 +603,7 @@ var _ = Describe("UamDAO", func() {
 		})
 	})
 
-	Context("AddUserToGroup", func() {
+	Context("InviteUserToGroup", func() {
 		When("get group request fails", func() {
 			Context("and there is a problem with the database", func() {
 				BeforeEach(func() {
@@ -435,7 +615,7 @@ var _ = Describe("UamDAO", func() {
 				})
 
 				It("propagates error", func() {
-					err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+					err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 					Expect(err).To(HaveOccurred())
 					_, ok := err.(*myerr.ServerError)
 					Expect(ok).To(Equal(true))
@@ -453,7 +633,7 @@ var _ = Describe("UamDAO", func() {
 				})
 
 				It("propagates error", func() {
-					err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+					err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 					Expect(err).To(HaveOccurred())
 					_, ok := err.(*myerr.ItemNotFoundError)
 					Expect(ok).To(Equal(true))
@@ -475,7 +655,7 @@ var _ = Describe("UamDAO", func() {
 				})
 
 				It("propagates error", func() {
-					err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+					err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 					Expect(err).To(HaveOccurred())
 					_, ok := err.(*myerr.ClientError)
 					Expect(ok).To(Equal(true))
@@ -484,19 +664,22 @@ var _ = Describe("UamDAO", func() {
 			})
 
 			Context("and group is active", func() {
-				Context("and you arent the owner of the group", func() {
+				Context("and your role doesnt allow managing members", func() {
 					BeforeEach(func() {
 						rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active"}).
-							AddRow(1, time.Now(), time.Now(), groupName, userID+1, true)
+							AddRow(groupID, time.Now(), time.Now(), groupName, userID+1, true)
 						mock.ExpectBegin()
 						mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 							WithArgs(groupName).
 							WillReturnRows(rows)
+						mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+							WithArgs(userID, groupID).
+							WillReturnRows(membershipRows(userID, groupID, models.RoleEditor))
 						mock.ExpectRollback()
 					})
 
 					It("propagates error", func() {
-						err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+						err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 						Expect(err).To(HaveOccurred())
 						_, ok := err.(*myerr.ClientError)
 						Expect(ok).To(Equal(true))
@@ -504,7 +687,7 @@ var _ = Describe("UamDAO", func() {
 					})
 				})
 
-				Context("and you are the owner of the group", func() {
+				Context("and your role allows managing members", func() {
 					var groupRow *sqlmock.Rows
 					BeforeEach(func() {
 						groupRow = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active"}).
@@ -518,6 +701,9 @@ var _ = Describe("UamDAO", func() {
 								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 									WithArgs(groupName).
 									WillReturnRows(groupRow)
+								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+									WithArgs(userID, groupID).
+									WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 									WithArgs(username).
 									WillReturnError(fmt.Errorf("some error"))
@@ -525,7 +711,7 @@ var _ = Describe("UamDAO", func() {
 							})
 
 							It("propagates error", func() {
-								err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+								err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 								Expect(err).To(HaveOccurred())
 								_, ok := err.(*myerr.ServerError)
 								Expect(ok).To(Equal(true))
@@ -539,6 +725,9 @@ var _ = Describe("UamDAO", func() {
 								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 									WithArgs(groupName).
 									WillReturnRows(groupRow)
+								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+									WithArgs(userID, groupID).
+									WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 									WithArgs(username).
 									WillReturnError(gorm.ErrRecordNotFound)
@@ -546,7 +735,7 @@ var _ = Describe("UamDAO", func() {
 							})
 
 							It("propagates error", func() {
-								err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+								err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 								Expect(err).To(HaveOccurred())
 								_, ok := err.(*myerr.ItemNotFoundError)
 								Expect(ok).To(Equal(true))
@@ -570,6 +759,9 @@ var _ = Describe("UamDAO", func() {
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 										WithArgs(groupName).
 										WillReturnRows(groupRow)
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(userID, groupID).
+										WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 										WithArgs(username).
 										WillReturnRows(userRows)
@@ -580,7 +772,7 @@ var _ = Describe("UamDAO", func() {
 								})
 
 								It("propagates error", func() {
-									err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+									err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 									Expect(err).To(HaveOccurred())
 									_, ok := err.(*myerr.ServerError)
 									Expect(ok).To(Equal(true))
@@ -593,6 +785,9 @@ var _ = Describe("UamDAO", func() {
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 										WithArgs(groupName).
 										WillReturnRows(groupRow)
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(userID, groupID).
+										WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 										WithArgs(username).
 										WillReturnRows(userRows)
@@ -603,7 +798,7 @@ var _ = Describe("UamDAO", func() {
 								})
 
 								It("propagates error", func() {
-									err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+									err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 									Expect(err).To(HaveOccurred())
 									_, ok := err.(*myerr.ClientError)
 									Expect(ok).To(Equal(true))
@@ -613,26 +808,62 @@ var _ = Describe("UamDAO", func() {
 						})
 
 						Context("and request if membership exists is successful", func() {
-							Context("and creation of membership fails", func() {
+							Context("and the user is already invited", func() {
 								BeforeEach(func() {
 									mock.ExpectBegin()
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 										WithArgs(groupName).
 										WillReturnRows(groupRow)
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(userID, groupID).
+										WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 										WithArgs(username).
 										WillReturnRows(userRows)
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "memberships"`)).
 										WithArgs(groupID, userID).
 										WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
-									mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "memberships"`)).
-										WithArgs(Any{}, Any{}, groupID, userID).
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "invitations"`)).
+										WithArgs(userID, groupID, Any{}).
+										WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+									mock.ExpectRollback()
+								})
+
+								It("propagates error", func() {
+									err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
+									Expect(err).To(HaveOccurred())
+									_, ok := err.(*myerr.ClientError)
+									Expect(ok).To(Equal(true))
+									Expect(mock.ExpectationsWereMet()).To(BeNil())
+								})
+							})
+
+							Context("and creation of invitation fails", func() {
+								BeforeEach(func() {
+									mock.ExpectBegin()
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+										WithArgs(groupName).
+										WillReturnRows(groupRow)
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(userID, groupID).
+										WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+										WithArgs(username).
+										WillReturnRows(userRows)
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "memberships"`)).
+										WithArgs(groupID, userID).
+										WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "invitations"`)).
+										WithArgs(userID, groupID, Any{}).
+										WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+									mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "invitations"`)).
+										WithArgs(Any{}, groupID, userID, userID, models.RoleEditor, nil).
 										WillReturnError(fmt.Errorf("some error"))
 									mock.ExpectRollback()
 								})
 
 								It("propagates error", func() {
-									err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+									err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 									Expect(err).To(HaveOccurred())
 									_, ok := err.(*myerr.ServerError)
 									Expect(ok).To(Equal(true))
@@ -645,20 +876,26 @@ var _ = Describe("UamDAO", func() {
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 										WithArgs(groupName).
 										WillReturnRows(groupRow)
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(userID, groupID).
+										WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 										WithArgs(username).
 										WillReturnRows(userRows)
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "memberships"`)).
 										WithArgs(groupID, userID).
 										WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
-									mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "memberships"`)).
-										WithArgs(Any{}, Any{}, groupID, userID).
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "invitations"`)).
+										WithArgs(userID, groupID, Any{}).
+										WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+									mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "invitations"`)).
+										WithArgs(Any{}, groupID, userID, userID, models.RoleEditor, nil).
 										WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 									mock.ExpectCommit()
 								})
 
 								It("returns no error", func() {
-									err := uamDao.AddUserToGroup(uint(userID), username, groupName)
+									err := uamDao.InviteUserToGroup(uint(userID), username, groupName, "", nil)
 									Expect(err).NotTo(HaveOccurred())
 									Expect(mock.ExpectationsWereMet()).To(BeNil())
 								})
@@ -797,7 +1034,7 @@ var _ = Describe("UamDAO", func() {
 							AddRow(targetUserID, time.Now(), time.Now(), username, password)
 					})
 
-					Context("and you arent the owner of the group or the targeted user", func() {
+					Context("and your role doesnt allow managing members and you arent the targeted user", func() {
 						BeforeEach(func() {
 							mock.ExpectBegin()
 							mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
@@ -806,6 +1043,9 @@ var _ = Describe("UamDAO", func() {
 							mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 								WithArgs(username).
 								WillReturnRows(userRows)
+							mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+								WithArgs(userID+2, groupID).
+								WillReturnRows(membershipRows(userID+2, groupID, models.RoleEditor))
 							mock.ExpectRollback()
 						})
 
@@ -843,7 +1083,7 @@ var _ = Describe("UamDAO", func() {
 						})
 					})
 
-					Context("and you are the owner(not the targeted user) or the targeted users", func() {
+					Context("and you outrank the targeted user", func() {
 						Context("and delete membership request fails", func() {
 							BeforeEach(func() {
 								mock.ExpectBegin()
@@ -853,6 +1093,12 @@ var _ = Describe("UamDAO", func() {
 								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 									WithArgs(username).
 									WillReturnRows(userRows)
+								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+									WithArgs(userID, groupID).
+									WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+									WithArgs(targetUserID, groupID).
+									WillReturnRows(membershipRows(targetUserID, groupID, models.RoleEditor))
 								mock.ExpectExec("DELETE FROM \"memberships\"").
 									WithArgs(targetUserID, groupID).
 									WillReturnError(fmt.Errorf("some error"))
@@ -877,6 +1123,12 @@ var _ = Describe("UamDAO", func() {
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 										WithArgs(username).
 										WillReturnRows(userRows)
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(userID, groupID).
+										WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(targetUserID, groupID).
+										WillReturnRows(membershipRows(targetUserID, groupID, models.RoleEditor))
 									mock.ExpectExec("DELETE FROM \"memberships\"").
 										WithArgs(targetUserID, groupID).
 										WillReturnResult(sqlmock.NewResult(0, 0))
@@ -901,6 +1153,12 @@ var _ = Describe("UamDAO", func() {
 									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
 										WithArgs(username).
 										WillReturnRows(userRows)
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(userID, groupID).
+										WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+									mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+										WithArgs(targetUserID, groupID).
+										WillReturnRows(membershipRows(targetUserID, groupID, models.RoleEditor))
 									mock.ExpectExec("DELETE FROM \"memberships\"").
 										WithArgs(targetUserID, groupID).
 										WillReturnResult(sqlmock.NewResult(0, 1))
@@ -922,6 +1180,360 @@ var _ = Describe("UamDAO", func() {
 
 	})
 
+	Context("SetGroupVisibility", func() {
+		When("the visibility is invalid", func() {
+			It("returns client error", func() {
+				err := uamDao.SetGroupVisibility(uint(userID), groupName, "public")
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+			})
+		})
+
+		When("the group becomes private", func() {
+			BeforeEach(func() {
+				groupRow := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active", "visibility"}).
+					AddRow(groupID, time.Now(), time.Now(), groupName, userID, true, models.VisibilityDiscoverable)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "groups" SET "visibility"`)).
+					WithArgs(models.VisibilityPrivate, Any{}, groupID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "join_requests"`)).
+					WithArgs(groupID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("removes the pending join requests", func() {
+				err := uamDao.SetGroupVisibility(uint(userID), groupName, models.VisibilityPrivate)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Context("RequestToJoinGroup", func() {
+		groupRowWithVisibility := func(visibility string) *sqlmock.Rows {
+			return sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active", "visibility"}).
+				AddRow(groupID, time.Now(), time.Now(), groupName, userID+1, true, visibility)
+		}
+
+		When("the group is private", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRowWithVisibility(models.VisibilityPrivate))
+				mock.ExpectRollback()
+			})
+
+			It("hides the group", func() {
+				_, err := uamDao.RequestToJoinGroup(uint(userID), groupName)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(Equal(true))
+			})
+		})
+
+		When("the group is open", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRowWithVisibility(models.VisibilityOpen))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "memberships"`)).
+					WithArgs(groupID, userID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "memberships"`)).
+					WithArgs(Any{}, Any{}, groupID, userID, models.RoleViewer).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			})
+
+			It("makes the user a viewer", func() {
+				joined, err := uamDao.RequestToJoinGroup(uint(userID), groupName)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(joined).To(BeTrue())
+			})
+		})
+
+		When("the group is discoverable", func() {
+			Context("and the user already requested to join", func() {
+				BeforeEach(func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+						WithArgs(groupName).
+						WillReturnRows(groupRowWithVisibility(models.VisibilityDiscoverable))
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "memberships"`)).
+						WithArgs(groupID, userID).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "join_requests"`)).
+						WithArgs(groupID, userID).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				})
+
+				It("returns client error", func() {
+					_, err := uamDao.RequestToJoinGroup(uint(userID), groupName)
+					Expect(err).To(HaveOccurred())
+					_, ok := err.(*myerr.ClientError)
+					Expect(ok).To(Equal(true))
+				})
+			})
+
+			Context("and the user didnt request to join yet", func() {
+				BeforeEach(func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+						WithArgs(groupName).
+						WillReturnRows(groupRowWithVisibility(models.VisibilityDiscoverable))
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "memberships"`)).
+						WithArgs(groupID, userID).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(1) FROM "join_requests"`)).
+						WithArgs(groupID, userID).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "join_requests"`)).
+						WithArgs(Any{}, groupID, userID).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				})
+
+				It("creates a join request", func() {
+					joined, err := uamDao.RequestToJoinGroup(uint(userID), groupName)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(joined).To(BeFalse())
+				})
+			})
+		})
+	})
+
+	Context("ResolveJoinRequest", func() {
+		var groupRow, userRows *sqlmock.Rows
+		const requesterID = userID + 1
+
+		BeforeEach(func() {
+			groupRow = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active", "visibility"}).
+				AddRow(groupID, time.Now(), time.Now(), groupName, userID, true, models.VisibilityDiscoverable)
+			userRows = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "username", "password"}).
+				AddRow(requesterID, time.Now(), time.Now(), username, password)
+		})
+
+		When("the role of the current user doesnt allow approving admins", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleAdmin))
+				mock.ExpectRollback()
+			})
+
+			It("returns client error", func() {
+				err := uamDao.ResolveJoinRequest(uint(userID), groupName, username, true, models.RoleAdmin)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+			})
+		})
+
+		When("there is no join request", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(username).
+					WillReturnRows(userRows)
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "join_requests"`)).
+					WithArgs(requesterID, groupID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			})
+
+			It("returns item not found error", func() {
+				err := uamDao.ResolveJoinRequest(uint(userID), groupName, username, false, "")
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(Equal(true))
+			})
+		})
+
+		When("the join request is approved", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(username).
+					WillReturnRows(userRows)
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "join_requests"`)).
+					WithArgs(requesterID, groupID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "memberships"`)).
+					WithArgs(Any{}, Any{}, groupID, requesterID, models.RoleEditor).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectCommit()
+			})
+
+			It("makes the user a member", func() {
+				err := uamDao.ResolveJoinRequest(uint(userID), groupName, username, true, "")
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Context("GetInvitations", func() {
+		When("the invitations are fetched", func() {
+			BeforeEach(func() {
+				rows := sqlmock.NewRows([]string{"id", "group_name", "inviter", "role", "created_at", "expires_at"}).
+					AddRow(3, groupName, username, models.RoleViewer, time.Now(), nil)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT invitations.id, groups.name AS group_name`)).
+					WithArgs(userID, Any{}).
+					WillReturnRows(rows)
+			})
+
+			It("returns the pending invitations", func() {
+				invitations, err := uamDao.GetInvitations(uint(userID))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(invitations).To(HaveLen(1))
+				Expect(invitations[0].GroupName).To(Equal(groupName))
+				Expect(invitations[0].Inviter).To(Equal(username))
+				Expect(invitations[0].Role).To(Equal(models.RoleViewer))
+				Expect(invitations[0].ExpiresAt).To(BeNil())
+			})
+		})
+	})
+
+	Context("AcceptInvitation", func() {
+		var groupRow *sqlmock.Rows
+		BeforeEach(func() {
+			groupRow = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active"}).
+				AddRow(groupID, time.Now(), time.Now(), groupName, userID+1, true)
+		})
+
+		When("there is no pending invitation", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "invitations"`)).
+					WithArgs(userID, groupID, Any{}).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			})
+
+			It("returns item not found error", func() {
+				err := uamDao.AcceptInvitation(uint(userID), groupName)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(Equal(true))
+			})
+		})
+
+		When("the invitation is pending", func() {
+			BeforeEach(func() {
+				invitationRows := sqlmock.NewRows([]string{"id", "created_at", "group_id", "user_id", "inviter_id", "role", "expires_at"}).
+					AddRow(3, time.Now(), groupID, userID, userID+1, models.RoleViewer, nil)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "invitations"`)).
+					WithArgs(userID, groupID, Any{}).
+					WillReturnRows(invitationRows)
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "memberships"`)).
+					WithArgs(Any{}, Any{}, groupID, userID, models.RoleViewer).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "invitations"`)).
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("creates the membership with the role from the invitation", func() {
+				err := uamDao.AcceptInvitation(uint(userID), groupName)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Context("DeclineInvitation", func() {
+		BeforeEach(func() {
+			groupRow := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active"}).
+				AddRow(groupID, time.Now(), time.Now(), groupName, userID+1, true)
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+				WithArgs(groupName).
+				WillReturnRows(groupRow)
+		})
+
+		When("there is no invitation", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "invitations"`)).
+					WithArgs(userID, groupID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			})
+
+			It("returns item not found error", func() {
+				err := uamDao.DeclineInvitation(uint(userID), groupName)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(Equal(true))
+			})
+		})
+
+		When("the invitation exists", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "invitations"`)).
+					WithArgs(userID, groupID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("returns no error", func() {
+				err := uamDao.DeclineInvitation(uint(userID), groupName)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Context("DeleteExpiredInvitations", func() {
+		When("there are expired invitations", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "invitations" WHERE expires_at <= $1`)).
+					WithArgs(Any{}).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			})
+
+			It("returns the count of deleted invitations", func() {
+				count, err := uamDao.DeleteExpiredInvitations(time.Now())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(Equal(int64(2)))
+			})
+		})
+	})
+
 	Context("DeactivateGroup", func() {
 		When("get group request fails", func() {
 			Context("problem with the database", func() {
@@ -995,6 +1607,9 @@ var _ = Describe("UamDAO", func() {
 						mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 							WithArgs(groupName).
 							WillReturnRows(groupRow)
+						mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+							WithArgs(userID+2, groupID).
+							WillReturnRows(membershipRows(userID+2, groupID, models.RoleAdmin))
 						mock.ExpectRollback()
 					})
 
@@ -1015,6 +1630,9 @@ var _ = Describe("UamDAO", func() {
 							mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 								WithArgs(groupName).
 								WillReturnRows(groupRow)
+							mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+								WithArgs(userID, groupID).
+								WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 							mock.ExpectExec("DELETE FROM \"memberships\"").
 								WithArgs(groupID).
 								WillReturnError(fmt.Errorf("some error"))
@@ -1036,9 +1654,18 @@ var _ = Describe("UamDAO", func() {
 								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 									WithArgs(groupName).
 									WillReturnRows(groupRow)
+								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+									WithArgs(userID, groupID).
+									WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 								mock.ExpectExec("DELETE FROM \"memberships\"").
 									WithArgs(groupID).
 									WillReturnResult(sqlmock.NewResult(0, 1))
+								mock.ExpectExec("DELETE FROM \"invitations\"").
+									WithArgs(groupID).
+									WillReturnResult(sqlmock.NewResult(0, 0))
+								mock.ExpectExec("DELETE FROM \"join_requests\"").
+									WithArgs(groupID).
+									WillReturnResult(sqlmock.NewResult(0, 0))
 								mock.ExpectExec("UPDATE \"groups\"").
 									WithArgs(false, Any{}, groupID).
 									WillReturnError(fmt.Errorf("some error"))
@@ -1059,9 +1686,18 @@ var _ = Describe("UamDAO", func() {
 								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
 									WithArgs(groupName).
 									WillReturnRows(groupRow)
+								mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+									WithArgs(userID, groupID).
+									WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
 								mock.ExpectExec("DELETE FROM \"memberships\"").
 									WithArgs(groupID).
 									WillReturnResult(sqlmock.NewResult(0, 1))
+								mock.ExpectExec("DELETE FROM \"invitations\"").
+									WithArgs(groupID).
+									WillReturnResult(sqlmock.NewResult(0, 0))
+								mock.ExpectExec("DELETE FROM \"join_requests\"").
+									WithArgs(groupID).
+									WillReturnResult(sqlmock.NewResult(0, 0))
 								mock.ExpectExec("UPDATE \"groups\"").
 									WithArgs(false, Any{}, groupID).
 									WillReturnResult(sqlmock.NewResult(0, 1))
@@ -1080,6 +1716,212 @@ var _ = Describe("UamDAO", func() {
 		})
 	})
 
+	Context("ChangeMemberRole", func() {
+		var (
+			groupRow     *sqlmock.Rows
+			userRows     *sqlmock.Rows
+			targetUserID uint
+		)
+
+		BeforeEach(func() {
+			targetUserID = userID + 1
+			groupRow = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active"}).
+				AddRow(groupID, time.Now(), time.Now(), groupName, userID, true)
+			userRows = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "username", "password"}).
+				AddRow(targetUserID, time.Now(), time.Now(), username, password)
+		})
+
+		When("the role is invalid", func() {
+			It("propagates error", func() {
+				err := uamDao.ChangeMemberRole(uint(userID), username, groupName, models.RoleOwner)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+			})
+		})
+
+		When("an admin tries to promote a member to admin", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(username).
+					WillReturnRows(userRows)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleAdmin))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(targetUserID, groupID).
+					WillReturnRows(membershipRows(targetUserID, groupID, models.RoleEditor))
+				mock.ExpectRollback()
+			})
+
+			It("propagates error", func() {
+				err := uamDao.ChangeMemberRole(uint(userID), username, groupName, models.RoleAdmin)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the targeted user isnt a member of the group", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(username).
+					WillReturnRows(userRows)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(targetUserID, groupID).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			})
+
+			It("propagates error", func() {
+				err := uamDao.ChangeMemberRole(uint(userID), username, groupName, models.RoleAdmin)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ItemNotFoundError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the owner promotes an editor to admin", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(username).
+					WillReturnRows(userRows)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(targetUserID, groupID).
+					WillReturnRows(membershipRows(targetUserID, groupID, models.RoleEditor))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "memberships" SET "role"`)).
+					WithArgs(models.RoleAdmin, Any{}, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("succeeds", func() {
+				err := uamDao.ChangeMemberRole(uint(userID), username, groupName, models.RoleAdmin)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
+	Context("TransferOwnership", func() {
+		var (
+			groupRow     *sqlmock.Rows
+			userRows     *sqlmock.Rows
+			targetUserID uint
+		)
+
+		BeforeEach(func() {
+			targetUserID = userID + 1
+			groupRow = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active"}).
+				AddRow(groupID, time.Now(), time.Now(), groupName, userID, true)
+			userRows = sqlmock.NewRows([]string{"id", "created_at", "updated_at", "username", "password"}).
+				AddRow(targetUserID, time.Now(), time.Now(), username, password)
+		})
+
+		When("you arent the owner of the group", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleAdmin))
+				mock.ExpectRollback()
+			})
+
+			It("propagates error", func() {
+				err := uamDao.TransferOwnership(uint(userID), username, groupName)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the user isnt a member of the group", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(username).
+					WillReturnRows(userRows)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(targetUserID, groupID).
+					WillReturnError(gorm.ErrRecordNotFound)
+				mock.ExpectRollback()
+			})
+
+			It("propagates error", func() {
+				err := uamDao.TransferOwnership(uint(userID), username, groupName)
+				Expect(err).To(HaveOccurred())
+				_, ok := err.(*myerr.ClientError)
+				Expect(ok).To(Equal(true))
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+
+		When("the user is a member of the group", func() {
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+					WithArgs(groupName).
+					WillReturnRows(groupRow)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(userID, groupID).
+					WillReturnRows(membershipRows(userID, groupID, models.RoleOwner))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+					WithArgs(username).
+					WillReturnRows(userRows)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "memberships"`)).
+					WithArgs(targetUserID, groupID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "group_id", "user_id", "role"}).
+						AddRow(2, groupID, targetUserID, models.RoleEditor))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "groups" SET "owner_id"`)).
+					WithArgs(targetUserID, Any{}, groupID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "memberships" SET "role"`)).
+					WithArgs(models.RoleOwner, Any{}, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE "memberships" SET "role"`)).
+					WithArgs(models.RoleAdmin, Any{}, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			})
+
+			It("makes the user the owner and the former owner an admin", func() {
+				err := uamDao.TransferOwnership(uint(userID), username, groupName)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mock.ExpectationsWereMet()).To(BeNil())
+			})
+		})
+	})
+
 	Context("MemberExists", func() {
 		When("request to check count of memberships with given user id and group name", func() {
 			Context("and request fails", func() {
@@ -1288,7 +2130,7 @@ var _ = Describe("UamDAO", func() {
 				})
 
 				It("propagates error", func() {
-					_, err := uamDao.GetAllGroups()
+					_, err := uamDao.GetAllGroups(uint(userID))
 					Expect(err).To(HaveOccurred())
 					_, ok := err.(*myerr.ServerError)
 					Expect(ok).To(Equal(true))
@@ -1304,7 +2146,7 @@ var _ = Describe("UamDAO", func() {
 					})
 
 					It("propagates error", func() {
-						groups, err := uamDao.GetAllGroups()
+						groups, err := uamDao.GetAllGroups(uint(userID))
 						Expect(err).ToNot(HaveOccurred())
 						Expect(groups).To(BeEmpty())
 						Expect(mock.ExpectationsWereMet()).To(BeNil())
@@ -1315,12 +2157,13 @@ var _ = Describe("UamDAO", func() {
 					BeforeEach(func() {
 						mockTime := time.Now()
 						rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "name", "owner_id", "active"}).AddRow(1, mockTime, mockTime, groupName, userID, true)
-						mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups"`)).
+						mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "groups" WHERE active = $1 AND (visibility <> $2 OR id IN (SELECT group_id FROM "memberships" WHERE user_id = $3))`)).
+							WithArgs(true, models.VisibilityPrivate, userID).
 							WillReturnRows(rows)
 					})
 
 					It("succeds", func() {
-						groups, err := uamDao.GetAllGroups()
+						groups, err := uamDao.GetAllGroups(uint(userID))
 						Expect(err).ToNot(HaveOccurred())
 						Expect(len(groups)).To(Equal(1))
 						Expect(groups[0].Name).To(Equal(groupName))
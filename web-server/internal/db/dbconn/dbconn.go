@@ -33,11 +33,12 @@ func GetDBConn(creator func(string) gorm.Dialector) (*gorm.DB, error) {
 		return dbConn, nil
 	}
 
-	dbConn, err := gorm.Open(creator(getDBDns()), &gorm.Config{})
+	conn, err := gorm.Open(creator(getDBDns()), &gorm.Config{})
 	if err != nil {
 		return nil, myerr.NewServerErrorWrap(err, "Cannot create a connection to the database.")
 	}
 
+	dbConn = conn
 	return dbConn, nil
 
 }
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	//OutcomeSuccess - the audited request succeeded
+	OutcomeSuccess = "success"
+	//OutcomeFailure - the audited request was rejected or failed
+	OutcomeFailure = "failure"
+)
+
+//AuditEvent is a model representing a security or data event. The table is append-only, the events are never changed or deleted
+type AuditEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+	//ActorID - the user, who made the request. 0 for the requests without an account, like the logins and the downloads through share links
+	ActorID uint   `gorm:"type:Integer;not null;default:0;index"`
+	Action  string `gorm:"type:varchar(64);not null;index"`
+	//GroupName, FileID and TargetUser - the targets of the action, if known. Names are kept instead of ids, because the groups and the users are deleted
+	GroupName  string `gorm:"type:varchar(256);not null;default:'';index"`
+	FileID     uint   `gorm:"type:Integer;not null;default:0"`
+	TargetUser string `gorm:"type:varchar(256);not null;default:''"`
+	IP         string `gorm:"type:varchar(64);not null;default:''"`
+	Outcome    string `gorm:"type:varchar(16);not null"`
+	StatusCode int    `gorm:"type:Integer;not null"`
+}
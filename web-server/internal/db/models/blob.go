@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+//Blob is a model representing a stored content, which is shared by all files with the same checksum
+type Blob struct {
+	Checksum  string `gorm:"primarykey;type:varchar(64)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Size      int64 `gorm:"type:bigint;not null"`
+	RefCount  int64 `gorm:"type:bigint;not null;default:0"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+//DropBox is a model representing an upload-only link, through which people without an account send files to a folder of the group. Only the hash of the token is stored
+type DropBox struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	TokenHash string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	GroupID   uint   `gorm:"type:Integer;not null;index"`
+	FolderID  uint   `gorm:"type:Integer;not null;default:0"`
+	//UserID - the user, who created the drop box. The received files dont belong to any user, but to the group
+	UserID uint `gorm:"type:Integer;not null;index"`
+	//MaxFileSize - the maximum size of every received file in bytes. 0 means unlimited
+	MaxFileSize int64 `gorm:"type:bigint;not null;default:0"`
+	//MaxFiles - how many files can be received. 0 means unlimited
+	MaxFiles uint `gorm:"type:Integer;not null;default:0"`
+	//ExpiresAt - nil means that the drop box doesnt expire
+	ExpiresAt *time.Time
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 //FileInfo is a model representing the most important info for a file
 type FileInfo struct {
@@ -9,4 +13,20 @@ type FileInfo struct {
 	Name      string `gorm:"type:varchar(256);not null"`
 	OwnerID   uint   `gorm:"type:Integer;not null"`
 	GroupID   uint   `gorm:"type:Integer;not null"`
+	FolderID  uint   `gorm:"type:Integer;not null;default:0"`
+	Checksum  string `gorm:"type:varchar(64)"`
+	Size      int64  `gorm:"type:bigint;not null;default:0"`
+	//Version - number of the version among all files with the same name in the folder
+	Version uint `gorm:"type:Integer;not null;default:1"`
+	//Deduplicated - whether the content is kept in the blob with the same checksum, instead of under the group
+	Deduplicated bool `gorm:"type:boolean;not null;default:false"`
+	//DeletedAt - when the file was moved to the trash of the group. Trashed files are excluded from the queries, unless they are unscoped
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	//DeletedBy - the user, who moved the file to the trash
+	DeletedBy uint `gorm:"type:Integer;not null;default:0"`
+	//DropBoxID - the drop box, through which the file was received from someone without an account. 0 for the files, uploaded by members
+	DropBoxID uint `gorm:"type:Integer;not null;default:0;index"`
+	//UploaderName and UploaderEmail - supplied by the sender of a file, received through a drop box
+	UploaderName  string `gorm:"type:varchar(128);not null;default:''"`
+	UploaderEmail string `gorm:"type:varchar(256);not null;default:''"`
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+//Folder is a model representing a folder inside a group. Folders with ParentID 0 are in the root of the group
+type Folder struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string `gorm:"type:varchar(256);not null"`
+	GroupID   uint   `gorm:"type:Integer;not null"`
+	ParentID  uint   `gorm:"type:Integer;not null;default:0"`
+	OwnerID   uint   `gorm:"type:Integer;not null"`
+}
@@ -2,6 +2,15 @@ package models
 
 import "time"
 
+const (
+	//VisibilityPrivate - the group is seen only by its members and users join it only by invitation
+	VisibilityPrivate = "private"
+	//VisibilityDiscoverable - the group is seen by every user, who can request to join it
+	VisibilityDiscoverable = "discoverable"
+	//VisibilityOpen - the group is seen by every user, who can join it without approval
+	VisibilityOpen = "open"
+)
+
 //Group is a model representing a record in the table of groups
 type Group struct {
 	ID        uint `gorm:"primarykey"`
@@ -10,4 +19,8 @@ type Group struct {
 	Name      string `gorm:"type:varchar(256);not null"`
 	OwnerID   uint   `gorm:"type:Integer;not null"`
 	Active    bool   `gorm:"type:boolean;not null;default:true"`
+	//MaxFileVersions - how many versions of a file are kept, 0 means the default of the server
+	MaxFileVersions uint `gorm:"type:Integer;not null;default:0"`
+	//Visibility - one of private, discoverable and open, which determines who can see and join the group
+	Visibility string `gorm:"type:varchar(16);not null;default:private"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+//Invitation is a model representing a pending invitation of a user to become a member of a group
+type Invitation struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	GroupID   uint   `gorm:"type:bigint;not null"`
+	UserID    uint   `gorm:"type:bigint;not null"`
+	InviterID uint   `gorm:"type:bigint;not null"`
+	Role      string `gorm:"type:varchar(16);not null"`
+	//ExpiresAt - after that moment the invitation cannot be accepted. Nil means that the invitation doesnt expire
+	ExpiresAt *time.Time
+}
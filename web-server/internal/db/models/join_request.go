@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+//JoinRequest is a model representing a pending request of a user to become a member of a discoverable group
+type JoinRequest struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	GroupID   uint `gorm:"type:bigint;not null"`
+	UserID    uint `gorm:"type:bigint;not null"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	//LoginFailureUsername - failed logins with a username
+	LoginFailureUsername = "username"
+	//LoginFailureIP - failed logins from a client IP
+	LoginFailureIP = "ip"
+)
+
+//LoginFailure is a model representing the recent failed logins with a username or from a client IP, which are used for slowing down password guessing
+type LoginFailure struct {
+	ID uint `gorm:"primarykey"`
+	//Kind - whether the failures are counted for a username or for a client IP
+	Kind       string `gorm:"type:varchar(16);not null;uniqueIndex:idx_login_failure_subject"`
+	Identifier string `gorm:"type:varchar(255);not null;uniqueIndex:idx_login_failure_subject"`
+	Failures   uint   `gorm:"type:integer;not null;default:0"`
+	//BlockedUntil - no login is allowed with the username or from the IP before that moment
+	BlockedUntil time.Time `gorm:"not null"`
+	//ExpiresAt - after that moment the failures are forgotten
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
@@ -2,6 +2,17 @@ package models
 
 import "time"
 
+const (
+	//RoleOwner - role of the member, who created the group or to whom it was transferred. Can do everything in the group
+	RoleOwner = "owner"
+	//RoleAdmin - role of a member, who can manage the other members and all files of the group
+	RoleAdmin = "admin"
+	//RoleEditor - role of a member, who can upload files and change its own files
+	RoleEditor = "editor"
+	//RoleViewer - role of a member, who can only list and download files
+	RoleViewer = "viewer"
+)
+
 //Membership is a model representing a record in the table of Memberships
 type Membership struct {
 	ID        uint `gorm:"primarykey"`
@@ -9,4 +20,6 @@ type Membership struct {
 	UpdatedAt time.Time
 	GroupID   uint `gorm:"type:bigint;not null"`
 	UserID    uint `gorm:"type:bigint;not null"`
+	//Role - one of owner, admin, editor and viewer, which determines what the member can do in the group
+	Role string `gorm:"type:varchar(16);not null;default:editor"`
 }
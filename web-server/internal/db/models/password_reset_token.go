@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+//PasswordResetToken is a model representing a one-time token, issued by an administrator, with which a user sets a new password without knowing the old one. Only the hash of the token is stored
+type PasswordResetToken struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint      `gorm:"type:bigint;not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	//ScopeRead - the personal access token can only list and download, it is limited to GET requests
+	ScopeRead = "read"
+	//ScopeUpload - the personal access token can upload files
+	ScopeUpload = "upload"
+	//ScopeWrite - the personal access token can do everything, which its user can, except managing the account
+	ScopeWrite = "write"
+)
+
+//PersonalAccessToken is a model representing a named token, created by a user for scripts and CI. Only the hash of the token is stored
+type PersonalAccessToken struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint   `gorm:"type:bigint;not null;uniqueIndex:idx_personal_access_token_name"`
+	Name      string `gorm:"type:varchar(64);not null;uniqueIndex:idx_personal_access_token_name"`
+	TokenHash string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	//Scopes - comma separated list of read, upload and write, which determines what the token can be used for
+	Scopes string `gorm:"type:varchar(64);not null"`
+	//GroupRestricted - the token can be used only in the groups from PersonalAccessTokenGroup
+	GroupRestricted bool      `gorm:"type:boolean;not null;default:false"`
+	ExpiresAt       time.Time `gorm:"not null"`
+	LastUsedAt      *time.Time
+}
+
+//PersonalAccessTokenGroup is a model representing a group, to which a personal access token is limited
+type PersonalAccessTokenGroup struct {
+	TokenID uint `gorm:"type:bigint;primarykey"`
+	GroupID uint `gorm:"type:bigint;primarykey"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+//RefreshToken is a model representing a refresh token, issued to a user at login. Only the hash of the token is stored
+type RefreshToken struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint      `gorm:"type:bigint;not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	//RevokedAt - the moment the token was used or the user logged out. A revoked token cannot be used again
+	RevokedAt *time.Time
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+//RevokedToken is a model representing an access token, which was revoked before its expiration
+type RevokedToken struct {
+	//TokenID - the unique id (jti) of the access token
+	TokenID   string    `gorm:"type:varchar(64);primarykey"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+//ShareLink is a model representing a link, through which a file can be downloaded without an account. Only the hash of the token is stored
+type ShareLink struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	TokenHash string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	FileID    uint   `gorm:"type:Integer;not null;index"`
+	GroupID   uint   `gorm:"type:Integer;not null;index"`
+	//UserID - the user, who created the link
+	UserID uint `gorm:"type:Integer;not null;index"`
+	//PasswordHash - bcrypt hash of the password, which has to be sent together with the link. Empty, if the link isnt password-protected
+	PasswordHash string `gorm:"type:varchar(60);not null;default:''"`
+	//ExpiresAt - nil means that the link doesnt expire
+	ExpiresAt *time.Time
+	//MaxDownloads - 0 means that the file can be downloaded unlimited number of times
+	MaxDownloads uint `gorm:"type:Integer;not null;default:0"`
+	Downloads    uint `gorm:"type:Integer;not null;default:0"`
+	//PasswordFailures - the number of wrong passwords, sent for the link. The link is locked, after too many of them
+	PasswordFailures uint `gorm:"type:Integer;not null;default:0"`
+}
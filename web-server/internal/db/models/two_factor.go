@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+//TwoFactor is a model representing the TOTP two-factor authentication of a user
+type TwoFactor struct {
+	UserID    uint `gorm:"type:bigint;primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	//Secret - base32 encoded secret, shared with the authenticator app of the user
+	Secret string `gorm:"type:varchar(64);not null"`
+	//Enabled - the enrollment was confirmed with a code. Only then a code is required at login
+	Enabled bool `gorm:"type:boolean;not null;default:false"`
+	//LastUsedStep - the time step of the last accepted code, so the same code cannot be used twice
+	LastUsedStep int64 `gorm:"type:bigint;not null;default:0"`
+}
+
+//RecoveryCode is a model representing a one-time code, which replaces the TOTP code, when the authenticator app is lost. Only the hash of the code is stored
+type RecoveryCode struct {
+	ID       uint   `gorm:"primarykey"`
+	UserID   uint   `gorm:"type:bigint;not null;index"`
+	CodeHash string `gorm:"type:varchar(64);not null"`
+	UsedAt   *time.Time
+}
+
+//LoginChallenge is a model representing a login, whose password was confirmed and which waits for a TOTP code. Only the hash of the challenge is stored
+type LoginChallenge struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint      `gorm:"type:bigint;not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	//Attempts - the number of codes, checked with the challenge. After too many of them the challenge cannot be used anymore
+	Attempts uint `gorm:"type:integer;not null;default:0"`
+}
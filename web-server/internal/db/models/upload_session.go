@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+//UploadSession is a model representing an upload of a file, which is sent in chunks
+type UploadSession struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	FileName  string `gorm:"type:varchar(256);not null"`
+	Size      int64  `gorm:"type:bigint;not null"`
+	OwnerID   uint   `gorm:"type:Integer;not null"`
+	GroupID   uint   `gorm:"type:Integer;not null"`
+	FolderID  uint   `gorm:"type:Integer;not null;default:0"`
+}
+
+//UploadChunk is a model representing a part of an upload session, which was already received
+type UploadChunk struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	SessionID uint  `gorm:"type:Integer;not null"`
+	Offset    int64 `gorm:"type:bigint;not null"`
+	Size      int64 `gorm:"type:bigint;not null"`
+}
@@ -9,4 +9,13 @@ type User struct {
 	UpdatedAt time.Time
 	Username  string `gorm:"type:varchar(20);not null"`
 	Password  string `gorm:"type:varchar(256);not null"`
+	//PasswordChangedAt - the moment of the last password change, truncated to seconds. The access tokens, issued before it, are rejected
+	PasswordChangedAt *time.Time
+	//IsAdmin - whether the user administers the whole server
+	IsAdmin bool `gorm:"type:boolean;not null;default:false"`
+	//SuspendedAt - when an administrator suspended the user. Suspended users cannot login or use their tokens
+	SuspendedAt      *time.Time
+	SuspensionReason string `gorm:"type:varchar(256)"`
+	//SuspendedUntil - when the suspension is lifted automatically. Nil means that it lasts until an administrator lifts it
+	SuspendedUntil *time.Time
 }
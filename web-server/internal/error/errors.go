@@ -1,6 +1,10 @@
 package error
 
-import "github.com/pkg/errors"
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 //ClientError represents a problem with client request
 type ClientError struct {
@@ -43,6 +47,23 @@ func NewItemNotFoundError(description string) *ItemNotFoundError {
 	}
 }
 
+//QuotaExceededError - used when a request would make a user or a group use more storage than allowed
+type QuotaExceededError struct {
+	Err error
+}
+
+//Error - returns description of the error
+func (e *QuotaExceededError) Error() string {
+	return e.Err.Error()
+}
+
+//NewQuotaExceededError - creates an instance of QuotaExceededError
+func NewQuotaExceededError(description string) *QuotaExceededError {
+	return &QuotaExceededError{
+		Err: errors.New(description),
+	}
+}
+
 //ServerError represents a problem with server
 type ServerError struct {
 	Err error
@@ -66,3 +87,23 @@ func NewServerErrorWrap(err error, description string) *ServerError {
 		Err: errors.Wrapf(err, description),
 	}
 }
+
+//TooManyRequestsError - used when a client has made too many failed attempts and has to wait before trying again
+type TooManyRequestsError struct {
+	Err error
+	//RetryAfter - how long the client has to wait. 0 means that the limit is permanent
+	RetryAfter time.Duration
+}
+
+//Error - returns description of the error
+func (e *TooManyRequestsError) Error() string {
+	return e.Err.Error()
+}
+
+//NewTooManyRequestsError - creates an instance of TooManyRequestsError
+func NewTooManyRequestsError(description string, retryAfter time.Duration) *TooManyRequestsError {
+	return &TooManyRequestsError{
+		Err:        errors.New(description),
+		RetryAfter: retryAfter,
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/gin-gonic/gin"
+)
+
+//AdminFilter - middleware for filtering the requests of users, who arent administrators of the server
+type AdminFilter interface {
+	Admin(c *gin.Context)
+}
+
+//AdminFilterImpl - implementation of AdminFilter
+type AdminFilterImpl struct {
+	adminDAO dao.AdminDAO
+}
+
+//NewAdminFilterImpl - creates a new instance of AdminFilterImpl
+func NewAdminFilterImpl(adminDAO dao.AdminDAO) *AdminFilterImpl {
+	return &AdminFilterImpl{
+		adminDAO: adminDAO,
+	}
+}
+
+//Admin - lets through only the requests of administrators. It has to be used after AuthzFilter, which identifies the user
+func (f *AdminFilterImpl) Admin(c *gin.Context) {
+	userID, err := common.GetIDFromContext(c)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "The user of the request isnt identified")
+		return
+	}
+
+	isAdmin, err := f.adminDAO.IsAdmin(userID)
+	if err != nil {
+		log.Println(err)
+		abortWithError(c, http.StatusInternalServerError, "Problem with the server, please try again later")
+		return
+	} else if !isAdmin {
+		abortWithError(c, http.StatusForbidden, "Only administrators can access this resource")
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	mw "github.com/danielpenchev98/UShare/web-server/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AdminFilter", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		adminDAO *dao_mocks.MockAdminDAO
+		req      *http.Request
+	)
+
+	const userID = 1
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		adminDAO = dao_mocks.NewMockAdminDAO(controller)
+		filter := mw.NewAdminFilterImpl(adminDAO)
+
+		router = gin.Default()
+		admin := router.Group("/admin").Use(func(c *gin.Context) {
+			c.Set("userID", uint(userID))
+			c.Next()
+		}, filter.Admin)
+		admin.GET("/users", func(c *gin.Context) {
+			c.JSON(http.StatusOK, "")
+		})
+
+		recorder = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/admin/users", nil)
+	})
+
+	When("the lookup of the administrator role fails", func() {
+		BeforeEach(func() {
+			adminDAO.EXPECT().
+				IsAdmin(uint(userID)).
+				Return(false, myerr.NewServerError("test error"))
+		})
+
+		It("returns error response", func() {
+			router.ServeHTTP(recorder, req)
+			assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server")
+		})
+	})
+
+	When("the user isnt an administrator", func() {
+		BeforeEach(func() {
+			adminDAO.EXPECT().
+				IsAdmin(uint(userID)).
+				Return(false, nil)
+		})
+
+		It("returns forbidden", func() {
+			router.ServeHTTP(recorder, req)
+			assertErrorResponse(recorder, http.StatusForbidden, "Only administrators can access this resource")
+		})
+	})
+
+	When("the user is an administrator", func() {
+		BeforeEach(func() {
+			adminDAO.EXPECT().
+				IsAdmin(uint(userID)).
+				Return(true, nil)
+		})
+
+		It("lets the request through", func() {
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+	})
+})
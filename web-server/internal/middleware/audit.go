@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	"github.com/gin-gonic/gin"
+)
+
+//maxAuditTargetLength - the maximum length of the recorded group name and username. The longer ones are cut
+const maxAuditTargetLength = 256
+
+//Auditor - middleware for recording the requests in the audit log
+type Auditor interface {
+	Audit(action string) gin.HandlerFunc
+}
+
+//AuditorImpl - implementation of Auditor
+type AuditorImpl struct {
+	auditDAO dao.AuditDAO
+}
+
+//NewAuditorImpl - creates a new instance of AuditorImpl
+func NewAuditorImpl(auditDAO dao.AuditDAO) *AuditorImpl {
+	return &AuditorImpl{
+		auditDAO: auditDAO,
+	}
+}
+
+//auditPayload - the targets of the request, which are looked for in its json body
+type auditPayload struct {
+	GroupName string `json:"group_name"`
+	FileID    uint   `json:"file_id"`
+	Username  string `json:"username"`
+}
+
+//Audit - creates a handler, which records the action in the audit log, after the request is handled
+//the targets of the action are taken from the query and the json body of the request or from the context, if the handler put them there
+//the response isnt affected, if the event cannot be recorded
+func (a *AuditorImpl) Audit(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var targets auditPayload
+		body, _ := peekBody(c)
+		json.Unmarshal(body, &targets)
+		if groupName := c.Query("group_name"); groupName != "" {
+			targets.GroupName = groupName
+		}
+		if fileID, err := strconv.ParseUint(c.Query("file_id"), 10, 32); err == nil {
+			targets.FileID = uint(fileID)
+		}
+		if username := c.Query("username"); username != "" {
+			targets.Username = username
+		}
+
+		c.Next()
+
+		if groupName := c.GetString(common.AuditGroupKey); groupName != "" {
+			targets.GroupName = groupName
+		}
+		if fileID, ok := c.Get(common.AuditFileIDKey); ok {
+			targets.FileID, _ = fileID.(uint)
+		}
+
+		var actorID uint
+		if userID, ok := c.Get("userID"); ok {
+			actorID, _ = userID.(uint)
+		}
+
+		outcome := models.OutcomeSuccess
+		if c.Writer.Status() >= http.StatusBadRequest {
+			outcome = models.OutcomeFailure
+		}
+
+		event := models.AuditEvent{
+			ActorID:    actorID,
+			Action:     action,
+			GroupName:  truncate(targets.GroupName, maxAuditTargetLength),
+			FileID:     targets.FileID,
+			TargetUser: truncate(targets.Username, maxAuditTargetLength),
+			IP:         common.GetClientIP(c),
+			Outcome:    outcome,
+			StatusCode: c.Writer.Status(),
+		}
+
+		if err := a.auditDAO.RecordEvent(event); err != nil {
+			log.Printf("Couldnt record the audit event [%s]. Reason: %v\n", action, err)
+		}
+	}
+}
+
+func truncate(value string, maxLength int) string {
+	if len(value) > maxLength {
+		return value[:maxLength]
+	}
+	return value
+}
@@ -0,0 +1,120 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	mw "github.com/danielpenchev98/UShare/web-server/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Auditor", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		auditDAO *dao_mocks.MockAuditDAO
+		event    models.AuditEvent
+	)
+
+	const userID = 1
+
+	BeforeEach(func() {
+		controller := gomock.NewController(GinkgoT())
+		auditDAO = dao_mocks.NewMockAuditDAO(controller)
+		auditor := mw.NewAuditorImpl(auditDAO)
+
+		router = gin.Default()
+		protected := router.Group("/protected").Use(func(c *gin.Context) {
+			c.Set("userID", uint(userID))
+			c.Next()
+		})
+		protected.DELETE("/group/file/deletion", auditor.Audit("file.delete"), func(c *gin.Context) {
+			var rq common.FileRequestPayload
+			if err := c.ShouldBindJSON(&rq); err != nil || rq.FileID == 0 {
+				c.JSON(http.StatusBadRequest, "")
+				return
+			}
+			c.JSON(http.StatusOK, "")
+		})
+		protected.POST("/group/file/upload", auditor.Audit("file.upload"), func(c *gin.Context) {
+			common.SetAuditTarget(c, "group", 7)
+			c.JSON(http.StatusCreated, "")
+		})
+
+		recorder = httptest.NewRecorder()
+	})
+
+	When("the request succeeds", func() {
+		BeforeEach(func() {
+			auditDAO.EXPECT().
+				RecordEvent(gomock.Any()).
+				DoAndReturn(func(recorded models.AuditEvent) error {
+					event = recorded
+					return nil
+				})
+		})
+
+		It("records the actor and the targets from the body, which is still readable by the handler", func() {
+			req, _ := http.NewRequest("DELETE", "/protected/group/file/deletion", strings.NewReader(`{"group_name":"group","file_id":5}`))
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			Expect(event.ActorID).To(Equal(uint(userID)))
+			Expect(event.Action).To(Equal("file.delete"))
+			Expect(event.GroupName).To(Equal("group"))
+			Expect(event.FileID).To(Equal(uint(5)))
+			Expect(event.Outcome).To(Equal(models.OutcomeSuccess))
+			Expect(event.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("records the targets, put in the context by the handler", func() {
+			req, _ := http.NewRequest("POST", "/protected/group/file/upload", nil)
+			router.ServeHTTP(recorder, req)
+
+			Expect(event.GroupName).To(Equal("group"))
+			Expect(event.FileID).To(Equal(uint(7)))
+		})
+	})
+
+	When("the request fails", func() {
+		BeforeEach(func() {
+			auditDAO.EXPECT().
+				RecordEvent(gomock.Any()).
+				DoAndReturn(func(recorded models.AuditEvent) error {
+					event = recorded
+					return nil
+				})
+		})
+
+		It("records the failure", func() {
+			req, _ := http.NewRequest("DELETE", "/protected/group/file/deletion?group_name=group", strings.NewReader(`{}`))
+			router.ServeHTTP(recorder, req)
+
+			Expect(event.GroupName).To(Equal("group"))
+			Expect(event.Outcome).To(Equal(models.OutcomeFailure))
+			Expect(event.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("the event cannot be recorded", func() {
+		BeforeEach(func() {
+			auditDAO.EXPECT().
+				RecordEvent(gomock.Any()).
+				Return(errors.New("test-error"))
+		})
+
+		It("doesnt affect the response", func() {
+			req, _ := http.NewRequest("POST", "/protected/group/file/upload", nil)
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+	})
+})
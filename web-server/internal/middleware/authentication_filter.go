@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/danielpenchev98/UShare/web-server/api/common"
 	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,16 +20,23 @@ type AuthzFilter interface {
 //AuthzFilterImpl - implementation of AuthorizationFilter
 type AuthzFilterImpl struct {
 	jwtCreator auth.JwtCreator
+	tokenDAO   dao.TokenDAO
+	adminDAO   dao.AdminDAO
 }
 
 //NewAuthzFilterImpl - creates a new instance of AuthzFilterImpl
-func NewAuthzFilterImpl(creator auth.JwtCreator) *AuthzFilterImpl {
+func NewAuthzFilterImpl(creator auth.JwtCreator, tokenDAO dao.TokenDAO, adminDAO dao.AdminDAO) *AuthzFilterImpl {
 	return &AuthzFilterImpl{
 		jwtCreator: creator,
+		tokenDAO:   tokenDAO,
+		adminDAO:   adminDAO,
 	}
 }
 
 //Authz - creating handlers for filtering unauthorized requests
+//the tokens, which were revoked or belong to deleted users, are also rejected
+//the requests of suspended users are rejected with 403, even if their tokens are valid
+//besides JWTs, personal access tokens are accepted for the routes, allowed by their scopes and groups
 func (f *AuthzFilterImpl) Authz(c *gin.Context) {
 	clientToken := c.Request.Header.Get("Authorization")
 	if clientToken == "" {
@@ -50,6 +60,11 @@ func (f *AuthzFilterImpl) Authz(c *gin.Context) {
 		return
 	}
 
+	if auth.IsPersonalAccessToken(clientToken) {
+		f.authzPersonalAccessToken(c, clientToken)
+		return
+	}
+
 	claims, err := f.jwtCreator.ValidateToken(clientToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, common.ErrorResponse{
@@ -60,6 +75,44 @@ func (f *AuthzFilterImpl) Authz(c *gin.Context) {
 		return
 	}
 
+	revoked, err := f.tokenDAO.IsAccessTokenRevoked(claims.Id, claims.UserID, time.Unix(claims.IssuedAt, 0))
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, common.ErrorResponse{
+			ErrorCode: http.StatusInternalServerError,
+			ErrorMsg:  "Problem with the server, please try again later",
+		})
+		c.Abort()
+		return
+	} else if revoked {
+		c.JSON(http.StatusUnauthorized, common.ErrorResponse{
+			ErrorCode: http.StatusUnauthorized,
+			ErrorMsg:  "Revoked Authorization token. Please login again",
+		})
+		c.Abort()
+		return
+	}
+
+	if f.isSuspended(c, claims.UserID) {
+		return
+	}
+
 	c.Set("userID", claims.UserID)
+	c.Set("tokenID", claims.Id)
+	c.Set("tokenExpiresAt", time.Unix(claims.ExpiresAt, 0))
 	c.Next()
 }
+
+//isSuspended - checks if the user is suspended and if so, aborts the request
+func (f *AuthzFilterImpl) isSuspended(c *gin.Context, userID uint) bool {
+	suspension, err := f.adminDAO.GetSuspension(userID)
+	if err != nil {
+		log.Println(err)
+		abortWithError(c, http.StatusInternalServerError, "Problem with the server, please try again later")
+		return true
+	} else if suspension != nil {
+		abortWithError(c, http.StatusForbidden, common.SuspensionMessage(suspension.Reason, suspension.Until))
+		return true
+	}
+	return false
+}
@@ -3,12 +3,19 @@ package middleware_test
 import (
 	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"time"
 
 	"github.com/danielpenchev98/UShare/web-server/api/common"
 	"github.com/danielpenchev98/UShare/web-server/internal/auth"
 	authMock "github.com/danielpenchev98/UShare/web-server/internal/auth/auth_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao/dao_mocks"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
 	mw "github.com/danielpenchev98/UShare/web-server/internal/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -22,6 +29,16 @@ func setupRouter(filter mw.AuthzFilter) *gin.Engine {
 	v1.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, "")
 	})
+	v1.POST("/group/folder", func(c *gin.Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+	v1.POST("/group/file/upload", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, "")
+	})
+	v1.GET("/user/tokens", func(c *gin.Context) {
+		c.JSON(http.StatusOK, "")
+	})
 	return r
 }
 
@@ -38,13 +55,17 @@ var _ = Describe("AuthzFilter", func() {
 		router     *gin.Engine
 		recorder   *httptest.ResponseRecorder
 		jwtCreator *authMock.MockJwtCreator
+		tokenDAO   *dao_mocks.MockTokenDAO
+		adminDAO   *dao_mocks.MockAdminDAO
 	)
 
 	BeforeEach(func() {
 		controller := gomock.NewController(GinkgoT())
 
 		jwtCreator = authMock.NewMockJwtCreator(controller)
-		filter := mw.NewAuthzFilterImpl(jwtCreator)
+		tokenDAO = dao_mocks.NewMockTokenDAO(controller)
+		adminDAO = dao_mocks.NewMockAdminDAO(controller)
+		filter := mw.NewAuthzFilterImpl(jwtCreator, tokenDAO, adminDAO)
 		router = setupRouter(filter)
 		recorder = httptest.NewRecorder()
 	})
@@ -95,18 +116,258 @@ var _ = Describe("AuthzFilter", func() {
 					})
 					Context("and token is succeessfully verified", func() {
 						BeforeEach(func() {
+							claims := &auth.JwtClaim{UserID: 1}
+							claims.Id = "token-id"
+							claims.IssuedAt = 100
 							jwtCreator.EXPECT().
 								ValidateToken(gomock.Any()).
-								Return(&auth.JwtClaim{
-									UserID: 1,
-								}, nil)
+								Return(claims, nil)
+						})
+
+						Context("and the revocation check fails", func() {
+							BeforeEach(func() {
+								tokenDAO.EXPECT().
+									IsAccessTokenRevoked("token-id", uint(1), time.Unix(100, 0)).
+									Return(false, myerr.NewServerError("test error"))
+							})
+
+							It("returns error response", func() {
+								router.ServeHTTP(recorder, req)
+								assertErrorResponse(recorder, http.StatusInternalServerError, "Problem with the server")
+							})
+						})
+
+						Context("and the token is revoked", func() {
+							BeforeEach(func() {
+								tokenDAO.EXPECT().
+									IsAccessTokenRevoked("token-id", uint(1), time.Unix(100, 0)).
+									Return(true, nil)
+							})
+
+							It("returns error response", func() {
+								router.ServeHTTP(recorder, req)
+								assertErrorResponse(recorder, http.StatusUnauthorized, "Revoked Authorization token")
+							})
+						})
+
+						Context("and the token isnt revoked", func() {
+							BeforeEach(func() {
+								tokenDAO.EXPECT().
+									IsAccessTokenRevoked("token-id", uint(1), time.Unix(100, 0)).
+									Return(false, nil)
+							})
+
+							Context("and the user is suspended", func() {
+								BeforeEach(func() {
+									adminDAO.EXPECT().
+										GetSuspension(uint(1)).
+										Return(&dao.Suspension{Reason: "spam"}, nil)
+								})
+
+								It("returns forbidden", func() {
+									router.ServeHTTP(recorder, req)
+									assertErrorResponse(recorder, http.StatusForbidden, "The account is suspended. Reason: spam")
+								})
+							})
+
+							Context("and the user isnt suspended", func() {
+								BeforeEach(func() {
+									adminDAO.EXPECT().
+										GetSuspension(uint(1)).
+										Return(nil, nil)
+								})
+
+								It("returns success", func() {
+									router.ServeHTTP(recorder, req)
+									Expect(recorder.Code).To(Equal(http.StatusOK))
+								})
+							})
+						})
+					})
+				})
+
+				Context("with personal access token", func() {
+					const token = auth.PersonalAccessTokenPrefix + "sometoken"
+
+					var patInfo dao.PersonalAccessTokenInfo
+
+					BeforeEach(func() {
+						req.Header.Set("Authorization", "Bearer "+token)
+						patInfo = dao.PersonalAccessTokenInfo{
+							PersonalAccessToken: models.PersonalAccessToken{UserID: 1},
+							ScopeList:           []string{models.ScopeRead},
+						}
+						jwtCreator.EXPECT().
+							ValidateToken(gomock.Any()).
+							Times(0)
+					})
+
+					Context("which doesnt exist or is expired", func() {
+						BeforeEach(func() {
+							tokenDAO.EXPECT().
+								UsePersonalAccessToken(auth.HashRefreshToken(token)).
+								Return(dao.PersonalAccessTokenInfo{}, myerr.NewItemNotFoundError("test error"))
+						})
+
+						It("returns error response", func() {
+							router.ServeHTTP(recorder, req)
+							assertErrorResponse(recorder, http.StatusUnauthorized, "Invalid Authorization token")
+						})
+					})
+
+					Context("whose user is suspended", func() {
+						BeforeEach(func() {
+							until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+							tokenDAO.EXPECT().
+								UsePersonalAccessToken(auth.HashRefreshToken(token)).
+								Return(patInfo, nil)
+							adminDAO.EXPECT().
+								GetSuspension(uint(1)).
+								Return(&dao.Suspension{Reason: "spam", Until: &until}, nil)
 						})
 
-						It("returns success", func() {
+						It("returns forbidden", func() {
 							router.ServeHTTP(recorder, req)
-							Expect(recorder.Code).To(Equal(http.StatusOK))
+							assertErrorResponse(recorder, http.StatusForbidden, "The account is suspended until 2030-01-02T03:04:05Z. Reason: spam")
+						})
+					})
+
+					Context("which is valid", func() {
+						JustBeforeEach(func() {
+							tokenDAO.EXPECT().
+								UsePersonalAccessToken(auth.HashRefreshToken(token)).
+								Return(patInfo, nil)
+							adminDAO.EXPECT().
+								GetSuspension(uint(1)).
+								Return(nil, nil)
+						})
+
+						Context("and has the required scope", func() {
+							It("returns success", func() {
+								router.ServeHTTP(recorder, req)
+								Expect(recorder.Code).To(Equal(http.StatusOK))
+							})
+						})
+
+						Context("and is used for managing the account", func() {
+							BeforeEach(func() {
+								req, _ = http.NewRequest("GET", "/protected/user/tokens", nil)
+								req.Header.Set("Authorization", "Bearer "+token)
+							})
+
+							It("returns error response", func() {
+								router.ServeHTTP(recorder, req)
+								assertErrorResponse(recorder, http.StatusForbidden, "cannot be used for managing the account")
+							})
 						})
 
+						Context("and doesnt have the required scope", func() {
+							BeforeEach(func() {
+								req, _ = http.NewRequest("POST", "/protected/group/file/upload?group_name=first", nil)
+								req.Header.Set("Authorization", "Bearer "+token)
+							})
+
+							It("returns error response", func() {
+								router.ServeHTTP(recorder, req)
+								assertErrorResponse(recorder, http.StatusForbidden, "doesnt have the required scope")
+							})
+						})
+
+						Context("and is limited to groups", func() {
+							const body = `{"group_name":"first","path":"reports"}`
+
+							BeforeEach(func() {
+								patInfo.ScopeList = []string{models.ScopeWrite}
+								patInfo.GroupRestricted = true
+								patInfo.Groups = []string{"first"}
+							})
+
+							Context("and the request is for one of them", func() {
+								BeforeEach(func() {
+									req, _ = http.NewRequest("POST", "/protected/group/folder", strings.NewReader(body))
+									req.Header.Set("Authorization", "Bearer "+token)
+								})
+
+								It("returns success and keeps the body", func() {
+									router.ServeHTTP(recorder, req)
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+									Expect(recorder.Body.String()).To(Equal(body))
+								})
+							})
+
+							Context("and the request is for another group", func() {
+								BeforeEach(func() {
+									req, _ = http.NewRequest("POST", "/protected/group/folder?group_name=first", strings.NewReader(`{"group_name":"second"}`))
+									req.Header.Set("Authorization", "Bearer "+token)
+								})
+
+								It("returns error response", func() {
+									router.ServeHTTP(recorder, req)
+									assertErrorResponse(recorder, http.StatusForbidden, "are different")
+								})
+							})
+
+							Context("and the body is for another group", func() {
+								BeforeEach(func() {
+									req, _ = http.NewRequest("POST", "/protected/group/folder", strings.NewReader(`{"group_name":"second"}`))
+									req.Header.Set("Authorization", "Bearer "+token)
+								})
+
+								It("returns error response", func() {
+									router.ServeHTTP(recorder, req)
+									assertErrorResponse(recorder, http.StatusForbidden, "cannot be used in group [second]")
+								})
+							})
+
+							Context("and the request doesnt specify a group", func() {
+								BeforeEach(func() {
+									req, _ = http.NewRequest("POST", "/protected/group/folder", strings.NewReader(`{"path":"reports"}`))
+									req.Header.Set("Authorization", "Bearer "+token)
+								})
+
+								It("returns error response", func() {
+									router.ServeHTTP(recorder, req)
+									assertErrorResponse(recorder, http.StatusForbidden, "doesnt specify a group")
+								})
+							})
+
+							Context("and the body is padded past the maximum size", func() {
+								BeforeEach(func() {
+									padded := `{"group_name":"second","path":"` + strings.Repeat("a", 1<<20) + `"}`
+									req, _ = http.NewRequest("POST", "/protected/group/folder?group_name=first", strings.NewReader(padded))
+									req.Header.Set("Authorization", "Bearer "+token)
+								})
+
+								It("returns error response", func() {
+									router.ServeHTTP(recorder, req)
+									assertErrorResponse(recorder, http.StatusForbidden, "cannot be larger than")
+								})
+							})
+
+							Context("and the body isnt valid json", func() {
+								BeforeEach(func() {
+									req, _ = http.NewRequest("POST", "/protected/group/folder?group_name=first", strings.NewReader(`{"group_name":"second"`))
+									req.Header.Set("Authorization", "Bearer "+token)
+								})
+
+								It("returns error response", func() {
+									router.ServeHTTP(recorder, req)
+									assertErrorResponse(recorder, http.StatusForbidden, "must be a valid json object")
+								})
+							})
+
+							Context("and the request uploads a file to one of them", func() {
+								BeforeEach(func() {
+									req, _ = http.NewRequest("POST", "/protected/group/file/upload?group_name=first", strings.NewReader("raw content"))
+									req.Header.Set("Authorization", "Bearer "+token)
+								})
+
+								It("returns success", func() {
+									router.ServeHTTP(recorder, req)
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+								})
+							})
+						})
 					})
 				})
 			})
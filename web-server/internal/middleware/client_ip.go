@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/gin-gonic/gin"
+)
+
+//ClientIPResolver - middleware for resolving the IP of the client, which sent the request
+type ClientIPResolver interface {
+	ResolveClientIP(c *gin.Context)
+}
+
+//ClientIPResolverImpl - implementation of ClientIPResolver
+type ClientIPResolverImpl struct {
+	trustedProxies []*net.IPNet
+}
+
+//NewClientIPResolverImpl - creates a new instance of ClientIPResolverImpl
+func NewClientIPResolverImpl(trustedProxies []*net.IPNet) *ClientIPResolverImpl {
+	return &ClientIPResolverImpl{
+		trustedProxies: trustedProxies,
+	}
+}
+
+//ResolveClientIP - stores the IP of the client in the context. The X-Forwarded-For header is read only
+//when the request comes from a trusted proxy, otherwise the address of the connection is used
+func (r *ClientIPResolverImpl) ResolveClientIP(c *gin.Context) {
+	ip := remoteIP(c.Request.RemoteAddr)
+	if r.isTrusted(ip) {
+		hops := strings.Split(c.GetHeader("X-Forwarded-For"), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+
+			ip = hop
+			if !r.isTrusted(hop) {
+				break
+			}
+		}
+	}
+
+	c.Set(common.ClientIPKey, ip)
+	c.Next()
+}
+
+func (r *ClientIPResolverImpl) isTrusted(ip string) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, proxy := range r.trustedProxies {
+		if proxy.Contains(parsedIP) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	mw "github.com/danielpenchev98/UShare/web-server/internal/middleware"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientIPResolver", func() {
+	var (
+		router   *gin.Engine
+		recorder *httptest.ResponseRecorder
+		req      *http.Request
+		clientIP string
+	)
+
+	BeforeEach(func() {
+		_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+		resolver := mw.NewClientIPResolverImpl([]*net.IPNet{proxies})
+
+		router = gin.Default()
+		router.Use(resolver.ResolveClientIP)
+		router.GET("/ip", func(c *gin.Context) {
+			clientIP = common.GetClientIP(c)
+			c.JSON(http.StatusOK, "")
+		})
+
+		recorder = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/ip", nil)
+		req.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.2")
+		req.Header.Set("X-Real-Ip", "1.2.3.4")
+	})
+
+	When("the request doesnt come from a trusted proxy", func() {
+		BeforeEach(func() {
+			req.RemoteAddr = "192.168.0.1:40000"
+		})
+
+		It("ignores the forwarded headers", func() {
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(clientIP).To(Equal("192.168.0.1"))
+		})
+	})
+
+	When("the request comes from a trusted proxy", func() {
+		BeforeEach(func() {
+			req.RemoteAddr = "10.0.0.1:40000"
+		})
+
+		It("uses the first untrusted address in the forwarded header", func() {
+			router.ServeHTTP(recorder, req)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(clientIP).To(Equal("1.2.3.4"))
+		})
+	})
+})
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/danielpenchev98/UShare/web-server/api/common"
+	"github.com/danielpenchev98/UShare/web-server/internal/auth"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/dao"
+	"github.com/danielpenchev98/UShare/web-server/internal/db/models"
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/gin-gonic/gin"
+)
+
+//maxGroupPayloadSize - how many bytes of the body are read, while looking for the group or the other targets of the request
+const maxGroupPayloadSize = 1 << 20
+
+//uploadRoute - prefix of the routes, which can be accessed with the upload scope
+const uploadRoute = "/group/file/upload"
+
+//rawBodyRoutes - routes, whose body is the content of a file instead of json. They specify the group only in the query
+var rawBodyRoutes = []string{"/group/file/upload", "/group/file/upload/session/chunk"}
+
+//adminRoute - prefix of the routes for the administration of the server, which cannot be accessed with personal access tokens
+const adminRoute = "/admin/"
+
+//accountRoutes - routes for managing the account, which cannot be accessed with personal access tokens
+var accountRoutes = []string{"/user/tokens", "/user/logout", "/user/2fa", "/user/password", "/group/user/deletion"}
+
+//authzPersonalAccessToken - filters the requests with personal access token, which is expired or isnt allowed to access the route
+//the token is allowed to access the route, if one of its scopes allows it and the group of the request is one of the groups of the token
+func (f *AuthzFilterImpl) authzPersonalAccessToken(c *gin.Context, clientToken string) {
+	token, err := f.tokenDAO.UsePersonalAccessToken(auth.HashRefreshToken(clientToken))
+	switch err.(type) {
+	case nil:
+		break
+	case *myerr.ItemNotFoundError:
+		abortWithError(c, http.StatusUnauthorized, "Invalid Authorization token")
+		return
+	default:
+		log.Println(err)
+		abortWithError(c, http.StatusInternalServerError, "Problem with the server, please try again later")
+		return
+	}
+
+	if f.isSuspended(c, token.UserID) {
+		return
+	}
+
+	route := c.FullPath()
+	for _, accountRoute := range accountRoutes {
+		if strings.Contains(route, accountRoute) {
+			abortWithError(c, http.StatusForbidden, "Personal access tokens cannot be used for managing the account")
+			return
+		}
+	}
+
+	if strings.Contains(route, adminRoute) {
+		abortWithError(c, http.StatusForbidden, "Personal access tokens cannot be used for the administration of the server")
+		return
+	}
+
+	if !scopesAllow(token.ScopeList, c.Request.Method, route) {
+		abortWithError(c, http.StatusForbidden, "The personal access token doesnt have the required scope")
+		return
+	}
+
+	if token.GroupRestricted {
+		if err = checkTokenGroups(c, token); err != nil {
+			abortWithError(c, http.StatusForbidden, err.Error())
+			return
+		}
+	}
+
+	c.Set("userID", token.UserID)
+	c.Next()
+}
+
+func scopesAllow(scopes []string, method string, route string) bool {
+	for _, scope := range scopes {
+		switch scope {
+		case models.ScopeWrite:
+			return true
+		case models.ScopeRead:
+			if method == http.MethodGet || method == http.MethodHead {
+				return true
+			}
+		case models.ScopeUpload:
+			if strings.Contains(route, uploadRoute) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//checkTokenGroups - checks if the group of the request is one of the groups of the token
+//the group is taken from both the query and the json body, which must specify the same group, if both specify one
+//only the requests, which dont change anything, can be without a group
+func checkTokenGroups(c *gin.Context, token dao.PersonalAccessTokenInfo) error {
+	groupName, err := requestGroupName(c)
+	if err != nil {
+		return err
+	}
+
+	if groupName == "" && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return myerr.NewClientError("The personal access token is limited to groups, but the request doesnt specify a group")
+	}
+
+	if groupName != "" && !containsString(token.Groups, groupName) {
+		return myerr.NewClientError(fmt.Sprintf("The personal access token cannot be used in group [%s]", groupName))
+	}
+	return nil
+}
+
+//requestGroupName - extracts the group name from the query and the json body of the request
+//the body must be a json object, which isnt larger than maxGroupPayloadSize, so the group checked here is the one, the handler uses
+//the routes with raw content in the body specify the group only in the query
+func requestGroupName(c *gin.Context) (string, error) {
+	groupName := c.Query("group_name")
+	if isRawBodyRoute(c.FullPath()) {
+		return groupName, nil
+	}
+
+	body, complete := peekBody(c)
+	if !complete {
+		return "", myerr.NewClientError(fmt.Sprintf("The body of a request with personal access token, limited to groups, cannot be larger than %d bytes", maxGroupPayloadSize))
+	} else if len(bytes.TrimSpace(body)) == 0 {
+		return groupName, nil
+	}
+
+	var payload common.GroupPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", myerr.NewClientError("The body of a request with personal access token, limited to groups, must be a valid json object")
+	}
+
+	if groupName != "" && payload.GroupName != "" && groupName != payload.GroupName {
+		return "", myerr.NewClientError("The group in the query and the group in the body of the request are different")
+	} else if payload.GroupName != "" {
+		groupName = payload.GroupName
+	}
+	return groupName, nil
+}
+
+func isRawBodyRoute(route string) bool {
+	for _, rawBodyRoute := range rawBodyRoutes {
+		if strings.HasSuffix(route, rawBodyRoute) {
+			return true
+		}
+	}
+	return false
+}
+
+//peekBody - reads the beginning of the body of the request and puts it back, so the handlers can still read the whole body
+//at most maxGroupPayloadSize bytes are returned. The body is complete, if it isnt longer than that and was read without error
+func peekBody(c *gin.Context) ([]byte, bool) {
+	if c.Request.Body == nil {
+		return nil, true
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxGroupPayloadSize+1))
+	c.Request.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if err != nil {
+		return nil, false
+	} else if len(body) > maxGroupPayloadSize {
+		return body[:maxGroupPayloadSize], false
+	}
+	return body, true
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func abortWithError(c *gin.Context, statusCode int, message string) {
+	c.JSON(statusCode, common.ErrorResponse{
+		ErrorCode: statusCode,
+		ErrorMsg:  message,
+	})
+	c.Abort()
+}
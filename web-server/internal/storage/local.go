@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+)
+
+//LocalBackend - implementation of Backend, which keeps the files on the local filesystem
+//every key is mapped to a path relative to the root directory
+type LocalBackend struct {
+	rootDir string
+}
+
+//NewLocalBackend - creates an instance of LocalBackend
+func NewLocalBackend(rootDir string) *LocalBackend {
+	return &LocalBackend{
+		rootDir: rootDir,
+	}
+}
+
+//Put - saves the content under the given key, replacing any existing content
+func (i *LocalBackend) Put(key string, content io.Reader) error {
+	dst, err := i.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return myerr.NewServerErrorWrap(err, "Couldnt create the parent directory of the file")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), ".upload-")
+	if err != nil {
+		return myerr.NewServerErrorWrap(err, "Couldnt create a temporary file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return myerr.NewServerErrorWrap(err, "Couldnt write the content of the file")
+	}
+
+	if err = tmp.Close(); err != nil {
+		return myerr.NewServerErrorWrap(err, "Couldnt write the content of the file")
+	}
+
+	if err = os.Rename(tmp.Name(), dst); err != nil {
+		return myerr.NewServerErrorWrap(err, "Couldnt move the file to its destination")
+	}
+	return nil
+}
+
+//Get - opens the content, stored under the given key
+func (i *LocalBackend) Get(key string) (Object, error) {
+	src, err := i.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(src)
+	if os.IsNotExist(err) {
+		return nil, myerr.NewItemNotFoundError("File content does not exist")
+	} else if err != nil {
+		return nil, myerr.NewServerErrorWrap(err, "Couldnt open the file")
+	}
+	return file, nil
+}
+
+//Delete - deletes the content, stored under the given key. Deleting missing content isnt an error
+func (i *LocalBackend) Delete(key string) error {
+	src, err := i.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(src); err != nil && !os.IsNotExist(err) {
+		return myerr.NewServerErrorWrap(err, "Couldnt delete the file")
+	}
+	return nil
+}
+
+//Stat - returns metadata about the content, stored under the given key
+func (i *LocalBackend) Stat(key string) (ObjectInfo, error) {
+	src, err := i.resolve(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		return ObjectInfo{}, myerr.NewItemNotFoundError("File content does not exist")
+	} else if err != nil {
+		return ObjectInfo{}, myerr.NewServerErrorWrap(err, "Couldnt get information about the file")
+	}
+
+	return ObjectInfo{
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+//DeletePrefix - deletes all contents, whose keys start with the given prefix
+func (i *LocalBackend) DeletePrefix(prefix string) error {
+	dir, err := i.resolve(prefix)
+	if err != nil {
+		return err
+	}
+
+	if err = os.RemoveAll(dir); err != nil {
+		return myerr.NewServerErrorWrap(err, "Couldnt delete the directory")
+	}
+	return nil
+}
+
+//Move - moves the content from one key to another, replacing any existing content under the destination key
+func (i *LocalBackend) Move(srcKey string, dstKey string) error {
+	src, err := i.resolve(srcKey)
+	if err != nil {
+		return err
+	}
+
+	dst, err := i.resolve(dstKey)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return myerr.NewServerErrorWrap(err, "Couldnt create the parent directory of the file")
+	}
+
+	if err = os.Rename(src, dst); os.IsNotExist(err) {
+		return myerr.NewItemNotFoundError("File content does not exist")
+	} else if err != nil {
+		return myerr.NewServerErrorWrap(err, "Couldnt move the file")
+	}
+	return nil
+}
+
+func (i *LocalBackend) resolve(key string) (string, error) {
+	cleaned := filepath.Clean("/" + filepath.FromSlash(key))
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", myerr.NewClientError("Invalid storage key")
+	}
+	return filepath.Join(i.rootDir, cleaned), nil
+}
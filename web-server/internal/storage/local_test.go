@@ -0,0 +1,108 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LocalBackend", func() {
+	const (
+		groupName = "test-group"
+		fileID    = 3
+		content   = "test-content"
+	)
+
+	var (
+		backend *storage.LocalBackend
+		rootDir string
+		key     string
+	)
+
+	BeforeEach(func() {
+		rootDir, _ = ioutil.TempDir("", "local-backend")
+		backend = storage.NewLocalBackend(rootDir)
+		key = storage.FileKey(groupName, fileID)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(rootDir)
+	})
+
+	When("content is stored", func() {
+		BeforeEach(func() {
+			Expect(backend.Put(key, strings.NewReader(content))).To(Succeed())
+		})
+
+		It("keeps the layout <root>/<group>/<file id>", func() {
+			data, err := ioutil.ReadFile(path.Join(rootDir, groupName, "3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(content))
+		})
+
+		It("can be read back", func() {
+			object, err := backend.Get(key)
+			Expect(err).NotTo(HaveOccurred())
+			defer object.Close()
+
+			data, _ := ioutil.ReadAll(object)
+			Expect(string(data)).To(Equal(content))
+		})
+
+		It("returns its size", func() {
+			info, err := backend.Stat(key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(len(content))))
+		})
+
+		It("can be deleted", func() {
+			Expect(backend.Delete(key)).To(Succeed())
+			_, err := backend.Get(key)
+			_, ok := err.(*myerr.ItemNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("can be moved under another key", func() {
+			Expect(backend.Move(key, storage.BlobKey("abcdef"))).To(Succeed())
+
+			data, err := ioutil.ReadFile(path.Join(rootDir, "blobs", "ab", "abcdef"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(content))
+
+			_, err = os.Stat(path.Join(rootDir, groupName, "3"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("is deleted together with the group", func() {
+			Expect(backend.DeletePrefix(storage.GroupPrefix(groupName))).To(Succeed())
+			_, err := os.Stat(path.Join(rootDir, groupName))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	When("content doesnt exist", func() {
+		It("returns not found error on retrieval", func() {
+			_, err := backend.Stat(key)
+			_, ok := err.(*myerr.ItemNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("doesnt fail on deletion", func() {
+			Expect(backend.Delete(key)).To(Succeed())
+		})
+	})
+
+	When("key tries to escape the root directory", func() {
+		It("returns client error", func() {
+			err := backend.Put("../escaped", strings.NewReader(content))
+			_, ok := err.(*myerr.ClientError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,452 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+)
+
+const (
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFormat   = "20060102T150405Z"
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+)
+
+//S3Config - configuration needed for the communication with an S3-compatible object storage
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+//S3Backend - implementation of Backend, which keeps the files in a bucket of an S3-compatible object storage
+//The objects are addressed path-style - <endpoint>/<bucket>/<key>
+type S3Backend struct {
+	config S3Config
+	client *http.Client
+}
+
+//NewS3Backend - creates an instance of S3Backend
+func NewS3Backend(config S3Config, client *http.Client) *S3Backend {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+
+	return &S3Backend{
+		config: config,
+		client: client,
+	}
+}
+
+//Put - uploads the content as an object with the given key
+func (i *S3Backend) Put(key string, content io.Reader) error {
+	body, size, cleanup, err := sizedReader(content)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	//the caller remains the owner of the content, so the transport shouldnt close it
+	req, err := i.newRequest(http.MethodPut, key, nil, ioutil.NopCloser(body))
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+
+	resp, err := i.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+//Get - opens the object with the given key. The object is fetched lazily, using range requests
+func (i *S3Backend) Get(key string) (Object, error) {
+	info, err := i.Stat(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Object{
+		backend: i,
+		key:     key,
+		size:    info.Size,
+	}, nil
+}
+
+//Delete - deletes the object with the given key. Deleting a missing object isnt an error
+func (i *S3Backend) Delete(key string) error {
+	req, err := i.newRequest(http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := i.do(req)
+	if _, ok := err.(*myerr.ItemNotFoundError); ok {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+//Stat - returns metadata about the object with the given key
+func (i *S3Backend) Stat(key string) (ObjectInfo, error) {
+	req, err := i.newRequest(http.MethodHead, key, nil, nil)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	resp, err := i.do(req)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	resp.Body.Close()
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return ObjectInfo{
+		Size:    resp.ContentLength,
+		ModTime: modTime,
+	}, nil
+}
+
+//DeletePrefix - deletes all objects, whose keys start with the given prefix
+func (i *S3Backend) DeletePrefix(prefix string) error {
+	keys, err := i.listKeys(prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err = i.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Move - copies the object on the server side and deletes the original
+func (i *S3Backend) Move(srcKey string, dstKey string) error {
+	req, err := i.newRequest(http.MethodPut, dstKey, nil, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Amz-Copy-Source", "/"+uriEncode(i.config.Bucket, true)+"/"+uriEncode(srcKey, false))
+
+	resp, err := i.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return i.Delete(srcKey)
+}
+
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+}
+
+func (i *S3Backend) listKeys(prefix string) ([]string, error) {
+	var (
+		keys              []string
+		continuationToken string
+	)
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		req, err := i.newRequest(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := i.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, myerr.NewServerErrorWrap(err, "Couldnt parse the list of objects")
+		}
+
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (i *S3Backend) newRequest(method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	canonicalURI := "/" + uriEncode(i.config.Bucket, true)
+	if key != "" {
+		canonicalURI += "/" + uriEncode(key, false)
+	}
+
+	rawURL := i.config.Endpoint + canonicalURI
+	if len(query) != 0 {
+		rawURL += "?" + canonicalQuery(query)
+	}
+
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return nil, myerr.NewServerErrorWrap(err, "Couldnt create a request to the object storage")
+	}
+	return req, nil
+}
+
+func (i *S3Backend) do(req *http.Request) (*http.Response, error) {
+	i.sign(req, time.Now().UTC())
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, myerr.NewServerErrorWrap(err, "Couldnt reach the object storage")
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, myerr.NewItemNotFoundError("File content does not exist")
+	} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, myerr.NewServerError(fmt.Sprintf("Object storage responded with status %d: %s", resp.StatusCode, msg))
+	}
+	return resp, nil
+}
+
+//sign - signs the request with AWS Signature Version 4, leaving the payload unsigned
+func (i *S3Backend) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format(amzDateFormat)
+	shortDate := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	signedHeaders := []string{"host"}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			signedHeaders = append(signedHeaders, lower)
+		}
+	}
+	sort.Strings(signedHeaders)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		unsignedPayload,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, i.config.Region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+i.config.SecretKey), shortDate)
+	signingKey = hmacSHA256(signingKey, i.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, i.config.AccessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+//s3Object - Object, which reads the content of an S3 object on demand, starting from the current offset
+type s3Object struct {
+	backend *S3Backend
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.offset >= o.size {
+		return 0, io.EOF
+	}
+
+	if o.body == nil {
+		req, err := o.backend.newRequest(http.MethodGet, o.key, nil, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", o.offset))
+
+		resp, err := o.backend.do(req)
+		if err != nil {
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = o.offset + offset
+	case io.SeekEnd:
+		target = o.size + offset
+	default:
+		return 0, myerr.NewServerError("Invalid seek whence")
+	}
+
+	if target < 0 {
+		return 0, myerr.NewServerError("Negative seek position")
+	}
+
+	if target != o.offset && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.offset = target
+	return target, nil
+}
+
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+//sizedReader - S3 requires the length of the payload in advance
+//so readers of unknown size are spooled to a temporary file first
+func sizedReader(content io.Reader) (io.Reader, int64, func(), error) {
+	if seeker, ok := content.(io.Seeker); ok {
+		current, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			end, err := seeker.Seek(0, io.SeekEnd)
+			if err == nil {
+				if _, err = seeker.Seek(current, io.SeekStart); err == nil {
+					return content, end - current, func() {}, nil
+				}
+			}
+		}
+	}
+
+	tmp, err := ioutil.TempFile("", "s3-upload-")
+	if err != nil {
+		return nil, 0, nil, myerr.NewServerErrorWrap(err, "Couldnt create a temporary file")
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+
+	size, err := io.Copy(tmp, content)
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, myerr.NewServerErrorWrap(err, "Couldnt buffer the content of the file")
+	}
+
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, 0, nil, myerr.NewServerErrorWrap(err, "Couldnt buffer the content of the file")
+	}
+	return tmp, size, cleanup, nil
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+//uriEncode - encodes every byte except the unreserved characters, as required by the signature
+func uriEncode(value string, encodeSlash bool) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			builder.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			builder.WriteByte(b)
+		default:
+			builder.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+	return builder.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
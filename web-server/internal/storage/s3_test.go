@@ -0,0 +1,233 @@
+package storage_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	myerr "github.com/danielpenchev98/UShare/web-server/internal/error"
+	"github.com/danielpenchev98/UShare/web-server/internal/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+//fakeS3 - in-process imitation of the subset of the S3 API, used by S3Backend
+type fakeS3 struct {
+	bucket   string
+	pageSize int
+	mutex    sync.Mutex
+	objects  map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if parts[0] != f.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(parts) == 1 {
+		f.list(w, r)
+		return
+	}
+
+	key := parts[1]
+	switch r.Method {
+	case http.MethodPut:
+		if source := r.Header.Get("X-Amz-Copy-Source"); source != "" {
+			data, ok := f.objects[strings.TrimPrefix(source, "/"+f.bucket+"/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			f.objects[key] = data
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		f.objects[key] = data
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, key, time.Now(), bytes.NewReader(data))
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	start, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+
+	keys := make([]string, 0)
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	type content struct {
+		Key string `xml:"Key"`
+	}
+	result := struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+		Contents              []content `xml:"Contents"`
+	}{}
+
+	end := start + f.pageSize
+	if end < len(keys) {
+		result.IsTruncated = true
+		result.NextContinuationToken = strconv.Itoa(end)
+	} else {
+		end = len(keys)
+	}
+
+	for _, key := range keys[start:end] {
+		result.Contents = append(result.Contents, content{Key: key})
+	}
+	xml.NewEncoder(w).Encode(result)
+}
+
+var _ = Describe("S3Backend", func() {
+	const (
+		bucket    = "test-bucket"
+		groupName = "test-group"
+		content   = "0123456789"
+	)
+
+	var (
+		fake    *fakeS3
+		server  *httptest.Server
+		backend *storage.S3Backend
+		key     string
+	)
+
+	BeforeEach(func() {
+		fake = &fakeS3{
+			bucket:   bucket,
+			pageSize: 2,
+			objects:  make(map[string][]byte),
+		}
+		server = httptest.NewServer(fake)
+		backend = storage.NewS3Backend(storage.S3Config{
+			Endpoint:  server.URL,
+			Region:    "eu-central-1",
+			Bucket:    bucket,
+			AccessKey: "access",
+			SecretKey: "secret",
+		}, server.Client())
+		key = storage.FileKey(groupName, 1)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	When("content is stored", func() {
+		BeforeEach(func() {
+			Expect(backend.Put(key, strings.NewReader(content))).To(Succeed())
+		})
+
+		It("is uploaded as an object in the bucket", func() {
+			Expect(string(fake.objects[key])).To(Equal(content))
+		})
+
+		It("can be read back from an arbitrary offset", func() {
+			object, err := backend.Get(key)
+			Expect(err).NotTo(HaveOccurred())
+			defer object.Close()
+
+			_, err = object.Seek(4, io.SeekStart)
+			Expect(err).NotTo(HaveOccurred())
+			data, err := ioutil.ReadAll(object)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(content[4:]))
+		})
+
+		It("returns its size", func() {
+			info, err := backend.Stat(key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(len(content))))
+		})
+
+		It("can be deleted", func() {
+			Expect(backend.Delete(key)).To(Succeed())
+			Expect(fake.objects).NotTo(HaveKey(key))
+		})
+
+		It("can be moved under another key", func() {
+			Expect(backend.Move(key, storage.BlobKey("abcdef"))).To(Succeed())
+			Expect(fake.objects).NotTo(HaveKey(key))
+			Expect(string(fake.objects[storage.BlobKey("abcdef")])).To(Equal(content))
+		})
+	})
+
+	When("content of unknown size is stored", func() {
+		It("is uploaded as a whole", func() {
+			reader := ioutil.NopCloser(strings.NewReader(content))
+			Expect(backend.Put(key, reader)).To(Succeed())
+			Expect(string(fake.objects[key])).To(Equal(content))
+		})
+	})
+
+	When("all objects of a group are deleted", func() {
+		BeforeEach(func() {
+			for i := uint(1); i <= 5; i++ {
+				backend.Put(storage.FileKey(groupName, i), strings.NewReader(content))
+			}
+			backend.Put(storage.FileKey(groupName+"2", 1), strings.NewReader(content))
+		})
+
+		It("deletes every page of the listing, but only in that group", func() {
+			Expect(backend.DeletePrefix(storage.GroupPrefix(groupName))).To(Succeed())
+			Expect(fake.objects).To(HaveLen(1))
+			Expect(fake.objects).To(HaveKey(storage.FileKey(groupName+"2", 1)))
+		})
+	})
+
+	When("object doesnt exist", func() {
+		It("returns not found error", func() {
+			_, err := backend.Get(key)
+			_, ok := err.(*myerr.ItemNotFoundError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	When("object storage rejects the credentials", func() {
+		BeforeEach(func() {
+			backend = storage.NewS3Backend(storage.S3Config{
+				Endpoint:  server.URL,
+				Bucket:    bucket,
+				AccessKey: "other",
+			}, server.Client())
+		})
+
+		It("returns server error", func() {
+			err := backend.Put(key, strings.NewReader(content))
+			_, ok := err.(*myerr.ServerError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+//go:generate mockgen --source=storage.go --destination storage_mocks/storage.go --package storage_mocks
+
+//Backend - interface, abstracting the place where the contents of the uploaded files are kept
+type Backend interface {
+	Put(key string, content io.Reader) error
+	Get(key string) (Object, error)
+	Delete(key string) error
+	Stat(key string) (ObjectInfo, error)
+	DeletePrefix(prefix string) error
+	Move(srcKey string, dstKey string) error
+}
+
+//Object - content of a stored file, which can be read from an arbitrary offset
+type Object interface {
+	io.Reader
+	io.Seeker
+	io.Closer
+}
+
+//ObjectInfo - metadata about a stored file
+type ObjectInfo struct {
+	Size    int64
+	ModTime time.Time
+}
+
+//GroupPrefix - returns the prefix, under which all files of a group are stored
+func GroupPrefix(groupName string) string {
+	return groupName + "/"
+}
+
+//FileKey - returns the key, under which the content of a file is stored
+func FileKey(groupName string, fileID uint) string {
+	return fmt.Sprintf("%s%d", GroupPrefix(groupName), fileID)
+}
+
+//UploadPrefix - returns the prefix, under which the chunks of an upload session are stored
+func UploadPrefix(groupName string, sessionID uint) string {
+	return fmt.Sprintf("%suploads/%d/", GroupPrefix(groupName), sessionID)
+}
+
+//ChunkKey - returns the key, under which a chunk of an upload session is stored
+func ChunkKey(groupName string, sessionID uint, offset int64, size int64) string {
+	return fmt.Sprintf("%s%d-%d", UploadPrefix(groupName, sessionID), offset, size)
+}
+
+//BlobKey - returns the key, under which a content, shared by all files with the same checksum, is stored
+func BlobKey(checksum string) string {
+	if len(checksum) < 2 {
+		return "blobs/" + checksum
+	}
+	return fmt.Sprintf("blobs/%s/%s", checksum[:2], checksum)
+}